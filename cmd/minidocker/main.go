@@ -31,5 +31,12 @@ func main() {
 		return
 	}
 
+	// Phase 15: pod infra (pause) process.
+	// It holds the pod's shared namespaces and does nothing else.
+	if os.Getenv(envutil.PauseEnvVar) == "1" {
+		runtime.RunPause()
+		return
+	}
+
 	cli.Execute()
 }
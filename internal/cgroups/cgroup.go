@@ -8,6 +8,9 @@
 // - CPU 限制 (cpu.max)
 // - 进程数限制 (pids.max)
 //
+// Phase 14 新增：
+// - 设备白名单（BPF_PROG_TYPE_CGROUP_DEVICE 程序，替代 v1 的 devices.allow/deny）
+//
 // 设计决策：
 // - 仅支持 cgroup v2（统一层级），v1 兼容可作为后续可选阶段
 // - cgroup 路径格式：/sys/fs/cgroup/minidocker/<container-id>/
@@ -54,16 +57,31 @@ type CgroupConfig struct {
 	// 对应 pids.max
 	// 0 表示不限制
 	PidsLimit int64 `json:"pidsLimit,omitempty"`
+
+	// Devices 设备访问白名单（Phase 14 新增）
+	// 非空时在 Create 阶段向容器 cgroup 挂载 eBPF 设备过滤程序，
+	// 未列出的设备访问（open/mknod）一律返回 EPERM。
+	// 为空表示不做设备限制（--privileged）。
+	Devices []DeviceRule `json:"devices,omitempty"`
 }
 
 // IsEmpty 检查是否有任何资源限制配置。
-// 如果所有限制都为零值，则认为没有配置资源限制。
+// 如果所有限制都为零值且没有设备白名单，则认为无需创建 cgroup。
 func (c *CgroupConfig) IsEmpty() bool {
 	if c == nil {
 		return true
 	}
-	return c.Memory == 0 && c.MemorySwap == 0 &&
-		c.CPUQuota == 0 && c.PidsLimit == 0
+	return !c.HasLimits() && len(c.Devices) == 0
+}
+
+// HasLimits 检查是否配置了资源限制（memory/cpu/pids）。
+// 设备白名单不计入：它不需要启用任何控制器。
+func (c *CgroupConfig) HasLimits() bool {
+	if c == nil {
+		return false
+	}
+	return c.Memory != 0 || c.MemorySwap != 0 ||
+		c.CPUQuota != 0 || c.PidsLimit != 0
 }
 
 // Manager 定义 cgroup 管理器接口。
@@ -97,15 +115,33 @@ type Stats struct {
 	MemoryLimit   int64 `json:"memoryLimit"`
 	MemoryMaxUsed int64 `json:"memoryMaxUsed,omitempty"`
 
+	// Phase 20 新增：memory.stat 分项
+	MemoryStat MemoryStat `json:"memoryStat"`
+
 	// CPU
 	CPUUsage int64 `json:"cpuUsage"` // 纳秒
 
+	// Phase 20 新增：cpu.stat 的用户态/内核态时间与 cpu.max 限流统计
+	CPUUserUsage     int64 `json:"cpuUserUsage"`   // 纳秒
+	CPUSystemUsage   int64 `json:"cpuSystemUsage"` // 纳秒
+	CPUPeriods       int64 `json:"cpuPeriods,omitempty"`
+	CPUThrottled     int64 `json:"cpuThrottledPeriods,omitempty"`
+	CPUThrottledTime int64 `json:"cpuThrottledTime,omitempty"` // 纳秒
+
 	// Pids
 	PidsCount int64 `json:"pidsCount"`
 	PidsLimit int64 `json:"pidsLimit"`
 
 	// OOM
 	OOMKillCount int64 `json:"oomKillCount,omitempty"`
+
+	// Block I/O（Phase 20 新增：io.stat 中所有设备的累计读写字节数，以及按设备的明细）
+	IOReadBytes  int64           `json:"ioReadBytes"`
+	IOWriteBytes int64           `json:"ioWriteBytes"`
+	IO           []IODeviceStats `json:"io,omitempty"`
+
+	// Phase 20 新增：PSI 压力信息（内核未开启 PSI 时为 nil）
+	Pressure *PressureStats `json:"pressure,omitempty"`
 }
 
 // NewManager 创建一个新的 cgroup 管理器。
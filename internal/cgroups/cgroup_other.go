@@ -12,15 +12,36 @@ type CgroupConfig struct {
 	CPUQuota   int64 `json:"cpuQuota,omitempty"`
 	CPUPeriod  int64 `json:"cpuPeriod,omitempty"`
 	PidsLimit  int64 `json:"pidsLimit,omitempty"`
+
+	Devices []DeviceRule `json:"devices,omitempty"`
+}
+
+// DeviceRule 描述设备白名单中的一条规则。
+type DeviceRule struct {
+	Type        string `json:"type"`
+	Major       int64  `json:"major"`
+	Minor       int64  `json:"minor"`
+	Permissions string `json:"permissions"`
 }
 
+// Wildcard 表示主/次设备号通配。
+const Wildcard int64 = -1
+
 // IsEmpty 检查是否有任何资源限制配置。
 func (c *CgroupConfig) IsEmpty() bool {
 	if c == nil {
 		return true
 	}
-	return c.Memory == 0 && c.MemorySwap == 0 &&
-		c.CPUQuota == 0 && c.PidsLimit == 0
+	return !c.HasLimits() && len(c.Devices) == 0
+}
+
+// HasLimits 检查是否配置了资源限制（memory/cpu/pids）。
+func (c *CgroupConfig) HasLimits() bool {
+	if c == nil {
+		return false
+	}
+	return c.Memory != 0 || c.MemorySwap != 0 ||
+		c.CPUQuota != 0 || c.PidsLimit != 0
 }
 
 // Manager 定义 cgroup 管理器接口。
@@ -35,13 +56,23 @@ type Manager interface {
 
 // Stats 保存 cgroup 统计信息。
 type Stats struct {
-	MemoryUsage   int64 `json:"memoryUsage"`
-	MemoryLimit   int64 `json:"memoryLimit"`
-	MemoryMaxUsed int64 `json:"memoryMaxUsed,omitempty"`
-	CPUUsage      int64 `json:"cpuUsage"`
-	PidsCount     int64 `json:"pidsCount"`
-	PidsLimit     int64 `json:"pidsLimit"`
-	OOMKillCount  int64 `json:"oomKillCount,omitempty"`
+	MemoryUsage      int64           `json:"memoryUsage"`
+	MemoryLimit      int64           `json:"memoryLimit"`
+	MemoryMaxUsed    int64           `json:"memoryMaxUsed,omitempty"`
+	MemoryStat       MemoryStat      `json:"memoryStat"`
+	CPUUsage         int64           `json:"cpuUsage"`
+	CPUUserUsage     int64           `json:"cpuUserUsage"`
+	CPUSystemUsage   int64           `json:"cpuSystemUsage"`
+	CPUPeriods       int64           `json:"cpuPeriods,omitempty"`
+	CPUThrottled     int64           `json:"cpuThrottledPeriods,omitempty"`
+	CPUThrottledTime int64           `json:"cpuThrottledTime,omitempty"`
+	PidsCount        int64           `json:"pidsCount"`
+	PidsLimit        int64           `json:"pidsLimit"`
+	OOMKillCount     int64           `json:"oomKillCount,omitempty"`
+	IOReadBytes      int64           `json:"ioReadBytes"`
+	IOWriteBytes     int64           `json:"ioWriteBytes"`
+	IO               []IODeviceStats `json:"io,omitempty"`
+	Pressure         *PressureStats  `json:"pressure,omitempty"`
 }
 
 // NewManager 创建一个新的 cgroup 管理器。
@@ -140,3 +140,19 @@ func CheckRequiredControllers(root string, config *CgroupConfig) error {
 func GetCgroupPath(containerID string) string {
 	return filepath.Join(CgroupMinidockerPrefix, containerID)
 }
+
+// GetPodCgroupPath 返回 pod 的 cgroup 父路径（Phase 15 新增）。
+//
+// 格式: minidocker/pod-<pod-id>，pod 内所有容器的 cgroup 都创建在其下。
+func GetPodCgroupPath(podID string) string {
+	return filepath.Join(CgroupMinidockerPrefix, "pod-"+podID)
+}
+
+// GetCgroupPathWithParent 返回指定父路径下的容器 cgroup 路径（Phase 15 新增）。
+// parent 为空时等价于 GetCgroupPath。
+func GetCgroupPathWithParent(parent, containerID string) string {
+	if parent == "" {
+		return GetCgroupPath(containerID)
+	}
+	return filepath.Join(parent, containerID)
+}
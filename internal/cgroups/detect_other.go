@@ -38,3 +38,16 @@ func CheckRequiredControllers(root string, config *CgroupConfig) error {
 func GetCgroupPath(containerID string) string {
 	return CgroupMinidockerPrefix + "/" + containerID
 }
+
+// GetPodCgroupPath 返回 pod 的 cgroup 父路径。
+func GetPodCgroupPath(podID string) string {
+	return CgroupMinidockerPrefix + "/pod-" + podID
+}
+
+// GetCgroupPathWithParent 返回指定父路径下的容器 cgroup 路径。
+func GetCgroupPathWithParent(parent, containerID string) string {
+	if parent == "" {
+		return GetCgroupPath(containerID)
+	}
+	return parent + "/" + containerID
+}
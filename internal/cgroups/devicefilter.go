@@ -0,0 +1,286 @@
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroup v2 没有 devices.allow/deny 接口文件，设备访问控制改由
+// BPF_PROG_TYPE_CGROUP_DEVICE 程序完成：内核在 open/mknod 设备时调用程序，
+// 返回 1 放行、返回 0 拒绝（EPERM）。
+//
+// 这里手工生成 eBPF 指令（对齐 runc libcontainer/cgroups/devices/ebpf），
+// 避免引入完整的 BPF 库依赖。程序上下文为 struct bpf_cgroup_dev_ctx：
+//
+//	struct bpf_cgroup_dev_ctx {
+//	    __u32 access_type; /* (access << 16) | type */
+//	    __u32 major;
+//	    __u32 minor;
+//	};
+
+// bpfInsn 对应内核 struct bpf_insn（8 字节）。
+type bpfInsn struct {
+	Code uint8
+	Regs uint8 // 低 4 位 dst_reg，高 4 位 src_reg（小端布局）
+	Off  int16
+	Imm  int32
+}
+
+// 程序使用的寄存器
+const (
+	regR0 = 0 // 返回值
+	regR1 = 1 // 上下文指针（读取完毕后复用为临时寄存器）
+	regR2 = 2 // 设备类型
+	regR3 = 3 // 访问类型
+	regR4 = 4 // 主设备号
+	regR5 = 5 // 次设备号
+)
+
+func insnLdxW(dst, src uint8, off int16) bpfInsn {
+	return bpfInsn{Code: unix.BPF_LDX | unix.BPF_MEM | unix.BPF_W, Regs: dst | src<<4, Off: off}
+}
+
+func insnAlu32Imm(op, dst uint8, imm int32) bpfInsn {
+	return bpfInsn{Code: unix.BPF_ALU | op | unix.BPF_K, Regs: dst, Imm: imm}
+}
+
+func insnMov32Reg(dst, src uint8) bpfInsn {
+	return bpfInsn{Code: unix.BPF_ALU | unix.BPF_MOV | unix.BPF_X, Regs: dst | src<<4}
+}
+
+func insnJneImm(dst uint8, imm int32) bpfInsn {
+	return bpfInsn{Code: unix.BPF_JMP | unix.BPF_JNE | unix.BPF_K, Regs: dst, Imm: imm}
+}
+
+func insnJneReg(dst, src uint8) bpfInsn {
+	return bpfInsn{Code: unix.BPF_JMP | unix.BPF_JNE | unix.BPF_X, Regs: dst | src<<4}
+}
+
+func insnExit() bpfInsn {
+	return bpfInsn{Code: unix.BPF_JMP | unix.BPF_EXIT}
+}
+
+// isJne 判断指令是否为条件跳转（需要回填偏移）。
+func (i bpfInsn) isJne() bool {
+	return i.Code&0x07 == unix.BPF_JMP && i.Code&0xf0 == unix.BPF_JNE
+}
+
+// buildDeviceFilter 根据白名单生成设备过滤程序。
+//
+// 程序结构：
+//
+//	序言：从上下文读取 type/access/major/minor 到 R2~R5
+//	每条规则一个块：任一条件不匹配则跳到下一块，全部匹配则返回 1
+//	结尾：返回 0（默认拒绝）
+func buildDeviceFilter(rules []DeviceRule) ([]bpfInsn, error) {
+	prog := []bpfInsn{
+		insnLdxW(regR2, regR1, 0),
+		insnAlu32Imm(unix.BPF_AND, regR2, 0xffff),
+		insnLdxW(regR3, regR1, 0),
+		insnAlu32Imm(unix.BPF_RSH, regR3, 16),
+		insnLdxW(regR4, regR1, 4),
+		insnLdxW(regR5, regR1, 8),
+	}
+
+	for _, rule := range rules {
+		block, err := deviceRuleBlock(rule)
+		if err != nil {
+			return nil, err
+		}
+		prog = append(prog, block...)
+	}
+
+	prog = append(prog,
+		insnAlu32Imm(unix.BPF_MOV, regR0, 0),
+		insnExit(),
+	)
+	return prog, nil
+}
+
+// deviceRuleBlock 生成单条规则的指令块。
+func deviceRuleBlock(rule DeviceRule) ([]bpfInsn, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	var block []bpfInsn
+
+	switch rule.Type {
+	case "c":
+		block = append(block, insnJneImm(regR2, unix.BPF_DEVCG_DEV_CHAR))
+	case "b":
+		block = append(block, insnJneImm(regR2, unix.BPF_DEVCG_DEV_BLOCK))
+	}
+
+	var access int32
+	for _, c := range rule.Permissions {
+		switch c {
+		case 'r':
+			access |= unix.BPF_DEVCG_ACC_READ
+		case 'w':
+			access |= unix.BPF_DEVCG_ACC_WRITE
+		case 'm':
+			access |= unix.BPF_DEVCG_ACC_MKNOD
+		}
+	}
+	allAccess := int32(unix.BPF_DEVCG_ACC_READ | unix.BPF_DEVCG_ACC_WRITE | unix.BPF_DEVCG_ACC_MKNOD)
+	if access != allAccess {
+		// 请求的访问类型必须是允许集合的子集：(access & allowed) == access
+		block = append(block,
+			insnMov32Reg(regR1, regR3),
+			insnAlu32Imm(unix.BPF_AND, regR1, access),
+			insnJneReg(regR1, regR3),
+		)
+	}
+
+	if rule.Major != Wildcard {
+		block = append(block, insnJneImm(regR4, int32(rule.Major)))
+	}
+	if rule.Minor != Wildcard {
+		block = append(block, insnJneImm(regR5, int32(rule.Minor)))
+	}
+
+	block = append(block,
+		insnAlu32Imm(unix.BPF_MOV, regR0, 1),
+		insnExit(),
+	)
+
+	// 回填跳转偏移：不匹配时跳过本块剩余指令
+	for i := range block {
+		if block[i].isJne() {
+			block[i].Off = int16(len(block) - i - 1)
+		}
+	}
+
+	return block, nil
+}
+
+// bpfProgLoadAttr 对应 union bpf_attr 中 BPF_PROG_LOAD 使用的部分。
+type bpfProgLoadAttr struct {
+	progType           uint32
+	insnCnt            uint32
+	insns              uint64
+	license            uint64
+	logLevel           uint32
+	logSize            uint32
+	logBuf             uint64
+	kernVersion        uint32
+	progFlags          uint32
+	progName           [16]byte
+	progIfindex        uint32
+	expectedAttachType uint32
+}
+
+// bpfProgAttachAttr 对应 union bpf_attr 中 BPF_PROG_ATTACH 使用的部分。
+type bpfProgAttachAttr struct {
+	targetFd    uint32
+	attachBpfFd uint32
+	attachType  uint32
+	attachFlags uint32
+}
+
+// loadDeviceFilter 将指令加载为 BPF_PROG_TYPE_CGROUP_DEVICE 程序，返回程序 fd。
+// 首次加载不带 verifier 日志；失败时带日志重试一次，便于定位指令错误。
+func loadDeviceFilter(insns []bpfInsn) (int, error) {
+	fd, err := bpfProgLoad(insns, nil)
+	if err == nil {
+		return fd, nil
+	}
+
+	logBuf := make([]byte, 64*1024)
+	if _, retryErr := bpfProgLoad(insns, logBuf); retryErr != nil {
+		if n := clen(logBuf); n > 0 {
+			return -1, fmt.Errorf("%w (verifier: %s)", retryErr, logBuf[:n])
+		}
+	}
+	return -1, err
+}
+
+// bpfProgLoad 执行 bpf(BPF_PROG_LOAD)。logBuf 非空时启用 verifier 日志。
+func bpfProgLoad(insns []bpfInsn, logBuf []byte) (int, error) {
+	license := []byte("MIT\x00")
+
+	attr := bpfProgLoadAttr{
+		progType: unix.BPF_PROG_TYPE_CGROUP_DEVICE,
+		insnCnt:  uint32(len(insns)),
+		insns:    uint64(uintptr(unsafe.Pointer(&insns[0]))),
+		license:  uint64(uintptr(unsafe.Pointer(&license[0]))),
+	}
+	if len(logBuf) > 0 {
+		attr.logLevel = 1
+		attr.logSize = uint32(len(logBuf))
+		attr.logBuf = uint64(uintptr(unsafe.Pointer(&logBuf[0])))
+	}
+	copy(attr.progName[:], "minidocker_dev")
+
+	fd, _, errno := unix.Syscall(unix.SYS_BPF, unix.BPF_PROG_LOAD,
+		uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	runtime.KeepAlive(insns)
+	runtime.KeepAlive(license)
+	runtime.KeepAlive(logBuf)
+	if errno != 0 {
+		return -1, fmt.Errorf("BPF_PROG_LOAD: %w", errno)
+	}
+	return int(fd), nil
+}
+
+// attachDeviceFilter 将设备过滤程序挂载到 cgroup 目录。
+//
+// 使用 BPF_F_ALLOW_MULTI：不替换祖先 cgroup 上已有的程序（例如 systemd 的设备策略），
+// 内核会依次执行，任一程序拒绝即拒绝。
+func attachDeviceFilter(cgroupDir string, progFd int) error {
+	dirFd, err := unix.Open(cgroupDir, unix.O_DIRECTORY|unix.O_RDONLY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("open cgroup dir %s: %w", cgroupDir, err)
+	}
+	defer unix.Close(dirFd)
+
+	attr := bpfProgAttachAttr{
+		targetFd:    uint32(dirFd),
+		attachBpfFd: uint32(progFd),
+		attachType:  unix.BPF_CGROUP_DEVICE,
+		attachFlags: unix.BPF_F_ALLOW_MULTI,
+	}
+	_, _, errno := unix.Syscall(unix.SYS_BPF, unix.BPF_PROG_ATTACH,
+		uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr))
+	if errno != 0 {
+		return fmt.Errorf("BPF_PROG_ATTACH: %w", errno)
+	}
+	return nil
+}
+
+// setDeviceFilter 生成、加载并挂载设备白名单程序。
+// 程序生命周期由 cgroup 持有：挂载成功后即可关闭 fd，cgroup 删除时程序自动释放。
+func setDeviceFilter(cgroupDir string, rules []DeviceRule) error {
+	insns, err := buildDeviceFilter(rules)
+	if err != nil {
+		return err
+	}
+
+	progFd, err := loadDeviceFilter(insns)
+	if err != nil {
+		return fmt.Errorf("load device filter: %w", err)
+	}
+	defer unix.Close(progFd)
+
+	if err := attachDeviceFilter(cgroupDir, progFd); err != nil {
+		return fmt.Errorf("attach device filter: %w", err)
+	}
+	return nil
+}
+
+// clen 返回以 NUL 结尾的字节串长度。
+func clen(b []byte) int {
+	for i, c := range b {
+		if c == 0 {
+			return i
+		}
+	}
+	return len(b)
+}
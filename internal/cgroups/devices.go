@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wildcard 表示主/次设备号通配（对应 devices.allow 语法中的 "*"）。
+const Wildcard int64 = -1
+
+// DeviceRule 描述设备白名单中的一条规则（Phase 14 新增）。
+// 语义对齐 cgroup v1 devices.allow 与 runc 的 devices.Rule：
+//
+//	c 1:3 rwm   → 允许字符设备 1:3（/dev/null）的读/写/mknod
+//	c 136:* rw  → 允许所有 pts 终端的读写
+type DeviceRule struct {
+	// Type 设备类型："c"（字符设备）、"b"（块设备）或 "a"（全部）
+	Type string `json:"type"`
+
+	// Major 主设备号，Wildcard 表示任意
+	Major int64 `json:"major"`
+
+	// Minor 次设备号，Wildcard 表示任意
+	Minor int64 `json:"minor"`
+
+	// Permissions 访问权限，"rwm" 的子集
+	// r = read, w = write, m = mknod
+	Permissions string `json:"permissions"`
+}
+
+// String 返回 devices.allow 风格的规则描述，用于日志和错误信息。
+func (r DeviceRule) String() string {
+	num := func(n int64) string {
+		if n == Wildcard {
+			return "*"
+		}
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%s %s:%s %s", r.Type, num(r.Major), num(r.Minor), r.Permissions)
+}
+
+// Validate 检查规则的类型和权限是否合法。
+func (r DeviceRule) Validate() error {
+	switch r.Type {
+	case "a", "b", "c":
+	default:
+		return fmt.Errorf("invalid device type %q in rule %q", r.Type, r.String())
+	}
+	if r.Permissions == "" {
+		return fmt.Errorf("empty permissions in device rule %q", r.String())
+	}
+	for _, c := range r.Permissions {
+		if !strings.ContainsRune("rwm", c) {
+			return fmt.Errorf("invalid permission %q in device rule %q", c, r.String())
+		}
+	}
+	return nil
+}
+
+// DefaultDeviceRules 返回容器默认允许访问的设备列表。
+//
+// 与 mountDev 创建的设备节点保持一致（对齐 runc/Docker 的默认白名单）：
+// /dev/null, /dev/zero, /dev/full, /dev/random, /dev/urandom,
+// /dev/tty, /dev/console, /dev/ptmx 以及 /dev/pts/*。
+func DefaultDeviceRules() []DeviceRule {
+	return []DeviceRule{
+		{Type: "c", Major: 1, Minor: 3, Permissions: "rwm"},          // /dev/null
+		{Type: "c", Major: 1, Minor: 5, Permissions: "rwm"},          // /dev/zero
+		{Type: "c", Major: 1, Minor: 7, Permissions: "rwm"},          // /dev/full
+		{Type: "c", Major: 1, Minor: 8, Permissions: "rwm"},          // /dev/random
+		{Type: "c", Major: 1, Minor: 9, Permissions: "rwm"},          // /dev/urandom
+		{Type: "c", Major: 5, Minor: 0, Permissions: "rwm"},          // /dev/tty
+		{Type: "c", Major: 5, Minor: 1, Permissions: "rwm"},          // /dev/console
+		{Type: "c", Major: 5, Minor: 2, Permissions: "rwm"},          // /dev/ptmx
+		{Type: "c", Major: 136, Minor: Wildcard, Permissions: "rwm"}, // /dev/pts/*
+	}
+}
@@ -0,0 +1,176 @@
+package cgroups
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+)
+
+// Phase 20 新增：Stats 的细分统计类型及 cgroup v2 统计文件的解析。
+//
+// 这些文件的格式与平台无关（纯文本），因此不加构建标签；
+// 实际读取只发生在 Linux 的 V2Manager.GetStats 中。
+
+// IODeviceStats 是 io.stat 中单个块设备的统计
+type IODeviceStats struct {
+	Major      int64 `json:"major"`
+	Minor      int64 `json:"minor"`
+	ReadBytes  int64 `json:"readBytes"`
+	WriteBytes int64 `json:"writeBytes"`
+	ReadIOs    int64 `json:"readIOs"`
+	WriteIOs   int64 `json:"writeIOs"`
+}
+
+// MemoryStat 是 memory.stat 的主要分项（字节）
+type MemoryStat struct {
+	Anon         int64 `json:"anon"`
+	File         int64 `json:"file"`
+	Kernel       int64 `json:"kernel"`
+	KernelStack  int64 `json:"kernelStack"`
+	Slab         int64 `json:"slab"`
+	Sock         int64 `json:"sock"`
+	Shmem        int64 `json:"shmem"`
+	InactiveFile int64 `json:"inactiveFile"`
+}
+
+// PSIData 是一行 PSI 数据（avg 为百分比，total 为累计停顿微秒数）
+type PSIData struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  int64   `json:"total"`
+}
+
+// PSIStats 是一个 *.pressure 文件的内容（cpu.pressure 在旧内核上没有 full 行）
+type PSIStats struct {
+	Some PSIData  `json:"some"`
+	Full *PSIData `json:"full,omitempty"`
+}
+
+// PressureStats 汇总 cpu/memory/io 的 PSI（内核未开启 PSI 时对应字段为 nil）
+type PressureStats struct {
+	CPU    *PSIStats `json:"cpu,omitempty"`
+	Memory *PSIStats `json:"memory,omitempty"`
+	IO     *PSIStats `json:"io,omitempty"`
+}
+
+// parseIOStat 解析 io.stat：
+//
+//	<major>:<minor> rbytes=N wbytes=N rios=N wios=N dbytes=N dios=N
+func parseIOStat(data string) []IODeviceStats {
+	var devices []IODeviceStats
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		majorStr, minorStr, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+
+		var dev IODeviceStats
+		dev.Major, _ = strconv.ParseInt(majorStr, 10, 64)
+		dev.Minor, _ = strconv.ParseInt(minorStr, 10, 64)
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			n, _ := strconv.ParseInt(value, 10, 64)
+			switch key {
+			case "rbytes":
+				dev.ReadBytes = n
+			case "wbytes":
+				dev.WriteBytes = n
+			case "rios":
+				dev.ReadIOs = n
+			case "wios":
+				dev.WriteIOs = n
+			}
+		}
+		devices = append(devices, dev)
+	}
+	return devices
+}
+
+// parseFlatKeyed 解析 "key value" 每行一项的文件（cpu.stat、memory.stat、memory.events）
+func parseFlatKeyed(data string) map[string]int64 {
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if n, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			values[fields[0]] = n
+		}
+	}
+	return values
+}
+
+// memoryStatFrom 从 memory.stat 的键值中取出 MemoryStat 分项
+func memoryStatFrom(values map[string]int64) MemoryStat {
+	return MemoryStat{
+		Anon:         values["anon"],
+		File:         values["file"],
+		Kernel:       values["kernel"],
+		KernelStack:  values["kernel_stack"],
+		Slab:         values["slab"],
+		Sock:         values["sock"],
+		Shmem:        values["shmem"],
+		InactiveFile: values["inactive_file"],
+	}
+}
+
+// parsePSI 解析 *.pressure 文件：
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parsePSI(data string) *PSIStats {
+	var stats PSIStats
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		var line PSIData
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "avg10":
+				line.Avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				line.Avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				line.Avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				line.Total, _ = strconv.ParseInt(value, 10, 64)
+			}
+		}
+
+		switch fields[0] {
+		case "some":
+			stats.Some = line
+			found = true
+		case "full":
+			full := line
+			stats.Full = &full
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return &stats
+}
@@ -4,7 +4,6 @@
 package cgroups
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -62,6 +61,15 @@ func (m *V2Manager) Create(cgroupPath string, config *CgroupConfig) error {
 		return err
 	}
 
+	// Phase 14: 挂载设备白名单程序
+	// 仅在 Create 时挂载一次；Update 只调整资源限制，不重复挂载
+	if config != nil && len(config.Devices) > 0 {
+		if err := setDeviceFilter(fullPath, config.Devices); err != nil {
+			_ = os.Remove(fullPath)
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -249,6 +257,7 @@ func (m *V2Manager) Destroy(cgroupPath string) error {
 	}
 
 	// 尝试清理 minidocker 父目录（如果为空）
+	// Phase 15: pod 内容器的父目录是 minidocker/pod-<id>，由 pod rm 负责清理
 	parentPath := filepath.Dir(fullPath)
 	if filepath.Base(parentPath) == CgroupMinidockerPrefix {
 		// 尝试删除，忽略错误（可能还有其他容器）
@@ -278,18 +287,22 @@ func (m *V2Manager) GetStats(cgroupPath string) (*Stats, error) {
 		stats.MemoryMaxUsed, _ = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
 	}
 
+	// Phase 20: memory.stat 分项
+	if data, err := os.ReadFile(filepath.Join(fullPath, "memory.stat")); err == nil {
+		stats.MemoryStat = memoryStatFrom(parseFlatKeyed(string(data)))
+	}
+
 	// CPU 统计
+	// cpu.stat 格式: usage_usec / user_usec / system_usec，
+	// 配置 cpu.max 后还有 nr_periods / nr_throttled / throttled_usec
 	if data, err := os.ReadFile(filepath.Join(fullPath, "cpu.stat")); err == nil {
-		// 解析 cpu.stat 格式: usage_usec <value>
-		scanner := bufio.NewScanner(strings.NewReader(string(data)))
-		for scanner.Scan() {
-			fields := strings.Fields(scanner.Text())
-			if len(fields) >= 2 && fields[0] == "usage_usec" {
-				usec, _ := strconv.ParseInt(fields[1], 10, 64)
-				stats.CPUUsage = usec * 1000 // 转换为纳秒
-				break
-			}
-		}
+		values := parseFlatKeyed(string(data))
+		stats.CPUUsage = values["usage_usec"] * 1000 // 转换为纳秒
+		stats.CPUUserUsage = values["user_usec"] * 1000
+		stats.CPUSystemUsage = values["system_usec"] * 1000
+		stats.CPUPeriods = values["nr_periods"]
+		stats.CPUThrottled = values["nr_throttled"]
+		stats.CPUThrottledTime = values["throttled_usec"] * 1000
 	}
 
 	// Pids 统计
@@ -305,19 +318,40 @@ func (m *V2Manager) GetStats(cgroupPath string) (*Stats, error) {
 
 	// OOM 统计
 	if data, err := os.ReadFile(filepath.Join(fullPath, "memory.events")); err == nil {
-		scanner := bufio.NewScanner(strings.NewReader(string(data)))
-		for scanner.Scan() {
-			fields := strings.Fields(scanner.Text())
-			if len(fields) >= 2 && fields[0] == "oom_kill" {
-				stats.OOMKillCount, _ = strconv.ParseInt(fields[1], 10, 64)
-				break
-			}
+		stats.OOMKillCount = parseFlatKeyed(string(data))["oom_kill"]
+	}
+
+	// Block I/O 统计（Phase 20 新增）
+	if data, err := os.ReadFile(filepath.Join(fullPath, "io.stat")); err == nil {
+		stats.IO = parseIOStat(string(data))
+		for _, dev := range stats.IO {
+			stats.IOReadBytes += dev.ReadBytes
+			stats.IOWriteBytes += dev.WriteBytes
 		}
 	}
 
+	// PSI 压力信息（Phase 20 新增，需要内核 CONFIG_PSI）
+	pressure := &PressureStats{
+		CPU:    readPSI(filepath.Join(fullPath, "cpu.pressure")),
+		Memory: readPSI(filepath.Join(fullPath, "memory.pressure")),
+		IO:     readPSI(filepath.Join(fullPath, "io.pressure")),
+	}
+	if pressure.CPU != nil || pressure.Memory != nil || pressure.IO != nil {
+		stats.Pressure = pressure
+	}
+
 	return stats, nil
 }
 
+// readPSI 读取并解析一个 *.pressure 文件，文件不存在或不可读时返回 nil
+func readPSI(path string) *PSIStats {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return parsePSI(string(data))
+}
+
 // GetPath 返回 cgroup 的完整路径。
 func (m *V2Manager) GetPath(cgroupPath string) string {
 	return filepath.Join(m.root, cgroupPath)
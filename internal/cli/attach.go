@@ -0,0 +1,103 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"minidocker/internal/runtime"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// attach command flags
+	attachDetachKeys string
+	attachNoStdin    bool
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach [OPTIONS] CONTAINER",
+	Short: "连接到运行中容器的标准输入输出",
+	Long: `连接到后台运行容器的标准输入、输出和错误输出。
+
+容器 shim 持有容器的 stdio（-t 时为 PTY master），并在容器目录下提供
+attach socket；多个 attach 可同时连接，输出会广播给所有连接。
+
+仅当容器以 -i 启动时才转发输入。按下 detach 按键序列（默认 Ctrl-P Ctrl-Q）
+断开连接，容器继续运行；容器退出时 attach 以容器退出码退出。
+
+示例:
+  minidocker run -dit --name shell --rootfs /path/to/rootfs -- /bin/sh
+  minidocker attach shell
+  minidocker attach --detach-keys ctrl-x shell
+  minidocker attach --no-stdin shell`,
+	Args: cobra.ExactArgs(1),
+	RunE: attachContainer,
+}
+
+func init() {
+	attachCmd.Flags().StringVar(&attachDetachKeys, "detach-keys", runtime.DefaultDetachKeys, "断开连接的按键序列（例如 ctrl-p,ctrl-q）")
+	attachCmd.Flags().BoolVar(&attachNoStdin, "no-stdin", false, "不转发标准输入")
+}
+
+func attachContainer(cmd *cobra.Command, args []string) error {
+	detachKeys, err := runtime.ParseDetachKeys(attachDetachKeys)
+	if err != nil {
+		return err
+	}
+
+	// 初始化状态存储
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	// 通过 ID/前缀查找容器
+	containerState, err := store.Get(args[0])
+	if err != nil {
+		return fmt.Errorf("container %s not found: %w", args[0], err)
+	}
+
+	// 验证容器正在运行
+	if !containerState.IsRunning() {
+		return fmt.Errorf("container %s is not running", containerState.ID[:12])
+	}
+
+	config, err := state.LoadConfig(containerState.GetContainerDir())
+	if err != nil {
+		return fmt.Errorf("failed to load container config: %w", err)
+	}
+
+	opts := &runtime.AttachOptions{
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		TTY:        config.TTY,
+		DetachKeys: detachKeys,
+	}
+	// 容器未开启 -i 时 stdin 没有接收方
+	if config.Interactive && !attachNoStdin {
+		if config.TTY && !runtime.IsTerminal(os.Stdin) {
+			return fmt.Errorf("the input device is not a TTY")
+		}
+		opts.Stdin = os.Stdin
+	}
+
+	exitCode, err := runtime.Attach(containerState.GetContainerDir(), opts)
+	if errors.Is(err, runtime.ErrDetached) {
+		// raw mode 下需要手动换行
+		fmt.Fprintln(os.Stderr, "\r\nread escape sequence")
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	os.Exit(exitCode)
+	return nil // unreachable
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	attachDetachKeys string
+	attachNoStdin    bool
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach [OPTIONS] CONTAINER",
+	Short: "连接到运行中容器的标准输入输出",
+	Long:  `连接到后台运行容器的标准输入、输出和错误输出。仅支持 Linux 平台。`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker attach only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
+
+func init() {
+	attachCmd.Flags().StringVar(&attachDetachKeys, "detach-keys", "ctrl-p,ctrl-q", "断开连接的按键序列（例如 ctrl-p,ctrl-q）")
+	attachCmd.Flags().BoolVar(&attachNoStdin, "no-stdin", false, "不转发标准输入")
+}
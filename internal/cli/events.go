@@ -0,0 +1,295 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"minidocker/internal/events"
+	"minidocker/internal/state"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// events 命令标志
+	eventsSince   string
+	eventsUntil   string
+	eventsFilters []string
+	eventsFormat  string
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [OPTIONS]",
+	Short: "实时输出生命周期事件",
+	Long: `实时输出容器、镜像和 volume 的生命周期事件。
+
+事件追加写入 <root>/events.jsonl：
+  container  create, start, kill, stop, oom, die, destroy
+  image      pull, load, untag, delete
+  volume     create, destroy
+  network    create, connect, disconnect, destroy
+
+默认只输出命令启动之后的新事件；--since 先回放指定时间之后的历史事件，
+--until 到达指定时间后退出（时间格式同 logs --since）。
+
+--filter 可重复，也可用逗号组合多个条件（key=value）：同一 key 的多个值
+为“或”，不同 key 之间为“且”。支持的 key：type、event、container（ID 前缀
+或名称）、image、volume、network（ID 前缀或名称）。
+
+示例:
+  minidocker events
+  minidocker events --filter type=container,event=die
+  minidocker events --since 1h --until 10m --format json
+  minidocker events --filter container=web --filter event=start --filter event=die`,
+	Args: cobra.NoArgs,
+	RunE: showEvents,
+}
+
+func init() {
+	eventsCmd.Flags().StringVar(&eventsSince, "since", "", "回放此时间之后的事件")
+	eventsCmd.Flags().StringVar(&eventsUntil, "until", "", "输出到此时间后退出")
+	eventsCmd.Flags().StringArrayVarP(&eventsFilters, "filter", "f", nil, "按条件过滤（例如 type=container,event=die）")
+	eventsCmd.Flags().StringVar(&eventsFormat, "format", "", "格式化输出（默认文本，或 json）")
+}
+
+// eventFilter 是解析后的 --filter 条件与时间范围
+type eventFilter struct {
+	terms map[string][]string
+	since time.Time
+	until time.Time
+}
+
+// eventFilterKeys 是 --filter 支持的 key
+var eventFilterKeys = []string{"type", "event", "container", "image", "volume", "network"}
+
+// parseEventFilters 解析 --filter 的值（每个值可包含逗号分隔的多个 key=value）
+func parseEventFilters(values []string) (map[string][]string, error) {
+	terms := make(map[string][]string)
+	for _, value := range values {
+		for _, term := range strings.Split(value, ",") {
+			key, val, ok := strings.Cut(strings.TrimSpace(term), "=")
+			if !ok || val == "" {
+				return nil, fmt.Errorf("invalid filter %q (expected key=value)", term)
+			}
+			if !slices.Contains(eventFilterKeys, key) {
+				return nil, fmt.Errorf("invalid filter key %q (supported: %s)", key, strings.Join(eventFilterKeys, ", "))
+			}
+			terms[key] = append(terms[key], val)
+		}
+	}
+	return terms, nil
+}
+
+func (f *eventFilter) match(ev *events.Event) bool {
+	if !f.since.IsZero() && ev.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && ev.Time.After(f.until) {
+		return false
+	}
+
+	for key, values := range f.terms {
+		matched := false
+		for _, value := range values {
+			if matchEventTerm(ev, key, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchEventTerm 判断事件是否满足单个 key=value 条件
+func matchEventTerm(ev *events.Event, key, value string) bool {
+	switch key {
+	case "type":
+		return ev.Type == value
+	case "event":
+		return ev.Action == value
+	case "container":
+		return ev.Type == events.TypeContainer &&
+			(strings.HasPrefix(ev.ID, value) || ev.Attributes["name"] == value)
+	case "image":
+		if ev.Type == events.TypeImage {
+			return strings.HasPrefix(ev.ID, value) || ev.Attributes["name"] == value
+		}
+		return ev.Type == events.TypeContainer && ev.Attributes["image"] == value
+	case "volume":
+		return ev.Type == events.TypeVolume && ev.ID == value
+	case "network":
+		return ev.Type == events.TypeNetwork &&
+			(strings.HasPrefix(ev.ID, value) || ev.Attributes["name"] == value)
+	}
+	return false
+}
+
+// printEvent 按 --format 输出一条事件
+func printEvent(ev *events.Event) {
+	if eventsFormat == "json" {
+		if data, err := json.Marshal(ev); err == nil {
+			fmt.Println(string(data))
+		}
+		return
+	}
+
+	// 文本格式（对齐 Docker）：<time> <type> <action> <id> (k=v, ...)
+	line := fmt.Sprintf("%s %s %s %s", ev.Time.Format(time.RFC3339Nano), ev.Type, ev.Action, ev.ID)
+	if len(ev.Attributes) > 0 {
+		keys := make([]string, 0, len(ev.Attributes))
+		for key := range ev.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = key + "=" + ev.Attributes[key]
+		}
+		line += " (" + strings.Join(pairs, ", ") + ")"
+	}
+	fmt.Println(line)
+}
+
+func showEvents(cmd *cobra.Command, args []string) error {
+	if eventsFormat != "" && eventsFormat != "json" {
+		return fmt.Errorf("unknown format: %s (supported: json)", eventsFormat)
+	}
+
+	terms, err := parseEventFilters(eventsFilters)
+	if err != nil {
+		return err
+	}
+	filter := &eventFilter{terms: terms}
+
+	now := time.Now()
+	if eventsSince != "" {
+		if filter.since, err = parseLogTime(eventsSince, now); err != nil {
+			return fmt.Errorf("invalid --since value: %w", err)
+		}
+	}
+	if eventsUntil != "" {
+		if filter.until, err = parseLogTime(eventsUntil, now); err != nil {
+			return fmt.Errorf("invalid --until value: %w", err)
+		}
+	}
+
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+	path := events.Path(store.RootDir)
+
+	// 先建立监听，避免回放与跟踪之间漏掉事件
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+	if err := watcher.Add(store.RootDir); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", store.RootDir, err)
+	}
+
+	// 未指定 --since 时只输出新事件：从文件末尾开始
+	var offset int64
+	if filter.since.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			offset = info.Size()
+		}
+	}
+	offset = readNewEvents(path, offset, filter)
+
+	if !filter.until.IsZero() && !time.Now().Before(filter.until) {
+		return nil
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Name == path && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				offset = readNewEvents(path, offset, filter)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Watcher error: %v\n", err)
+
+		case <-ticker.C:
+			if !filter.until.IsZero() && time.Now().After(filter.until) {
+				readNewEvents(path, offset, filter)
+				return nil
+			}
+
+		case <-sigChan:
+			return nil
+		}
+	}
+}
+
+// readNewEvents 输出 offset 之后写入的完整事件，返回新的偏移量
+func readNewEvents(path string, offset int64, filter *eventFilter) int64 {
+	file, err := os.Open(path)
+	if err != nil {
+		return offset
+	}
+	defer file.Close()
+
+	// 事件日志被删除后重建时从头读取
+	if info, err := file.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return offset
+	}
+
+	evs, consumed := events.Decode(data)
+	for i := range evs {
+		if filter.match(&evs[i]) {
+			printEvent(&evs[i])
+		}
+	}
+	return offset + int64(consumed)
+}
+
+// logContainerEvent 记录一条由 CLI 触发的容器事件（Phase 20 新增），附带名称与镜像。
+// name 由调用方传入：rm 删除容器后名称已注销。
+func logContainerEvent(store *state.Store, containerState *state.ContainerState, name, action string, extra map[string]string) {
+	attrs := map[string]string{
+		"name":  name,
+		"image": containerState.ImageRef,
+	}
+	for key, value := range extra {
+		attrs[key] = value
+	}
+	_ = events.Log(store.RootDir, events.TypeContainer, action, containerState.ID, attrs)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [OPTIONS]",
+	Short: "实时输出生命周期事件",
+	Long:  `实时输出容器、镜像和 volume 的生命周期事件。仅支持 Linux 平台。`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker events only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
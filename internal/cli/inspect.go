@@ -9,6 +9,8 @@ import (
 	"os"
 	"time"
 
+	"minidocker/internal/cgroups"
+	"minidocker/internal/runtime"
 	"minidocker/internal/state"
 
 	"github.com/spf13/cobra"
@@ -62,6 +64,9 @@ type StateInfo struct {
 
 	// Phase 7: 网络状态（对齐 Docker inspect 的体验，且便于集成测试验收）
 	NetworkState *state.NetworkState `json:"networkState,omitempty"`
+
+	// Phase 20: 运行中且有 cgroup 的容器的资源统计（内存分项、CPU 限流、块设备 I/O、PSI）
+	ResourceStats *cgroups.Stats `json:"ResourceStats,omitempty"`
 }
 
 // ConfigInfo 表示容器配置信息
@@ -75,6 +80,32 @@ type ConfigInfo struct {
 // HostConfigInfo 表示主机配置信息
 type HostConfigInfo struct {
 	Rootfs string `json:"Rootfs"`
+
+	// Phase 14: cgroup namespace 模式
+	CgroupnsMode string `json:"CgroupnsMode,omitempty"`
+
+	// Phase 15: 命名空间共享模式
+	NetworkMode string `json:"NetworkMode,omitempty"`
+	PidMode     string `json:"PidMode,omitempty"`
+	IpcMode     string `json:"IpcMode,omitempty"`
+	UTSMode     string `json:"UTSMode,omitempty"`
+
+	// Phase 15: 所属 pod 与 cgroup 父路径
+	Pod          string `json:"Pod,omitempty"`
+	CgroupParent string `json:"CgroupParent,omitempty"`
+
+	// Phase 17: 资源限制与内核参数
+	Ulimits []state.UlimitConfig `json:"Ulimits,omitempty"`
+	Sysctls map[string]string    `json:"Sysctls,omitempty"`
+
+	// Phase 19: 日志配置
+	LogConfig LogConfigInfo `json:"LogConfig"`
+}
+
+// LogConfigInfo 表示日志驱动配置
+type LogConfigInfo struct {
+	Type   string            `json:"Type"`
+	Config map[string]string `json:"Config,omitempty"`
 }
 
 func inspectContainers(cmd *cobra.Command, args []string) error {
@@ -132,6 +163,12 @@ func inspectContainer(store *state.Store, idOrPrefix string) (*InspectOutput, er
 	// 构建完整命令
 	fullCmd := config.GetCommand()
 
+	// Phase 19: 旧容器没有记录日志驱动，即默认的 json-file
+	logDriver := config.LogDriver
+	if logDriver == "" {
+		logDriver = runtime.DefaultLogDriver
+	}
+
 	// 计算退出码
 	exitCode := 0
 	if containerState.ExitCode != nil {
@@ -157,10 +194,29 @@ func inspectContainer(store *state.Store, idOrPrefix string) (*InspectOutput, er
 			Detached: config.Detached,
 		},
 		HostConfig: HostConfigInfo{
-			Rootfs: config.Rootfs,
+			Rootfs:       config.Rootfs,
+			CgroupnsMode: config.CgroupNS,
+			NetworkMode:  config.NetworkMode,
+			PidMode:      config.PidMode,
+			IpcMode:      config.IpcMode,
+			UTSMode:      config.UTSMode,
+			Pod:          config.Pod,
+			CgroupParent: config.CgroupParent,
+			Ulimits:      config.Ulimits,
+			Sysctls:      config.Sysctls,
+			LogConfig:    LogConfigInfo{Type: logDriver, Config: config.LogOpts},
 		},
 		LogPath: containerState.GetLogDir(),
 	}
 
+	// Phase 20: 读取 cgroup 统计（未配置 cgroup 或系统不支持 cgroup v2 时省略）
+	if containerState.IsRunning() && containerState.CgroupPath != "" {
+		if manager, err := cgroups.NewManager(); err == nil {
+			if stats, err := manager.GetStats(containerState.CgroupPath); err == nil {
+				output.State.ResourceStats = stats
+			}
+		}
+	}
+
 	return output, nil
 }
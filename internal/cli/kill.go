@@ -10,6 +10,7 @@ import (
 	"strings"
 	"syscall"
 
+	"minidocker/internal/events"
 	"minidocker/internal/state"
 
 	"github.com/spf13/cobra"
@@ -146,5 +147,8 @@ func killContainer(store *state.Store, idOrPrefix string, sig syscall.Signal) er
 		return fmt.Errorf("failed to send signal: %w", err)
 	}
 
+	// Phase 20: 记录 kill 事件
+	logContainerEvent(store, containerState, store.NameStore.GetName(containerState.ID), events.ActionKill,
+		map[string]string{"signal": strconv.Itoa(int(sig))})
 	return nil
 }
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"minidocker/internal/events"
 	"minidocker/internal/image"
 	"minidocker/internal/state"
 )
@@ -69,6 +71,9 @@ func runLoad(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("import image: %w", err)
 	}
 
+	// Phase 20: 记录 load 事件
+	_ = events.Log(root, events.TypeImage, events.ActionLoad, img.ID.String(), map[string]string{"name": strings.Join(img.RepoTags, ",")})
+
 	// Print result
 	id := img.ID.Encoded()
 	if len(id) > 12 {
@@ -4,16 +4,17 @@
 package cli
 
 import (
-	"bufio"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"minidocker/internal/runtime"
 	"minidocker/internal/state"
 
 	"github.com/fsnotify/fsnotify"
@@ -26,7 +27,9 @@ var (
 	logsTail       string
 	logsShowStdout bool
 	logsShowStderr bool
-	logsTimestamps bool // 预留，Phase 4 不实现
+	logsTimestamps bool
+	logsSince      string // Phase 19 新增
+	logsUntil      string // Phase 19 新增
 )
 
 var logsCmd = &cobra.Command{
@@ -34,11 +37,19 @@ var logsCmd = &cobra.Command{
 	Short: "获取容器的日志",
 	Long: `获取容器的标准输出和标准错误日志。
 
+日志以 json-file 格式保存（每行一条带流标签与时间戳的记录），
+stdout 记录输出到标准输出，stderr 记录输出到标准错误，保持原始交错顺序。
+
+--since / --until 接受 RFC 3339 时间（2006-01-02T15:04:05Z07:00）、
+日期（2006-01-02）、Unix 时间戳（1700000000.5）或相对时长（10m、1h30m）。
+
 示例:
   minidocker logs my_container
   minidocker logs -f my_container        # 跟踪日志输出
   minidocker logs --tail 100 my_container # 显示最后 100 行
-  minidocker logs --stdout my_container   # 只显示标准输出`,
+  minidocker logs --stdout my_container   # 只显示标准输出
+  minidocker logs -t --since 10m my_container
+  minidocker logs --since 2024-01-02T10:00:00Z --until 2024-01-02T11:00:00Z my_container`,
 	Args: cobra.ExactArgs(1),
 	RunE: showLogs,
 }
@@ -48,7 +59,51 @@ func init() {
 	logsCmd.Flags().StringVarP(&logsTail, "tail", "n", "all", "显示最后 N 行（默认 \"all\"）")
 	logsCmd.Flags().BoolVar(&logsShowStdout, "stdout", false, "只显示标准输出")
 	logsCmd.Flags().BoolVar(&logsShowStderr, "stderr", false, "只显示标准错误")
-	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "显示时间戳（预留，当前未实现）")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "显示时间戳")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "只显示该时间之后的日志（时间戳或相对时长，例如 10m）")
+	logsCmd.Flags().StringVar(&logsUntil, "until", "", "只显示该时间之前的日志（时间戳或相对时长，例如 10m）")
+}
+
+// logFilter 描述 logs 命令的输出过滤条件
+type logFilter struct {
+	stdout     bool
+	stderr     bool
+	since      time.Time // 零值表示不限制
+	until      time.Time // 零值表示不限制
+	timestamps bool
+}
+
+// match 判断一条记录是否需要输出
+func (f *logFilter) match(rec *runtime.LogRecord) bool {
+	switch rec.Stream {
+	case runtime.LogStreamStdout:
+		if !f.stdout {
+			return false
+		}
+	case runtime.LogStreamStderr:
+		if !f.stderr {
+			return false
+		}
+	}
+	if !f.since.IsZero() && rec.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && rec.Time.After(f.until) {
+		return false
+	}
+	return true
+}
+
+// print 按记录的流输出到 stdout 或 stderr
+func (f *logFilter) print(rec *runtime.LogRecord) {
+	out := os.Stdout
+	if rec.Stream == runtime.LogStreamStderr {
+		out = os.Stderr
+	}
+	if f.timestamps {
+		fmt.Fprint(out, rec.Time.Format(time.RFC3339Nano)+" ")
+	}
+	fmt.Fprint(out, rec.Log)
 }
 
 func showLogs(cmd *cobra.Command, args []string) error {
@@ -64,17 +119,25 @@ func showLogs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// 预留参数提示：当前不实现时间戳输出
-	if logsTimestamps {
-		fmt.Fprintln(os.Stderr, "Warning: --timestamps is reserved for future use, currently ignored")
-	}
-
-	logDir := containerState.GetLogDir()
-	stdoutPath := filepath.Join(logDir, "stdout.log")
-	stderrPath := filepath.Join(logDir, "stderr.log")
-
 	// 如果没有指定 --stdout 或 --stderr，则显示两者
 	showBoth := !logsShowStdout && !logsShowStderr
+	filter := &logFilter{
+		stdout:     showBoth || logsShowStdout,
+		stderr:     showBoth || logsShowStderr,
+		timestamps: logsTimestamps,
+	}
+
+	now := time.Now()
+	if logsSince != "" {
+		if filter.since, err = parseLogTime(logsSince, now); err != nil {
+			return fmt.Errorf("invalid --since value: %w", err)
+		}
+	}
+	if logsUntil != "" {
+		if filter.until, err = parseLogTime(logsUntil, now); err != nil {
+			return fmt.Errorf("invalid --until value: %w", err)
+		}
+	}
 
 	// 解析 tail 参数
 	var tailLines int = -1 // -1 表示显示所有
@@ -89,135 +152,148 @@ func showLogs(cmd *cobra.Command, args []string) error {
 		tailLines = n
 	}
 
-	if logsFollow {
-		return followLogs(containerState, stdoutPath, stderrPath, showBoth, logsShowStdout, logsShowStderr, tailLines)
+	// Phase 19: 只有 json-file 日志保存在本地，其他驱动无法读回
+	config, err := state.LoadConfig(containerState.GetContainerDir())
+	if err != nil {
+		return fmt.Errorf("failed to load container config: %w", err)
 	}
-
-	// 非 follow 模式：读取并输出日志
-	if showBoth || logsShowStdout {
-		if err := outputLogFile(stdoutPath, tailLines, ""); err != nil {
-			// 文件不存在不算错误
-			if !os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "Warning: cannot read stdout.log: %v\n", err)
-			}
-		}
+	if !runtime.IsLogDriverReadable(config.LogDriver) {
+		return fmt.Errorf("configured logging driver %q does not support reading (only %s logs can be read back)", config.LogDriver, runtime.LogDriverJSONFile)
 	}
 
-	if showBoth || logsShowStderr {
-		if err := outputLogFile(stderrPath, tailLines, ""); err != nil {
-			if !os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "Warning: cannot read stderr.log: %v\n", err)
-			}
+	logDir := containerState.GetLogDir()
+
+	// Phase 19: 旧版本容器的原始日志（stdout.log / stderr.log）。
+	// 容器已停止时原地迁移为 json-file；仍在运行时（旧 shim 仍在写入）只读取不迁移。
+	if !containerState.IsRunning() {
+		if err := runtime.MigrateLegacyLogs(logDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot migrate legacy logs: %v\n", err)
 		}
 	}
-
-	return nil
-}
-
-// outputLogFile 输出日志文件内容
-func outputLogFile(path string, tailLines int, prefix string) error {
-	file, err := os.Open(path)
+	records, err := runtime.ReadLegacyLogs(logDir)
 	if err != nil {
-		return err
+		fmt.Fprintf(os.Stderr, "Warning: cannot read legacy logs: %v\n", err)
 	}
-	defer file.Close()
 
-	if tailLines < 0 {
-		// 输出所有内容
-		_, err := io.Copy(os.Stdout, file)
-		return err
+	// 先打开当前文件再列出历史文件：若两步之间发生轮转，
+	// 历史文件中与已打开文件相同的那个会被跳过，避免重复输出
+	logPath := filepath.Join(logDir, runtime.JSONLogFileName)
+	file, err := os.Open(logPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open %s: %w", runtime.JSONLogFileName, err)
 	}
+	defer func() {
+		// follow 模式下轮转后 file 会切换为新文件
+		if file != nil {
+			file.Close()
+		}
+	}()
 
-	// 读取最后 N 行
-	lines, err := readLastNLines(file, tailLines)
+	// Phase 19: 按从旧到新的顺序读取轮转后的历史文件
+	rotated, err := runtime.RotatedLogFiles(logDir)
 	if err != nil {
-		return err
+		fmt.Fprintf(os.Stderr, "Warning: cannot list rotated logs: %v\n", err)
 	}
-
-	for _, line := range lines {
-		fmt.Print(prefix + line)
+	for _, path := range rotated {
+		if file != nil && isSameFile(path, file) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// 读取期间可能被再次轮转删除
+			continue
+		}
+		recs, _ := runtime.DecodeLogRecords(data)
+		records = append(records, recs...)
 	}
 
-	return nil
-}
-
-// readLastNLines 读取文件的最后 N 行
-func readLastNLines(file *os.File, n int) ([]string, error) {
-	if n == 0 {
-		return nil, nil
+	var offset int64
+	if file != nil {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", runtime.JSONLogFileName, err)
+		}
+		recs, consumed := runtime.DecodeLogRecords(data)
+		records = append(records, recs...)
+		offset = int64(consumed)
 	}
 
-	// 确保从文件头开始读取
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return nil, err
+	// 先过滤再取最后 N 条（对齐 Docker：--tail 作用于过滤后的结果）
+	matched := records[:0]
+	for i := range records {
+		if filter.match(&records[i]) {
+			matched = append(matched, records[i])
+		}
 	}
-
-	// 使用 ring buffer 仅保留最后 N 行，避免把整个文件读入内存
-	scanner := bufio.NewScanner(file)
-	// bufio.Scanner 默认 token 上限较小；提高上限以避免长行导致错误
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	ring := make([]string, n)
-	count := 0
-	for scanner.Scan() {
-		ring[count%n] = scanner.Text() + "\n"
-		count++
+	if tailLines >= 0 && len(matched) > tailLines {
+		matched = matched[len(matched)-tailLines:]
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	for i := range matched {
+		filter.print(&matched[i])
 	}
 
-	if count == 0 {
-		return nil, nil
-	}
-	if count < n {
-		return ring[:count], nil
+	if logsFollow {
+		file, err = followLogs(containerState, logPath, file, offset, filter)
+		return err
 	}
+	return nil
+}
 
-	start := count % n
-	lines := make([]string, 0, n)
-	lines = append(lines, ring[start:]...)
-	lines = append(lines, ring[:start]...)
-	return lines, nil
+// isSameFile 判断 path 是否与已打开的文件是同一个文件
+func isSameFile(path string, file *os.File) bool {
+	a, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	b, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return os.SameFile(a, b)
 }
 
-// followLogs 使用 fsnotify 跟踪日志文件变化
-func followLogs(containerState *state.ContainerState, stdoutPath, stderrPath string, showBoth, showStdout, showStderr bool, tailLines int) error {
+// followLogs 使用 fsnotify 跟踪 json-file 日志，从 file 的 offset 开始输出新记录。
+//
+// Phase 19 更新：支持日志轮转。监听日志目录而不是文件本身（轮转后路径指向新文件），
+// 发现路径对应的文件变化时，先读完旧文件剩余的记录，再按从旧到新的顺序读完两次读取之间
+// 轮转出的历史文件（按 inode 定位旧文件），最后切换到新文件从头读取。
+// 返回最终打开的文件，由调用方关闭。
+func followLogs(containerState *state.ContainerState, logPath string, file *os.File, offset int64, filter *logFilter) (*os.File, error) {
+	// --until 已经过去时没有可跟踪的新日志
+	if !filter.until.IsZero() && time.Now().After(filter.until) {
+		return file, nil
+	}
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
+		return file, fmt.Errorf("failed to create file watcher: %w", err)
 	}
 	defer watcher.Close()
 
-	// 打开文件并定位到末尾（先输出 tail 行）
-	var stdoutFile, stderrFile *os.File
-	var stdoutOffset, stderrOffset int64
+	if err := watcher.Add(filepath.Dir(logPath)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: cannot watch log directory: %v\n", err)
+	}
 
-	if showBoth || showStdout {
-		stdoutFile, stdoutOffset, err = openAndTail(stdoutPath, tailLines)
-		if err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to open stdout.log: %w", err)
-		}
-		if stdoutFile != nil {
-			defer stdoutFile.Close()
-			if err := watcher.Add(stdoutPath); err != nil {
-				// 忽略监控添加失败（文件可能不存在）
-				fmt.Fprintf(os.Stderr, "Warning: cannot watch stdout.log: %v\n", err)
+	// readNew 输出 offset 之后新写入的完整记录；路径已指向新文件（轮转）时切换过去
+	readNew := func() {
+		if file != nil {
+			offset = readNewRecords(file, offset, filter)
+			if isSameFile(logPath, file) {
+				return
 			}
 		}
-	}
 
-	if showBoth || showStderr {
-		stderrFile, stderrOffset, err = openAndTail(stderrPath, tailLines)
-		if err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to open stderr.log: %w", err)
+		// 新文件可能尚未创建（轮转在重命名与创建之间），留到下次读取
+		next, err := os.Open(logPath)
+		if err != nil {
+			return
 		}
-		if stderrFile != nil {
-			defer stderrFile.Close()
-			if err := watcher.Add(stderrPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: cannot watch stderr.log: %v\n", err)
-			}
+		if file != nil {
+			readRotatedBetween(filepath.Dir(logPath), file, next, filter)
+			file.Close()
 		}
+		file, offset = next, 0
+		offset = readNewRecords(file, offset, filter)
 	}
 
 	// 设置信号处理，以便优雅退出
@@ -232,122 +308,153 @@ func followLogs(containerState *state.ContainerState, stdoutPath, stderrPath str
 		select {
 		case event, ok := <-watcher.Events:
 			if !ok {
-				return nil
+				return file, nil
 			}
 
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				// 文件被写入，读取新内容
-				if event.Name == stdoutPath && stdoutFile != nil {
-					stdoutOffset = readNewContent(stdoutFile, stdoutOffset, "")
-				} else if event.Name == stderrPath && stderrFile != nil {
-					stderrOffset = readNewContent(stderrFile, stderrOffset, "")
-				}
+			if event.Name == logPath && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				// 文件被写入或轮转后重新创建，读取新内容
+				readNew()
 			}
 
 		case err, ok := <-watcher.Errors:
 			if !ok {
-				return nil
+				return file, nil
 			}
 			fmt.Fprintf(os.Stderr, "Watcher error: %v\n", err)
 
 		case <-ticker.C:
+			// Phase 19: 到达 --until 后停止跟踪
+			if !filter.until.IsZero() && time.Now().After(filter.until) {
+				readNew()
+				return file, nil
+			}
+
 			// 周期性检查容器状态：stopped 后退出
 			if err := containerState.Reload(); err == nil {
 				// running 状态下进一步用 IsRunning() 触发孤儿检测（ESRCH）
 				if containerState.Status == state.StatusRunning && !containerState.IsRunning() {
-					if stdoutFile != nil {
-						stdoutOffset = readNewContent(stdoutFile, stdoutOffset, "")
-					}
-					if stderrFile != nil {
-						stderrOffset = readNewContent(stderrFile, stderrOffset, "")
-					}
-					return nil
+					readNew()
+					return file, nil
 				}
 				if containerState.Status == state.StatusStopped {
-					if stdoutFile != nil {
-						stdoutOffset = readNewContent(stdoutFile, stdoutOffset, "")
-					}
-					if stderrFile != nil {
-						stderrOffset = readNewContent(stderrFile, stderrOffset, "")
-					}
-					return nil
+					readNew()
+					return file, nil
 				}
 			}
 
 		case <-sigChan:
 			// 收到中断信号，优雅退出
-			return nil
+			return file, nil
 		}
 	}
 }
 
-// openAndTail 打开文件，输出最后 N 行，返回文件和当前偏移量
-func openAndTail(path string, tailLines int) (*os.File, int64, error) {
-	file, err := os.Open(path)
+// readRotatedBetween 按从旧到新的顺序输出 prev 之后、next 之前轮转出的历史文件。
+// 两次读取之间可能发生多次轮转：prev 已被重命名为 .N 时，.N-1 到 .1 中早于 next 的文件都需要读取。
+// prev 已超出 max-file 被删除时，无法确定中间是否还有文件被删除，输出警告。
+func readRotatedBetween(logDir string, prev, next *os.File, filter *logFilter) {
+	rotated, err := runtime.RotatedLogFiles(logDir)
 	if err != nil {
-		return nil, 0, err
+		fmt.Fprintf(os.Stderr, "Warning: cannot list rotated logs, some records may be missing: %v\n", err)
+		return
 	}
 
-	// 先输出 tail 行
-	if tailLines >= 0 {
-		lines, err := readLastNLines(file, tailLines)
-		if err != nil {
-			file.Close()
-			return nil, 0, err
-		}
-		for _, line := range lines {
-			fmt.Print(line)
-		}
-	} else {
-		// 输出所有内容
-		if _, err := io.Copy(os.Stdout, file); err != nil {
-			file.Close()
-			return nil, 0, err
+	start := -1
+	for i, path := range rotated {
+		if isSameFile(path, prev) {
+			start = i + 1
+			break
 		}
 	}
-
-	// 获取当前偏移量
-	offset, err := file.Seek(0, io.SeekEnd)
-	if err != nil {
-		file.Close()
-		return nil, 0, err
+	if start < 0 {
+		fmt.Fprintln(os.Stderr, "Warning: log files were rotated away before they could be read, some records may be missing")
+		start = 0
 	}
 
-	return file, offset, nil
+	for _, path := range rotated[start:] {
+		// next 本身也已被轮转：之后的文件在下次读取时处理
+		if isSameFile(path, next) {
+			break
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot read rotated log %s, some records may be missing: %v\n", filepath.Base(path), err)
+			continue
+		}
+		records, _ := runtime.DecodeLogRecords(data)
+		for i := range records {
+			if filter.match(&records[i]) {
+				filter.print(&records[i])
+			}
+		}
+	}
 }
 
-// readNewContent 从指定偏移量开始读取新内容
-func readNewContent(file *os.File, offset int64, prefix string) int64 {
-	// 文件可能被截断（例如 log rotation / truncate）；偏移量超过文件大小时回退
+// readNewRecords 从指定偏移量开始读取并输出新的完整记录，返回新的偏移量。
+// 写入中的不完整行留到下次读取。
+func readNewRecords(file *os.File, offset int64, filter *logFilter) int64 {
+	// 文件可能被截断（max-file 为 1 时轮转即截断）；偏移量超过文件大小时回退，截断前未读取的记录丢失
 	if info, err := file.Stat(); err == nil {
 		if info.Size() < offset {
+			fmt.Fprintln(os.Stderr, "Warning: log file was truncated, some records may be missing")
 			offset = 0
 		}
 	}
 
 	// 定位到上次读取的位置
-	_, err := file.Seek(offset, io.SeekStart)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+
+	data, err := io.ReadAll(file)
 	if err != nil {
 		return offset
 	}
 
-	// 读取新内容
-	reader := bufio.NewReader(file)
-	for {
-		line, err := reader.ReadString('\n')
-		if len(line) > 0 {
-			fmt.Print(prefix + line)
+	records, consumed := runtime.DecodeLogRecords(data)
+	for i := range records {
+		if filter.match(&records[i]) {
+			filter.print(&records[i])
 		}
-		if err != nil {
-			break
+	}
+	return offset + int64(consumed)
+}
+
+// parseLogTime 解析 --since / --until 的值（对齐 Docker 支持的格式）：
+// RFC 3339 时间、不带时区的本地时间、日期、Unix 时间戳（可带小数）、
+// 以及相对于 now 的时长（例如 10m 表示 10 分钟前）。
+func parseLogTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
 		}
 	}
 
-	// 更新偏移量
-	newOffset, err := file.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return offset
+	// Unix 时间戳：秒[.小数部分]
+	sec, frac, _ := strings.Cut(value, ".")
+	if s, err := strconv.ParseInt(sec, 10, 64); err == nil {
+		var nsec int64
+		if frac != "" {
+			if len(frac) > 9 {
+				frac = frac[:9]
+			}
+			n, err := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("%q is not a valid timestamp", value)
+			}
+			nsec = n
+		}
+		return time.Unix(s, nsec), nil
 	}
 
-	return newOffset
+	return time.Time{}, fmt.Errorf("%q is not a valid timestamp or duration", value)
 }
@@ -0,0 +1,133 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "管理网络",
+	Long: `管理 minidocker 网络（Phase 21 新增）。
+
+内置网络：bridge（默认，minidocker0，172.17.0.0/16）、host、none。
+用户自定义网络各自拥有独立的 Linux bridge（md-<网络 ID 前 12 位>）、子网、网关和 IPAM，
+元数据保存在 /var/lib/minidocker/network/networks.json。
+用户自定义网络上的容器通过内嵌 DNS（127.0.0.11）按容器名、别名互相解析。
+--ipv6 创建的双栈网络同时分配 IPv6 子网，NAT 与端口发布规则同时为 IPv6 设置。
+
+NAT、端口发布和转发规则由防火墙后端设置：iptables 可用时使用 iptables/ip6tables，
+仅有 nftables 的宿主机使用独立的 minidocker 表（ip/ip6 minidocker）。
+环境变量 MINIDOCKER_FIREWALL_BACKEND=iptables|nftables 可覆盖自动检测。
+默认只用 DNAT 发布端口，并为 bridge 开启 route_localnet、为容器 veth 开启 hairpin，
+使 127.0.0.1 和容器自身都能访问发布端口；$MINIDOCKER_ROOT/daemon.json 中设置
+{"userland-proxy": true} 时改由每个容器的用户态代理（TCP/UDP）处理这两类流量。
+用户自定义网络可用 network create -o icc=false 禁止容器互相访问，默认 bridge 网络
+在 daemon.json 中设置 {"icc": false}；经发布端口的访问不受影响。
+宿主机重启或 shim 崩溃后遗留的 IP 分配、宿主机端口、veth 和端口映射规则由 network reconcile
+清理，运行、删除容器等命令启动时也会自动执行。
+
+示例:
+  minidocker network create mynet
+  minidocker network create --subnet 10.10.0.0/24 backend
+  minidocker network create --ipv6 --subnet fd00:10::/64 dualnet
+  minidocker run -d --network mynet --name web alpine sleep 1000
+  minidocker run -d --network mynet --network-alias db alpine sleep 1000
+  minidocker network connect backend web
+  minidocker network disconnect backend web
+  minidocker network ls
+  minidocker network inspect mynet
+  minidocker network rm mynet
+  minidocker network reconcile`,
+}
+
+func init() {
+	// 添加子命令
+	networkCmd.AddCommand(networkCreateCmd)
+	networkCmd.AddCommand(networkLsCmd)
+	networkCmd.AddCommand(networkInspectCmd)
+	networkCmd.AddCommand(networkRmCmd)
+	networkCmd.AddCommand(networkConnectCmd)
+	networkCmd.AddCommand(networkDisconnectCmd)
+	networkCmd.AddCommand(networkReconcileCmd)
+}
+
+// openNetworkStores 初始化容器状态存储和网络注册表
+func openNetworkStores() (*state.Store, *network.Store, error) {
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	networkStore, err := network.NewStore(store.RootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize network store: %w", err)
+	}
+	return store, networkStore, nil
+}
+
+// networkEndpointInfo 描述运行中容器在某个网络上的网卡
+type networkEndpointInfo struct {
+	Name        string   `json:"name"`
+	Interface   string   `json:"interface,omitempty"`
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	IPv6Address string   `json:"ipv6Address,omitempty"`
+	MacAddress  string   `json:"macAddress,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+}
+
+// attachedContainers 返回接入指定网络的运行中容器（容器 ID -> 网卡）
+func attachedContainers(store *state.Store, info *network.NetworkInfo) (map[string]networkEndpointInfo, error) {
+	containers, err := store.List(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	attached := make(map[string]networkEndpointInfo)
+	for _, c := range containers {
+		ns := c.NetworkState
+		if ns == nil {
+			continue
+		}
+		name := store.NameStore.GetName(c.ID)
+
+		// 主网卡：bridge 模式按网络名匹配，host/none 按模式匹配
+		primary := ns.Mode
+		if ns.Mode == string(network.NetworkModeBridge) {
+			primary = primaryNetwork(ns)
+		}
+		if primary == info.Name {
+			attached[c.ID] = networkEndpointInfo{
+				Name:        name,
+				Interface:   ns.VethContainer,
+				IPAddress:   ns.IPAddress,
+				IPv6Address: ns.IPv6Address,
+				MacAddress:  ns.MacAddress,
+				Aliases:     ns.Aliases,
+			}
+			continue
+		}
+
+		for _, ep := range ns.Endpoints {
+			if ep.Network == info.Name {
+				attached[c.ID] = networkEndpointInfo{
+					Name:        name,
+					Interface:   ep.VethContainer,
+					IPAddress:   ep.IPAddress,
+					IPv6Address: ep.IPv6Address,
+					MacAddress:  ep.MacAddress,
+					Aliases:     ep.Aliases,
+				}
+				break
+			}
+		}
+	}
+	return attached, nil
+}
@@ -0,0 +1,225 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+
+	"minidocker/internal/events"
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+// network connect 命令标志
+var networkConnectAliases []string
+
+var networkConnectCmd = &cobra.Command{
+	Use:   "connect NETWORK CONTAINER",
+	Short: "将运行中的容器接入网络",
+	Long: `将运行中的 bridge 模式容器接入另一个 bridge 网络。
+
+容器内新增一块网卡（eth1、eth2...），从该网络的子网分配 IP；默认路由仍走主网卡 eth0。
+附加网卡记录在容器状态中，容器退出或删除时自动清理。
+--alias 为容器在该网络上添加 DNS 别名，由同网络容器的内嵌 DNS 解析。
+
+示例:
+  minidocker network connect backend web
+  minidocker network connect --alias api backend web`,
+	Args: cobra.ExactArgs(2),
+	RunE: connectNetwork,
+}
+
+var networkDisconnectCmd = &cobra.Command{
+	Use:   "disconnect NETWORK CONTAINER",
+	Short: "将容器从网络断开",
+	Long: `删除 network connect 为容器添加的网卡并释放其 IP。
+
+容器的主网络（run --network 指定的网络）不能断开。
+
+示例:
+  minidocker network disconnect backend web`,
+	Args: cobra.ExactArgs(2),
+	RunE: disconnectNetwork,
+}
+
+func init() {
+	networkConnectCmd.Flags().StringArrayVar(&networkConnectAliases, "alias", nil, "在该网络上为容器添加 DNS 别名")
+}
+
+func connectNetwork(cmd *cobra.Command, args []string) error {
+	for _, alias := range networkConnectAliases {
+		if err := network.ValidateAlias(alias); err != nil {
+			return err
+		}
+	}
+
+	store, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	info, err := networkStore.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if info.Driver != network.DriverBridge {
+		return fmt.Errorf("cannot connect containers to network %s (driver %s)", info.Name, info.Driver)
+	}
+	if len(networkConnectAliases) > 0 && info.Builtin {
+		return fmt.Errorf("network-scoped aliases are only supported for user-defined networks")
+	}
+
+	containerState, err := store.Get(args[1])
+	if err != nil {
+		return err
+	}
+	if !containerState.IsRunning() {
+		return fmt.Errorf("container %s is not running", shortID(containerState.ID))
+	}
+	ns := containerState.NetworkState
+	if ns == nil || ns.Mode != string(network.NetworkModeBridge) {
+		return fmt.Errorf("container %s does not use bridge networking and cannot be connected to other networks", shortID(containerState.ID))
+	}
+	if connectedTo(ns, info.Name) {
+		return fmt.Errorf("container %s is already connected to network %s", shortID(containerState.ID), info.Name)
+	}
+
+	manager, err := network.NewManager(store.RootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize network manager: %w", err)
+	}
+
+	endpoint, err := manager.Connect(containerState.ID, info.Name, containerState.Pid, nextInterfaceName(ns))
+	if err != nil {
+		return fmt.Errorf("failed to connect container to network %s: %w", info.Name, err)
+	}
+
+	// 持久化附加网卡（shim 退出时据此清理）；失败时回滚
+	ns.Endpoints = append(ns.Endpoints, state.Endpoint{
+		Network:       endpoint.Network,
+		IPAddress:     endpoint.IPAddress,
+		Gateway:       endpoint.Gateway,
+		IPv6Address:   endpoint.IPv6Address,
+		IPv6Gateway:   endpoint.IPv6Gateway,
+		MacAddress:    endpoint.MacAddress,
+		MTU:           endpoint.MTU,
+		VethHost:      endpoint.VethHost,
+		VethContainer: endpoint.VethContainer,
+		Aliases:       networkConnectAliases,
+	})
+	if err := containerState.Save(); err != nil {
+		_ = manager.Disconnect(containerState.ID, endpoint)
+		return fmt.Errorf("failed to update container state: %w", err)
+	}
+
+	logNetworkEvent(store, info, events.ActionConnect, containerState.ID)
+	return nil
+}
+
+func disconnectNetwork(cmd *cobra.Command, args []string) error {
+	store, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	info, err := networkStore.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	containerState, err := store.Get(args[1])
+	if err != nil {
+		return err
+	}
+	ns := containerState.NetworkState
+	if ns != nil && ns.Mode == string(network.NetworkModeBridge) && primaryNetwork(ns) == info.Name {
+		return fmt.Errorf("cannot disconnect container %s from its primary network %s", shortID(containerState.ID), info.Name)
+	}
+
+	index := -1
+	if ns != nil {
+		for i, ep := range ns.Endpoints {
+			if ep.Network == info.Name {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("container %s is not connected to network %s", shortID(containerState.ID), info.Name)
+	}
+
+	// 已停止的容器网卡随网络命名空间销毁，这里只需释放 IP 并更新状态（均为幂等操作）
+	ep := ns.Endpoints[index]
+	manager, err := network.NewManager(store.RootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize network manager: %w", err)
+	}
+	if err := manager.Disconnect(containerState.ID, &network.Endpoint{
+		Network:       ep.Network,
+		IPAddress:     ep.IPAddress,
+		Gateway:       ep.Gateway,
+		IPv6Address:   ep.IPv6Address,
+		IPv6Gateway:   ep.IPv6Gateway,
+		MacAddress:    ep.MacAddress,
+		VethHost:      ep.VethHost,
+		VethContainer: ep.VethContainer,
+	}); err != nil {
+		return fmt.Errorf("failed to disconnect container from network %s: %w", info.Name, err)
+	}
+
+	ns.Endpoints = append(ns.Endpoints[:index], ns.Endpoints[index+1:]...)
+	if err := containerState.Save(); err != nil {
+		return fmt.Errorf("failed to update container state: %w", err)
+	}
+
+	logNetworkEvent(store, info, events.ActionDisconnect, containerState.ID)
+	return nil
+}
+
+// primaryNetwork 返回 bridge 模式容器主网卡所在的网络名
+func primaryNetwork(ns *state.NetworkState) string {
+	if ns.Network == "" {
+		return network.DefaultNetworkName
+	}
+	return ns.Network
+}
+
+// connectedTo 返回容器是否已接入指定网络（主网卡或附加网卡）
+func connectedTo(ns *state.NetworkState, networkName string) bool {
+	if primaryNetwork(ns) == networkName {
+		return true
+	}
+	for _, ep := range ns.Endpoints {
+		if ep.Network == networkName {
+			return true
+		}
+	}
+	return false
+}
+
+// nextInterfaceName 返回容器内下一个未使用的接口名（eth1、eth2...）
+func nextInterfaceName(ns *state.NetworkState) string {
+	used := map[string]bool{ns.VethContainer: true}
+	for _, ep := range ns.Endpoints {
+		used[ep.VethContainer] = true
+	}
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("eth%d", i)
+		if !used[name] {
+			return name
+		}
+	}
+}
+
+// logNetworkEvent 记录一条网络 connect/disconnect 事件，附带网络名与容器 ID
+func logNetworkEvent(store *state.Store, info *network.NetworkInfo, action, containerID string) {
+	_ = events.Log(store.RootDir, events.TypeNetwork, action, info.ID, map[string]string{
+		"name":      info.Name,
+		"type":      info.Driver,
+		"container": containerID,
+	})
+}
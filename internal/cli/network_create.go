@@ -0,0 +1,143 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"minidocker/internal/events"
+	"minidocker/internal/network"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// network create 命令标志
+	networkCreateDriver  string
+	networkCreateSubnet  []string // Phase 21: 可指定 IPv4 和 IPv6 子网各一个
+	networkCreateGateway []string
+	networkCreateIPv6    bool
+	networkCreateMTU     int      // Phase 21: bridge 与容器网卡的 MTU
+	networkCreateOpts    []string // Phase 21: 驱动选项（KEY=VALUE）
+)
+
+var networkCreateCmd = &cobra.Command{
+	Use:   "create [OPTIONS] NETWORK",
+	Short: "创建网络",
+	Long: `创建一个用户自定义 bridge 网络。
+
+未指定 --subnet 时从 172.18.0.0/16 ~ 172.31.0.0/16 中选择第一个未被占用的子网；
+网关默认为子网的第一个地址。bridge 接口在首个容器接入时创建。
+
+--ipv6 创建双栈网络：容器同时获得 IPv6 地址，NAT 与端口发布规则同时为 IPv6 设置。
+IPv6 子网通过再指定一个 --subnet 给出，未指定时根据网络 ID 生成 fdXX:XXXX:XXXX::/64 唯一本地地址子网。
+
+--mtu 设置 bridge 和接入容器网卡的 MTU（例如隧道/VPN 环境下的 1450），未指定时使用内核默认值。
+
+-o/--opt 设置驱动选项：
+  icc=false  禁止网络上的容器互相访问（经 -p 发布的端口仍可访问），
+             也可写作 com.docker.network.bridge.enable_icc=false。
+             依赖 br_netfilter 模块，首个容器接入时自动加载。
+默认 bridge 网络的 icc 在 $MINIDOCKER_ROOT/daemon.json 中设置：{"icc": false}。
+
+示例:
+  minidocker network create mynet
+  minidocker network create --subnet 10.10.0.0/24 --gateway 10.10.0.254 backend
+  minidocker network create --ipv6 --subnet 10.20.0.0/24 --subnet fd00:20::/64 dualnet
+  minidocker network create --mtu 1450 overlaynet
+  minidocker network create -o icc=false isolated`,
+	Args: cobra.ExactArgs(1),
+	RunE: createNetwork,
+}
+
+func init() {
+	networkCreateCmd.Flags().StringVarP(&networkCreateDriver, "driver", "d", network.DriverBridge, "网络驱动（仅支持 bridge）")
+	networkCreateCmd.Flags().StringArrayVar(&networkCreateSubnet, "subnet", nil, "子网（CIDR 格式，例如 10.10.0.0/24；--ipv6 时可再指定一个 IPv6 子网）")
+	networkCreateCmd.Flags().StringArrayVar(&networkCreateGateway, "gateway", nil, "网关地址（需同时指定对应地址族的 --subnet）")
+	networkCreateCmd.Flags().BoolVar(&networkCreateIPv6, "ipv6", false, "启用 IPv6（双栈网络）")
+	networkCreateCmd.Flags().IntVar(&networkCreateMTU, "mtu", 0, "bridge 与容器网卡的 MTU（0 表示内核默认值）")
+	networkCreateCmd.Flags().StringArrayVarP(&networkCreateOpts, "opt", "o", nil, "驱动选项（KEY=VALUE，支持 icc=true|false）")
+}
+
+func createNetwork(cmd *cobra.Command, args []string) error {
+	store, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	opts := network.CreateOptions{
+		Name:       args[0],
+		Driver:     networkCreateDriver,
+		EnableIPv6: networkCreateIPv6,
+		MTU:        networkCreateMTU,
+	}
+	// Phase 21: --subnet/--gateway 按地址族分别归入 IPv4 和 IPv6 配置
+	for _, subnet := range networkCreateSubnet {
+		target := &opts.Subnet
+		if isIPv6Spec(subnet) {
+			target = &opts.SubnetV6
+		}
+		if *target != "" {
+			return fmt.Errorf("only one subnet per address family is supported: %s", subnet)
+		}
+		*target = subnet
+	}
+	for _, gateway := range networkCreateGateway {
+		target := &opts.Gateway
+		if isIPv6Spec(gateway) {
+			target = &opts.GatewayV6
+		}
+		if *target != "" {
+			return fmt.Errorf("only one gateway per address family is supported: %s", gateway)
+		}
+		*target = gateway
+	}
+
+	if err := parseNetworkCreateOpts(&opts); err != nil {
+		return err
+	}
+
+	info, err := networkStore.Create(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create network: %w", err)
+	}
+
+	// Phase 21: 记录 create 事件
+	_ = events.Log(store.RootDir, events.TypeNetwork, events.ActionCreate, info.ID, map[string]string{
+		"name": info.Name,
+		"type": info.Driver,
+	})
+
+	// 输出网络 ID（与 Docker 行为一致）
+	fmt.Println(info.ID)
+	return nil
+}
+
+// parseNetworkCreateOpts 解析 --opt KEY=VALUE 驱动选项（Phase 21 新增）
+func parseNetworkCreateOpts(opts *network.CreateOptions) error {
+	for _, opt := range networkCreateOpts {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return fmt.Errorf("invalid --opt %q: expected KEY=VALUE", opt)
+		}
+		switch key {
+		case "icc", "com.docker.network.bridge.enable_icc":
+			icc, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid --opt %s: %q is not a boolean", key, value)
+			}
+			opts.DisableICC = !icc
+		default:
+			return fmt.Errorf("unsupported network option: %s (supported: icc)", key)
+		}
+	}
+	return nil
+}
+
+// isIPv6Spec 返回地址或 CIDR 是否为 IPv6（包含冒号）
+func isIPv6Spec(s string) bool {
+	return strings.Contains(s, ":")
+}
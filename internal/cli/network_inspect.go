@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"minidocker/internal/network"
+
+	"github.com/spf13/cobra"
+)
+
+var networkInspectCmd = &cobra.Command{
+	Use:   "inspect NETWORK [NETWORK...]",
+	Short: "显示网络详细信息",
+	Long: `以 JSON 格式显示一个或多个网络的详细信息，包括接入该网络的运行中容器。
+
+NETWORK 可以是网络名、完整 ID 或 ID 前缀（至少 3 个字符）。
+
+示例:
+  minidocker network inspect bridge
+  minidocker network inspect mynet backend`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: inspectNetworks,
+}
+
+// networkInspectOutput 是 network inspect 的输出格式
+type networkInspectOutput struct {
+	*network.NetworkInfo
+
+	// Containers 是接入该网络的运行中容器（容器 ID -> 网卡）
+	Containers map[string]networkEndpointInfo `json:"containers"`
+}
+
+func inspectNetworks(cmd *cobra.Command, args []string) error {
+	store, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]networkInspectOutput, 0, len(args))
+	hasError := false
+	for _, nameOrID := range args {
+		info, err := networkStore.Get(nameOrID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error inspecting network %s: %v\n", nameOrID, err)
+			hasError = true
+			continue
+		}
+
+		containers, err := attachedContainers(store, info)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error inspecting network %s: %v\n", nameOrID, err)
+			hasError = true
+			continue
+		}
+		outputs = append(outputs, networkInspectOutput{NetworkInfo: info, Containers: containers})
+	}
+
+	if len(outputs) > 0 {
+		data, err := json.MarshalIndent(outputs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
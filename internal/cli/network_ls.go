@@ -0,0 +1,101 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"minidocker/internal/network"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	networkLsQuiet  bool
+	networkLsFormat string
+)
+
+var networkLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "列出网络",
+	Long: `列出内置网络和所有用户自定义网络。
+
+默认以表格格式输出，包含网络 ID、名称、驱动、子网和网关。
+使用 -q 只输出网络 ID。
+使用 --format json 输出 JSON 格式。
+
+示例:
+  minidocker network ls
+  minidocker network ls -q
+  minidocker network ls --format json`,
+	Aliases: []string{"list"},
+	Args:    cobra.NoArgs,
+	RunE:    listNetworks,
+}
+
+func init() {
+	networkLsCmd.Flags().BoolVarP(&networkLsQuiet, "quiet", "q", false, "只显示网络 ID")
+	networkLsCmd.Flags().StringVar(&networkLsFormat, "format", "table", "输出格式（table/json）")
+}
+
+func listNetworks(cmd *cobra.Command, args []string) error {
+	_, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	networks, err := networkStore.List()
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	// 按名称排序
+	sort.Slice(networks, func(i, j int) bool {
+		return networks[i].Name < networks[j].Name
+	})
+
+	if networkLsQuiet {
+		for _, n := range networks {
+			fmt.Println(shortID(n.ID))
+		}
+		return nil
+	}
+
+	switch networkLsFormat {
+	case "json":
+		data, err := json.MarshalIndent(networks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal networks: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	case "table":
+		return outputNetworksTable(networks)
+	default:
+		return fmt.Errorf("unsupported format: %s (supported: table, json)", networkLsFormat)
+	}
+}
+
+func outputNetworksTable(networks []*network.NetworkInfo) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NETWORK ID\tNAME\tDRIVER\tIPV6\tSUBNET\tGATEWAY")
+	for _, n := range networks {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n", shortID(n.ID), n.Name, n.Driver, n.EnableIPv6, orDash(n.Subnet), orDash(n.Gateway))
+	}
+	return nil
+}
+
+// orDash 将空字符串显示为 "-"
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "管理网络",
+	Long:  "管理 minidocker 网络。（仅支持 Linux）",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
+
+func init() {
+	for _, sub := range []string{"create", "ls", "inspect", "rm", "connect", "disconnect", "reconcile"} {
+		networkCmd.AddCommand(&cobra.Command{
+			Use:   sub,
+			Short: "管理网络（仅支持 Linux）",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("minidocker only supports Linux (current OS: %s)", runtime.GOOS)
+			},
+		})
+	}
+}
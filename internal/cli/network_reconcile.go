@@ -0,0 +1,48 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var networkReconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "清理孤儿网络资源",
+	Long: `对比 IPAM 分配、容器状态（state.json）中的网络状态、bridge 上的 veth 和端口映射规则，
+清理宿主机重启或 shim 崩溃后遗留的孤儿资源（Phase 21 新增）：
+
+  - 释放不属于运行中或正在创建的容器的 IP 地址和宿主机端口
+  - 删除接入 bridge、但所属容器已不在运行的 veth
+  - 删除指向这些容器的端口映射（DNAT/转发）规则
+
+每条清理记录输出一行。创建网络管理器的命令（运行、删除容器以及 network connect/disconnect 等）
+发现孤儿资源时也会自动执行同样的清理，只是不输出清理记录。
+
+示例:
+  minidocker network reconcile`,
+	Args: cobra.NoArgs,
+	RunE: reconcileNetworks,
+}
+
+func reconcileNetworks(cmd *cobra.Command, args []string) error {
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	actions, err := network.Reconcile(store.RootDir)
+	for _, action := range actions {
+		fmt.Println(action)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile networks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"minidocker/internal/events"
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var networkRmCmd = &cobra.Command{
+	Use:   "rm NETWORK [NETWORK...]",
+	Short: "删除网络",
+	Long: `删除一个或多个用户自定义网络，连同其 bridge 接口、NAT/转发规则和 IPAM 文件。
+
+内置网络（bridge/host/none）不能删除；仍有运行中容器接入的网络也不能删除，
+需要先停止容器或执行 network disconnect。
+
+示例:
+  minidocker network rm mynet
+  minidocker network rm mynet backend`,
+	Aliases: []string{"remove"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE:    removeNetworks,
+}
+
+func removeNetworks(cmd *cobra.Command, args []string) error {
+	store, networkStore, err := openNetworkStores()
+	if err != nil {
+		return err
+	}
+
+	hasError := false
+	for _, nameOrID := range args {
+		if err := removeNetwork(store, networkStore, nameOrID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing network %s: %v\n", nameOrID, err)
+			hasError = true
+			continue
+		}
+		// 成功时输出参数（与 Docker 行为一致）
+		fmt.Println(nameOrID)
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// removeNetwork 依次清理宿主机资源、网络元数据，并记录 destroy 事件
+func removeNetwork(store *state.Store, networkStore *network.Store, nameOrID string) error {
+	info, err := networkStore.Get(nameOrID)
+	if err != nil {
+		return err
+	}
+	if info.Builtin {
+		return fmt.Errorf("%s is a pre-defined network and cannot be removed", info.Name)
+	}
+
+	attached, err := attachedContainers(store, info)
+	if err != nil {
+		return err
+	}
+	if len(attached) > 0 {
+		ids := make([]string, 0, len(attached))
+		for id := range attached {
+			ids = append(ids, shortID(id))
+		}
+		sort.Strings(ids)
+		return fmt.Errorf("network %s has active endpoints: %s", info.Name, strings.Join(ids, ","))
+	}
+
+	if err := network.RemoveNetwork(info); err != nil {
+		return err
+	}
+	if err := networkStore.Delete(info.ID); err != nil {
+		return err
+	}
+
+	// Phase 21: 记录 destroy 事件
+	_ = events.Log(store.RootDir, events.TypeNetwork, events.ActionDestroy, info.ID, map[string]string{
+		"name": info.Name,
+		"type": info.Driver,
+	})
+	return nil
+}
@@ -0,0 +1,185 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/network"
+	"minidocker/internal/pod"
+	"minidocker/internal/runtime"
+	"minidocker/internal/state"
+	"minidocker/pkg/envutil"
+
+	"github.com/spf13/cobra"
+)
+
+var podCmd = &cobra.Command{
+	Use:   "pod",
+	Short: "管理 pod",
+	Long: `管理 minidocker pod（Phase 15 新增）。
+
+pod 是一组共享 network/IPC/UTS 命名空间和 cgroup 父目录的容器。
+每个 pod 有一个 infra（pause）容器持有共享命名空间，端口在 pod 级发布；
+成员容器通过 run --pod 加入。
+
+示例:
+  minidocker pod create --name web -p 8080:80
+  minidocker run -d --pod web alpine /bin/sleep 100
+  minidocker pod ps
+  minidocker pod inspect web
+  minidocker pod stop web
+  minidocker pod start web
+  minidocker pod rm -f web`,
+}
+
+func init() {
+	podCmd.AddCommand(podCreateCmd)
+	podCmd.AddCommand(podStartCmd)
+	podCmd.AddCommand(podStopCmd)
+	podCmd.AddCommand(podRmCmd)
+	podCmd.AddCommand(podPsCmd)
+	podCmd.AddCommand(podInspectCmd)
+}
+
+// openPodStores 初始化容器状态存储与 pod 存储
+func openPodStores() (*state.Store, *pod.Store, error) {
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	podStore, err := pod.NewStore(store.RootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize pod store: %w", err)
+	}
+
+	return store, podStore, nil
+}
+
+// startPodInfra 启动 pod 的 infra（pause）容器并记录其 ID。
+//
+// infra 容器不使用 rootfs：主进程是 minidocker 自身（MINIDOCKER_PAUSE=1），
+// 只负责持有 pod 的命名空间；pod 级端口映射由它的网络配置承载。
+func startPodInfra(store *state.Store, podStore *pod.Store, p *pod.Pod) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve minidocker executable: %w", err)
+	}
+
+	networkConfig := &network.NetworkConfig{Mode: network.NetworkMode(p.NetworkMode)}
+	for _, pm := range p.PortMappings {
+		networkConfig.PortMappings = append(networkConfig.PortMappings, network.PortMapping{
+			HostIP:        pm.HostIP,
+			HostPort:      pm.HostPort,
+			ContainerPort: pm.ContainerPort,
+			Protocol:      pm.Protocol,
+		})
+	}
+
+	cgroupNSMode := runtime.CgroupNSHost
+	if cgroups.IsCgroupV2() {
+		cgroupNSMode = runtime.CgroupNSPrivate
+	}
+
+	config := &runtime.ContainerConfig{
+		ID:            runtime.GenerateContainerID(),
+		Command:       []string{exe},
+		Env:           []string{envutil.PauseEnvVar + "=1"},
+		Hostname:      p.Hostname,
+		Detached:      true,
+		CgroupConfig:  &cgroups.CgroupConfig{},
+		NetworkConfig: networkConfig,
+		CgroupNS:      cgroupNSMode,
+		Pod:           p.ID,
+		CgroupParent:  p.CgroupParent,
+	}
+	config.Name = config.ID[:12] + "-infra"
+
+	if _, err := runtime.Run(config, &runtime.RunOptions{StateStore: store}); err != nil {
+		return fmt.Errorf("start infra container: %w", err)
+	}
+
+	p.InfraContainerID = config.ID
+	return podStore.Update(p)
+}
+
+// destroyPodCgroup 删除 pod 的 cgroup 父目录（尽力而为，目录不存在或非空时忽略）
+func destroyPodCgroup(p *pod.Pod) {
+	if p.CgroupParent == "" {
+		return
+	}
+	if manager, err := cgroups.NewManager(); err == nil {
+		_ = manager.Destroy(p.CgroupParent)
+	}
+}
+
+// podMembers 返回属于 pod 的成员容器（不含 infra 容器）
+func podMembers(store *state.Store, p *pod.Pod) ([]*state.ContainerState, error) {
+	states, err := store.List(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*state.ContainerState
+	for _, st := range states {
+		if st.ID == p.InfraContainerID {
+			continue
+		}
+		cfg, err := state.LoadConfig(st.GetContainerDir())
+		if err != nil || cfg.Pod != p.ID {
+			continue
+		}
+		members = append(members, st)
+	}
+	return members, nil
+}
+
+// podInfraRunning 返回 pod 的 infra 容器是否在运行
+func podInfraRunning(store *state.Store, p *pod.Pod) bool {
+	if p.InfraContainerID == "" {
+		return false
+	}
+	st, err := store.Get(p.InfraContainerID)
+	if err != nil {
+		return false
+	}
+	return st.IsRunning()
+}
+
+// podStatus 返回 pod 状态（以 infra 容器为准）
+func podStatus(store *state.Store, p *pod.Pod) string {
+	if podInfraRunning(store, p) {
+		return "Running"
+	}
+	return "Exited"
+}
+
+// resolvePodForRun 解析 run --pod 的目标 pod（Phase 15 新增）。
+//
+// pod 成员共享 infra 容器的 network/IPC/UTS 命名空间，因此与
+// --network/-p/--ipc/--uts/--hostname 冲突；端口应在 pod create 时发布。
+func resolvePodForRun(cmd *cobra.Command, store *state.Store, nameOrID string) (*pod.Pod, error) {
+	for _, flag := range []string{"network", "publish", "ipc", "uts", "hostname"} {
+		if cmd.Flags().Changed(flag) {
+			return nil, fmt.Errorf("conflicting options: --%s cannot be used with --pod (configure it on the pod instead)", flag)
+		}
+	}
+
+	podStore, err := pod.NewStore(store.RootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize pod store: %w", err)
+	}
+
+	p, err := podStore.Get(nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if !podInfraRunning(store, p) {
+		return nil, fmt.Errorf("pod %s is not running, start it with `minidocker pod start %s`", p.Name, p.Name)
+	}
+	return p, nil
+}
@@ -0,0 +1,111 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/network"
+	"minidocker/internal/pod"
+	"minidocker/internal/runtime"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	podCreateName     string
+	podCreateHostname string
+	podCreateNetwork  string
+	podCreatePublish  []string
+)
+
+var podCreateCmd = &cobra.Command{
+	Use:   "create [flags]",
+	Short: "创建并启动 pod",
+	Long: `创建一个 pod 并启动其 infra（pause）容器。
+
+infra 容器持有 pod 共享的 network/IPC/UTS 命名空间；-p 发布的端口
+映射到 infra 容器的网络命名空间，对 pod 内所有容器生效。
+
+示例:
+  minidocker pod create --name web
+  minidocker pod create --name web -p 8080:80
+  minidocker pod create --name batch --network none`,
+	Args: cobra.NoArgs,
+	RunE: createPod,
+}
+
+func init() {
+	podCreateCmd.Flags().StringVar(&podCreateName, "name", "", "pod 名称（默认: pod ID 前 12 位）")
+	podCreateCmd.Flags().StringVar(&podCreateHostname, "hostname", "", "pod 共享的主机名（默认: pod 名称）")
+	podCreateCmd.Flags().StringVar(&podCreateNetwork, "network", "bridge", "网络模式（bridge/host/none）")
+	podCreateCmd.Flags().StringArrayVarP(&podCreatePublish, "publish", "p", nil, "发布端口（格式: [hostIP:][hostPort:]containerPort[/protocol]，端口可写作范围 8000-8010）")
+}
+
+func createPod(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	p := &pod.Pod{
+		ID:        runtime.GenerateContainerID(),
+		Name:      podCreateName,
+		CreatedAt: time.Now(),
+	}
+	if p.Name == "" {
+		p.Name = p.ID[:12]
+	} else if err := validateContainerName(p.Name); err != nil {
+		return fmt.Errorf("invalid pod name: %w", err)
+	}
+
+	p.Hostname = podCreateHostname
+	if p.Hostname == "" {
+		p.Hostname = p.Name
+	}
+
+	switch mode := network.NetworkMode(strings.ToLower(podCreateNetwork)); mode {
+	case network.NetworkModeBridge, network.NetworkModeHost, network.NetworkModeNone:
+		p.NetworkMode = string(mode)
+	default:
+		return fmt.Errorf("unsupported pod network mode: %s (supported: bridge, host, none)", podCreateNetwork)
+	}
+
+	if len(podCreatePublish) > 0 && p.NetworkMode != string(network.NetworkModeBridge) {
+		return fmt.Errorf("port mapping (-p) is only supported in bridge network mode")
+	}
+	for _, spec := range podCreatePublish {
+		mappings, err := network.ParsePortMappings(spec)
+		if err != nil {
+			return fmt.Errorf("invalid port mapping %q: %w", spec, err)
+		}
+		for _, pm := range mappings {
+			p.PortMappings = append(p.PortMappings, state.PortMapping{
+				HostIP:        pm.HostIP,
+				HostPort:      pm.HostPort,
+				ContainerPort: pm.ContainerPort,
+				Protocol:      pm.Protocol,
+			})
+		}
+	}
+
+	p.CgroupParent = cgroups.GetPodCgroupPath(p.ID)
+
+	if err := podStore.Create(p); err != nil {
+		return fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	if err := startPodInfra(store, podStore, p); err != nil {
+		destroyPodCgroup(p)
+		_ = podStore.Delete(p.ID)
+		return fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	fmt.Println(p.ID)
+	return nil
+}
@@ -0,0 +1,124 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"minidocker/internal/pod"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var podInspectCmd = &cobra.Command{
+	Use:   "inspect POD [POD...]",
+	Short: "显示 pod 详细信息",
+	Long: `以 JSON 格式显示一个或多个 pod 的详细信息，包括 infra 容器和成员容器。
+
+示例:
+  minidocker pod inspect web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: inspectPods,
+}
+
+// PodInspectOutput 表示 pod inspect 的输出
+type PodInspectOutput struct {
+	ID               string                `json:"Id"`
+	Name             string                `json:"Name"`
+	Created          time.Time             `json:"Created"`
+	State            string                `json:"State"`
+	Hostname         string                `json:"Hostname"`
+	NetworkMode      string                `json:"NetworkMode"`
+	PortMappings     []state.PortMapping   `json:"PortMappings,omitempty"`
+	CgroupParent     string                `json:"CgroupParent"`
+	InfraContainerID string                `json:"InfraContainerId"`
+	NumContainers    int                   `json:"NumContainers"`
+	Containers       []PodInspectContainer `json:"Containers"`
+}
+
+// PodInspectContainer 表示 pod 中的一个容器
+type PodInspectContainer struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State string `json:"State"`
+}
+
+func inspectPods(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]PodInspectOutput, 0, len(args))
+	hasError := false
+
+	for _, nameOrID := range args {
+		p, err := podStore.Get(nameOrID)
+		if err == nil {
+			var output *PodInspectOutput
+			if output, err = inspectPod(store, p); err == nil {
+				outputs = append(outputs, *output)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error inspecting pod %s: %v\n", nameOrID, err)
+			hasError = true
+		}
+	}
+
+	if len(outputs) > 0 {
+		data, err := json.MarshalIndent(outputs, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func inspectPod(store *state.Store, p *pod.Pod) (*PodInspectOutput, error) {
+	members, err := podMembers(store, p)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &PodInspectOutput{
+		ID:               p.ID,
+		Name:             p.Name,
+		Created:          p.CreatedAt,
+		State:            podStatus(store, p),
+		Hostname:         p.Hostname,
+		NetworkMode:      p.NetworkMode,
+		PortMappings:     p.PortMappings,
+		CgroupParent:     p.CgroupParent,
+		InfraContainerID: p.InfraContainerID,
+		NumContainers:    len(members) + 1,
+	}
+
+	// infra 容器排在首位
+	if p.InfraContainerID != "" {
+		if st, err := store.Get(p.InfraContainerID); err == nil {
+			members = append([]*state.ContainerState{st}, members...)
+		}
+	}
+
+	output.Containers = make([]PodInspectContainer, 0, len(members))
+	for _, st := range members {
+		c := PodInspectContainer{ID: st.ID, State: string(st.Status)}
+		if cfg, err := state.LoadConfig(st.GetContainerDir()); err == nil {
+			c.Name = cfg.Name
+		}
+		output.Containers = append(output.Containers, c)
+	}
+
+	return output, nil
+}
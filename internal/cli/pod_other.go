@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var podCmd = &cobra.Command{
+	Use:   "pod",
+	Short: "管理 pod",
+	Long:  "管理 minidocker pod。（仅支持 Linux）",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
+
+func init() {
+	for _, sub := range []string{"create", "start", "stop", "rm", "ps", "inspect"} {
+		podCmd.AddCommand(&cobra.Command{
+			Use:   sub,
+			Short: "管理 pod（仅支持 Linux）",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("minidocker only supports Linux (current OS: %s)", runtime.GOOS)
+			},
+		})
+	}
+}
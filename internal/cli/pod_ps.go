@@ -0,0 +1,111 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	podPsQuiet  bool
+	podPsFormat string
+)
+
+var podPsCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "列出 pod",
+	Long: `列出所有 pod。
+
+示例:
+  minidocker pod ps
+  minidocker pod ps -q
+  minidocker pod ps --format json`,
+	Aliases: []string{"ls", "list"},
+	Args:    cobra.NoArgs,
+	RunE:    listPods,
+}
+
+func init() {
+	podPsCmd.Flags().BoolVarP(&podPsQuiet, "quiet", "q", false, "只显示 pod ID")
+	podPsCmd.Flags().StringVar(&podPsFormat, "format", "table", "输出格式（table/json）")
+}
+
+// PodPsEntry 表示 pod ps 输出的一行
+type PodPsEntry struct {
+	ID            string    `json:"ID"`
+	Name          string    `json:"Name"`
+	Status        string    `json:"Status"`
+	Created       time.Time `json:"Created"`
+	NumContainers int       `json:"NumContainers"`
+	InfraID       string    `json:"InfraID"`
+}
+
+func listPods(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	pods, err := podStore.List()
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	entries := make([]PodPsEntry, 0, len(pods))
+	for _, p := range pods {
+		members, err := podMembers(store, p)
+		if err != nil {
+			return fmt.Errorf("failed to list containers of pod %s: %w", p.Name, err)
+		}
+
+		// 与 podman 一致：容器数包含 infra 容器
+		entries = append(entries, PodPsEntry{
+			ID:            p.ID,
+			Name:          p.Name,
+			Status:        podStatus(store, p),
+			Created:       p.CreatedAt,
+			NumContainers: len(members) + 1,
+			InfraID:       p.InfraContainerID,
+		})
+	}
+
+	// 按创建时间倒序
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.After(entries[j].Created)
+	})
+
+	if podPsQuiet {
+		for _, e := range entries {
+			fmt.Println(shortID(e.ID))
+		}
+		return nil
+	}
+
+	switch podPsFormat {
+	case "json":
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	case "table":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "POD ID\tNAME\tSTATUS\tCREATED\t# OF CONTAINERS\tINFRA ID")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				shortID(e.ID), e.Name, e.Status, formatCreatedTime(e.Created), e.NumContainers, shortID(e.InfraID))
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown format: %s (supported: table, json)", podPsFormat)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"minidocker/internal/pod"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var podRmForce bool
+
+var podRmCmd = &cobra.Command{
+	Use:   "rm POD [POD...]",
+	Short: "删除 pod",
+	Long: `删除一个或多个 pod，连同其中的所有容器和 infra 容器。
+
+pod 必须已停止，除非使用 -f 强制删除（会先杀死 pod 内所有容器）。
+
+示例:
+  minidocker pod rm web
+  minidocker pod rm -f web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: removePods,
+}
+
+func init() {
+	podRmCmd.Flags().BoolVarP(&podRmForce, "force", "f", false, "强制删除运行中的 pod")
+}
+
+func removePods(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	hasError := false
+	for _, nameOrID := range args {
+		p, err := podStore.Get(nameOrID)
+		if err == nil {
+			err = removePod(store, podStore, p, podRmForce)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing pod %s: %v\n", nameOrID, err)
+			hasError = true
+		} else {
+			fmt.Println(nameOrID)
+		}
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// removePod 依次删除成员容器、infra 容器、pod cgroup 父目录和 pod 元数据。
+// 成员必须先于 infra 删除：infra 在成员运行期间受依赖跟踪保护。
+func removePod(store *state.Store, podStore *pod.Store, p *pod.Pod, force bool) error {
+	members, err := podMembers(store, p)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		if podInfraRunning(store, p) {
+			return fmt.Errorf("pod %s is running, stop it first or use -f to force remove", p.Name)
+		}
+		for _, m := range members {
+			if m.IsRunning() {
+				return fmt.Errorf("pod %s has running container %s, stop it first or use -f to force remove", p.Name, m.ID[:12])
+			}
+		}
+	}
+
+	for _, m := range members {
+		if err := removeContainer(store, m.ID, force); err != nil {
+			return fmt.Errorf("remove container %s: %w", m.ID[:12], err)
+		}
+	}
+
+	if p.InfraContainerID != "" {
+		if err := removeContainer(store, p.InfraContainerID, force); err != nil {
+			return fmt.Errorf("remove infra container: %w", err)
+		}
+	}
+
+	destroyPodCgroup(p)
+
+	return podStore.Delete(p.ID)
+}
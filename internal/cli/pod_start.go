@@ -0,0 +1,65 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var podStartCmd = &cobra.Command{
+	Use:   "start POD [POD...]",
+	Short: "启动 pod",
+	Long: `启动一个或多个已停止的 pod。
+
+pod 的 infra 容器已停止时会重新创建（旧 infra 容器被删除），
+之后可以通过 run --pod 向 pod 中添加新容器。已在运行的 pod 不受影响。
+
+示例:
+  minidocker pod start web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: startPods,
+}
+
+func startPods(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	hasError := false
+	for _, nameOrID := range args {
+		if err := func() error {
+			p, err := podStore.Get(nameOrID)
+			if err != nil {
+				return err
+			}
+
+			// 幂等：infra 仍在运行则无需操作
+			if podInfraRunning(store, p) {
+				return nil
+			}
+
+			// 旧 infra 容器无法重启，删除后重新创建
+			if p.InfraContainerID != "" {
+				if err := removeContainer(store, p.InfraContainerID, true); err != nil {
+					return fmt.Errorf("remove stale infra container: %w", err)
+				}
+			}
+			return startPodInfra(store, podStore, p)
+		}(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting pod %s: %v\n", nameOrID, err)
+			hasError = true
+		} else {
+			fmt.Println(nameOrID)
+		}
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"minidocker/internal/pod"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var podStopTimeout int
+
+var podStopCmd = &cobra.Command{
+	Use:   "stop POD [POD...]",
+	Short: "停止 pod",
+	Long: `停止 pod 内所有运行中的容器，最后停止 infra 容器。
+
+示例:
+  minidocker pod stop web
+  minidocker pod stop -t 5 web`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: stopPods,
+}
+
+func init() {
+	podStopCmd.Flags().IntVarP(&podStopTimeout, "time", "t", 10, "等待停止的秒数，超时后强制杀死")
+}
+
+func stopPods(cmd *cobra.Command, args []string) error {
+	store, podStore, err := openPodStores()
+	if err != nil {
+		return err
+	}
+
+	hasError := false
+	for _, nameOrID := range args {
+		p, err := podStore.Get(nameOrID)
+		if err == nil {
+			err = stopPod(store, p, podStopTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping pod %s: %v\n", nameOrID, err)
+			hasError = true
+		} else {
+			fmt.Println(nameOrID)
+		}
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// stopPod 先停止成员容器，再停止 infra 容器（成员依赖 infra 的命名空间）
+func stopPod(store *state.Store, p *pod.Pod, timeout int) error {
+	members, err := podMembers(store, p)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if err := stopContainer(store, m.ID, timeout); err != nil {
+			return fmt.Errorf("stop container %s: %w", m.ID[:12], err)
+		}
+	}
+
+	if p.InfraContainerID != "" {
+		if err := stopContainer(store, p.InfraContainerID, timeout); err != nil {
+			// infra 容器可能已被删除，按已停止处理
+			if !podInfraRunning(store, p) {
+				return nil
+			}
+			return fmt.Errorf("stop infra container: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var portCmd = &cobra.Command{
+	Use:   "port CONTAINER [PRIVATE_PORT[/PROTO]]",
+	Short: "列出容器的端口映射",
+	Long: `列出运行中容器发布的端口映射（Phase 21）。
+
+映射取自容器的网络状态，包含 -p 80、-P 等随机分配后的实际宿主机端口。
+未指定宿主机地址的映射同时发布到 IPv4 和 IPv6（容器有 IPv6 地址时），分两行输出。
+给出 PRIVATE_PORT 时只输出该容器端口对应的宿主机地址（协议默认 tcp）。
+加入 pod 或 container:<name|id> 网络的容器显示所共享网络命名空间的映射。
+
+示例:
+  minidocker port web
+  minidocker port web 80
+  minidocker port web 53/udp`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: listContainerPorts,
+}
+
+func listContainerPorts(cmd *cobra.Command, args []string) error {
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	containerState, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	// 共享其他容器网络命名空间时，端口由目标容器发布
+	ns := containerState.NetworkState
+	if ns != nil && network.NetworkMode(ns.Mode).IsContainer() {
+		target, err := store.Get(network.NetworkMode(ns.Mode).ConnectedContainer())
+		if err != nil {
+			return err
+		}
+		containerState, ns = target, target.NetworkState
+	}
+
+	var mappings []state.PortMapping
+	if ns != nil && containerState.IsRunning() {
+		mappings = ns.PortMappings
+	}
+
+	if len(args) == 1 {
+		for _, pm := range mappings {
+			for _, addr := range publishedAddresses(ns, pm) {
+				fmt.Printf("%d/%s -> %s\n", pm.ContainerPort, portProtocol(pm), addr)
+			}
+		}
+		return nil
+	}
+
+	portStr, protocol, _ := strings.Cut(args[1], "/")
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		return fmt.Errorf("invalid port: %s", args[1])
+	}
+
+	found := false
+	for _, pm := range mappings {
+		if pm.ContainerPort != uint16(port) || portProtocol(pm) != strings.ToLower(protocol) {
+			continue
+		}
+		for _, addr := range publishedAddresses(ns, pm) {
+			fmt.Println(addr)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no public port '%d/%s' published for %s", port, strings.ToLower(protocol), args[0])
+	}
+	return nil
+}
+
+// publishedAddresses 返回端口映射实际监听的宿主机地址（与防火墙规则的地址族选择一致）
+func publishedAddresses(ns *state.NetworkState, pm state.PortMapping) []string {
+	hostPort := strconv.Itoa(int(pm.HostPort))
+	if pm.HostIP != "" {
+		return []string{net.JoinHostPort(pm.HostIP, hostPort)}
+	}
+
+	addrs := []string{net.JoinHostPort("0.0.0.0", hostPort)}
+	if ns.IPv6Address != "" {
+		addrs = append(addrs, net.JoinHostPort("::", hostPort))
+	}
+	return addrs
+}
+
+// portProtocol 返回端口映射的协议，默认为 tcp
+func portProtocol(pm state.PortMapping) string {
+	if pm.Protocol == "" {
+		return "tcp"
+	}
+	return strings.ToLower(pm.Protocol)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var portCmd = &cobra.Command{
+	Use:   "port CONTAINER [PRIVATE_PORT[/PROTO]]",
+	Short: "列出容器的端口映射",
+	Long:  `列出容器的端口映射。仅支持 Linux 平台。`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker port only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"minidocker/internal/distribution"
+	"minidocker/internal/events"
 	"minidocker/internal/image"
 	"minidocker/internal/state"
 )
@@ -87,6 +88,9 @@ func runPull(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("pull image: %w", err)
 	}
 
+	// Phase 20: 记录 pull 事件
+	_ = events.Log(root, events.TypeImage, events.ActionPull, dgst.String(), map[string]string{"name": imageRef})
+
 	if pullQuiet {
 		// In quiet mode, just print the digest
 		fmt.Println(dgst.Encoded())
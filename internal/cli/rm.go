@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"minidocker/internal/cgroups"
+	"minidocker/internal/events"
 	"minidocker/internal/image"
 	"minidocker/internal/network"
 	"minidocker/internal/snapshot"
@@ -32,6 +33,8 @@ var rmCmd = &cobra.Command{
 
 容器必须已停止，除非使用 -f 强制删除。
 使用 -f 会先杀死运行中的容器再删除。
+若有运行中的容器共享了该容器的命名空间（--network/--pid/--ipc/--uts container:<id>），
+删除会被拒绝（即使使用 -f），需先停止这些容器。
 
 示例:
   minidocker rm my_container
@@ -60,7 +63,7 @@ func removeContainers(cmd *cobra.Command, args []string) error {
 
 	hasError := false
 	for _, idOrPrefix := range args {
-		if err := removeContainer(store, idOrPrefix); err != nil {
+		if err := removeContainer(store, idOrPrefix, rmForce); err != nil {
 			fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", idOrPrefix, err)
 			hasError = true
 		} else {
@@ -75,7 +78,7 @@ func removeContainers(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func removeContainer(store *state.Store, idOrPrefix string) error {
+func removeContainer(store *state.Store, idOrPrefix string, force bool) error {
 	containerState, err := store.Get(idOrPrefix)
 	if err != nil {
 		// 幂等：删除不存在的容器应成功（tests/integration/state_test.go: TestRmIdempotent）
@@ -86,9 +89,23 @@ func removeContainer(store *state.Store, idOrPrefix string) error {
 		return err
 	}
 
+	// Phase 15: 依赖跟踪——有运行中的容器共享其命名空间时拒绝删除（-f 也不例外）
+	dependents, err := store.Dependents(containerState.ID)
+	if err != nil {
+		return fmt.Errorf("check dependent containers: %w", err)
+	}
+	if len(dependents) > 0 {
+		shortIDs := make([]string, len(dependents))
+		for i, id := range dependents {
+			shortIDs[i] = id[:12]
+		}
+		return fmt.Errorf("container %s is in use by running container(s) %s sharing its namespaces; stop them first",
+			idOrPrefix, strings.Join(shortIDs, ", "))
+	}
+
 	// 检查容器是否正在运行
 	if containerState.IsRunning() {
-		if !rmForce {
+		if !force {
 			return fmt.Errorf("container %s is running, use -f to force remove", idOrPrefix)
 		}
 
@@ -139,6 +156,21 @@ func removeContainer(store *state.Store, idOrPrefix string) error {
 				MacAddress:    containerState.NetworkState.MacAddress,
 				VethHost:      containerState.NetworkState.VethHost,
 				VethContainer: containerState.NetworkState.VethContainer,
+				Network:       containerState.NetworkState.Network,     // Phase 21
+				IPv6Address:   containerState.NetworkState.IPv6Address, // Phase 21
+			}
+			// Phase 21: network connect 附加的网卡
+			for _, ep := range containerState.NetworkState.Endpoints {
+				netState.Endpoints = append(netState.Endpoints, network.Endpoint{
+					Network:       ep.Network,
+					IPAddress:     ep.IPAddress,
+					Gateway:       ep.Gateway,
+					IPv6Address:   ep.IPv6Address,
+					IPv6Gateway:   ep.IPv6Gateway,
+					MacAddress:    ep.MacAddress,
+					VethHost:      ep.VethHost,
+					VethContainer: ep.VethContainer,
+				})
 			}
 			if len(containerState.NetworkState.PortMappings) > 0 {
 				netState.PortMappings = make([]network.PortMapping, len(containerState.NetworkState.PortMappings))
@@ -165,10 +197,13 @@ func removeContainer(store *state.Store, idOrPrefix string) error {
 		}
 	}
 
-	// 删除容器状态目录
+	// 删除容器状态目录（名称随之注销，先取出用于事件）
+	name := store.NameStore.GetName(containerState.ID)
 	if err := store.Delete(containerState.ID); err != nil {
 		return fmt.Errorf("failed to delete container: %w", err)
 	}
 
+	// Phase 20: 记录 destroy 事件
+	logContainerEvent(store, containerState, name, events.ActionDestroy, nil)
 	return nil
 }
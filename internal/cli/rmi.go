@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"minidocker/internal/events"
 	"minidocker/internal/image"
 	"minidocker/internal/state"
 )
@@ -74,20 +75,26 @@ func runRmi(cmd *cobra.Command, args []string) error {
 		}
 
 		// Print results (Docker-like)
+		// Phase 20: 每个移除的标签记录 untag 事件，镜像本身被删除时记录 delete 事件
+		imageID := img.ID.String()
 		if deletesImage {
 			// When deleting by digest (or --force), all tags are removed.
 			for _, tag := range img.RepoTags {
 				fmt.Printf("Untagged: %s\n", tag)
+				_ = events.Log(root, events.TypeImage, events.ActionUntag, imageID, map[string]string{"name": tag})
 			}
 			fmt.Printf("Deleted: %s\n", shortImageID(img.ID))
+			_ = events.Log(root, events.TypeImage, events.ActionDelete, imageID, nil)
 			continue
 		}
 
 		// Tag-only deletion
 		fmt.Printf("Untagged: %s\n", ref)
+		_ = events.Log(root, events.TypeImage, events.ActionUntag, imageID, map[string]string{"name": ref})
 		// If this was the last tag, the underlying image is deleted too.
 		if len(img.RepoTags) <= 1 {
 			fmt.Printf("Deleted: %s\n", shortImageID(img.ID))
+			_ = events.Log(root, events.TypeImage, events.ActionDelete, imageID, nil)
 		}
 	}
 
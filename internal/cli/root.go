@@ -57,6 +57,14 @@ func init() {
 	rootCmd.AddCommand(loadCmd)    // Phase 8 新增
 	rootCmd.AddCommand(volumeCmd)  // Phase 10 新增
 	rootCmd.AddCommand(pullCmd)    // Phase 12 新增
+	rootCmd.AddCommand(podCmd)     // Phase 15 新增
+	rootCmd.AddCommand(attachCmd)  // Phase 18 新增
+	rootCmd.AddCommand(statsCmd)   // Phase 20 新增
+	rootCmd.AddCommand(topCmd)     // Phase 20 新增
+	rootCmd.AddCommand(eventsCmd)  // Phase 20 新增
+	rootCmd.AddCommand(waitCmd)    // Phase 20 新增
+	rootCmd.AddCommand(networkCmd) // Phase 21 新增
+	rootCmd.AddCommand(portCmd)    // Phase 21 新增
 
 	// Phase 3: 全局标志
 	rootCmd.PersistentFlags().StringVar(&rootDir, "root", "",
@@ -6,14 +6,17 @@ package cli
 import (
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"minidocker/internal/cgroups"
 	"minidocker/internal/image"
 	"minidocker/internal/network"
+	"minidocker/internal/pod"
 	"minidocker/internal/runtime"
 	"minidocker/internal/snapshot"
 	"minidocker/internal/state"
@@ -48,8 +51,49 @@ var (
 	networkMode  string   // --network，如 "bridge", "host", "none"
 	publishPorts []string // -p, --publish，如 "8080:80", "8080:80/tcp"
 
+	// Phase 21 新增：发布镜像 EXPOSE 的全部端口
+	publishAll bool // -P, --publish-all
+
+	// Phase 21 新增：用户自定义网络上的 DNS 别名
+	networkAliases []string // --network-alias
+
+	// Phase 21 新增：双栈网络上的静态 IPv6 地址
+	ip6Address string // --ip6，如 "fd00:10::20"
+
+	// Phase 21 新增：静态 IPv4 地址与容器网卡 MAC 地址
+	ipAddress  string // --ip，如 "10.10.0.20"
+	macAddress string // --mac-address，如 "02:42:ac:11:00:02"
+
+	// Phase 21 新增：容器网卡限速
+	networkOpts []string // --network-opt，如 "rate=10mbit"
+
+	// Phase 21 新增：容器的 /etc/resolv.conf 与 /etc/hosts
+	dnsServers []string // --dns，如 "1.1.1.1"
+	dnsSearch  []string // --dns-search，如 "example.com"
+	dnsOptions []string // --dns-option，如 "ndots:2"
+	addHosts   []string // --add-host，如 "db:10.0.0.5"
+
 	// Phase 10 新增：卷挂载
 	volumes []string // -v, --volume，如 "/host:/container", "volume:/container:ro"
+
+	// Phase 14 新增：设备管理
+	devices    []string // --device，如 "/dev/fuse", "/dev/sda:/dev/xvda:r"
+	privileged bool     // --privileged
+	cgroupNS   string   // --cgroupns，host 或 private
+
+	// Phase 15 新增：命名空间共享
+	pidMode string // --pid，host 或 container:<name|id>
+	ipcMode string // --ipc，host 或 container:<name|id>
+	utsMode string // --uts，host 或 container:<name|id>
+	podName string // --pod，加入已存在的 pod
+
+	// Phase 17 新增：资源限制（rlimit）与内核参数
+	ulimits []string // --ulimit，如 "nofile=1024:2048"
+	sysctls []string // --sysctl，如 "net.core.somaxconn=1024"
+
+	// Phase 19 新增：日志
+	logDriver string   // --log-driver，none/json-file/syslog/journald
+	logOpts   []string // --log-opt，如 "max-size=10m"
 )
 
 var runCmd = &cobra.Command{
@@ -78,6 +122,35 @@ var runCmd = &cobra.Command{
   - bridge: 默认模式，创建独立网络命名空间并连接到 minidocker0 bridge
   - host: 共享宿主机网络
   - none: 只有 loopback 的独立网络命名空间
+  - container:<name|id>: 加入另一个运行中容器的网络命名空间（Phase 15）
+  - <network>: 接入 network create 创建的用户自定义 bridge 网络（Phase 21）
+    用户自定义网络上的容器使用内嵌 DNS（127.0.0.11）：同网络容器可按容器名、
+    --network-alias 别名或短 ID 互相解析，其他域名转发给宿主机的 DNS
+    双栈网络（network create --ipv6）上的容器同时获得 IPv6 地址，--ip6 指定静态地址；
+    -p 未指定宿主机地址时同时发布到 IPv6，[::1]:8080:80 只发布到 IPv6
+    --ip 在用户自定义网络的子网内指定静态 IPv4 地址（已被占用时报错）
+  - --mac-address 指定容器网卡的 MAC 地址（bridge 模式，必须是单播地址）
+  - --network-opt rate=10mbit 用 tc 在宿主机端 veth 上限制容器的收发速率（bridge 模式）；
+    ingress-rate/egress-rate 分别限制接收/发送方向，单位 kbit、mbit、gbit 或 kbps、mbps（字节）
+  - -p 省略宿主机端口（-p 80、-p 127.0.0.1::80）或 -P 发布镜像 EXPOSE 的端口时，
+    从 ip_local_port_range 分配未被监听、也未被其他容器发布的宿主机端口；
+    minidocker port CONTAINER 查看实际映射
+  - -p 发布的端口可从宿主机 127.0.0.1 和容器自身访问；daemon.json 启用 userland-proxy 时
+    由 shim 为每个发布端口启动用户态代理
+
+DNS 与 /etc/hosts（Phase 21）：
+  - 容器的 /etc/hosts、/etc/resolv.conf、/etc/hostname 由 minidocker 生成并挂载
+  - --dns IP、--dns-search DOMAIN、--dns-option OPT 覆盖沿用自宿主机的 resolv.conf 配置
+    （用户自定义网络上 --dns 作为内嵌 DNS 的上游）
+  - --add-host name:ip 追加 /etc/hosts 条目
+  - container:<name|id> 网络模式沿用目标容器的 hosts/resolv.conf
+
+命名空间共享（Phase 15）：
+  - --pid host|container:<name|id>   共享宿主或其他容器的 PID namespace
+  - --ipc host|container:<name|id>   共享宿主或其他容器的 IPC namespace
+  - --uts host|container:<name|id>   共享宿主或其他容器的 UTS namespace（不可与 --hostname 同用）
+  - 被共享的容器在依赖者运行期间不能被删除
+  - --pod NAME                       加入 pod：共享其 infra 容器的 network/IPC/UTS 命名空间与 cgroup 父目录
 
 卷挂载（Phase 10）：
   - -v /host/path:/container/path      # Bind mount
@@ -85,6 +158,20 @@ var runCmd = &cobra.Command{
   - -v volume_name:/container/path     # Named volume
   - -v volume_name:/container/path:ro  # Named volume（只读）
 
+设备管理（Phase 14）：
+  - 默认仅允许访问 /dev/null、/dev/zero、/dev/tty 等基础设备（cgroup v2 eBPF 设备控制器）
+  - --device /dev/fuse[:/dev/fuse][:rwm]  将宿主设备映射进容器并加入白名单
+  - --privileged                          解除设备白名单限制
+  - --cgroupns host|private               cgroup namespace 模式（cgroup v2 默认 private，
+                                          容器内 /sys/fs/cgroup 只显示自己的 cgroup）
+
+资源限制与内核参数（Phase 17）：
+  - --ulimit nofile=1024:2048    进程资源限制（<name>=<soft>[:<hard>]，-1 表示不限制）
+  - --sysctl net.core.somaxconn=1024
+                                 写入容器 /proc/sys 的内核参数，仅允许命名空间化的参数：
+                                 net.*（需私有网络）、kernel.shm*/kernel.msg*/fs.mqueue.*（需私有 IPC）
+                                 非 --privileged 容器启动后 /proc/sys 为只读
+
 容器配置（Phase 11）：
   - --name       容器名称，用于引用容器
   - --hostname   容器主机名（默认: 容器 ID 前 12 位）
@@ -92,18 +179,48 @@ var runCmd = &cobra.Command{
   - -w, --workdir 容器内工作目录
   - -u, --user   运行用户（格式: user[:group] 或 uid[:gid]）
 
+终端（Phase 18）：
+  - -t           分配伪终端，容器内程序获得控制终端（作业控制、行编辑）
+  - -i           保持 STDIN 打开；-it 时本地终端切换为 raw mode，窗口大小随 SIGWINCH 同步
+  - -dit         后台容器同样持有 PTY（master 由 shim 保管），输出写入日志
+
+日志（Phase 19）：
+  - --log-driver json-file   默认；每行一条带流标签与时间戳的 JSON 记录，写入容器目录下的 logs/
+      --log-opt max-size=10m   单个日志文件的最大大小，超过后轮转（默认不限制）
+      --log-opt max-file=3     保留的日志文件数（包含当前文件，需同时设置 max-size）
+  - --log-driver none        不保存日志
+  - --log-driver syslog      发送到 syslog（--log-opt syslog-address=unixgram:///dev/log、
+                             syslog-facility=daemon、tag=<容器 ID 前 12 位>）
+  - --log-driver journald    以原生协议发送到 journald（--log-opt journald-address、tag）
+  - 只有 json-file 日志可以通过 minidocker logs 读取
+
 示例:
   minidocker run alpine:latest /bin/sh
   minidocker run -it alpine /bin/sh
+  minidocker run -dit alpine /bin/sh
   minidocker run alpine /bin/echo "Hello from container"
   minidocker run -d alpine /bin/sleep 100
   minidocker run -m 512m --cpus 0.5 alpine /bin/sh
   minidocker run --pids-limit 100 alpine /bin/sh
   minidocker run --network bridge alpine /bin/sh
   minidocker run --network host alpine /bin/sh
+  minidocker run --network mynet alpine /bin/sh
+  minidocker run -d --network mynet --network-alias db alpine sleep 1000
+  minidocker run --dns 1.1.1.1 --add-host db:10.0.0.5 alpine cat /etc/hosts
+  minidocker run --network container:web --pid container:web alpine /bin/sh
   minidocker run -p 8080:80 alpine /bin/httpd
+  minidocker run -d -p 8000-8010:8000-8010 -p 127.0.0.1::53/udp alpine /bin/sh
+  minidocker run -d -P nginx nginx
+  minidocker run -d --network v6net --ip6 fd00:10::20 -p [::1]:8080:80 alpine /bin/httpd
+  minidocker run -d --network mynet --ip 10.10.0.20 --mac-address 02:42:0a:0a:00:14 alpine sleep 1000
+  minidocker run -d --network-opt rate=10mbit --network-opt egress-rate=1mbit alpine sleep 1000
   minidocker run -v /host/data:/data alpine /bin/sh
   minidocker run -v myvolume:/data alpine /bin/sh
+  minidocker run --device /dev/fuse:/dev/fuse:rwm alpine /bin/sh
+  minidocker run --privileged alpine /bin/sh
+  minidocker run --ulimit nofile=1024:2048 --sysctl kernel.shmmax=268435456 alpine /bin/sh
+  minidocker run -d --log-opt max-size=10m --log-opt max-file=3 alpine /bin/sh
+  minidocker run -d --log-driver syslog --log-opt tag=web alpine /bin/sh
   minidocker run --name my-container alpine /bin/sh
   minidocker run --hostname myhost alpine /bin/sh
   minidocker run -e FOO=bar -e BAZ=qux alpine /bin/sh
@@ -115,10 +232,9 @@ var runCmd = &cobra.Command{
 }
 
 func init() {
-	// NOTE: Phase 1 暂不实现 PTY 分配/终端控制。保留 `-t/-i` 形态用于减少后续
-	// Phase 5（exec -it / 真实 TTY）引入时的 CLI 破坏性变更。
-	runCmd.Flags().BoolVarP(&tty, "tty", "t", false, "TTY 模式（预留：Phase 1 不分配 PTY）")
-	runCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "保持 STDIN 打开（预留：Phase 1 默认已透传 STDIN）")
+	// Phase 18: -t 分配真实 PTY（前台由 CLI 持有 master，-d 时由 shim 持有）
+	runCmd.Flags().BoolVarP(&tty, "tty", "t", false, "分配伪终端")
+	runCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "保持 STDIN 打开")
 
 	// Phase 2 新增：rootfs 参数
 	runCmd.Flags().StringVar(&rootfs, "rootfs", "", "容器根文件系统路径（例如：busybox 解压目录）")
@@ -135,12 +251,43 @@ func init() {
 	runCmd.Flags().Int64Var(&pidsLimit, "pids-limit", 0, "进程数限制")
 
 	// Phase 7 新增：网络配置
-	runCmd.Flags().StringVar(&networkMode, "network", "bridge", "网络模式（bridge/host/none）")
-	runCmd.Flags().StringArrayVarP(&publishPorts, "publish", "p", nil, "发布端口（格式: [hostIP:]hostPort:containerPort[/protocol]）")
+	runCmd.Flags().StringVar(&networkMode, "network", "bridge", "网络模式（bridge/host/none/container:<name|id>）或用户自定义网络名")
+	runCmd.Flags().StringArrayVarP(&publishPorts, "publish", "p", nil, "发布端口（格式: [hostIP:][hostPort:]containerPort[/protocol]，端口可写作范围 8000-8010，IPv6 地址写作 [::1]）")
+	runCmd.Flags().BoolVarP(&publishAll, "publish-all", "P", false, "将镜像 EXPOSE 的全部端口发布到随机宿主机端口（Phase 21）")
+	runCmd.Flags().StringArrayVar(&networkAliases, "network-alias", nil, "在用户自定义网络上为容器添加 DNS 别名（Phase 21）")
+	runCmd.Flags().StringVar(&ip6Address, "ip6", "", "在启用 IPv6 的用户自定义网络上指定容器的 IPv6 地址（Phase 21）")
+	runCmd.Flags().StringVar(&ipAddress, "ip", "", "在用户自定义网络上指定容器的 IPv4 地址（Phase 21）")
+	runCmd.Flags().StringVar(&macAddress, "mac-address", "", "容器网卡的 MAC 地址（Phase 21，仅 bridge 模式）")
+	runCmd.Flags().StringArrayVar(&networkOpts, "network-opt", nil, "容器网卡选项（Phase 21，仅 bridge 模式；rate|ingress-rate|egress-rate=10mbit）")
+
+	// Phase 21 新增：/etc/resolv.conf 与 /etc/hosts
+	runCmd.Flags().StringArrayVar(&dnsServers, "dns", nil, "自定义 DNS 服务器")
+	runCmd.Flags().StringArrayVar(&dnsSearch, "dns-search", nil, "自定义 DNS search 域（\".\" 表示不设置）")
+	runCmd.Flags().StringArrayVar(&dnsOptions, "dns-option", nil, "自定义 DNS 选项（例如: ndots:2）")
+	runCmd.Flags().StringArrayVar(&addHosts, "add-host", nil, "添加 /etc/hosts 条目（格式: name:ip）")
 
 	// Phase 10 新增：卷挂载
 	runCmd.Flags().StringArrayVarP(&volumes, "volume", "v", nil, "绑定挂载或命名卷（格式: /host:/container[:ro] 或 name:/container[:ro]）")
 
+	// Phase 14 新增：设备管理
+	runCmd.Flags().StringArrayVar(&devices, "device", nil, "映射宿主设备（格式: /host/dev[:/container/dev][:rwm]）")
+	runCmd.Flags().BoolVar(&privileged, "privileged", false, "解除默认设备白名单限制")
+	runCmd.Flags().StringVar(&cgroupNS, "cgroupns", "", "cgroup namespace 模式（host/private，cgroup v2 默认 private）")
+
+	// Phase 15 新增：命名空间共享
+	runCmd.Flags().StringVar(&pidMode, "pid", "", "PID namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&ipcMode, "ipc", "", "IPC namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&utsMode, "uts", "", "UTS namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&podName, "pod", "", "加入指定 pod（见 minidocker pod create）")
+
+	// Phase 17 新增：资源限制（rlimit）与内核参数
+	runCmd.Flags().StringArrayVar(&ulimits, "ulimit", nil, "进程资源限制（格式: <name>=<soft>[:<hard>]，如 nofile=1024:2048）")
+	runCmd.Flags().StringArrayVar(&sysctls, "sysctl", nil, "命名空间化的内核参数（格式: KEY=VALUE，如 net.core.somaxconn=1024）")
+
+	// Phase 19 新增：日志
+	runCmd.Flags().StringVar(&logDriver, "log-driver", runtime.DefaultLogDriver, "日志驱动（none、json-file、syslog、journald）")
+	runCmd.Flags().StringArrayVar(&logOpts, "log-opt", nil, "日志驱动选项（格式: KEY=VALUE，如 max-size=10m、max-file=3）")
+
 	// Phase 11 新增：容器配置
 	runCmd.Flags().StringVar(&containerName, "name", "", "容器名称")
 	runCmd.Flags().StringVar(&hostname, "hostname", "", "容器主机名（默认: 容器 ID 前 12 位）")
@@ -194,12 +341,18 @@ func runContainer(cmd *cobra.Command, args []string) error {
 	}
 
 	// Phase 6: 检查 cgroup v2 支持
-	if cgroupConfig != nil && !cgroupConfig.IsEmpty() {
+	// 设备白名单在 cgroup v1 上会被跳过，因此只有资源限制需要强制 v2
+	if cgroupConfig.HasLimits() {
 		if !cgroups.IsCgroupV2() {
 			return fmt.Errorf("resource limits require cgroup v2, but system uses cgroup v1")
 		}
 	}
 
+	// Phase 18: 前台 -it 需要本地终端（对齐 Docker）
+	if tty && interactive && !detach && !runtime.IsTerminal(os.Stdin) {
+		return fmt.Errorf("the input device is not a TTY")
+	}
+
 	// Phase 7: 解析网络配置
 	networkConfig, err := parseNetworkFlags()
 	if err != nil {
@@ -212,6 +365,56 @@ func runContainer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid volume configuration: %w", err)
 	}
 
+	// Phase 21: 校验 DNS 与 /etc/hosts 配置
+	if err := validateDNSFlags(); err != nil {
+		return err
+	}
+
+	// Phase 14: 解析设备映射
+	deviceList, err := parseDeviceFlags()
+	if err != nil {
+		return fmt.Errorf("invalid device configuration: %w", err)
+	}
+
+	// Phase 14: 解析 cgroup namespace 模式
+	cgroupNSMode, err := parseCgroupNSFlag()
+	if err != nil {
+		return fmt.Errorf("invalid cgroupns: %w", err)
+	}
+
+	// Phase 15: 解析命名空间共享模式（container:<name|id> 在初始化状态存储后解析为完整 ID）
+	pidNSMode, err := parseNamespaceModeFlag("pid", pidMode)
+	if err != nil {
+		return err
+	}
+	ipcNSMode, err := parseNamespaceModeFlag("ipc", ipcMode)
+	if err != nil {
+		return err
+	}
+	utsNSMode, err := parseNamespaceModeFlag("uts", utsMode)
+	if err != nil {
+		return err
+	}
+	if hostname != "" && !utsNSMode.IsPrivate() {
+		return fmt.Errorf("conflicting options: --hostname cannot be used with --uts %s", utsNSMode)
+	}
+
+	// Phase 17: 解析资源限制与内核参数（sysctl 白名单在确定命名空间模式后校验）
+	ulimitList, err := parseUlimitFlags()
+	if err != nil {
+		return fmt.Errorf("invalid ulimit: %w", err)
+	}
+	sysctlMap, err := parseSysctlFlags()
+	if err != nil {
+		return fmt.Errorf("invalid sysctl: %w", err)
+	}
+
+	// Phase 19: 解析日志选项
+	logOptMap, err := parseLogOptFlags()
+	if err != nil {
+		return err
+	}
+
 	// Phase 11: 解析容器配置
 	parsedEnvVars, err := parseEnvVars(envVars)
 	if err != nil {
@@ -231,10 +434,60 @@ func runContainer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize state store: %w", err)
 	}
 
+	// Phase 15: --pod 以 container:<infra-id> 模式共享 pod 的 network/IPC/UTS 命名空间
+	var podInfo *pod.Pod
+	if podName != "" {
+		podInfo, err = resolvePodForRun(cmd, store, podName)
+		if err != nil {
+			return err
+		}
+		infra := network.NetworkModeContainerPrefix + podInfo.InfraContainerID
+		networkConfig.Mode = network.NetworkMode(infra)
+		ipcNSMode = runtime.NamespaceMode(infra)
+		utsNSMode = runtime.NamespaceMode(infra)
+	}
+
+	// Phase 15: 将 container:<name|id> 解析为 container:<完整 ID>，目标必须正在运行
+	if networkConfig.Mode.IsContainer() {
+		target, err := resolveContainerTarget(store, "network", networkConfig.Mode.ConnectedContainer())
+		if err != nil {
+			return err
+		}
+		networkConfig.Mode = network.NetworkMode(network.NetworkModeContainerPrefix + target)
+	}
+	// Phase 21: 用户自定义网络必须已存在（network create）
+	if networkConfig.Network != "" {
+		if err := resolveUserNetwork(store, networkConfig); err != nil {
+			return err
+		}
+	}
+	if len(networkConfig.Aliases) > 0 && networkConfig.Network == "" {
+		return fmt.Errorf("--network-alias is only supported for containers on user-defined networks")
+	}
+	if networkConfig.IPv6Address != "" && networkConfig.Network == "" {
+		return fmt.Errorf("--ip6 is only supported for containers on user-defined networks")
+	}
+	if networkConfig.IPAddress != "" && networkConfig.Network == "" {
+		return fmt.Errorf("--ip is only supported for containers on user-defined networks")
+	}
+	// Phase 21: 共享其他容器网络命名空间时沿用其 hosts/resolv.conf
+	if networkConfig.Mode.IsContainer() && len(dnsServers)+len(dnsSearch)+len(dnsOptions)+len(addHosts) > 0 {
+		return fmt.Errorf("conflicting options: --dns, --dns-search, --dns-option and --add-host cannot be used with network mode %s", networkConfig.Mode)
+	}
+	for _, m := range []*runtime.NamespaceMode{&pidNSMode, &ipcNSMode, &utsNSMode} {
+		if !m.IsContainer() {
+			continue
+		}
+		target, err := resolveContainerTarget(store, "namespace", m.Container())
+		if err != nil {
+			return err
+		}
+		*m = runtime.NamespaceMode(runtime.NamespaceModeContainerPrefix + target)
+	}
+
 	config := &runtime.ContainerConfig{
-		Command: command[0:1],
-		Args:    command[1:],
-		// Phase 1: 记录 `-t` 但不分配 PTY（见 docs/phase1-dev-notes.md）。
+		Command:       command[0:1],
+		Args:          command[1:],
 		TTY:           tty,
 		Rootfs:        rootfs,        // Phase 2 新增
 		Detached:      detach,        // Phase 3 新增
@@ -246,8 +499,40 @@ func runContainer(cmd *cobra.Command, args []string) error {
 		Env:           parsedEnvVars, // Phase 11 新增
 		WorkingDir:    workDir,       // Phase 11 新增
 		User:          user,          // Phase 11 新增
+		Devices:       deviceList,    // Phase 14 新增
+		Privileged:    privileged,    // Phase 14 新增
+		CgroupNS:      cgroupNSMode,  // Phase 14 新增
+		PidMode:       pidNSMode,     // Phase 15 新增
+		IpcMode:       ipcNSMode,     // Phase 15 新增
+		UTSMode:       utsNSMode,     // Phase 15 新增
 	}
 
+	// Phase 18: -i 保持 STDIN 打开（-it 时本地终端以 raw mode 连接到容器 PTY）
+	config.Interactive = interactive
+
+	// Phase 15: pod 成员的 cgroup 创建在 pod 的 cgroup 父目录下
+	if podInfo != nil {
+		config.Pod = podInfo.ID
+		config.CgroupParent = podInfo.CgroupParent
+	}
+
+	// Phase 17: 资源限制与内核参数；sysctl 只能修改容器私有的命名空间
+	config.Ulimits = ulimitList
+	config.Sysctls = sysctlMap
+	if err := runtime.ValidateSysctls(config); err != nil {
+		return err
+	}
+
+	// Phase 19: 日志驱动与选项
+	config.LogDriver = logDriver
+	config.LogOpts = logOptMap
+
+	// Phase 21: DNS 与 /etc/hosts（文件在网络配置完成后生成并挂载到容器 /etc）
+	config.DNS = dnsServers
+	config.DNSSearch = dnsSearch
+	config.DNSOptions = dnsOptions
+	config.ExtraHosts = addHosts
+
 	// 生成容器 ID（64位十六进制，前12位用作默认主机名）
 	config.ID = runtime.GenerateContainerID()
 	// Phase 11: 支持自定义主机名，默认使用容器 ID 前 12 位
@@ -272,6 +557,15 @@ func runContainer(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("image not found: %w", err)
 		}
 
+		// Phase 21: -P 发布镜像 EXPOSE 的端口（宿主机端口在网络配置时分配）
+		if publishAll {
+			exposed, err := exposedPortMappings(img, networkConfig.PortMappings)
+			if err != nil {
+				return err
+			}
+			networkConfig.PortMappings = append(networkConfig.PortMappings, exposed...)
+		}
+
 		// 后台模式（-d）：snapshot 由 shim 进程准备与清理（对齐 containerd-shim 模型）。
 		// 前台模式：在父进程中准备 snapshot（提取层并挂载 overlay）。
 		if !detach {
@@ -468,106 +762,218 @@ func parseMemoryString(s string) (int64, error) {
 	return int64(num * float64(multiplier)), nil
 }
 
+// resolveUserNetwork 校验 --network 指定的用户自定义网络，并规范化为网络名（Phase 21 新增）
+func resolveUserNetwork(store *state.Store, config *network.NetworkConfig) error {
+	networkStore, err := network.NewStore(store.RootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize network store: %w", err)
+	}
+	info, err := networkStore.Get(config.Network)
+	if err != nil {
+		return err
+	}
+	if info.Driver != network.DriverBridge {
+		// 只有内置的 host/none 网络不是 bridge 驱动（通过 ID 引用时）
+		return fmt.Errorf("network %s (driver %s) must be referenced by name: --network %s", info.Name, info.Driver, info.Name)
+	}
+	if info.Builtin {
+		// 通过 ID 引用了内置 bridge 网络
+		config.Network = ""
+		return nil
+	}
+	if config.IPv6Address != "" && !info.EnableIPv6 {
+		return fmt.Errorf("network %s is not IPv6-enabled: --ip6 requires a network created with --ipv6", info.Name)
+	}
+	config.Network = info.Name
+	return nil
+}
+
+// validateDNSFlags 校验 --dns、--dns-search、--dns-option 和 --add-host（Phase 21 新增）
+func validateDNSFlags() error {
+	for _, server := range dnsServers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("invalid --dns %q: must be an IP address", server)
+		}
+	}
+	for _, domain := range dnsSearch {
+		if domain == "" || strings.ContainsAny(domain, " \t") {
+			return fmt.Errorf("invalid --dns-search %q", domain)
+		}
+	}
+	for _, opt := range dnsOptions {
+		if opt == "" || strings.ContainsAny(opt, " \t") {
+			return fmt.Errorf("invalid --dns-option %q", opt)
+		}
+	}
+	for _, entry := range addHosts {
+		name, ip, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || strings.ContainsAny(name, " \t") || net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid --add-host %q: expected name:ip", entry)
+		}
+	}
+	return nil
+}
+
 // parseNetworkFlags 解析网络配置参数
 func parseNetworkFlags() (*network.NetworkConfig, error) {
 	config := &network.NetworkConfig{}
 
 	// 解析网络模式
-	switch strings.ToLower(networkMode) {
-	case "bridge":
+	switch mode := strings.ToLower(networkMode); {
+	case strings.HasPrefix(mode, network.NetworkModeContainerPrefix):
+		// Phase 15: container:<name|id>，目标在状态存储初始化后解析
+		// 名称区分大小写，这里保留原始输入
+		target := networkMode[len(network.NetworkModeContainerPrefix):]
+		if target == "" {
+			return nil, fmt.Errorf("invalid network mode %q: missing container name or ID", networkMode)
+		}
+		config.Mode = network.NetworkMode(network.NetworkModeContainerPrefix + target)
+	case mode == "bridge":
 		config.Mode = network.NetworkModeBridge
-	case "host":
+	case mode == "host":
 		config.Mode = network.NetworkModeHost
-	case "none":
+	case mode == "none":
 		config.Mode = network.NetworkModeNone
+	case networkMode != "":
+		// Phase 21: 其他值视为用户自定义网络名（bridge 模式），存在性在状态存储初始化后校验
+		config.Mode = network.NetworkModeBridge
+		config.Network = networkMode
 	default:
-		return nil, fmt.Errorf("unsupported network mode: %s (supported: bridge, host, none)", networkMode)
+		return nil, fmt.Errorf("unsupported network mode: %s (supported: bridge, host, none, container:<name|id>, <network>)", networkMode)
+	}
+
+	// Phase 21: 网络别名（是否为用户自定义网络在状态存储初始化后校验）
+	for _, alias := range networkAliases {
+		if err := network.ValidateAlias(alias); err != nil {
+			return nil, err
+		}
+		config.Aliases = append(config.Aliases, alias)
+	}
+
+	// Phase 21: 静态 IPv6 地址（网络是否启用 IPv6 在状态存储初始化后校验）
+	if ip6Address != "" {
+		ip := net.ParseIP(ip6Address)
+		if ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid --ip6 %q: must be an IPv6 address", ip6Address)
+		}
+		config.IPv6Address = ip.String()
+	}
+
+	// Phase 21: 静态 IPv4 地址（是否在子网内、是否已被占用由 IPAM 在分配时校验）
+	if ipAddress != "" {
+		ip := net.ParseIP(ipAddress)
+		if ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("invalid --ip %q: must be an IPv4 address", ipAddress)
+		}
+		config.IPAddress = ip.To4().String()
+	}
+
+	// Phase 21: 容器网卡 MAC 地址
+	if macAddress != "" {
+		if config.Mode != network.NetworkModeBridge {
+			return nil, fmt.Errorf("--mac-address is only supported in bridge network mode")
+		}
+		mac, err := net.ParseMAC(macAddress)
+		if err != nil || len(mac) != 6 {
+			return nil, fmt.Errorf("invalid --mac-address %q: must be a 48-bit MAC address", macAddress)
+		}
+		if mac[0]&0x01 != 0 {
+			return nil, fmt.Errorf("invalid --mac-address %q: must be a unicast address", macAddress)
+		}
+		config.MacAddress = mac.String()
+	}
+
+	// Phase 21: 容器网卡限速
+	if len(networkOpts) > 0 {
+		if config.Mode != network.NetworkModeBridge {
+			return nil, fmt.Errorf("--network-opt is only supported in bridge network mode")
+		}
+		if err := parseNetworkOpts(config); err != nil {
+			return nil, err
+		}
 	}
 
 	// 解析端口映射（仅 bridge 模式支持）
-	if len(publishPorts) > 0 {
+	if len(publishPorts) > 0 || publishAll {
 		if config.Mode != network.NetworkModeBridge {
-			return nil, fmt.Errorf("port mapping (-p) is only supported in bridge network mode")
+			return nil, fmt.Errorf("port mapping (-p/-P) is only supported in bridge network mode")
 		}
 
 		for _, portSpec := range publishPorts {
-			pm, err := parsePortMapping(portSpec)
+			mappings, err := network.ParsePortMappings(portSpec)
 			if err != nil {
 				return nil, fmt.Errorf("invalid port mapping %q: %w", portSpec, err)
 			}
-			config.PortMappings = append(config.PortMappings, pm)
+			config.PortMappings = append(config.PortMappings, mappings...)
 		}
 	}
 
 	return config, nil
 }
 
-// parsePortMapping 解析端口映射字符串
-// 支持格式:
-//   - hostPort:containerPort (例如: 8080:80)
-//   - hostPort:containerPort/protocol (例如: 8080:80/tcp)
-//   - hostIP:hostPort:containerPort (例如: 127.0.0.1:8080:80)
-//   - hostIP:hostPort:containerPort/protocol (例如: 127.0.0.1:8080:80/tcp)
-func parsePortMapping(spec string) (network.PortMapping, error) {
-	pm := network.PortMapping{
-		Protocol: "tcp", // 默认 TCP
+// parseNetworkOpts 解析 --network-opt KEY=VALUE（Phase 21 新增）。
+// rate 同时限制容器的发送和接收方向，ingress-rate/egress-rate 只限制一个方向。
+func parseNetworkOpts(config *network.NetworkConfig) error {
+	for _, opt := range networkOpts {
+		key, value, ok := strings.Cut(opt, "=")
+		if !ok {
+			return fmt.Errorf("invalid --network-opt %q: expected KEY=VALUE", opt)
+		}
+		switch key {
+		case "rate", "ingress-rate", "egress-rate":
+			rate, err := network.ParseRate(value)
+			if err != nil {
+				return fmt.Errorf("invalid --network-opt %s: %w", key, err)
+			}
+			if key != "egress-rate" {
+				config.IngressRate = rate
+			}
+			if key != "ingress-rate" {
+				config.EgressRate = rate
+			}
+		default:
+			return fmt.Errorf("unsupported network option: %s (supported: rate, ingress-rate, egress-rate)", key)
+		}
 	}
+	return nil
+}
 
-	// 分离协议部分
-	if idx := strings.LastIndex(spec, "/"); idx != -1 {
-		protocol := strings.ToLower(spec[idx+1:])
-		if protocol != "tcp" && protocol != "udp" {
-			return pm, fmt.Errorf("unsupported protocol: %s (supported: tcp, udp)", protocol)
-		}
-		pm.Protocol = protocol
-		spec = spec[:idx]
+// exposedPortMappings 返回镜像 EXPOSE 的端口对应的随机宿主机端口映射（Phase 21 新增），
+// 跳过已由 -p 发布的容器端口
+func exposedPortMappings(img *image.Image, published []network.PortMapping) ([]network.PortMapping, error) {
+	if img.Config == nil {
+		return nil, nil
 	}
 
-	// 分离 hostIP:hostPort:containerPort 或 hostPort:containerPort
-	parts := strings.Split(spec, ":")
-	switch len(parts) {
-	case 2:
-		// hostPort:containerPort
-		hostPort, err := parsePort(parts[0])
-		if err != nil {
-			return pm, fmt.Errorf("invalid host port: %w", err)
-		}
-		containerPort, err := parsePort(parts[1])
-		if err != nil {
-			return pm, fmt.Errorf("invalid container port: %w", err)
-		}
-		pm.HostPort = hostPort
-		pm.ContainerPort = containerPort
-	case 3:
-		// hostIP:hostPort:containerPort
-		pm.HostIP = parts[0]
-		hostPort, err := parsePort(parts[1])
+	exposed := make([]string, 0, len(img.Config.Config.ExposedPorts))
+	for port := range img.Config.Config.ExposedPorts {
+		exposed = append(exposed, port)
+	}
+	sort.Strings(exposed)
+
+	var mappings []network.PortMapping
+	for _, port := range exposed {
+		parsed, err := network.ParsePortMappings(port)
 		if err != nil {
-			return pm, fmt.Errorf("invalid host port: %w", err)
+			return nil, fmt.Errorf("invalid exposed port %q in image config: %w", port, err)
 		}
-		containerPort, err := parsePort(parts[2])
-		if err != nil {
-			return pm, fmt.Errorf("invalid container port: %w", err)
+		for _, pm := range parsed {
+			if !isPortPublished(published, pm) {
+				mappings = append(mappings, pm)
+			}
 		}
-		pm.HostPort = hostPort
-		pm.ContainerPort = containerPort
-	default:
-		return pm, fmt.Errorf("invalid format, expected hostPort:containerPort or hostIP:hostPort:containerPort")
 	}
-
-	return pm, nil
+	return mappings, nil
 }
 
-// parsePort 解析端口号字符串
-func parsePort(s string) (uint16, error) {
-	port, err := strconv.ParseUint(s, 10, 16)
-	if err != nil {
-		return 0, fmt.Errorf("invalid port number: %s", s)
-	}
-	if port == 0 {
-		return 0, fmt.Errorf("port must be between 1 and 65535")
+// isPortPublished 判断容器端口是否已在映射列表中
+func isPortPublished(mappings []network.PortMapping, pm network.PortMapping) bool {
+	for _, m := range mappings {
+		if m.ContainerPort == pm.ContainerPort && m.GetProtocol() == pm.GetProtocol() {
+			return true
+		}
 	}
-	return uint16(port), nil
+	return false
 }
 
 // parseVolumeFlags 解析 -v 参数并返回 Mount 配置列表
@@ -658,6 +1064,187 @@ func parseVolumeSpec(spec string) (volume.Mount, error) {
 	return mount, nil
 }
 
+// parseDeviceFlags 解析 --device 参数并返回设备配置列表
+func parseDeviceFlags() ([]runtime.Device, error) {
+	var result []runtime.Device
+
+	for _, spec := range devices {
+		device, err := parseDeviceSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device spec %q: %w", spec, err)
+		}
+		result = append(result, *device)
+	}
+
+	return result, nil
+}
+
+// parseDeviceSpec 解析单个设备映射规格（对齐 Docker --device）
+// 支持格式:
+//   - /dev/fuse                      -> 同路径映射，权限 rwm
+//   - /dev/fuse:/dev/fuse            -> 指定容器内路径
+//   - /dev/fuse:rw                   -> 指定权限
+//   - /dev/sda:/dev/xvda:r           -> 指定容器内路径和权限
+func parseDeviceSpec(spec string) (*runtime.Device, error) {
+	parts := strings.Split(spec, ":")
+
+	var hostPath, containerPath string
+	permissions := "rwm"
+
+	switch len(parts) {
+	case 1:
+		hostPath = parts[0]
+	case 2:
+		hostPath = parts[0]
+		// 第二段不是绝对路径且是合法权限时，视为权限
+		if !filepath.IsAbs(parts[1]) && isValidDevicePermissions(parts[1]) {
+			permissions = parts[1]
+		} else {
+			containerPath = parts[1]
+		}
+	case 3:
+		hostPath, containerPath, permissions = parts[0], parts[1], parts[2]
+	default:
+		return nil, fmt.Errorf("invalid format, expected host[:container][:permissions]")
+	}
+
+	if !filepath.IsAbs(hostPath) {
+		return nil, fmt.Errorf("host device path must be absolute: %s", hostPath)
+	}
+	if containerPath != "" && !filepath.IsAbs(containerPath) {
+		return nil, fmt.Errorf("container device path must be absolute: %s", containerPath)
+	}
+	if !isValidDevicePermissions(permissions) {
+		return nil, fmt.Errorf("invalid permissions %q (must be a combination of r, w, m)", permissions)
+	}
+
+	return runtime.DeviceFromPath(hostPath, containerPath, permissions)
+}
+
+// isValidDevicePermissions 检查权限字符串是否为 rwm 的非空子集（不允许重复）
+func isValidDevicePermissions(perms string) bool {
+	if perms == "" || len(perms) > 3 {
+		return false
+	}
+	seen := make(map[rune]bool)
+	for _, c := range perms {
+		if !strings.ContainsRune("rwm", c) || seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+	return true
+}
+
+// parseCgroupNSFlag 解析 --cgroupns 参数
+// 对齐 Docker：未指定时 cgroup v2 默认 private，cgroup v1 默认 host
+func parseCgroupNSFlag() (string, error) {
+	switch cgroupNS {
+	case "":
+		if cgroups.IsCgroupV2() {
+			return runtime.CgroupNSPrivate, nil
+		}
+		return runtime.CgroupNSHost, nil
+	case runtime.CgroupNSHost:
+		return runtime.CgroupNSHost, nil
+	case runtime.CgroupNSPrivate:
+		if !cgroups.IsCgroupV2() {
+			return "", fmt.Errorf("private cgroup namespace requires cgroup v2, but system uses cgroup v1")
+		}
+		return runtime.CgroupNSPrivate, nil
+	default:
+		return "", fmt.Errorf("unsupported mode %q (supported: host, private)", cgroupNS)
+	}
+}
+
+// parseUlimitFlags 解析 --ulimit 参数（Phase 17 新增），同名资源后者覆盖前者
+func parseUlimitFlags() ([]runtime.Ulimit, error) {
+	var result []runtime.Ulimit
+	index := make(map[string]int)
+
+	for _, spec := range ulimits {
+		u, err := runtime.ParseUlimit(spec)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[u.Name]; ok {
+			result[i] = u
+			continue
+		}
+		index[u.Name] = len(result)
+		result = append(result, u)
+	}
+	return result, nil
+}
+
+// parseSysctlFlags 解析 --sysctl 参数（Phase 17 新增）
+// 这里只检查 KEY=VALUE 格式，命名空间白名单由 runtime.ValidateSysctls 校验。
+func parseSysctlFlags() (map[string]string, error) {
+	if len(sysctls) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]string, len(sysctls))
+	for _, spec := range sysctls {
+		key, value, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid sysctl %q (expected KEY=VALUE)", spec)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// parseLogOptFlags 解析 --log-opt 参数（Phase 19 新增），同名选项后者覆盖前者，
+// 并按 --log-driver 校验选项
+func parseLogOptFlags() (map[string]string, error) {
+	if len(logOpts) == 0 {
+		return nil, runtime.ValidateLogConfig(logDriver, nil)
+	}
+
+	result := make(map[string]string, len(logOpts))
+	for _, spec := range logOpts {
+		key, value, ok := strings.Cut(spec, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid log-opt %q (expected KEY=VALUE)", spec)
+		}
+		result[key] = value
+	}
+	if err := runtime.ValidateLogConfig(logDriver, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseNamespaceModeFlag 解析 --pid/--ipc/--uts 参数（Phase 15 新增）
+// 支持: ""（私有，默认）、host、container:<name|id>
+func parseNamespaceModeFlag(flag, value string) (runtime.NamespaceMode, error) {
+	mode := runtime.NamespaceMode(value)
+	switch {
+	case mode.IsPrivate(), mode.IsHost():
+		return mode, nil
+	case mode.IsContainer() && mode.Container() != "":
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid --%s mode %q (supported: host, container:<name|id>)", flag, value)
+	}
+}
+
+// resolveContainerTarget 将 container:<name|id> 中的目标解析为完整容器 ID（Phase 15 新增）
+// 目标容器必须存在且正在运行，否则无法通过 /proc/<pid>/ns 加入其命名空间。
+func resolveContainerTarget(store *state.Store, kind, nameOrID string) (string, error) {
+	st, err := store.Get(nameOrID)
+	if err != nil {
+		return "", fmt.Errorf("cannot join %s of container %s: %w", kind, nameOrID, err)
+	}
+	if !st.IsRunning() {
+		return "", fmt.Errorf("cannot join %s of container %s: container is not running", kind, nameOrID)
+	}
+	return st.ID, nil
+}
+
 // parseEnvVars 解析环境变量参数
 // 支持格式:
 //   - KEY=VALUE: 设置环境变量
@@ -23,6 +23,25 @@ var (
 	envVars       []string
 	workDir       string
 	user          string
+
+	// Phase 14 新增
+	devices    []string
+	privileged bool
+	cgroupNS   string
+
+	// Phase 15 新增
+	pidMode string
+	ipcMode string
+	utsMode string
+	podName string
+
+	// Phase 17 新增
+	ulimits []string
+	sysctls []string
+
+	// Phase 19 新增
+	logDriver string
+	logOpts   []string
 )
 
 var runCmd = &cobra.Command{
@@ -47,4 +66,23 @@ func init() {
 	runCmd.Flags().StringArrayVarP(&envVars, "env", "e", nil, "设置环境变量")
 	runCmd.Flags().StringVarP(&workDir, "workdir", "w", "", "容器内工作目录")
 	runCmd.Flags().StringVarP(&user, "user", "u", "", "运行用户")
+
+	// Phase 14 新增
+	runCmd.Flags().StringArrayVar(&devices, "device", nil, "映射宿主设备")
+	runCmd.Flags().BoolVar(&privileged, "privileged", false, "解除默认设备白名单限制")
+	runCmd.Flags().StringVar(&cgroupNS, "cgroupns", "", "cgroup namespace 模式（host/private）")
+
+	// Phase 15 新增
+	runCmd.Flags().StringVar(&pidMode, "pid", "", "PID namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&ipcMode, "ipc", "", "IPC namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&utsMode, "uts", "", "UTS namespace（host 或 container:<name|id>）")
+	runCmd.Flags().StringVar(&podName, "pod", "", "加入指定 pod")
+
+	// Phase 17 新增
+	runCmd.Flags().StringArrayVar(&ulimits, "ulimit", nil, "进程资源限制")
+	runCmd.Flags().StringArrayVar(&sysctls, "sysctl", nil, "命名空间化的内核参数")
+
+	// Phase 19 新增
+	runCmd.Flags().StringVar(&logDriver, "log-driver", "json-file", "日志驱动")
+	runCmd.Flags().StringArrayVar(&logOpts, "log-opt", nil, "日志驱动选项")
 }
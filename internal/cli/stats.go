@@ -0,0 +1,300 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/network"
+	"minidocker/internal/runtime"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// stats 命令标志
+	statsNoStream bool
+	statsFormat   string
+)
+
+// statsInterval 是两次采样之间的间隔（CPU% 由相邻两次采样的差值计算）
+const statsInterval = time.Second
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [OPTIONS] [CONTAINER...]",
+	Short: "显示容器的实时资源使用情况",
+	Long: `显示容器的实时资源使用情况。
+
+不指定容器时显示所有运行中的容器（每次刷新重新列出）。CPU% 由相邻两次
+采样的 cgroup CPU 时间差除以经过的时间计算，多核满载时可超过 100%。
+
+数据来源:
+  CPU / 内存 / PIDS / BLOCK I/O  容器 cgroup（cpu.stat、memory.current、pids.current、io.stat）
+  NET I/O                        宿主机端 veth 的收发计数（仅 bridge 网络）
+
+未配置 cgroup（没有资源限制）的容器或系统不支持 cgroup v2 时无法采集
+cgroup 数据，对应列显示 "--"。
+MEM USAGE 不含可回收的 inactive_file 页缓存；未设置内存限制时 LIMIT 显示宿主机
+内存总量。--format json 额外输出完整的 cgroup 统计（CgroupStats：memory.stat
+分项、CPU 限流、按设备的 io.stat、cpu/memory/io PSI）。
+
+示例:
+  minidocker stats                     # 持续刷新所有运行中的容器
+  minidocker stats web db              # 只显示指定容器
+  minidocker stats --no-stream         # 只输出一次
+  minidocker stats --no-stream --format json`,
+	RunE: showStats,
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsNoStream, "no-stream", false, "只输出一次结果，不持续刷新")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "格式化输出（table/json）")
+}
+
+// StatsEntry 表示 stats 命令中单个容器的一次采样结果
+type StatsEntry struct {
+	ID            string    `json:"Id"`
+	Name          string    `json:"Name,omitempty"`
+	Read          time.Time `json:"Read"`
+	CPUPercent    float64   `json:"CPUPercent"`
+	MemoryUsage   int64     `json:"MemoryUsage"`
+	MemoryLimit   int64     `json:"MemoryLimit"`
+	MemoryPercent float64   `json:"MemoryPercent"`
+	NetworkRx     uint64    `json:"NetworkRx"`
+	NetworkTx     uint64    `json:"NetworkTx"`
+	BlockRead     int64     `json:"BlockRead"`
+	BlockWrite    int64     `json:"BlockWrite"`
+	Pids          int64     `json:"Pids"`
+
+	// Phase 20: 完整的 cgroup 统计（内存分项、CPU 限流、按设备的 I/O、PSI），仅 JSON 输出
+	CgroupStats *cgroups.Stats `json:"CgroupStats,omitempty"`
+
+	// 内部字段：是否采集到了 cgroup / 网络数据（table 输出时决定显示 "--"）
+	hasCgroup  bool
+	hasNetwork bool
+}
+
+// cpuSample 记录一次 CPU 时间采样
+type cpuSample struct {
+	usage int64 // 纳秒
+	at    time.Time
+}
+
+func showStats(cmd *cobra.Command, args []string) error {
+	if statsFormat != "table" && statsFormat != "json" {
+		return fmt.Errorf("unknown format: %s (supported: table, json)", statsFormat)
+	}
+
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	// 系统不支持 cgroup v2 时仍可显示网络统计，cgroup 相关列显示 "--"
+	manager, err := cgroups.NewManager()
+	if err != nil {
+		manager = nil
+	}
+
+	// 指定容器时先解析 ID，找不到直接报错
+	var ids []string
+	for _, idOrPrefix := range args {
+		containerState, err := store.Get(idOrPrefix)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, containerState.ID)
+	}
+
+	hostMemory := hostMemoryTotal()
+	clearScreen := !statsNoStream && statsFormat == "table" && runtime.IsTerminal(os.Stdout)
+
+	// 首次采样只用于计算 CPU 差值，不输出
+	samples := make(map[string]cpuSample)
+	if _, err := collectStats(store, manager, ids, samples, hostMemory); err != nil {
+		return err
+	}
+
+	for {
+		time.Sleep(statsInterval)
+
+		entries, err := collectStats(store, manager, ids, samples, hostMemory)
+		if err != nil {
+			return err
+		}
+
+		if clearScreen {
+			// 清屏并回到左上角，效果类似 top
+			fmt.Print("\033[2J\033[H")
+		}
+		if statsFormat == "json" {
+			if err := outputStatsJSON(entries); err != nil {
+				return err
+			}
+		} else if err := outputStatsTable(entries); err != nil {
+			return err
+		}
+
+		if statsNoStream {
+			return nil
+		}
+	}
+}
+
+// collectStats 采集一轮统计数据，并用 samples 中上一次的 CPU 采样计算 CPU%。
+// ids 为空时采集所有运行中的容器。
+func collectStats(store *state.Store, manager cgroups.Manager, ids []string, samples map[string]cpuSample, hostMemory int64) ([]StatsEntry, error) {
+	var states []*state.ContainerState
+	if len(ids) == 0 {
+		list, err := store.List(false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		})
+		states = list
+	} else {
+		for _, id := range ids {
+			containerState, err := store.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			states = append(states, containerState)
+		}
+	}
+
+	entries := make([]StatsEntry, 0, len(states))
+	seen := make(map[string]bool, len(states))
+	for _, containerState := range states {
+		seen[containerState.ID] = true
+		entries = append(entries, containerStats(store, manager, containerState, samples, hostMemory))
+	}
+
+	// 丢弃已不再显示的容器的采样
+	for id := range samples {
+		if !seen[id] {
+			delete(samples, id)
+		}
+	}
+	return entries, nil
+}
+
+// containerStats 采集单个容器的统计数据；已停止的容器各项为 0
+func containerStats(store *state.Store, manager cgroups.Manager, containerState *state.ContainerState, samples map[string]cpuSample, hostMemory int64) StatsEntry {
+	now := time.Now()
+	entry := StatsEntry{
+		ID:   containerState.ID,
+		Name: store.NameStore.GetName(containerState.ID),
+		Read: now,
+	}
+
+	if !containerState.IsRunning() {
+		delete(samples, containerState.ID)
+		return entry
+	}
+
+	if manager != nil && containerState.CgroupPath != "" {
+		if stats, err := manager.GetStats(containerState.CgroupPath); err == nil {
+			entry.hasCgroup = true
+			entry.CgroupStats = stats
+			// 对齐 Docker：内存用量不计可回收的 inactive_file 页缓存
+			entry.MemoryUsage = stats.MemoryUsage
+			if inactive := stats.MemoryStat.InactiveFile; inactive < entry.MemoryUsage {
+				entry.MemoryUsage -= inactive
+			}
+			entry.MemoryLimit = stats.MemoryLimit
+			entry.BlockRead = stats.IOReadBytes
+			entry.BlockWrite = stats.IOWriteBytes
+			entry.Pids = stats.PidsCount
+
+			if prev, ok := samples[containerState.ID]; ok {
+				elapsed := now.Sub(prev.at)
+				if delta := stats.CPUUsage - prev.usage; elapsed > 0 && delta > 0 {
+					entry.CPUPercent = float64(delta) / float64(elapsed.Nanoseconds()) * 100
+				}
+			}
+			samples[containerState.ID] = cpuSample{usage: stats.CPUUsage, at: now}
+		}
+	}
+
+	// 未设置内存限制时以宿主机内存总量作为上限（对齐 Docker）
+	if entry.MemoryLimit == 0 || (hostMemory > 0 && entry.MemoryLimit > hostMemory) {
+		entry.MemoryLimit = hostMemory
+	}
+	if entry.MemoryLimit > 0 {
+		entry.MemoryPercent = float64(entry.MemoryUsage) / float64(entry.MemoryLimit) * 100
+	}
+
+	// 宿主机端 veth 的 rx/tx 与容器视角相反
+	if ns := containerState.NetworkState; ns != nil && ns.VethHost != "" {
+		if rx, tx, err := network.InterfaceStats(ns.VethHost); err == nil {
+			entry.hasNetwork = true
+			entry.NetworkRx = tx
+			entry.NetworkTx = rx
+		}
+	}
+
+	return entry
+}
+
+// hostMemoryTotal 返回宿主机内存总量（字节），获取失败时返回 0
+func hostMemoryTotal() int64 {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0
+	}
+	return int64(info.Totalram) * int64(info.Unit)
+}
+
+// outputStatsJSON 每个容器输出一行 JSON（便于流式消费）
+func outputStatsJSON(entries []StatsEntry) error {
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	}
+	return nil
+}
+
+func outputStatsTable(entries []StatsEntry) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+
+	for _, entry := range entries {
+		name := entry.Name
+		if name == "" {
+			name = "-"
+		}
+
+		cpu, mem, memPercent, block, pids := "--", "--", "--", "--", "--"
+		if entry.hasCgroup {
+			cpu = fmt.Sprintf("%.2f%%", entry.CPUPercent)
+			mem = formatSize(entry.MemoryUsage) + " / " + formatSize(entry.MemoryLimit)
+			memPercent = fmt.Sprintf("%.2f%%", entry.MemoryPercent)
+			block = formatSize(entry.BlockRead) + " / " + formatSize(entry.BlockWrite)
+			pids = fmt.Sprintf("%d", entry.Pids)
+		}
+
+		netIO := "--"
+		if entry.hasNetwork {
+			netIO = formatSize(int64(entry.NetworkRx)) + " / " + formatSize(int64(entry.NetworkTx))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			shortID(entry.ID), name, cpu, mem, memPercent, netIO, block, pids)
+	}
+
+	return w.Flush()
+}
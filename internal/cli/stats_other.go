@@ -0,0 +1,20 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [OPTIONS] [CONTAINER...]",
+	Short: "显示容器的实时资源使用情况",
+	Long:  `显示容器的实时资源使用情况。仅支持 Linux 平台。`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker stats only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
@@ -6,9 +6,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"syscall"
 	"time"
 
+	"minidocker/internal/events"
 	"minidocker/internal/state"
 
 	"github.com/spf13/cobra"
@@ -71,6 +73,21 @@ func stopContainer(store *state.Store, idOrPrefix string, timeout int) error {
 		return nil
 	}
 
+	// Phase 20: 记录 kill/stop 事件（对齐 Docker：kill(SIGTERM) → die → stop）
+	name := store.NameStore.GetName(containerState.ID)
+	onSignal := func(sig syscall.Signal) {
+		logContainerEvent(store, containerState, name, events.ActionKill,
+			map[string]string{"signal": strconv.Itoa(int(sig))})
+	}
+	if err := terminateContainer(containerState, timeout, onSignal); err != nil {
+		return err
+	}
+	logContainerEvent(store, containerState, name, events.ActionStop, nil)
+	return nil
+}
+
+// terminateContainer 发送 SIGTERM，超时后发送 SIGKILL；每次成功发送信号后调用 onSignal
+func terminateContainer(containerState *state.ContainerState, timeout int, onSignal func(syscall.Signal)) error {
 	pid := containerState.Pid
 
 	// 发送 SIGTERM
@@ -82,6 +99,7 @@ func stopContainer(store *state.Store, idOrPrefix string, timeout int) error {
 		}
 		return fmt.Errorf("failed to send SIGTERM: %w", err)
 	}
+	onSignal(syscall.SIGTERM)
 
 	// 等待进程退出
 	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
@@ -106,6 +124,7 @@ func stopContainer(store *state.Store, idOrPrefix string, timeout int) error {
 		}
 		return fmt.Errorf("failed to send SIGKILL: %w", err)
 	}
+	onSignal(syscall.SIGKILL)
 
 	// 等待 SIGKILL 生效
 	time.Sleep(100 * time.Millisecond)
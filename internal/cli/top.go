@@ -0,0 +1,165 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	osuser "os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/runtime"
+	"minidocker/internal/state"
+
+	"github.com/spf13/cobra"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top CONTAINER [ps OPTIONS]",
+	Short: "显示容器内运行的进程",
+	Long: `显示容器内运行的进程，不依赖镜像中的 ps。
+
+进程列表优先取自容器 cgroup 的 cgroup.procs；没有 cgroup 时遍历 /proc，
+找出与容器 init 处于同一 PID 命名空间的进程（共享 PID 命名空间时为 init 的进程树）。
+
+默认输出宿主机 PID、容器内 PID（NSpid）、用户、CPU 时间和命令行。
+在容器名之后给出 ps 选项时，改为执行宿主机的 ps 并只保留属于容器的行
+（输出中必须包含 PID 列）。
+
+示例:
+  minidocker top web
+  minidocker top web -eo pid,rss,args`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: topContainer,
+}
+
+func init() {
+	// 容器名之后的参数原样交给 ps（例如 -eo pid,args）
+	topCmd.Flags().SetInterspersed(false)
+}
+
+func topContainer(cmd *cobra.Command, args []string) error {
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	containerState, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if !containerState.IsRunning() {
+		return fmt.Errorf("container %s is not running", shortID(containerState.ID))
+	}
+
+	config, err := state.LoadConfig(containerState.GetContainerDir())
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var cgroupDir string
+	if containerState.CgroupPath != "" {
+		if manager, err := cgroups.NewManager(); err == nil {
+			cgroupDir = manager.GetPath(containerState.CgroupPath)
+		}
+	}
+
+	sharedPidNS := !runtime.NamespaceMode(config.PidMode).IsPrivate()
+	pids, err := runtime.ContainerPIDs(containerState.Pid, cgroupDir, sharedPidNS)
+	if err != nil {
+		return fmt.Errorf("failed to list container processes: %w", err)
+	}
+
+	if len(args) > 1 {
+		return topWithPS(pids, args[1:])
+	}
+	return topDefault(pids)
+}
+
+// topDefault 从 /proc 读取进程信息并输出表格
+func topDefault(pids []int) error {
+	users := make(map[int]string)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "USER\tPID\tNSPID\tPPID\tTIME\tCOMMAND")
+	for _, pid := range pids {
+		info, err := runtime.ReadProcessInfo(pid)
+		if err != nil {
+			// 进程在列出后已退出
+			continue
+		}
+
+		name, ok := users[info.UID]
+		if !ok {
+			name = strconv.Itoa(info.UID)
+			if u, err := osuser.LookupId(name); err == nil {
+				name = u.Username
+			}
+			users[info.UID] = name
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%s\n",
+			name, info.PID, info.NSPID, info.PPID, formatCPUTime(info.CPUTime), info.Command)
+	}
+	return w.Flush()
+}
+
+// topWithPS 执行宿主机 ps，只输出 PID 列属于容器的行（对齐 Docker top 的 ps 选项用法）
+func topWithPS(pids []int, psArgs []string) error {
+	output, err := exec.Command("ps", psArgs...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("ps %s failed: %s", strings.Join(psArgs, " "), bytes.TrimSpace(exitErr.Stderr))
+		}
+		return fmt.Errorf("run ps: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	pidIndex := -1
+	for i, field := range strings.Fields(lines[0]) {
+		if field == "PID" {
+			pidIndex = i
+			break
+		}
+	}
+	if pidIndex < 0 {
+		return fmt.Errorf("couldn't find PID field in ps output (add pid to -o)")
+	}
+
+	inContainer := make(map[int]bool, len(pids))
+	for _, pid := range pids {
+		inContainer[pid] = true
+	}
+
+	fmt.Println(lines[0])
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) <= pidIndex {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[pidIndex]); err == nil && inContainer[pid] {
+			fmt.Println(line)
+		}
+	}
+	return nil
+}
+
+// formatCPUTime 按 ps 的 TIME 格式输出：[DD-]HH:MM:SS
+func formatCPUTime(d time.Duration) string {
+	total := int64(d / time.Second)
+	days := total / 86400
+	hours := total % 86400 / 3600
+	minutes := total % 3600 / 60
+	seconds := total % 60
+	if days > 0 {
+		return fmt.Sprintf("%d-%02d:%02d:%02d", days, hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
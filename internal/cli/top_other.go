@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top CONTAINER [ps OPTIONS]",
+	Short: "显示容器内运行的进程",
+	Long:  `显示容器内运行的进程。仅支持 Linux 平台。`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker top only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
@@ -6,6 +6,7 @@ package cli
 import (
 	"fmt"
 
+	"minidocker/internal/events"
 	"minidocker/internal/state"
 	"minidocker/internal/volume"
 
@@ -49,6 +50,9 @@ func createVolume(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create volume: %w", err)
 	}
 
+	// Phase 20: 记录 create 事件
+	_ = events.Log(store.RootDir, events.TypeVolume, events.ActionCreate, vol.Name, nil)
+
 	// 输出卷名（与 Docker 行为一致）
 	fmt.Println(vol.Name)
 	return nil
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"minidocker/internal/events"
 	"minidocker/internal/state"
 	"minidocker/internal/volume"
 
@@ -64,6 +65,8 @@ func removeVolumes(cmd *cobra.Command, args []string) error {
 			fmt.Fprintf(os.Stderr, "Error removing volume %s: %v\n", name, err)
 			hasError = true
 		} else {
+			// Phase 20: 记录 destroy 事件
+			_ = events.Log(store.RootDir, events.TypeVolume, events.ActionDestroy, name, nil)
 			// 成功时输出卷名（与 Docker 行为一致）
 			fmt.Println(name)
 		}
@@ -0,0 +1,195 @@
+//go:build linux
+// +build linux
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"minidocker/internal/state"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// wait 条件（对齐 Docker --condition）
+const (
+	waitConditionNotRunning = "not-running"
+	waitConditionNextExit   = "next-exit"
+	waitConditionRemoved    = "removed"
+)
+
+// waitOrphanGrace 是进程退出后等待 shim 写入退出码的时间
+const waitOrphanGrace = 2 * time.Second
+
+var (
+	// wait 命令标志
+	waitCondition string
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [OPTIONS] CONTAINER [CONTAINER...]",
+	Short: "阻塞直到容器停止，然后输出退出码",
+	Long: `阻塞直到一个或多个容器满足等待条件，然后按参数顺序逐行输出退出码。
+
+通过 fsnotify 监听容器目录下 state.json 的更新（shim 在容器退出时写入退出码），
+并定期检查进程是否存在，以覆盖 shim 异常退出的情况。
+
+--condition:
+  not-running  容器未运行时立即返回，否则等待其退出（默认）
+  next-exit    等待下一次退出，即使容器当前已停止
+  removed      等待容器被删除（rm），输出最后记录的退出码
+
+示例:
+  minidocker run -d --name job --rootfs /path/to/rootfs -- /bin/sh -c 'exit 3'
+  minidocker wait job                       # 输出 3
+  minidocker wait --condition removed job`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: waitContainers,
+}
+
+func init() {
+	waitCmd.Flags().StringVar(&waitCondition, "condition", waitConditionNotRunning, "等待条件（not-running/next-exit/removed）")
+}
+
+func waitContainers(cmd *cobra.Command, args []string) error {
+	switch waitCondition {
+	case waitConditionNotRunning, waitConditionNextExit, waitConditionRemoved:
+	default:
+		return fmt.Errorf("invalid condition %q (supported: %s, %s, %s)",
+			waitCondition, waitConditionNotRunning, waitConditionNextExit, waitConditionRemoved)
+	}
+
+	store, err := state.NewStore(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize state store: %w", err)
+	}
+
+	// 先解析所有容器，避免等待第一个容器时后面的容器被删除而无法解析
+	states := make([]*state.ContainerState, 0, len(args))
+	hasError := false
+	for _, idOrPrefix := range args {
+		containerState, err := store.Get(idOrPrefix)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error waiting %s: %v\n", idOrPrefix, err)
+			hasError = true
+			states = append(states, nil)
+			continue
+		}
+		states = append(states, containerState)
+	}
+
+	for i, containerState := range states {
+		if containerState == nil {
+			continue
+		}
+		exitCode, err := waitContainer(containerState, waitCondition)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error waiting %s: %v\n", args[i], err)
+			hasError = true
+			continue
+		}
+		fmt.Println(exitCode)
+	}
+
+	if hasError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// waitContainer 阻塞直到容器满足 condition，返回退出码
+func waitContainer(containerState *state.ContainerState, condition string) (int, error) {
+	containerDir := containerState.GetContainerDir()
+
+	// 容器目录可能在建立监听前就被删除：监听失败时依赖轮询
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return -1, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+	_ = watcher.Add(containerDir)
+
+	// next-exit 以开始等待时的 FinishedAt 为基准，只接受之后的退出
+	startFinishedAt := containerState.FinishedAt
+
+	lastExitCode := func() int {
+		if containerState.ExitCode != nil {
+			return *containerState.ExitCode
+		}
+		return -1
+	}
+
+	// 进程退出后由 shim（或前台 run）写入退出码；超过宽限期仍未写入时按孤儿处理
+	var exitedAt time.Time
+
+	// check 重新加载状态并判断是否满足条件
+	check := func() (bool, error) {
+		if _, err := os.Stat(containerDir); os.IsNotExist(err) {
+			if condition == waitConditionRemoved {
+				return true, nil
+			}
+			return false, fmt.Errorf("container %s was removed", shortID(containerState.ID))
+		}
+		if err := containerState.Reload(); err != nil {
+			// state.json 正在被删除或替换，下次再检查
+			return false, nil
+		}
+
+		if containerState.Status == state.StatusRunning {
+			if !processExited(containerState.Pid) {
+				exitedAt = time.Time{}
+				return false, nil
+			}
+			if exitedAt.IsZero() {
+				exitedAt = time.Now()
+			}
+			if time.Since(exitedAt) < waitOrphanGrace {
+				return false, nil
+			}
+			// 修正孤儿状态（退出码记为 -1）
+			containerState.IsRunning()
+		}
+
+		switch condition {
+		case waitConditionNotRunning:
+			return true, nil
+		case waitConditionNextExit:
+			if containerState.FinishedAt == nil {
+				return false, nil
+			}
+			return startFinishedAt == nil || containerState.FinishedAt.After(*startFinishedAt), nil
+		default:
+			// removed：容器退出后继续等待删除
+			return false, nil
+		}
+	}
+
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		done, err := check()
+		if err != nil {
+			return -1, err
+		}
+		if done {
+			return lastExitCode(), nil
+		}
+
+		// state.json 被更新、容器目录被删除或轮询到期时重新检查
+		select {
+		case <-watcher.Events:
+		case <-watcher.Errors:
+		case <-ticker.C:
+		}
+	}
+}
+
+// processExited 判断进程是否已不存在（不修改容器状态）
+func processExited(pid int) bool {
+	return pid == 0 || syscall.Kill(pid, 0) == syscall.ESRCH
+}
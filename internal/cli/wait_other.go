@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package cli
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [OPTIONS] CONTAINER [CONTAINER...]",
+	Short: "阻塞直到容器停止，然后输出退出码",
+	Long:  `阻塞直到容器停止，然后输出退出码。仅支持 Linux 平台。`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return fmt.Errorf("minidocker wait only supports Linux (current OS: %s)", runtime.GOOS)
+	},
+}
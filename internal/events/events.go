@@ -0,0 +1,135 @@
+// Package events 实现 minidocker 的生命周期事件日志（Phase 20 新增）。
+//
+// 事件以每行一条 JSON 的形式追加写入 <root>/events.jsonl：
+// - 写入方：runtime.Run / shim（create、start、oom、die）、stop/kill/rm、镜像 pull/load/rmi、volume create/rm、network create/rm/connect/disconnect
+// - 读取方：minidocker events（回放 --since 之后的事件并持续跟踪新事件）
+//
+// 设计决策：
+// - 多个进程（CLI、各容器的 shim）并发写入同一文件，每条事件只调用一次 write，依赖 O_APPEND 的原子追加
+// - 写入失败只影响可观测性，调用方忽略错误，不影响容器生命周期操作
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileName 是事件日志在 root 目录下的文件名
+const FileName = "events.jsonl"
+
+// 事件对象类型
+const (
+	TypeContainer = "container"
+	TypeImage     = "image"
+	TypeVolume    = "volume"
+	TypeNetwork   = "network" // Phase 21
+)
+
+// 容器事件（create/destroy 也用于 volume）
+const (
+	ActionCreate  = "create"
+	ActionStart   = "start"
+	ActionDie     = "die"
+	ActionOOM     = "oom"
+	ActionKill    = "kill"
+	ActionStop    = "stop"
+	ActionDestroy = "destroy"
+)
+
+// 网络事件（Phase 21 新增，create/destroy 与容器共用）
+const (
+	ActionConnect    = "connect"
+	ActionDisconnect = "disconnect"
+)
+
+// 镜像与 volume 事件
+const (
+	ActionPull   = "pull"
+	ActionLoad   = "load"
+	ActionDelete = "delete"
+	ActionUntag  = "untag"
+)
+
+// Event 是一条生命周期事件
+type Event struct {
+	Time       time.Time         `json:"time"`
+	Type       string            `json:"type"`
+	Action     string            `json:"action"`
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Path 返回事件日志的路径
+func Path(rootDir string) string {
+	return filepath.Join(rootDir, FileName)
+}
+
+// Log 以当前时间追加一条事件，attrs 中的空值会被忽略。
+func Log(rootDir, typ, action, id string, attrs map[string]string) error {
+	ev := Event{
+		Time:   time.Now().UTC(),
+		Type:   typ,
+		Action: action,
+		ID:     id,
+	}
+	for key, value := range attrs {
+		if value == "" {
+			continue
+		}
+		if ev.Attributes == nil {
+			ev.Attributes = make(map[string]string)
+		}
+		ev.Attributes[key] = value
+	}
+	return Append(rootDir, ev)
+}
+
+// Append 将事件编码为一行 JSON 并以单次 write 追加到事件日志
+func Append(rootDir string, ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	data = append(data, '\n')
+
+	f, err := os.OpenFile(Path(rootDir), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("open events log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write events log: %w", err)
+	}
+	return nil
+}
+
+// Decode 解析 data 中的完整事件行，返回事件和已消费的字节数。
+// 末尾不完整的行（仍在写入）不消费；无法解析的行被跳过。
+func Decode(data []byte) ([]Event, int) {
+	var evs []Event
+	consumed := 0
+	for {
+		idx := bytes.IndexByte(data[consumed:], '\n')
+		if idx < 0 {
+			break
+		}
+		line := data[consumed : consumed+idx]
+		consumed += idx + 1
+
+		var ev Event
+		if err := json.Unmarshal(line, &ev); err != nil {
+			continue
+		}
+		evs = append(evs, ev)
+	}
+	return evs, consumed
+}
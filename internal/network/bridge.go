@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 )
 
 // bridgeDriver 实现 bridge 网络模式
@@ -18,25 +22,58 @@ type bridgeDriver struct {
 	bridgeName string
 	subnet     *net.IPNet
 	gateway    net.IP
+
+	// Phase 21: 双栈网络的 IPv6 子网和网关（未启用 IPv6 时为 nil）
+	subnet6  *net.IPNet
+	gateway6 net.IP
+
+	// mtu 是 bridge 和 veth 的 MTU（Phase 21 新增，0 表示使用内核默认值）
+	mtu int
+
+	// hairpin 表示未启用 userland-proxy（Phase 21 新增）：端口发布只依赖 DNAT，
+	// 需要为 bridge 开启 route_localnet（loopback 访问）和 veth 的 hairpin 模式（容器访问自身发布端口）
+	hairpin bool
 }
 
-// newBridgeDriver 创建新的 bridge 驱动
-func newBridgeDriver(bridgeName string) (*bridgeDriver, error) {
-	_, subnet, err := net.ParseCIDR(DefaultSubnet)
+// vethConfig 是容器端网卡的配置（Phase 21 新增）
+type vethConfig struct {
+	ip  string // IPv4 地址
+	ip6 string // IPv6 地址，空表示只配置 IPv4
+	mac string // MAC 地址（--mac-address），空表示由内核随机生成
+
+	// Phase 21: 容器接收/发送方向的限速（bit/s，0 表示不限速）
+	ingressRate uint64
+	egressRate  uint64
+}
+
+// newBridgeDriver 创建新的 bridge 驱动（Phase 21: 子网和网关由网络决定）
+func newBridgeDriver(info *NetworkInfo) (*bridgeDriver, error) {
+	_, subnet, err := net.ParseCIDR(info.Subnet)
 	if err != nil {
 		return nil, fmt.Errorf("parse subnet: %w", err)
 	}
 
-	gateway := net.ParseIP(DefaultGateway)
+	gateway := net.ParseIP(info.Gateway)
 	if gateway == nil {
-		return nil, fmt.Errorf("parse gateway: %s", DefaultGateway)
+		return nil, fmt.Errorf("parse gateway: %s", info.Gateway)
 	}
 
-	return &bridgeDriver{
-		bridgeName: bridgeName,
+	d := &bridgeDriver{
+		bridgeName: info.BridgeName,
 		subnet:     subnet,
 		gateway:    gateway,
-	}, nil
+		mtu:        info.MTU,
+	}
+
+	if info.EnableIPv6 {
+		if _, d.subnet6, err = net.ParseCIDR(info.SubnetV6); err != nil {
+			return nil, fmt.Errorf("parse IPv6 subnet: %w", err)
+		}
+		if d.gateway6 = net.ParseIP(info.GatewayV6); d.gateway6 == nil {
+			return nil, fmt.Errorf("parse IPv6 gateway: %s", info.GatewayV6)
+		}
+	}
+	return d, nil
 }
 
 // EnsureBridge 确保 bridge 接口存在
@@ -62,30 +99,32 @@ func (d *bridgeDriver) EnsureBridge() error {
 		}
 	}
 
-	// 检查是否已有 IP 地址
-	addrs, err := netlink.AddrList(br, netlink.FAMILY_V4)
-	if err != nil {
-		return fmt.Errorf("list bridge addresses: %w", err)
-	}
-
-	hasIP := false
-	gatewayWithMask := fmt.Sprintf("%s/%d", d.gateway.String(), DefaultSubnetMask)
-	for _, addr := range addrs {
-		if addr.IPNet.String() == gatewayWithMask {
-			hasIP = true
-			break
+	// Phase 21: 显式设置 MTU，否则内核会在最后一个端口移除后将 bridge MTU 恢复为 1500
+	if d.mtu != 0 && br.Attrs().MTU != d.mtu {
+		if err := netlink.LinkSetMTU(br, d.mtu); err != nil {
+			return fmt.Errorf("set bridge MTU: %w", err)
 		}
 	}
 
 	// 如果没有 IP，添加网关 IP
-	if !hasIP {
-		addr, err := netlink.ParseAddr(gatewayWithMask)
-		if err != nil {
-			return fmt.Errorf("parse gateway address: %w", err)
+	if err := ensureAddr(br, netlink.FAMILY_V4, &net.IPNet{IP: d.gateway, Mask: d.subnet.Mask}); err != nil {
+		return err
+	}
+
+	// Phase 21: 双栈网络同时配置 IPv6 网关地址
+	if d.subnet6 != nil {
+		if err := enableIPv6(d.bridgeName); err != nil {
+			return err
+		}
+		if err := ensureAddr(br, netlink.FAMILY_V6, &net.IPNet{IP: d.gateway6, Mask: d.subnet6.Mask}); err != nil {
+			return err
 		}
+	}
 
-		if err := netlink.AddrAdd(br, addr); err != nil {
-			return fmt.Errorf("add address to bridge: %w", err)
+	// Phase 21: 允许 DNAT 到容器的 127.0.0.1 访问经 bridge 路由
+	if d.hairpin {
+		if err := enableRouteLocalnet(d.bridgeName); err != nil {
+			return err
 		}
 	}
 
@@ -98,12 +137,51 @@ func (d *bridgeDriver) EnsureBridge() error {
 	if err := enableIPForwarding(); err != nil {
 		return fmt.Errorf("enable IP forwarding: %w", err)
 	}
+	if d.subnet6 != nil {
+		if err := enableIPv6Forwarding(); err != nil {
+			return fmt.Errorf("enable IPv6 forwarding: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// ensureAddr 确保接口上配置了指定地址（已存在时跳过）
+func ensureAddr(link netlink.Link, family int, ipNet *net.IPNet) error {
+	// 检查是否已有 IP 地址
+	addrs, err := netlink.AddrList(link, family)
+	if err != nil {
+		return fmt.Errorf("list bridge addresses: %w", err)
+	}
+	for _, addr := range addrs {
+		if addr.IPNet.String() == ipNet.String() {
+			return nil
+		}
+	}
+
+	if err := netlink.AddrAdd(link, newAddr(ipNet)); err != nil {
+		return fmt.Errorf("add address to bridge: %w", err)
+	}
+	return nil
+}
+
+// newAddr 构造接口地址（Phase 21: IPv6 地址跳过重复地址检测，避免容器启动时地址仍处于 tentative 状态）
+func newAddr(ipNet *net.IPNet) *netlink.Addr {
+	addr := &netlink.Addr{IPNet: ipNet}
+	if ipNet.IP.To4() == nil {
+		addr.Flags = unix.IFA_F_NODAD
+	}
+	return addr
+}
+
+// maskSize 返回子网掩码位数
+func (d *bridgeDriver) maskSize() int {
+	ones, _ := d.subnet.Mask.Size()
+	return ones
+}
+
 // SetupVeth 创建 veth pair 并配置网络
-func (d *bridgeDriver) SetupVeth(containerID string, pid int, containerIP string) (*NetworkState, error) {
+func (d *bridgeDriver) SetupVeth(containerID string, pid int, cfg vethConfig) (*NetworkState, error) {
 	// 生成 veth 名称
 	// 宿主机端: veth + containerID 前8位
 	// 容器端: 先在宿主机 netns 里用临时唯一名创建，移入容器 netns 后再 rename 为 eth0
@@ -111,64 +189,138 @@ func (d *bridgeDriver) SetupVeth(containerID string, pid int, containerIP string
 	peerVethName := fmt.Sprintf("ceth%s", containerID[:8])
 	containerVethName := "eth0"
 
+	macAddress, mtu, err := d.setupVeth(hostVethName, peerVethName, containerVethName, pid, cfg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &NetworkState{
+		Mode:          NetworkModeBridge,
+		IPAddress:     cfg.ip,
+		Gateway:       d.gateway.String(),
+		MacAddress:    macAddress,
+		MTU:           mtu,
+		VethHost:      hostVethName,
+		VethContainer: containerVethName,
+	}
+	if cfg.ip6 != "" {
+		state.IPv6Address = cfg.ip6
+		state.IPv6Gateway = d.gateway6.String()
+	}
+	return state, nil
+}
+
+// SetupEndpoint 为 network connect 创建附加网卡（Phase 21 新增）。
+// 附加网卡不设置默认路由，容器出网仍走主网卡。
+func (d *bridgeDriver) SetupEndpoint(containerID, networkID string, pid int, cfg vethConfig, ifName string) (*Endpoint, error) {
+	// 宿主机接口名限制为 15 个字符：vn/cn + 容器 ID 前 6 位 + 网络 ID 前 7 位
+	hostVethName := fmt.Sprintf("vn%s%s", containerID[:6], networkID[:7])
+	peerVethName := fmt.Sprintf("cn%s%s", containerID[:6], networkID[:7])
+
+	macAddress, mtu, err := d.setupVeth(hostVethName, peerVethName, ifName, pid, cfg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &Endpoint{
+		IPAddress:     cfg.ip,
+		Gateway:       d.gateway.String(),
+		MacAddress:    macAddress,
+		MTU:           mtu,
+		VethHost:      hostVethName,
+		VethContainer: ifName,
+	}
+	if cfg.ip6 != "" {
+		endpoint.IPv6Address = cfg.ip6
+		endpoint.IPv6Gateway = d.gateway6.String()
+	}
+	return endpoint, nil
+}
+
+// setupVeth 创建 veth pair，将宿主机端接入 bridge，容器端移入容器网络命名空间并配置 IP。
+// 返回容器内网卡的 MAC 地址和 MTU。
+func (d *bridgeDriver) setupVeth(hostVethName, peerVethName, containerVethName string, pid int, cfg vethConfig, defaultRoute bool) (string, int, error) {
 	// 获取 bridge
 	br, err := netlink.LinkByName(d.bridgeName)
 	if err != nil {
-		return nil, fmt.Errorf("get bridge: %w", err)
+		return "", 0, fmt.Errorf("get bridge: %w", err)
 	}
 
-	// 创建 veth pair
+	// 创建 veth pair（Phase 21: MTU 同时作用于两端，--mac-address 设置在容器端）
 	veth := &netlink.Veth{
 		LinkAttrs: netlink.LinkAttrs{
 			Name: hostVethName,
+			MTU:  d.mtu,
 		},
 		PeerName: peerVethName,
 	}
+	if cfg.mac != "" {
+		mac, err := net.ParseMAC(cfg.mac)
+		if err != nil {
+			return "", 0, fmt.Errorf("parse MAC address: %w", err)
+		}
+		veth.PeerHardwareAddr = mac
+	}
 
 	if err := netlink.LinkAdd(veth); err != nil {
-		return nil, fmt.Errorf("create veth pair: %w", err)
+		return "", 0, fmt.Errorf("create veth pair: %w", err)
 	}
 
 	// 获取宿主机端 veth
 	hostVeth, err := netlink.LinkByName(hostVethName)
 	if err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("get host veth: %w", err)
+		return "", 0, fmt.Errorf("get host veth: %w", err)
 	}
 
 	// 获取容器端 veth
 	containerVeth, err := netlink.LinkByName(peerVethName)
 	if err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("get container veth: %w", err)
+		return "", 0, fmt.Errorf("get container veth: %w", err)
 	}
 
 	// 将宿主机端连接到 bridge
 	if err := netlink.LinkSetMaster(hostVeth, br); err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("attach veth to bridge: %w", err)
+		return "", 0, fmt.Errorf("attach veth to bridge: %w", err)
+	}
+
+	// Phase 21: hairpin 模式让 DNAT 回同一容器的报文能从原 bridge 端口转发回去
+	if d.hairpin {
+		if err := netlink.LinkSetHairpin(hostVeth, true); err != nil {
+			d.cleanupVeth(hostVethName)
+			return "", 0, fmt.Errorf("enable hairpin mode: %w", err)
+		}
 	}
 
 	// 启动宿主机端 veth
 	if err := netlink.LinkSetUp(hostVeth); err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("bring up host veth: %w", err)
+		return "", 0, fmt.Errorf("bring up host veth: %w", err)
+	}
+
+	// Phase 21: --network-opt rate 在宿主机端 veth 上限速
+	if err := setupRateLimit(hostVeth, cfg.ingressRate, cfg.egressRate); err != nil {
+		d.cleanupVeth(hostVethName)
+		return "", 0, err
 	}
 
 	// 将容器端 veth 移动到容器网络命名空间
 	if err := netlink.LinkSetNsPid(containerVeth, pid); err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("move veth to container namespace: %w", err)
+		return "", 0, fmt.Errorf("move veth to container namespace: %w", err)
 	}
 
 	// 在容器命名空间中配置网络
-	if err := d.configureContainerNetwork(pid, peerVethName, containerVethName, containerIP); err != nil {
+	if err := d.configureContainerNetwork(pid, peerVethName, containerVethName, cfg, defaultRoute); err != nil {
 		d.cleanupVeth(hostVethName)
-		return nil, fmt.Errorf("configure container network: %w", err)
+		return "", 0, fmt.Errorf("configure container network: %w", err)
 	}
 
-	// 获取容器网卡的 MAC 地址
+	// 获取容器网卡的 MAC 地址和 MTU
 	macAddress := ""
+	mtu := 0
 	containerNs, err := netns.GetFromPid(pid)
 	if err == nil {
 		defer containerNs.Close()
@@ -179,22 +331,16 @@ func (d *bridgeDriver) SetupVeth(containerID string, pid int, containerIP string
 				return err
 			}
 			macAddress = link.Attrs().HardwareAddr.String()
+			mtu = link.Attrs().MTU
 			return nil
 		})
 	}
 
-	return &NetworkState{
-		Mode:          NetworkModeBridge,
-		IPAddress:     containerIP,
-		Gateway:       d.gateway.String(),
-		MacAddress:    macAddress,
-		VethHost:      hostVethName,
-		VethContainer: containerVethName,
-	}, nil
+	return macAddress, mtu, nil
 }
 
 // configureContainerNetwork 在容器命名空间中配置网络
-func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethName, containerIP string) error {
+func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethName string, cfg vethConfig, defaultRoute bool) error {
 	// 获取容器的网络命名空间
 	containerNs, err := netns.GetFromPid(pid)
 	if err != nil {
@@ -226,7 +372,7 @@ func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethNa
 		}
 
 		// 配置 IP 地址
-		addr, err := netlink.ParseAddr(fmt.Sprintf("%s/%d", containerIP, DefaultSubnetMask))
+		addr, err := netlink.ParseAddr(fmt.Sprintf("%s/%d", cfg.ip, d.maskSize()))
 		if err != nil {
 			return fmt.Errorf("parse container IP: %w", err)
 		}
@@ -235,6 +381,17 @@ func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethNa
 			return fmt.Errorf("add IP to container veth: %w", err)
 		}
 
+		// Phase 21: 双栈网络配置 IPv6 地址
+		if cfg.ip6 != "" {
+			if err := enableIPv6(dstVethName); err != nil {
+				return err
+			}
+			ipNet := &net.IPNet{IP: net.ParseIP(cfg.ip6), Mask: d.subnet6.Mask}
+			if err := netlink.AddrAdd(veth, newAddr(ipNet)); err != nil {
+				return fmt.Errorf("add IPv6 address to container veth: %w", err)
+			}
+		}
+
 		// 启动容器端 veth
 		if err := netlink.LinkSetUp(veth); err != nil {
 			return fmt.Errorf("bring up container veth: %w", err)
@@ -246,7 +403,10 @@ func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethNa
 			_ = netlink.LinkSetUp(lo)
 		}
 
-		// 添加默认路由
+		// 添加默认路由（附加网卡只使用子网直连路由）
+		if !defaultRoute {
+			return nil
+		}
 		route := &netlink.Route{
 			LinkIndex: veth.Attrs().Index,
 			Dst:       nil, // 默认路由
@@ -255,6 +415,15 @@ func (d *bridgeDriver) configureContainerNetwork(pid int, srcVethName, dstVethNa
 		if err := netlink.RouteReplace(route); err != nil {
 			return fmt.Errorf("add default route: %w", err)
 		}
+		if cfg.ip6 != "" {
+			route6 := &netlink.Route{
+				LinkIndex: veth.Attrs().Index,
+				Gw:        d.gateway6,
+			}
+			if err := netlink.RouteReplace(route6); err != nil {
+				return fmt.Errorf("add IPv6 default route: %w", err)
+			}
+		}
 
 		return nil
 	})
@@ -265,6 +434,40 @@ func (d *bridgeDriver) TeardownVeth(hostVethName string) error {
 	return d.cleanupVeth(hostVethName)
 }
 
+// RemoveBridge 删除 bridge 接口（Phase 21 新增，用于 network rm）
+func (d *bridgeDriver) RemoveBridge() error {
+	br, err := netlink.LinkByName(d.bridgeName)
+	if err != nil {
+		// bridge 不存在（网络从未被使用），视为已清理
+		return nil
+	}
+	if err := netlink.LinkDel(br); err != nil {
+		return fmt.Errorf("delete bridge %s: %w", d.bridgeName, err)
+	}
+	return nil
+}
+
+// AttachedVeths 返回接入 bridge 的 veth 接口名（Phase 21 新增，用于 network reconcile）。
+// bridge 不存在时返回空列表。
+func (d *bridgeDriver) AttachedVeths() ([]string, error) {
+	br, err := netlink.LinkByName(d.bridgeName)
+	if err != nil {
+		return nil, nil
+	}
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+
+	var names []string
+	for _, link := range links {
+		if link.Type() == "veth" && link.Attrs().MasterIndex == br.Attrs().Index {
+			names = append(names, link.Attrs().Name)
+		}
+	}
+	return names, nil
+}
+
 // cleanupVeth 删除 veth pair
 func (d *bridgeDriver) cleanupVeth(hostVethName string) error {
 	veth, err := netlink.LinkByName(hostVethName)
@@ -281,6 +484,52 @@ func enableIPForwarding() error {
 	return os.WriteFile("/proc/sys/net/ipv4/ip_forward", []byte("1"), 0644)
 }
 
+// enableIPv6Forwarding 启用 IPv6 转发（Phase 21 新增）
+func enableIPv6Forwarding() error {
+	return os.WriteFile("/proc/sys/net/ipv6/conf/all/forwarding", []byte("1"), 0644)
+}
+
+// enableIPv6 确保接口未禁用 IPv6（Phase 21 新增）。
+// sysctl 按当前线程的网络命名空间解析，在容器命名空间内调用时作用于容器网卡。
+func enableIPv6(ifName string) error {
+	path := filepath.Join("/proc/sys/net/ipv6/conf", ifName, "disable_ipv6")
+	if _, err := os.Stat("/proc/sys/net/ipv6"); err != nil {
+		return fmt.Errorf("IPv6 is not available on this host: %w", err)
+	}
+	if err := os.WriteFile(path, []byte("0"), 0644); err != nil {
+		return fmt.Errorf("enable IPv6 on %s: %w", ifName, err)
+	}
+	return nil
+}
+
+// enableRouteLocalnet 允许接口路由源/目的为 127.0.0.0/8 的报文（Phase 21 新增）
+func enableRouteLocalnet(ifName string) error {
+	path := filepath.Join("/proc/sys/net/ipv4/conf", ifName, "route_localnet")
+	if err := os.WriteFile(path, []byte("1"), 0644); err != nil {
+		return fmt.Errorf("enable route_localnet on %s: %w", ifName, err)
+	}
+	return nil
+}
+
+// enableBridgeNetfilter 使 bridge 内转发的报文经过 iptables/nftables 的 forward 钩子（Phase 21 新增，icc=false 依赖），
+// 必要时加载 br_netfilter 模块
+func enableBridgeNetfilter(ipv6 bool) error {
+	name := "bridge-nf-call-iptables"
+	if ipv6 {
+		name = "bridge-nf-call-ip6tables"
+	}
+	path := filepath.Join("/proc/sys/net/bridge", name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if output, err := exec.Command("modprobe", "br_netfilter").CombinedOutput(); err != nil {
+			return fmt.Errorf("load br_netfilter (required by icc=false): %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	if err := os.WriteFile(path, []byte("1"), 0644); err != nil {
+		return fmt.Errorf("enable %s: %w", name, err)
+	}
+	return nil
+}
+
 // withNetns 在指定的网络命名空间中执行函数
 func withNetns(ns netns.NsHandle, fn func() error) error {
 	// 锁定当前 goroutine 到 OS 线程
@@ -310,3 +559,17 @@ func withNetns(ns netns.NsHandle, fn func() error) error {
 
 	return fnErr
 }
+
+// InterfaceStats 返回宿主机上网络接口的累计收发字节数（Phase 20 新增）。
+// 用于 stats 读取容器 veth 的流量：宿主机端 veth 的 rx 即容器发送的数据。
+func InterfaceStats(name string) (rxBytes, txBytes uint64, err error) {
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return 0, 0, fmt.Errorf("find interface %s: %w", name, err)
+	}
+	stats := link.Attrs().Statistics
+	if stats == nil {
+		return 0, 0, fmt.Errorf("no statistics for interface %s", name)
+	}
+	return stats.RxBytes, stats.TxBytes, nil
+}
@@ -8,7 +8,7 @@ import "fmt"
 // bridgeDriver 实现 bridge 网络模式（非 Linux 平台 stub）
 type bridgeDriver struct{}
 
-func newBridgeDriver(bridgeName string) (*bridgeDriver, error) {
+func newBridgeDriver(bridgeName, subnetStr, gatewayStr string) (*bridgeDriver, error) {
 	return nil, fmt.Errorf("bridge networking is only supported on Linux")
 }
 
@@ -20,6 +20,23 @@ func (d *bridgeDriver) SetupVeth(containerID string, pid int, containerIP string
 	return nil, fmt.Errorf("bridge networking is only supported on Linux")
 }
 
+func (d *bridgeDriver) SetupEndpoint(containerID, networkID string, pid int, containerIP, ifName string) (*Endpoint, error) {
+	return nil, fmt.Errorf("bridge networking is only supported on Linux")
+}
+
 func (d *bridgeDriver) TeardownVeth(hostVethName string) error {
 	return fmt.Errorf("bridge networking is only supported on Linux")
 }
+
+func (d *bridgeDriver) RemoveBridge() error {
+	return fmt.Errorf("bridge networking is only supported on Linux")
+}
+
+func (d *bridgeDriver) AttachedVeths() ([]string, error) {
+	return nil, fmt.Errorf("bridge networking is only supported on Linux")
+}
+
+// InterfaceStats 返回网络接口的累计收发字节数（非 Linux 平台 stub）
+func InterfaceStats(name string) (rxBytes, txBytes uint64, err error) {
+	return 0, 0, fmt.Errorf("bridge networking is only supported on Linux")
+}
@@ -0,0 +1,44 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DaemonConfigFile 是 $MINIDOCKER_ROOT 下的全局网络设置文件（Phase 21 新增，对应 Docker 的 daemon.json）
+const DaemonConfigFile = "daemon.json"
+
+// DaemonConfig 是作用于所有容器的全局网络设置
+type DaemonConfig struct {
+	// UserlandProxy 为每个发布端口在宿主机上启动用户态代理（TCP/UDP），
+	// 处理 loopback（127.0.0.1、::1）访问和容器访问自身发布端口（hairpin）的流量。
+	// 默认关闭：此时只使用 DNAT，并自动为 bridge 开启 route_localnet 和 veth 的 hairpin 模式。
+	UserlandProxy bool `json:"userland-proxy,omitempty"`
+
+	// ICC 设为 false 时禁止默认 bridge 网络上的容器互相访问（未设置时允许）。
+	// 用户自定义网络通过 network create --opt icc=false 单独设置。
+	ICC *bool `json:"icc,omitempty"`
+}
+
+// DisableICC 返回默认 bridge 网络是否禁止容器之间互相访问
+func (c *DaemonConfig) DisableICC() bool {
+	return c.ICC != nil && !*c.ICC
+}
+
+// LoadDaemonConfig 读取 rootDir/daemon.json；文件不存在时返回默认设置
+func LoadDaemonConfig(rootDir string) (*DaemonConfig, error) {
+	config := &DaemonConfig{}
+	data, err := os.ReadFile(filepath.Join(rootDir, DaemonConfigFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", DaemonConfigFile, err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", DaemonConfigFile, err)
+	}
+	return config, nil
+}
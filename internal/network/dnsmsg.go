@@ -0,0 +1,182 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// 内嵌 DNS 的最小报文编解码（Phase 21 新增）。
+//
+// 只处理包含单个问题的标准查询：解析问题段，构造 A/AAAA/PTR 应答或错误应答。
+// 无法识别的报文不在这里解析，由 Resolver 原样转发给宿主机的上游 DNS。
+
+const (
+	dnsTypeA    uint16 = 1
+	dnsTypePTR  uint16 = 12
+	dnsTypeAAAA uint16 = 28
+	dnsClassIN  uint16 = 1
+
+	dnsRcodeSuccess  uint16 = 0
+	dnsRcodeServFail uint16 = 2
+
+	dnsHeaderLen = 12
+
+	// dnsAnswerTTL 是容器名称应答的 TTL（秒，与 Docker 内嵌 DNS 一致）
+	dnsAnswerTTL = 600
+
+	// dnsFlagQR/RD/AA/RA 是报文头中的标志位
+	dnsFlagQR uint16 = 1 << 15
+	dnsFlagAA uint16 = 1 << 10
+	dnsFlagRD uint16 = 1 << 8
+	dnsFlagRA uint16 = 1 << 7
+)
+
+var errUnsupportedQuery = errors.New("unsupported dns query")
+
+// aliasRegex 限定网络别名为 DNS 主机名（字母数字开头，可包含 - 和 . 分隔的标签）
+var aliasRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,62})(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,62}))*$`)
+
+// ValidateAlias 校验 --network-alias/--alias 指定的网络别名
+func ValidateAlias(alias string) error {
+	if len(alias) > 253 || !aliasRegex.MatchString(alias) {
+		return fmt.Errorf("invalid network alias %q: must be a valid DNS hostname", alias)
+	}
+	return nil
+}
+
+// dnsQuery 是解析后的 DNS 查询
+type dnsQuery struct {
+	id    uint16
+	flags uint16
+
+	// name 是查询名（小写，不含结尾的点）
+	name   string
+	qtype  uint16
+	qclass uint16
+
+	// question 是问题段的原始字节，应答时原样回填
+	question []byte
+}
+
+// parseDNSQuery 解析只含一个问题的标准查询（opcode QUERY）。
+// 问题段中的名称不允许使用压缩指针（标准客户端不会这样编码）。
+func parseDNSQuery(msg []byte) (*dnsQuery, error) {
+	if len(msg) < dnsHeaderLen {
+		return nil, errUnsupportedQuery
+	}
+	q := &dnsQuery{
+		id:    binary.BigEndian.Uint16(msg[0:2]),
+		flags: binary.BigEndian.Uint16(msg[2:4]),
+	}
+	opcode := (q.flags >> 11) & 0xF
+	if q.flags&dnsFlagQR != 0 || opcode != 0 || binary.BigEndian.Uint16(msg[4:6]) != 1 {
+		return nil, errUnsupportedQuery
+	}
+
+	var labels []string
+	off := dnsHeaderLen
+	for {
+		if off >= len(msg) {
+			return nil, errUnsupportedQuery
+		}
+		n := int(msg[off])
+		off++
+		if n == 0 {
+			break
+		}
+		if n&0xC0 != 0 || off+n > len(msg) {
+			return nil, errUnsupportedQuery
+		}
+		labels = append(labels, string(msg[off:off+n]))
+		off += n
+	}
+	if off+4 > len(msg) {
+		return nil, errUnsupportedQuery
+	}
+	q.qtype = binary.BigEndian.Uint16(msg[off : off+2])
+	q.qclass = binary.BigEndian.Uint16(msg[off+2 : off+4])
+	q.name = strings.ToLower(strings.Join(labels, "."))
+	q.question = msg[dnsHeaderLen : off+4]
+	return q, nil
+}
+
+// reply 构造对查询的应答：回填问题段，附加给定的资源记录
+func (q *dnsQuery) reply(rcode uint16, answers [][]byte) []byte {
+	msg := make([]byte, dnsHeaderLen, 512)
+	binary.BigEndian.PutUint16(msg[0:2], q.id)
+	binary.BigEndian.PutUint16(msg[2:4], dnsFlagQR|dnsFlagAA|dnsFlagRA|(q.flags&dnsFlagRD)|rcode)
+	binary.BigEndian.PutUint16(msg[4:6], 1)
+	binary.BigEndian.PutUint16(msg[6:8], uint16(len(answers)))
+	msg = append(msg, q.question...)
+	for _, rr := range answers {
+		msg = append(msg, rr...)
+	}
+	return msg
+}
+
+// dnsRecord 构造一条 IN 类资源记录，名称以压缩指针引用问题段中的查询名
+func dnsRecord(rtype uint16, rdata []byte) []byte {
+	rr := make([]byte, 12, 12+len(rdata))
+	binary.BigEndian.PutUint16(rr[0:2], 0xC000|dnsHeaderLen)
+	binary.BigEndian.PutUint16(rr[2:4], rtype)
+	binary.BigEndian.PutUint16(rr[4:6], dnsClassIN)
+	binary.BigEndian.PutUint32(rr[6:10], dnsAnswerTTL)
+	binary.BigEndian.PutUint16(rr[10:12], uint16(len(rdata)))
+	return append(rr, rdata...)
+}
+
+// encodeDNSName 将域名编码为标签序列（不压缩）
+func encodeDNSName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// reverseAddr 解析 in-addr.arpa / ip6.arpa 反向查询名，返回对应的 IP 地址；不是反向查询名时返回 nil
+func reverseAddr(name string) net.IP {
+	if rest := strings.TrimSuffix(name, ".ip6.arpa"); rest != name {
+		return reverseAddr6(rest)
+	}
+	rest := strings.TrimSuffix(name, ".in-addr.arpa")
+	if rest == name {
+		return nil
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) != 4 {
+		return nil
+	}
+	ip := net.ParseIP(parts[3] + "." + parts[2] + "." + parts[1] + "." + parts[0])
+	if ip == nil {
+		return nil
+	}
+	return ip.To4()
+}
+
+// reverseAddr6 将 ip6.arpa 的 32 个逆序半字节还原为 IPv6 地址（Phase 21 新增）
+func reverseAddr6(nibbles string) net.IP {
+	parts := strings.Split(nibbles, ".")
+	if len(parts) != 32 {
+		return nil
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		if len(parts[i]) != 1 {
+			return nil
+		}
+		b.WriteString(parts[i])
+		if i%4 == 0 && i > 0 {
+			b.WriteByte(':')
+		}
+	}
+	return net.ParseIP(b.String())
+}
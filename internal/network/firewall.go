@@ -0,0 +1,110 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// Phase 21: 防火墙后端
+//
+// bridge 网络的 NAT（MASQUERADE）、端口发布（DNAT）和转发规则由防火墙后端编程。
+// iptables 后端通过 coreos/go-iptables 调用 iptables/ip6tables；nftables 后端使用独立的
+// minidocker 表，通过 nft -f 以事务方式提交规则。进程启动时自动检测：iptables 可用时优先使用，
+// 否则使用 nftables（仅有 nftables 的宿主机）。
+
+// 防火墙后端名称
+const (
+	FirewallIptables = "iptables"
+	FirewallNftables = "nftables"
+)
+
+// FirewallBackendEnvVar 指定防火墙后端（iptables/nftables），覆盖自动检测。
+// 同一宿主机上的所有 minidocker 进程必须使用相同的后端，否则规则无法被正确清理。
+const FirewallBackendEnvVar = "MINIDOCKER_FIREWALL_BACKEND"
+
+// firewall 为一个 bridge 网络的一个地址族编程规则
+type firewall interface {
+	// SetupMasquerade 设置子网出网的 MASQUERADE 规则（幂等）
+	SetupMasquerade() error
+	// TeardownMasquerade 移除 MASQUERADE 规则
+	TeardownMasquerade() error
+	// SetupForwardAccept 设置允许 bridge 流量转发的规则（幂等）；
+	// icc 为 false 时丢弃 bridge 上容器之间的转发流量（经发布端口 DNAT 的流量除外），为 true 时移除这些规则
+	SetupForwardAccept(icc bool) error
+	// TeardownForwardAccept 移除 bridge 流量转发规则
+	TeardownForwardAccept() error
+	// SetupPortMappings 设置容器在该地址族上的全部端口映射（替换已有规则）
+	SetupPortMappings(containerID, containerIP string, mappings []PortMapping) error
+	// TeardownPortMappings 移除容器在该地址族上的端口映射规则
+	TeardownPortMappings(containerID, containerIP string, mappings []PortMapping) error
+	// PrunePortMappings 删除 keep 不保留的容器端口映射规则，返回删除的规则数（Phase 21 新增，用于 network reconcile）。
+	// 规则按创建时记录的容器 ID（comment）判断归属。
+	PrunePortMappings(keep func(containerID string) bool) (int, error)
+}
+
+var (
+	firewallOnce sync.Once
+	firewallName string
+	firewallErr  error
+)
+
+// firewallBackend 返回当前进程使用的防火墙后端（检测结果在进程内缓存）
+func firewallBackend() (string, error) {
+	firewallOnce.Do(func() {
+		firewallName, firewallErr = detectFirewallBackend()
+	})
+	return firewallName, firewallErr
+}
+
+// detectFirewallBackend 按环境变量或可用的命令选择防火墙后端
+func detectFirewallBackend() (string, error) {
+	if backend := os.Getenv(FirewallBackendEnvVar); backend != "" {
+		switch backend {
+		case FirewallIptables, FirewallNftables:
+			return backend, nil
+		default:
+			return "", fmt.Errorf("invalid %s %q (supported: %s, %s)", FirewallBackendEnvVar, backend, FirewallIptables, FirewallNftables)
+		}
+	}
+
+	if _, err := exec.LookPath("iptables"); err == nil {
+		return FirewallIptables, nil
+	}
+	if _, err := exec.LookPath("nft"); err == nil {
+		return FirewallNftables, nil
+	}
+	return "", fmt.Errorf("no firewall backend available: neither iptables nor nft found in PATH")
+}
+
+// newFirewall 为 bridge 网络的一个子网创建防火墙后端（IPv6 子网编程 IPv6 规则）。
+// userlandProxy 时发布端口的 loopback 与 hairpin 流量不做 DNAT，交给用户态代理。
+func newFirewall(bridgeName, subnet string, userlandProxy bool) (firewall, error) {
+	backend, err := firewallBackend()
+	if err != nil {
+		return nil, err
+	}
+	if backend == FirewallNftables {
+		return newNftablesManager(bridgeName, subnet, userlandProxy)
+	}
+	return newIptablesManager(bridgeName, subnet, userlandProxy)
+}
+
+// isLoopbackIP 返回宿主机地址是否为 loopback 地址（127.0.0.0/8 或 ::1）
+func isLoopbackIP(hostIP string) bool {
+	ip := net.ParseIP(hostIP)
+	return ip != nil && ip.IsLoopback()
+}
+
+// loopbackCIDR 返回地址族的 loopback 网段
+func loopbackCIDR(ipv6 bool) string {
+	if ipv6 {
+		return "::1/128"
+	}
+	return "127.0.0.0/8"
+}
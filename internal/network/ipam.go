@@ -4,15 +4,17 @@
 package network
 
 import (
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
 	"sync"
 
+	"minidocker/internal/state"
 	"minidocker/pkg/fileutil"
+	"minidocker/pkg/idutil"
 )
 
 // IPAM 定义 IP 地址管理接口
@@ -20,11 +22,20 @@ type IPAM interface {
 	// Allocate 为容器分配 IP 地址
 	Allocate(containerID string) (string, error)
 
+	// AllocateAddress 为容器分配指定的 IP 地址（Phase 21 新增，用于 --ip6 等静态分配）
+	AllocateAddress(containerID, ip string) (string, error)
+
 	// Release 释放容器的 IP 地址
 	Release(containerID string) error
 
 	// Get 获取容器的 IP 地址（如果已分配）
 	Get(containerID string) (string, bool)
+
+	// List 返回所有已分配的地址（containerID -> IP）（Phase 21 新增，用于 network reconcile）
+	List() (map[string]string, error)
+
+	// Prune 在同一次加锁中释放 keep 返回 false 的容器的地址，返回被释放的地址（Phase 21 新增）
+	Prune(keep func(containerID string) bool) (map[string]string, error)
 }
 
 // IPAMConfig 保存 IPAM 的持久化状态
@@ -51,18 +62,49 @@ type ipamManager struct {
 	filePath string
 }
 
-// NewIPAM 创建新的 IPAM 管理器
+// NewIPAM 创建默认 bridge 网络的 IPAM 管理器
 func NewIPAM(dataDir string) (IPAM, error) {
-	// 解析默认子网
-	_, subnet, err := net.ParseCIDR(DefaultSubnet)
+	return newIPAM(dataDir, DefaultSubnet, DefaultGateway, "ipam.json")
+}
+
+// newNetworkIPAM 创建指定网络的 IPAM 管理器（Phase 21 新增），每个用户自定义网络独立一个文件
+func newNetworkIPAM(dataDir string, info *NetworkInfo) (IPAM, error) {
+	if info.Name == DefaultNetworkName {
+		return NewIPAM(dataDir)
+	}
+	return newIPAM(dataDir, info.Subnet, info.Gateway, filepath.Base(ipamFilePath("", info.ID)))
+}
+
+// newNetworkIPAM6 创建双栈网络的 IPv6 IPAM 管理器（Phase 21 新增）；未启用 IPv6 的网络返回 nil
+func newNetworkIPAM6(dataDir string, info *NetworkInfo) (IPAM, error) {
+	if !info.EnableIPv6 {
+		return nil, nil
+	}
+	return newIPAM(dataDir, info.SubnetV6, info.GatewayV6, filepath.Base(ipam6FilePath("", info.ID)))
+}
+
+// ipamFilePath 返回用户自定义网络的 IPAM 文件路径
+func ipamFilePath(networkDir, networkID string) string {
+	return filepath.Join(networkDir, "ipam-"+networkID+".json")
+}
+
+// ipam6FilePath 返回双栈网络的 IPv6 IPAM 文件路径
+func ipam6FilePath(networkDir, networkID string) string {
+	return filepath.Join(networkDir, "ipam6-"+networkID+".json")
+}
+
+// newIPAM 创建管理指定子网的 IPAM 管理器
+func newIPAM(dataDir, subnetStr, gatewayStr, fileName string) (IPAM, error) {
+	// 解析子网
+	_, subnet, err := net.ParseCIDR(subnetStr)
 	if err != nil {
-		return nil, fmt.Errorf("parse default subnet: %w", err)
+		return nil, fmt.Errorf("parse subnet: %w", err)
 	}
 
-	// 解析默认网关
-	gateway := net.ParseIP(DefaultGateway)
+	// 解析网关
+	gateway := net.ParseIP(gatewayStr)
 	if gateway == nil {
-		return nil, fmt.Errorf("parse default gateway: %s", DefaultGateway)
+		return nil, fmt.Errorf("parse gateway: %s", gatewayStr)
 	}
 
 	// 确保数据目录存在
@@ -75,14 +117,17 @@ func NewIPAM(dataDir string) (IPAM, error) {
 		dataDir:  dataDir,
 		subnet:   subnet,
 		gateway:  gateway,
-		filePath: filepath.Join(networkDir, "ipam.json"),
+		filePath: filepath.Join(networkDir, fileName),
 	}, nil
 }
 
 // Allocate 为容器分配 IP 地址
 func (m *ipamManager) Allocate(containerID string) (string, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock, err := m.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
 
 	// 加载当前状态
 	config, err := m.load()
@@ -97,9 +142,7 @@ func (m *ipamManager) Allocate(containerID string) (string, error) {
 
 	// 计算子网范围
 	// 对于 172.17.0.0/16，范围是 172.17.0.1 到 172.17.255.254
-	ones, bits := m.subnet.Mask.Size()
-	hostBits := bits - ones
-	maxHosts := uint32(1<<hostBits) - 2 // 减去网络地址和广播地址
+	maxHosts := m.maxHosts()
 
 	// 从上次分配的位置开始查找
 	startHost := config.LastAllocated + 1
@@ -107,21 +150,15 @@ func (m *ipamManager) Allocate(containerID string) (string, error) {
 		startHost = 2 // 跳过 .0（网络地址）和 .1（网关）
 	}
 
-	// 获取子网的网络地址（如 172.17.0.0）
-	networkIP := binary.BigEndian.Uint32(m.subnet.IP.To4())
-
 	// 查找可用的 IP
 	for i := uint32(0); i < maxHosts; i++ {
-		hostPart := (startHost + i - 2) % (maxHosts - 1) + 2 // 在 2 到 maxHosts 之间循环
-		candidateIP := networkIP + hostPart
+		hostPart := uint32((uint64(startHost)+uint64(i)-2)%uint64(maxHosts-1) + 2) // 在 2 到 maxHosts 之间循环
 
-		// 转换为 IP 字符串
-		ipBytes := make([]byte, 4)
-		binary.BigEndian.PutUint32(ipBytes, candidateIP)
-		ip := net.IP(ipBytes).String()
+		// Phase 21: 按字节相加，同时支持 IPv4 和 IPv6 子网
+		ip := ipAdd(m.subnet.IP, hostPart).String()
 
-		// 检查是否已被使用
-		used := false
+		// 检查是否已被使用（Phase 21: 自定义网关不一定是 .1，同样跳过）
+		used := ip == m.gateway.String()
 		for _, allocatedIP := range config.Allocated {
 			if allocatedIP == ip {
 				used = true
@@ -145,10 +182,96 @@ func (m *ipamManager) Allocate(containerID string) (string, error) {
 	return "", fmt.Errorf("no available IP addresses in subnet %s", m.subnet.String())
 }
 
+// AllocateAddress 为容器分配指定的 IP 地址（Phase 21 新增）。
+// 地址必须属于子网，且不能是网络地址、IPv4 广播地址、网关或其他容器已使用的地址；容器已分配其他地址时报错。
+func (m *ipamManager) AllocateAddress(containerID, ipStr string) (string, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	requested := net.ParseIP(ipStr)
+	if requested == nil || !m.subnet.Contains(requested) {
+		return "", fmt.Errorf("address %s does not belong to subnet %s", ipStr, m.subnet.String())
+	}
+	broadcast := ipv4Broadcast(m.subnet)
+	if requested.Equal(m.subnet.IP) || requested.Equal(m.gateway) || (broadcast != nil && requested.Equal(broadcast)) {
+		return "", fmt.Errorf("address %s is reserved in subnet %s", ipStr, m.subnet.String())
+	}
+	ip := requested.String()
+
+	config, err := m.load()
+	if err != nil {
+		return "", err
+	}
+
+	if allocated, ok := config.Allocated[containerID]; ok {
+		if allocated == ip {
+			return ip, nil
+		}
+		return "", fmt.Errorf("container already has address %s in subnet %s", allocated, m.subnet.String())
+	}
+	for id, allocated := range config.Allocated {
+		if allocated == ip {
+			return "", fmt.Errorf("address %s is already in use by container %s", ip, idutil.ShortID(id))
+		}
+	}
+
+	config.Allocated[containerID] = ip
+	if err := m.save(config); err != nil {
+		return "", fmt.Errorf("save IPAM state: %w", err)
+	}
+	return ip, nil
+}
+
+// maxHosts 返回可顺序分配的主机号上限。
+// IPv6 子网的主机空间远大于实际容器数，只在前 2^32 个地址内分配。
+func (m *ipamManager) maxHosts() uint32 {
+	ones, bits := m.subnet.Mask.Size()
+	hostBits := bits - ones
+	if hostBits >= 32 {
+		return math.MaxUint32 - 1
+	}
+	return uint32(1<<hostBits) - 2 // 减去网络地址和广播地址
+}
+
+// ipv4Broadcast 返回 IPv4 子网的广播地址；IPv6 没有广播地址，返回 nil
+func ipv4Broadcast(subnet *net.IPNet) net.IP {
+	v4 := subnet.IP.To4()
+	if v4 == nil || len(subnet.Mask) != net.IPv4len {
+		return nil
+	}
+	out := make(net.IP, net.IPv4len)
+	for i := range v4 {
+		out[i] = v4[i] | ^subnet.Mask[i]
+	}
+	return out
+}
+
+// ipAdd 返回 ip + n（Phase 21 新增），IPv4 地址按 4 字节计算
+func ipAdd(ip net.IP, n uint32) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	out := make(net.IP, len(ip))
+	copy(out, ip)
+	carry := uint64(n)
+	for i := len(out) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(out[i]) + carry
+		out[i] = byte(sum)
+		carry = sum >> 8
+	}
+	return out
+}
+
 // Release 释放容器的 IP 地址
 func (m *ipamManager) Release(containerID string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	config, err := m.load()
 	if err != nil {
@@ -169,8 +292,11 @@ func (m *ipamManager) Release(containerID string) error {
 
 // Get 获取容器的 IP 地址
 func (m *ipamManager) Get(containerID string) (string, bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	unlock, err := m.lock()
+	if err != nil {
+		return "", false
+	}
+	defer unlock()
 
 	config, err := m.load()
 	if err != nil {
@@ -181,11 +307,70 @@ func (m *ipamManager) Get(containerID string) (string, bool) {
 	return ip, ok
 }
 
+// List 返回所有已分配的地址
+func (m *ipamManager) List() (map[string]string, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	return config.Allocated, nil
+}
+
+// Prune 释放 keep 返回 false 的容器的地址，返回被释放的地址（containerID -> IP）
+func (m *ipamManager) Prune(keep func(containerID string) bool) (map[string]string, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	released := make(map[string]string)
+	for containerID, ip := range config.Allocated {
+		if !keep(containerID) {
+			released[containerID] = ip
+			delete(config.Allocated, containerID)
+		}
+	}
+	if len(released) == 0 {
+		return released, nil
+	}
+	if err := m.save(config); err != nil {
+		return nil, fmt.Errorf("save IPAM state: %w", err)
+	}
+	return released, nil
+}
+
+// lock 获取进程内互斥锁和 network 目录的文件锁（与 networks.json、ports.json 相同）。
+// Phase 21: 多个 CLI/shim 进程以及 network reconcile 可能并发读-改-写 IPAM 文件。
+func (m *ipamManager) lock() (func(), error) {
+	m.mu.Lock()
+	lock, err := state.AcquireLock(filepath.Dir(m.filePath))
+	if err != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("acquire IPAM lock: %w", err)
+	}
+	return func() {
+		lock.Release()
+		m.mu.Unlock()
+	}, nil
+}
+
 // load 加载 IPAM 配置
 func (m *ipamManager) load() (*IPAMConfig, error) {
 	config := &IPAMConfig{
-		Subnet:    DefaultSubnet,
-		Gateway:   DefaultGateway,
+		Subnet:    m.subnet.String(),
+		Gateway:   m.gateway.String(),
 		Allocated: make(map[string]string),
 	}
 
@@ -10,6 +10,8 @@ type IPAM interface {
 	Allocate(containerID string) (string, error)
 	Release(containerID string) error
 	Get(containerID string) (string, bool)
+	List() (map[string]string, error)
+	Prune(keep func(containerID string) bool) (map[string]string, error)
 }
 
 // IPAMConfig 保存 IPAM 的持久化状态
@@ -5,7 +5,11 @@ package network
 
 import (
 	"fmt"
+	"net"
 	"strconv"
+	"strings"
+
+	"minidocker/pkg/idutil"
 
 	"github.com/coreos/go-iptables/iptables"
 )
@@ -15,40 +19,57 @@ type iptablesManager struct {
 	ipt        *iptables.IPTables
 	bridgeName string
 	subnet     string
+
+	// userlandProxy 表示发布端口的 loopback/hairpin 流量由用户态代理处理（Phase 21 新增）
+	userlandProxy bool
 }
 
-// newIptablesManager 创建新的 iptables 管理器
-func newIptablesManager(bridgeName string) (*iptablesManager, error) {
-	ipt, err := iptables.New()
+// newIptablesManager 创建新的 iptables 管理器（Phase 21: 每个 bridge 网络一个，规则按子网区分）。
+// IPv6 子网使用 ip6tables 编程同样的规则。
+func newIptablesManager(bridgeName, subnet string, userlandProxy bool) (*iptablesManager, error) {
+	proto := iptables.ProtocolIPv4
+	if ip, _, err := net.ParseCIDR(subnet); err == nil && ip.To4() == nil {
+		proto = iptables.ProtocolIPv6
+	}
+	ipt, err := iptables.NewWithProtocol(proto)
 	if err != nil {
 		return nil, fmt.Errorf("create iptables instance: %w", err)
 	}
 
 	return &iptablesManager{
-		ipt:        ipt,
-		bridgeName: bridgeName,
-		subnet:     DefaultSubnet,
+		ipt:           ipt,
+		bridgeName:    bridgeName,
+		subnet:        subnet,
+		userlandProxy: userlandProxy,
 	}, nil
 }
 
+// natRules 返回网络的 POSTROUTING 规则
+func (m *iptablesManager) natRules() [][]string {
+	return [][]string{
+		// 出网 NAT
+		{"-s", m.subnet, "!", "-o", m.bridgeName, "-j", "MASQUERADE"},
+		// Phase 21: 宿主机本地地址（包括 route_localnet 下的 127.0.0.1）经 DNAT 访问容器时改写源地址
+		{"-o", m.bridgeName, "-m", "addrtype", "--src-type", "LOCAL", "-j", "MASQUERADE"},
+		// Phase 21: 容器经发布端口访问同网络容器（hairpin，包括自身）时改写源地址，使回包经宿主机反向 DNAT
+		{"-s", m.subnet, "-o", m.bridgeName, "-m", "conntrack", "--ctstate", "DNAT", "-j", "MASQUERADE"},
+	}
+}
+
 // SetupMasquerade 设置 MASQUERADE 规则用于出网 NAT
 // iptables -t nat -A POSTROUTING -s 172.17.0.0/16 ! -o minidocker0 -j MASQUERADE
 func (m *iptablesManager) SetupMasquerade() error {
-	ruleSpec := []string{
-		"-s", m.subnet,
-		"!", "-o", m.bridgeName,
-		"-j", "MASQUERADE",
-	}
-
-	// 使用 AppendUnique 确保规则只添加一次
-	exists, err := m.ipt.Exists("nat", "POSTROUTING", ruleSpec...)
-	if err != nil {
-		return fmt.Errorf("check masquerade rule: %w", err)
-	}
+	for _, ruleSpec := range m.natRules() {
+		// 使用 Exists 确保规则只添加一次
+		exists, err := m.ipt.Exists("nat", "POSTROUTING", ruleSpec...)
+		if err != nil {
+			return fmt.Errorf("check masquerade rule: %w", err)
+		}
 
-	if !exists {
-		if err := m.ipt.Append("nat", "POSTROUTING", ruleSpec...); err != nil {
-			return fmt.Errorf("add masquerade rule: %w", err)
+		if !exists {
+			if err := m.ipt.Append("nat", "POSTROUTING", ruleSpec...); err != nil {
+				return fmt.Errorf("add masquerade rule: %w", err)
+			}
 		}
 	}
 
@@ -57,100 +78,87 @@ func (m *iptablesManager) SetupMasquerade() error {
 
 // TeardownMasquerade 移除 MASQUERADE 规则
 func (m *iptablesManager) TeardownMasquerade() error {
-	ruleSpec := []string{
-		"-s", m.subnet,
-		"!", "-o", m.bridgeName,
-		"-j", "MASQUERADE",
-	}
-
-	exists, err := m.ipt.Exists("nat", "POSTROUTING", ruleSpec...)
-	if err != nil {
-		return fmt.Errorf("check masquerade rule: %w", err)
-	}
+	for _, ruleSpec := range m.natRules() {
+		exists, err := m.ipt.Exists("nat", "POSTROUTING", ruleSpec...)
+		if err != nil {
+			return fmt.Errorf("check masquerade rule: %w", err)
+		}
 
-	if exists {
-		if err := m.ipt.Delete("nat", "POSTROUTING", ruleSpec...); err != nil {
-			return fmt.Errorf("delete masquerade rule: %w", err)
+		if exists {
+			if err := m.ipt.Delete("nat", "POSTROUTING", ruleSpec...); err != nil {
+				return fmt.Errorf("delete masquerade rule: %w", err)
+			}
 		}
 	}
 
 	return nil
 }
 
-// SetupPortMapping 设置端口映射规则
+// iptablesRule 是一条 iptables 规则
+type iptablesRule struct {
+	table string
+	chain string
+	spec  []string
+}
+
+// portMappingRules 返回端口映射的规则：
 // DNAT 规则：iptables -t nat -A PREROUTING -p tcp --dport <hostPort> -j DNAT --to <containerIP>:<containerPort>
 // FORWARD 规则：iptables -A FORWARD -p tcp -d <containerIP> --dport <containerPort> -j ACCEPT
-func (m *iptablesManager) SetupPortMapping(containerIP string, mapping PortMapping) error {
+//
+// Phase 21: userlandProxy 时 loopback 访问和来自 bridge 的 hairpin 流量由用户态代理处理，不做 DNAT。
+// 每条规则带有 -m comment --comment <containerID>，network reconcile 按 comment 判断规则归属；
+// containerID 为空时生成不带 comment 的规则（旧版本创建的规则）。
+func (m *iptablesManager) portMappingRules(containerID, containerIP string, mapping PortMapping, userlandProxy bool) []iptablesRule {
 	protocol := mapping.GetProtocol()
 	hostIP := mapping.GetHostIP()
 	hostPort := strconv.Itoa(int(mapping.HostPort))
 	containerPort := strconv.Itoa(int(mapping.ContainerPort))
-	destination := fmt.Sprintf("%s:%s", containerIP, containerPort)
-
-	// PREROUTING DNAT 规则（处理从外部进入的流量）
-	// 限制为目的地址为本机（LOCAL），避免错误劫持宿主访问远端的流量。
-	preroutingRule := []string{
-		"-p", protocol,
-		"-m", protocol,
-	}
-	if hostIP != "" && hostIP != "0.0.0.0" {
-		preroutingRule = append(preroutingRule, "-d", hostIP)
-	}
-	preroutingRule = append(preroutingRule,
-		"-m", "addrtype",
-		"--dst-type", "LOCAL",
-		"--dport", hostPort,
-		"-j", "DNAT",
-		"--to-destination", destination,
-	)
-
-	exists, err := m.ipt.Exists("nat", "PREROUTING", preroutingRule...)
-	if err != nil {
-		return fmt.Errorf("check PREROUTING rule: %w", err)
-	}
+	destination := net.JoinHostPort(containerIP, containerPort)
 
-	addedPrerouting := false
-	if !exists {
-		if err := m.ipt.Append("nat", "PREROUTING", preroutingRule...); err != nil {
-			return fmt.Errorf("add PREROUTING rule: %w", err)
-		}
-		addedPrerouting = true
-	}
-
-	// OUTPUT DNAT 规则（处理从本地发起到 localhost 的流量）
-	// 同样限制为目的地址为本机（LOCAL），避免将宿主到远端 :hostPort 的连接 DNAT 到容器。
-	outputRule := []string{
-		"-p", protocol,
-		"-m", protocol,
-	}
-	if hostIP != "" && hostIP != "0.0.0.0" {
-		outputRule = append(outputRule, "-d", hostIP)
+	var comment []string
+	if containerID != "" {
+		comment = []string{"-m", "comment", "--comment", containerID}
 	}
-	outputRule = append(outputRule,
-		"-m", "addrtype",
-		"--dst-type", "LOCAL",
-		"--dport", hostPort,
-		"-j", "DNAT",
-		"--to-destination", destination,
-	)
 
-	exists, err = m.ipt.Exists("nat", "OUTPUT", outputRule...)
-	if err != nil {
-		if addedPrerouting {
-			_ = m.ipt.Delete("nat", "PREROUTING", preroutingRule...)
+	dnatRule := func(extra ...string) []string {
+		rule := []string{
+			"-p", protocol,
+			"-m", protocol,
 		}
-		return fmt.Errorf("check OUTPUT rule: %w", err)
-	}
-
-	addedOutput := false
-	if !exists {
-		if err := m.ipt.Append("nat", "OUTPUT", outputRule...); err != nil {
-			if addedPrerouting {
-				_ = m.ipt.Delete("nat", "PREROUTING", preroutingRule...)
-			}
-			return fmt.Errorf("add OUTPUT rule: %w", err)
+		if !isUnspecifiedIP(hostIP) {
+			rule = append(rule, "-d", hostIP)
 		}
-		addedOutput = true
+		rule = append(rule, extra...)
+		// 限制为目的地址为本机（LOCAL），避免错误劫持宿主访问远端的流量。
+		rule = append(rule,
+			"-m", "addrtype",
+			"--dst-type", "LOCAL",
+			"--dport", hostPort,
+		)
+		rule = append(rule, comment...)
+		return append(rule, "-j", "DNAT", "--to-destination", destination)
+	}
+
+	var rules []iptablesRule
+	switch {
+	case !userlandProxy:
+		// PREROUTING 处理从外部进入的流量，OUTPUT 处理从本地发起的流量
+		rules = append(rules,
+			iptablesRule{"nat", "PREROUTING", dnatRule()},
+			iptablesRule{"nat", "OUTPUT", dnatRule()},
+		)
+	case isLoopbackIP(hostIP):
+		// 只发布到 loopback：全部由用户态代理处理
+	case isUnspecifiedIP(hostIP):
+		rules = append(rules,
+			iptablesRule{"nat", "PREROUTING", dnatRule("!", "-i", m.bridgeName)},
+			iptablesRule{"nat", "OUTPUT", dnatRule("!", "-d", loopbackCIDR(m.ipt.Proto() == iptables.ProtocolIPv6))},
+		)
+	default:
+		rules = append(rules,
+			iptablesRule{"nat", "PREROUTING", dnatRule("!", "-i", m.bridgeName)},
+			iptablesRule{"nat", "OUTPUT", dnatRule()},
+		)
 	}
 
 	// FORWARD ACCEPT 规则（允许转发到容器）
@@ -158,106 +166,167 @@ func (m *iptablesManager) SetupPortMapping(containerIP string, mapping PortMappi
 		"-p", protocol,
 		"-d", containerIP,
 		"--dport", containerPort,
-		"-j", "ACCEPT",
 	}
+	forwardRule = append(forwardRule, comment...)
+	return append(rules, iptablesRule{"filter", "FORWARD", append(forwardRule, "-j", "ACCEPT")})
+}
 
-	exists, err = m.ipt.Exists("filter", "FORWARD", forwardRule...)
-	if err != nil {
-		if addedOutput {
-			_ = m.ipt.Delete("nat", "OUTPUT", outputRule...)
+// SetupPortMapping 设置端口映射规则，失败时回滚已添加的规则
+func (m *iptablesManager) SetupPortMapping(containerID, containerIP string, mapping PortMapping) error {
+	var added []iptablesRule
+	rollback := func() {
+		for i := len(added) - 1; i >= 0; i-- {
+			_ = m.ipt.Delete(added[i].table, added[i].chain, added[i].spec...)
+		}
+	}
+
+	for _, rule := range m.portMappingRules(containerID, containerIP, mapping, m.userlandProxy) {
+		exists, err := m.ipt.Exists(rule.table, rule.chain, rule.spec...)
+		if err != nil {
+			rollback()
+			return fmt.Errorf("check %s rule: %w", rule.chain, err)
 		}
-		if addedPrerouting {
-			_ = m.ipt.Delete("nat", "PREROUTING", preroutingRule...)
+		if exists {
+			continue
 		}
-		return fmt.Errorf("check FORWARD rule: %w", err)
+		if err := m.ipt.Append(rule.table, rule.chain, rule.spec...); err != nil {
+			rollback()
+			return fmt.Errorf("add %s rule: %w", rule.chain, err)
+		}
+		added = append(added, rule)
 	}
+	return nil
+}
 
-	addedForward := false
-	if !exists {
-		if err := m.ipt.Append("filter", "FORWARD", forwardRule...); err != nil {
-			if addedOutput {
-				_ = m.ipt.Delete("nat", "OUTPUT", outputRule...)
-			}
-			if addedPrerouting {
-				_ = m.ipt.Delete("nat", "PREROUTING", preroutingRule...)
+// TeardownPortMapping 移除端口映射规则。
+// 两种模式的规则都尝试删除：userland-proxy 设置在容器运行期间改变时同样能清理干净；
+// 同时删除旧版本创建的不带 comment 的规则。
+func (m *iptablesManager) TeardownPortMapping(containerID, containerIP string, mapping PortMapping) error {
+	for _, id := range []string{containerID, ""} {
+		for _, userlandProxy := range []bool{m.userlandProxy, !m.userlandProxy} {
+			for _, rule := range m.portMappingRules(id, containerIP, mapping, userlandProxy) {
+				exists, err := m.ipt.Exists(rule.table, rule.chain, rule.spec...)
+				if err == nil && exists {
+					_ = m.ipt.Delete(rule.table, rule.chain, rule.spec...)
+				}
 			}
-			return fmt.Errorf("add FORWARD rule: %w", err)
 		}
-		addedForward = true
 	}
 
-	_ = addedForward // reserved for future: if we add more rules, keep symmetry
 	return nil
 }
 
-// TeardownPortMapping 移除端口映射规则
-func (m *iptablesManager) TeardownPortMapping(containerIP string, mapping PortMapping) error {
-	protocol := mapping.GetProtocol()
-	hostIP := mapping.GetHostIP()
-	hostPort := strconv.Itoa(int(mapping.HostPort))
-	containerPort := strconv.Itoa(int(mapping.ContainerPort))
-	destination := fmt.Sprintf("%s:%s", containerIP, containerPort)
-
-	// 删除 PREROUTING DNAT 规则
-	preroutingRule := []string{
-		"-p", protocol,
-		"-m", protocol,
-	}
-	if hostIP != "" && hostIP != "0.0.0.0" {
-		preroutingRule = append(preroutingRule, "-d", hostIP)
+// SetupPortMappings 逐条设置容器的端口映射规则，失败时回滚已设置的规则（实现 firewall）
+func (m *iptablesManager) SetupPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	for i, pm := range mappings {
+		if err := m.SetupPortMapping(containerID, containerIP, pm); err != nil {
+			for _, applied := range mappings[:i] {
+				_ = m.TeardownPortMapping(containerID, containerIP, applied)
+			}
+			return fmt.Errorf("%s: %w", pm.String(), err)
+		}
 	}
-	preroutingRule = append(preroutingRule,
-		"-m", "addrtype",
-		"--dst-type", "LOCAL",
-		"--dport", hostPort,
-		"-j", "DNAT",
-		"--to-destination", destination,
-	)
+	return nil
+}
 
-	exists, err := m.ipt.Exists("nat", "PREROUTING", preroutingRule...)
-	if err == nil && exists {
-		_ = m.ipt.Delete("nat", "PREROUTING", preroutingRule...)
+// TeardownPortMappings 逐条移除容器的端口映射规则（实现 firewall）
+func (m *iptablesManager) TeardownPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	var lastErr error
+	for _, pm := range mappings {
+		if err := m.TeardownPortMapping(containerID, containerIP, pm); err != nil {
+			lastErr = err
+		}
 	}
+	return lastErr
+}
 
-	// 删除 OUTPUT DNAT 规则
-	outputRule := []string{
-		"-p", protocol,
-		"-m", protocol,
-	}
-	if hostIP != "" && hostIP != "0.0.0.0" {
-		outputRule = append(outputRule, "-d", hostIP)
+// PrunePortMappings 删除 comment 为容器 ID、且 keep 不保留的容器的端口映射规则（实现 firewall）。
+// 链由所有网络共享，其他网络上的孤儿规则同样会被删除；不带 comment 的规则不做判断。
+func (m *iptablesManager) PrunePortMappings(keep func(containerID string) bool) (int, error) {
+	removed := 0
+	for _, c := range []struct{ table, chain string }{
+		{"nat", "PREROUTING"},
+		{"nat", "OUTPUT"},
+		{"filter", "FORWARD"},
+	} {
+		rules, err := m.ipt.List(c.table, c.chain)
+		if err != nil {
+			return removed, fmt.Errorf("list %s rules: %w", c.chain, err)
+		}
+		for _, rule := range rules {
+			// iptables -S 输出形如 "-A PREROUTING -p tcp ..."，去掉 "-A <chain>" 即为规则参数
+			spec := strings.Fields(rule)
+			if len(spec) < 2 || spec[0] != "-A" {
+				continue
+			}
+			spec = spec[2:]
+			containerID := ruleComment(spec)
+			if !idutil.IsFullID(containerID) || keep(containerID) {
+				continue
+			}
+			if err := m.ipt.Delete(c.table, c.chain, spec...); err != nil {
+				return removed, fmt.Errorf("delete %s rule: %w", c.chain, err)
+			}
+			removed++
+		}
 	}
-	outputRule = append(outputRule,
-		"-m", "addrtype",
-		"--dst-type", "LOCAL",
-		"--dport", hostPort,
-		"-j", "DNAT",
-		"--to-destination", destination,
-	)
+	return removed, nil
+}
 
-	exists, err = m.ipt.Exists("nat", "OUTPUT", outputRule...)
-	if err == nil && exists {
-		_ = m.ipt.Delete("nat", "OUTPUT", outputRule...)
+// ruleComment 返回规则参数中 --comment 的值（容器 ID 不含空格，iptables -S 输出不带引号）
+func ruleComment(spec []string) string {
+	for i := 0; i+1 < len(spec); i++ {
+		if spec[i] == "--comment" {
+			return spec[i+1]
+		}
 	}
+	return ""
+}
 
-	// 删除 FORWARD ACCEPT 规则
-	forwardRule := []string{
-		"-p", protocol,
-		"-d", containerIP,
-		"--dport", containerPort,
-		"-j", "ACCEPT",
-	}
+// isUnspecifiedIP 返回宿主机地址是否表示所有地址（0.0.0.0 或 ::），此时 DNAT 规则不限定目的地址
+func isUnspecifiedIP(hostIP string) bool {
+	ip := net.ParseIP(hostIP)
+	return ip != nil && ip.IsUnspecified()
+}
 
-	exists, err = m.ipt.Exists("filter", "FORWARD", forwardRule...)
-	if err == nil && exists {
-		_ = m.ipt.Delete("filter", "FORWARD", forwardRule...)
+// iccRules 返回 icc=false 时 FORWARD 链开头的规则（Phase 21 新增）：
+// 经发布端口 DNAT 的流量（hairpin）仍然允许，其余 bridge 内容器之间的流量丢弃
+func (m *iptablesManager) iccRules() [][]string {
+	return [][]string{
+		{"-i", m.bridgeName, "-o", m.bridgeName, "-m", "conntrack", "--ctstate", "DNAT", "-j", "ACCEPT"},
+		{"-i", m.bridgeName, "-o", m.bridgeName, "-j", "DROP"},
 	}
+}
 
+// TeardownForwardAccept 移除 bridge 流量转发规则（Phase 21 新增，用于 network rm）
+func (m *iptablesManager) TeardownForwardAccept() error {
+	if err := m.teardownICC(); err != nil {
+		return err
+	}
+	for _, rule := range [][]string{
+		{"-i", m.bridgeName, "-j", "ACCEPT"},
+		{"-o", m.bridgeName, "-j", "ACCEPT"},
+	} {
+		exists, err := m.ipt.Exists("filter", "FORWARD", rule...)
+		if err != nil {
+			return fmt.Errorf("check FORWARD rule: %w", err)
+		}
+		if exists {
+			if err := m.ipt.Delete("filter", "FORWARD", rule...); err != nil {
+				return fmt.Errorf("delete FORWARD rule: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
 // SetupForwardAccept 设置允许 bridge 流量转发的规则
-func (m *iptablesManager) SetupForwardAccept() error {
+func (m *iptablesManager) SetupForwardAccept(icc bool) error {
+	// Phase 21: icc=false 时在 FORWARD 开头插入容器间 DROP 规则（位于下面的 ACCEPT 规则之前）
+	if err := m.setupICC(icc); err != nil {
+		return err
+	}
+
 	// 允许从 bridge 出去的流量
 	outRule := []string{
 		"-i", m.bridgeName,
@@ -294,3 +363,53 @@ func (m *iptablesManager) SetupForwardAccept() error {
 
 	return nil
 }
+
+// setupICC 按 icc 设置添加或移除容器间 DROP 规则。
+// 规则不完整时先全部移除再按顺序插入，保证 DNAT ACCEPT 位于 DROP 之前。
+func (m *iptablesManager) setupICC(icc bool) error {
+	if icc {
+		return m.teardownICC()
+	}
+	if err := enableBridgeNetfilter(m.ipt.Proto() == iptables.ProtocolIPv6); err != nil {
+		return err
+	}
+
+	rules := m.iccRules()
+	complete := true
+	for _, rule := range rules {
+		exists, err := m.ipt.Exists("filter", "FORWARD", rule...)
+		if err != nil {
+			return fmt.Errorf("check FORWARD icc rule: %w", err)
+		}
+		complete = complete && exists
+	}
+	if complete {
+		return nil
+	}
+
+	if err := m.teardownICC(); err != nil {
+		return err
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		if err := m.ipt.Insert("filter", "FORWARD", 1, rules[i]...); err != nil {
+			return fmt.Errorf("add FORWARD icc rule: %w", err)
+		}
+	}
+	return nil
+}
+
+// teardownICC 移除 icc=false 时添加的 FORWARD 规则
+func (m *iptablesManager) teardownICC() error {
+	for _, rule := range m.iccRules() {
+		exists, err := m.ipt.Exists("filter", "FORWARD", rule...)
+		if err != nil {
+			return fmt.Errorf("check FORWARD icc rule: %w", err)
+		}
+		if exists {
+			if err := m.ipt.Delete("filter", "FORWARD", rule...); err != nil {
+				return fmt.Errorf("delete FORWARD icc rule: %w", err)
+			}
+		}
+	}
+	return nil
+}
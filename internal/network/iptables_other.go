@@ -8,7 +8,7 @@ import "fmt"
 // iptablesManager 管理 iptables 规则（非 Linux 平台 stub）
 type iptablesManager struct{}
 
-func newIptablesManager(bridgeName string) (*iptablesManager, error) {
+func newIptablesManager(bridgeName, subnet string, userlandProxy bool) (*iptablesManager, error) {
 	return nil, fmt.Errorf("iptables is only supported on Linux")
 }
 
@@ -20,14 +20,22 @@ func (m *iptablesManager) TeardownMasquerade() error {
 	return fmt.Errorf("iptables is only supported on Linux")
 }
 
-func (m *iptablesManager) SetupPortMapping(containerIP string, mapping PortMapping) error {
+func (m *iptablesManager) SetupPortMapping(containerID, containerIP string, mapping PortMapping) error {
 	return fmt.Errorf("iptables is only supported on Linux")
 }
 
-func (m *iptablesManager) TeardownPortMapping(containerIP string, mapping PortMapping) error {
+func (m *iptablesManager) TeardownPortMapping(containerID, containerIP string, mapping PortMapping) error {
 	return fmt.Errorf("iptables is only supported on Linux")
 }
 
-func (m *iptablesManager) SetupForwardAccept() error {
+func (m *iptablesManager) SetupForwardAccept(icc bool) error {
 	return fmt.Errorf("iptables is only supported on Linux")
 }
+
+func (m *iptablesManager) TeardownForwardAccept() error {
+	return fmt.Errorf("iptables is only supported on Linux")
+}
+
+func (m *iptablesManager) PrunePortMappings(keep func(containerID string) bool) (int, error) {
+	return 0, fmt.Errorf("iptables is only supported on Linux")
+}
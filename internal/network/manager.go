@@ -4,7 +4,9 @@
 package network
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/vishvananda/netlink"
 	"github.com/vishvananda/netns"
@@ -13,56 +15,215 @@ import (
 // networkManager 实现 Manager 接口
 type networkManager struct {
 	dataDir  string
+	store    *Store
+	networks map[string]*bridgeNetwork // Phase 21: 按网络名缓存
+
+	// Phase 21: daemon.json 中的全局设置
+	config *DaemonConfig
+
+	// Phase 21: 宿主机端口分配（所有网络共享）
+	ports *portAllocator
+}
+
+// bridgeNetwork 聚合一个 bridge 网络的 IPAM、bridge 驱动和防火墙规则（Phase 21 新增）
+type bridgeNetwork struct {
+	info     *NetworkInfo
 	ipam     IPAM
 	bridge   *bridgeDriver
-	iptables *iptablesManager
+	firewall firewall
+
+	// Phase 21: 双栈网络的 IPv6 IPAM 和 IPv6 防火墙规则（未启用 IPv6 时为 nil）
+	ipam6     IPAM
+	firewall6 firewall
 }
 
-// NewManager 创建新的网络管理器
+// NewManager 创建新的网络管理器。
+// Phase 21: 创建时清理宿主机重启或 shim 崩溃遗留的孤儿网络资源（见 reconcile），失败时只输出警告。
 func NewManager(dataDir string) (Manager, error) {
-	ipam, err := NewIPAM(dataDir)
+	m, err := newManager(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.reconcileLocked(true); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to reconcile networks: %v\n", err)
+	}
+	return m, nil
+}
+
+// newManager 创建网络管理器并初始化默认 bridge 网络
+func newManager(dataDir string) (*networkManager, error) {
+	store, err := NewStore(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("create network store: %w", err)
+	}
+
+	config, err := LoadDaemonConfig(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := newPortAllocator(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &networkManager{
+		dataDir:  dataDir,
+		store:    store,
+		networks: make(map[string]*bridgeNetwork),
+		config:   config,
+		ports:    ports,
+	}
+
+	// 默认 bridge 网络立即初始化：防火墙后端等不可用时尽早失败
+	if _, err := m.network(DefaultNetworkName); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// network 返回指定名称的 bridge 网络（空名称表示默认 bridge 网络）
+func (m *networkManager) network(name string) (*bridgeNetwork, error) {
+	if name == "" {
+		name = DefaultNetworkName
+	}
+	if n, ok := m.networks[name]; ok {
+		return n, nil
+	}
+
+	info, err := m.store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	// Phase 21: 默认 bridge 网络的 icc 由 daemon.json 设置
+	if info.Name == DefaultNetworkName {
+		info.DisableICC = m.config.DisableICC()
+	}
+	n, err := newBridgeNetwork(m.dataDir, info, m.config.UserlandProxy)
+	if err != nil {
+		return nil, err
+	}
+	m.networks[name] = n
+	return n, nil
+}
+
+// newBridgeNetwork 为网络创建 IPAM、bridge 驱动和防火墙后端。
+// Phase 21: 未启用 userlandProxy 时端口发布只依赖 DNAT，bridge 需要开启 hairpin 与 route_localnet。
+func newBridgeNetwork(dataDir string, info *NetworkInfo, userlandProxy bool) (*bridgeNetwork, error) {
+	if info.Driver != DriverBridge {
+		return nil, fmt.Errorf("network %s (driver %s) does not support container endpoints", info.Name, info.Driver)
+	}
+
+	ipam, err := newNetworkIPAM(dataDir, info)
 	if err != nil {
 		return nil, fmt.Errorf("create IPAM: %w", err)
 	}
 
-	bridge, err := newBridgeDriver(DefaultBridgeName)
+	bridge, err := newBridgeDriver(info)
 	if err != nil {
 		return nil, fmt.Errorf("create bridge driver: %w", err)
 	}
+	bridge.hairpin = !userlandProxy
 
-	iptables, err := newIptablesManager(DefaultBridgeName)
+	fw, err := newFirewall(info.BridgeName, info.Subnet, userlandProxy)
 	if err != nil {
-		return nil, fmt.Errorf("create iptables manager: %w", err)
+		return nil, fmt.Errorf("create firewall: %w", err)
 	}
 
-	return &networkManager{
-		dataDir:  dataDir,
+	n := &bridgeNetwork{
+		info:     info,
 		ipam:     ipam,
 		bridge:   bridge,
-		iptables: iptables,
-	}, nil
+		firewall: fw,
+	}
+
+	if info.EnableIPv6 {
+		if n.ipam6, err = newNetworkIPAM6(dataDir, info); err != nil {
+			return nil, fmt.Errorf("create IPv6 IPAM: %w", err)
+		}
+		if n.firewall6, err = newFirewall(info.BridgeName, info.SubnetV6, userlandProxy); err != nil {
+			return nil, fmt.Errorf("create IPv6 firewall: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// firewalls 返回网络使用的防火墙后端（双栈网络包括 IPv6）
+func (n *bridgeNetwork) firewalls() []firewall {
+	if n.firewall6 == nil {
+		return []firewall{n.firewall}
+	}
+	return []firewall{n.firewall, n.firewall6}
 }
 
 // EnsureBridge 确保 bridge 接口存在
 func (m *networkManager) EnsureBridge(config *NetworkConfig) error {
+	n, err := m.network(config.GetNetworkName())
+	if err != nil {
+		return err
+	}
+	return n.ensure()
+}
+
+// ensure 创建 bridge 并设置 NAT 与转发规则（幂等）
+func (n *bridgeNetwork) ensure() error {
 	// 创建/确保 bridge 存在
-	if err := m.bridge.EnsureBridge(); err != nil {
+	if err := n.bridge.EnsureBridge(); err != nil {
 		return fmt.Errorf("ensure bridge: %w", err)
 	}
 
-	// 设置 MASQUERADE NAT 规则
-	if err := m.iptables.SetupMasquerade(); err != nil {
-		return fmt.Errorf("setup masquerade: %w", err)
-	}
+	for _, fw := range n.firewalls() {
+		// 设置 MASQUERADE NAT 规则
+		if err := fw.SetupMasquerade(); err != nil {
+			return fmt.Errorf("setup masquerade: %w", err)
+		}
 
-	// 设置 FORWARD ACCEPT 规则
-	if err := m.iptables.SetupForwardAccept(); err != nil {
-		return fmt.Errorf("setup forward accept: %w", err)
+		// 设置 FORWARD ACCEPT 规则（Phase 21: 以及 icc=false 时的容器间 DROP 规则）
+		if err := fw.SetupForwardAccept(!n.info.DisableICC); err != nil {
+			return fmt.Errorf("setup forward accept: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// RemoveNetwork 删除用户自定义网络的宿主机资源：bridge 接口、NAT 与转发规则（Phase 21 新增）。
+// 网络元数据和 IPAM 文件由 Store.Delete 删除。
+func RemoveNetwork(info *NetworkInfo) error {
+	if info.Builtin {
+		return fmt.Errorf("%s is a pre-defined network and cannot be removed", info.Name)
+	}
+
+	// bridge 在首个容器接入时才创建（NAT/转发规则随之添加）：从未使用过的网络无需清理
+	if _, err := netlink.LinkByName(info.BridgeName); err != nil {
+		return nil
+	}
+
+	bridge, err := newBridgeDriver(info)
+	if err != nil {
+		return fmt.Errorf("create bridge driver: %w", err)
+	}
+
+	subnets := []string{info.Subnet}
+	if info.EnableIPv6 {
+		subnets = append(subnets, info.SubnetV6)
+	}
+	for _, subnet := range subnets {
+		// 网络级规则与 userland-proxy 设置无关
+		fw, err := newFirewall(info.BridgeName, subnet, false)
+		if err != nil {
+			return fmt.Errorf("create firewall: %w", err)
+		}
+		if err := fw.TeardownMasquerade(); err != nil {
+			return err
+		}
+		if err := fw.TeardownForwardAccept(); err != nil {
+			return err
+		}
+	}
+	return bridge.RemoveBridge()
+}
+
 // Setup 为容器配置网络
 func (m *networkManager) Setup(containerID string, config *NetworkConfig, pid int) (*NetworkState, error) {
 	mode := config.GetMode()
@@ -81,38 +242,151 @@ func (m *networkManager) Setup(containerID string, config *NetworkConfig, pid in
 
 // setupBridge 配置 bridge 网络
 func (m *networkManager) setupBridge(containerID string, config *NetworkConfig, pid int) (*NetworkState, error) {
-	// 分配 IP 地址
-	containerIP, err := m.ipam.Allocate(containerID)
+	// Phase 21: 选择容器接入的 bridge 网络
+	n, err := m.network(config.Network)
 	if err != nil {
-		return nil, fmt.Errorf("allocate IP: %w", err)
+		return nil, err
+	}
+
+	// 分配 IP 地址（Phase 21: --ip 指定时预留静态地址）
+	var containerIP string
+	if config.IPAddress != "" {
+		containerIP, err = n.ipam.AllocateAddress(containerID, config.IPAddress)
+		if err != nil {
+			return nil, fmt.Errorf("assign IP: %w", err)
+		}
+	} else {
+		containerIP, err = n.ipam.Allocate(containerID)
+		if err != nil {
+			return nil, fmt.Errorf("allocate IP: %w", err)
+		}
+	}
+
+	// Phase 21: 双栈网络同时分配 IPv6 地址（--ip6 指定时使用静态地址）
+	containerIP6, err := n.allocateIPv6(containerID, config.IPv6Address)
+	if err != nil {
+		_ = n.ipam.Release(containerID)
+		return nil, err
 	}
 
 	// 创建 veth pair 并配置
-	state, err := m.bridge.SetupVeth(containerID, pid, containerIP)
+	state, err := n.bridge.SetupVeth(containerID, pid, vethConfig{
+		ip:          containerIP,
+		ip6:         containerIP6,
+		mac:         config.MacAddress,
+		ingressRate: config.IngressRate,
+		egressRate:  config.EgressRate,
+	})
 	if err != nil {
 		// 回滚 IP 分配
-		_ = m.ipam.Release(containerID)
+		_ = n.releaseIPs(containerID)
 		return nil, fmt.Errorf("setup veth: %w", err)
 	}
+	state.Network = config.Network
 
-	// 设置端口映射
+	// 设置端口映射（Phase 21: 未指定宿主机端口的映射先分配空闲端口）
 	if len(config.PortMappings) > 0 {
-		applied := make([]PortMapping, 0, len(config.PortMappings))
-		for _, pm := range config.PortMappings {
-			if err := m.iptables.SetupPortMapping(containerIP, pm); err != nil {
-				// 回滚已创建的资源
-				state.PortMappings = applied
-				m.teardownBridge(containerID, state)
-				return nil, fmt.Errorf("setup port mapping %s: %w", pm.String(), err)
-			}
-			applied = append(applied, pm)
+		mappings, err := m.ports.Allocate(containerID, config.PortMappings)
+		if err != nil {
+			m.teardownBridge(containerID, state)
+			return nil, fmt.Errorf("allocate host ports: %w", err)
+		}
+		if err := n.setupPortMappings(containerID, state, mappings); err != nil {
+			// 回滚已创建的资源
+			m.teardownBridge(containerID, state)
+			return nil, fmt.Errorf("setup port mappings: %w", err)
 		}
-		state.PortMappings = applied
+		state.PortMappings = mappings
 	}
 
 	return state, nil
 }
 
+// allocateIPv6 在双栈网络上为容器分配 IPv6 地址；网络未启用 IPv6 时返回空地址（Phase 21 新增）
+func (n *bridgeNetwork) allocateIPv6(containerID, requested string) (string, error) {
+	if n.ipam6 == nil {
+		if requested != "" {
+			return "", fmt.Errorf("network %s is not IPv6-enabled: --ip6 requires a network created with --ipv6", n.info.Name)
+		}
+		return "", nil
+	}
+
+	if requested != "" {
+		ip, err := n.ipam6.AllocateAddress(containerID, requested)
+		if err != nil {
+			return "", fmt.Errorf("assign IPv6 address: %w", err)
+		}
+		return ip, nil
+	}
+	ip, err := n.ipam6.Allocate(containerID)
+	if err != nil {
+		return "", fmt.Errorf("allocate IPv6 address: %w", err)
+	}
+	return ip, nil
+}
+
+// releaseIPs 释放容器在网络上的 IPv4 和 IPv6 地址
+func (n *bridgeNetwork) releaseIPs(containerID string) error {
+	err := n.ipam.Release(containerID)
+	if n.ipam6 != nil {
+		if err6 := n.ipam6.Release(containerID); err6 != nil {
+			err = err6
+		}
+	}
+	return err
+}
+
+// portMappingTarget 是某个地址族上的防火墙后端、容器地址和需要发布的端口映射
+type portMappingTarget struct {
+	firewall    firewall
+	containerIP string
+	mappings    []PortMapping
+}
+
+// portMappingTargets 按地址族分组端口映射（Phase 21 新增）。
+// 未指定宿主机地址时发布到容器拥有的所有地址族；指定时（包括 0.0.0.0 和 ::）只发布到对应地址族。
+func (n *bridgeNetwork) portMappingTargets(state *NetworkState, mappings []PortMapping) ([]portMappingTarget, error) {
+	v4 := portMappingTarget{firewall: n.firewall, containerIP: state.IPAddress}
+	v6 := portMappingTarget{firewall: n.firewall6, containerIP: state.IPv6Address}
+	for _, pm := range mappings {
+		if pm.HostIP == "" || !pm.IsIPv6() {
+			v4.mappings = append(v4.mappings, pm)
+		}
+		if pm.HostIP == "" || pm.IsIPv6() {
+			if state.IPv6Address != "" {
+				v6.mappings = append(v6.mappings, pm)
+			} else if pm.IsIPv6() {
+				return nil, fmt.Errorf("%s: container has no IPv6 address on network %s", pm.String(), n.info.Name)
+			}
+		}
+	}
+
+	var targets []portMappingTarget
+	for _, t := range []portMappingTarget{v4, v6} {
+		if len(t.mappings) > 0 {
+			targets = append(targets, t)
+		}
+	}
+	return targets, nil
+}
+
+// setupPortMappings 在容器的每个地址族上设置端口映射规则，失败时回滚已设置的规则
+func (n *bridgeNetwork) setupPortMappings(containerID string, state *NetworkState, mappings []PortMapping) error {
+	targets, err := n.portMappingTargets(state, mappings)
+	if err != nil {
+		return err
+	}
+	for i, t := range targets {
+		if err := t.firewall.SetupPortMappings(containerID, t.containerIP, t.mappings); err != nil {
+			for _, applied := range targets[:i] {
+				_ = applied.firewall.TeardownPortMappings(containerID, applied.containerIP, applied.mappings)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // setupHost 配置 host 网络模式
 func (m *networkManager) setupHost(containerID string, config *NetworkConfig, pid int) (*NetworkState, error) {
 	// host 模式不需要额外配置，容器共享宿主机网络
@@ -166,30 +440,128 @@ func (m *networkManager) Teardown(containerID string, state *NetworkState) error
 	}
 }
 
-// teardownBridge 清理 bridge 网络资源
+// teardownBridge 清理 bridge 网络资源。
+// 端口和 IP 租约只依赖本地状态文件，最先释放；其余清理尽力而为，错误汇总后一并返回。
 func (m *networkManager) teardownBridge(containerID string, state *NetworkState) error {
-	var lastErr error
+	var errs []error
+
+	if err := m.ports.Release(containerID); err != nil {
+		errs = append(errs, err)
+	}
+	if err := m.releaseLeases(state.Network, containerID); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Phase 21: 清理 network connect 附加的网卡
+	for i := range state.Endpoints {
+		if err := m.Disconnect(containerID, &state.Endpoints[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	n, err := m.network(state.Network)
+	if err != nil {
+		errs = append(errs, err)
+		return errors.Join(errs...)
+	}
 
 	// 清理端口映射
 	if len(state.PortMappings) > 0 && state.IPAddress != "" {
-		for _, pm := range state.PortMappings {
-			if err := m.iptables.TeardownPortMapping(state.IPAddress, pm); err != nil {
-				lastErr = err
+		targets, _ := n.portMappingTargets(state, state.PortMappings)
+		for _, t := range targets {
+			if err := t.firewall.TeardownPortMappings(containerID, t.containerIP, t.mappings); err != nil {
+				errs = append(errs, err)
 			}
 		}
 	}
 
 	// 清理 veth pair
 	if state.VethHost != "" {
-		if err := m.bridge.TeardownVeth(state.VethHost); err != nil {
-			lastErr = err
+		if err := n.bridge.TeardownVeth(state.VethHost); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	// 释放 IP 地址
-	if err := m.ipam.Release(containerID); err != nil {
-		lastErr = err
+	return errors.Join(errs...)
+}
+
+// releaseLeases 释放容器在指定网络上的 IP 租约。
+// 只需要网络配置和 IPAM 文件，bridge 驱动或防火墙后端初始化失败时也能归还地址。
+func (m *networkManager) releaseLeases(networkName, containerID string) error {
+	if networkName == "" {
+		networkName = DefaultNetworkName
+	}
+	if n, ok := m.networks[networkName]; ok {
+		return n.releaseIPs(containerID)
+	}
+
+	info, err := m.store.Get(networkName)
+	if err != nil {
+		return err
+	}
+	ipam, err := newNetworkIPAM(m.dataDir, info)
+	if err != nil {
+		return err
+	}
+	ipam6, err := newNetworkIPAM6(m.dataDir, info)
+	if err != nil {
+		return err
+	}
+	n := &bridgeNetwork{info: info, ipam: ipam, ipam6: ipam6}
+	return n.releaseIPs(containerID)
+}
+
+// Connect 将运行中的容器接入另一个 bridge 网络（Phase 21 新增）
+func (m *networkManager) Connect(containerID, networkName string, pid int, ifName string) (*Endpoint, error) {
+	n, err := m.network(networkName)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := n.ipam.Get(containerID); ok {
+		return nil, fmt.Errorf("container %s is already connected to network %s", containerID[:12], n.info.Name)
+	}
+
+	if err := n.ensure(); err != nil {
+		return nil, err
+	}
+
+	containerIP, err := n.ipam.Allocate(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("allocate IP: %w", err)
+	}
+	containerIP6, err := n.allocateIPv6(containerID, "")
+	if err != nil {
+		_ = n.ipam.Release(containerID)
+		return nil, err
+	}
+
+	endpoint, err := n.bridge.SetupEndpoint(containerID, n.info.ID, pid, vethConfig{ip: containerIP, ip6: containerIP6}, ifName)
+	if err != nil {
+		// 回滚 IP 分配
+		_ = n.releaseIPs(containerID)
+		return nil, fmt.Errorf("setup veth: %w", err)
+	}
+	endpoint.Network = n.info.Name
+	return endpoint, nil
+}
+
+// Disconnect 删除附加网卡并释放其 IP（Phase 21 新增）
+func (m *networkManager) Disconnect(containerID string, endpoint *Endpoint) error {
+	var errs []error
+	if err := m.releaseLeases(endpoint.Network, containerID); err != nil {
+		errs = append(errs, err)
 	}
 
-	return lastErr
+	n, err := m.network(endpoint.Network)
+	if err != nil {
+		errs = append(errs, err)
+		return errors.Join(errs...)
+	}
+	// 删除宿主机端 veth（容器内的对端随之删除）
+	if endpoint.VethHost != "" {
+		if err := n.bridge.TeardownVeth(endpoint.VethHost); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
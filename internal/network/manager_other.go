@@ -9,3 +9,13 @@ import "fmt"
 func NewManager(dataDir string) (Manager, error) {
 	return nil, fmt.Errorf("network management is only supported on Linux")
 }
+
+// RemoveNetwork 删除用户自定义网络的宿主机资源（非 Linux 平台 stub）
+func RemoveNetwork(info *NetworkInfo) error {
+	return fmt.Errorf("network management is only supported on Linux")
+}
+
+// Reconcile 清理孤儿网络资源（非 Linux 平台 stub）
+func Reconcile(dataDir string) ([]string, error) {
+	return nil, fmt.Errorf("network management is only supported on Linux")
+}
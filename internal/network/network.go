@@ -5,6 +5,7 @@ package network
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 )
@@ -22,6 +23,9 @@ const (
 
 	// DefaultSubnetMask 是默认的子网掩码位数
 	DefaultSubnetMask = 16
+
+	// DefaultNetworkName 是默认 bridge 网络的名称（Phase 21 新增）
+	DefaultNetworkName = "bridge"
 )
 
 // NetworkMode 定义容器的网络模式
@@ -39,8 +43,25 @@ const (
 	// NetworkModeNone 是 none 网络模式
 	// 容器有独立的网络命名空间，但不配置任何网络接口（只有 loopback）
 	NetworkModeNone NetworkMode = "none"
+
+	// NetworkModeContainerPrefix 是 container 网络模式的前缀（Phase 15 新增）
+	// 完整形式为 "container:<id>"：加入另一个容器的网络命名空间，不创建任何网络资源
+	NetworkModeContainerPrefix = "container:"
 )
 
+// IsContainer 返回是否为 container:<id> 模式
+func (m NetworkMode) IsContainer() bool {
+	return strings.HasPrefix(string(m), NetworkModeContainerPrefix)
+}
+
+// ConnectedContainer 返回 container:<id> 模式下的目标容器 ID
+func (m NetworkMode) ConnectedContainer() string {
+	if !m.IsContainer() {
+		return ""
+	}
+	return strings.TrimPrefix(string(m), NetworkModeContainerPrefix)
+}
+
 // PortMapping 定义端口映射配置
 type PortMapping struct {
 	// HostIP 是宿主机绑定的 IP 地址（可选，默认 0.0.0.0）
@@ -72,9 +93,15 @@ func (p *PortMapping) GetHostIP() string {
 	return p.HostIP
 }
 
-// String 返回端口映射的字符串表示
+// IsIPv6 返回 HostIP 是否为 IPv6 地址（Phase 21 新增）
+func (p *PortMapping) IsIPv6() bool {
+	ip := net.ParseIP(p.HostIP)
+	return ip != nil && ip.To4() == nil
+}
+
+// String 返回端口映射的字符串表示（IPv6 宿主机地址用方括号包裹）
 func (p *PortMapping) String() string {
-	return fmt.Sprintf("%s:%d->%d/%s", p.GetHostIP(), p.HostPort, p.ContainerPort, p.GetProtocol())
+	return fmt.Sprintf("%s->%d/%s", net.JoinHostPort(p.GetHostIP(), strconv.Itoa(int(p.HostPort))), p.ContainerPort, p.GetProtocol())
 }
 
 // NetworkConfig 定义容器的网络配置
@@ -85,6 +112,25 @@ type NetworkConfig struct {
 	// BridgeName 是 bridge 接口名（仅 bridge 模式有效）
 	BridgeName string `json:"bridgeName,omitempty"`
 
+	// Network 是用户自定义网络名（Phase 21 新增，仅 bridge 模式有效，空表示默认 bridge 网络）
+	Network string `json:"network,omitempty"`
+
+	// Aliases 是容器在用户自定义网络上的 DNS 别名（Phase 21 新增，--network-alias）
+	Aliases []string `json:"aliases,omitempty"`
+
+	// IPAddress 是 --ip 指定的静态 IPv4 地址（Phase 21 新增，仅用户自定义网络）
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// IPv6Address 是 --ip6 指定的静态 IPv6 地址（Phase 21 新增，仅启用 IPv6 的用户自定义网络）
+	IPv6Address string `json:"ipv6Address,omitempty"`
+
+	// MacAddress 是 --mac-address 指定的容器网卡 MAC 地址（Phase 21 新增）
+	MacAddress string `json:"macAddress,omitempty"`
+
+	// IngressRate/EgressRate 是容器接收/发送方向的限速（Phase 21 新增，--network-opt，bit/s，0 表示不限速）
+	IngressRate uint64 `json:"ingressRate,omitempty"`
+	EgressRate  uint64 `json:"egressRate,omitempty"`
+
 	// PortMappings 是端口映射列表（仅 bridge 模式有效）
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
 }
@@ -110,9 +156,18 @@ func (c *NetworkConfig) GetBridgeName() string {
 	return c.BridgeName
 }
 
+// GetNetworkName 返回容器接入的 bridge 网络名，默认为 bridge
+func (c *NetworkConfig) GetNetworkName() string {
+	if c == nil || c.Network == "" {
+		return DefaultNetworkName
+	}
+	return c.Network
+}
+
 // NeedsNetworkNamespace 返回是否需要创建网络命名空间
 // bridge 和 none 模式需要独立的网络命名空间
 // host 模式共享宿主机网络命名空间
+// container 模式加入目标容器的网络命名空间（Phase 15）
 func (c *NetworkConfig) NeedsNetworkNamespace() bool {
 	mode := c.GetMode()
 	return mode == NetworkModeBridge || mode == NetworkModeNone
@@ -129,9 +184,18 @@ type NetworkState struct {
 	// Gateway 是网关地址
 	Gateway string `json:"gateway,omitempty"`
 
+	// IPv6Address 是容器的 IPv6 地址（Phase 21 新增，仅双栈网络）
+	IPv6Address string `json:"ipv6Address,omitempty"`
+
+	// IPv6Gateway 是 IPv6 网关地址（Phase 21 新增）
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+
 	// MacAddress 是容器网卡的 MAC 地址
 	MacAddress string `json:"macAddress,omitempty"`
 
+	// MTU 是容器网卡的 MTU（Phase 21 新增）
+	MTU int `json:"mtu,omitempty"`
+
 	// VethHost 是宿主机侧的 veth 接口名
 	VethHost string `json:"vethHost,omitempty"`
 
@@ -140,6 +204,50 @@ type NetworkState struct {
 
 	// PortMappings 是实际的端口映射（包含分配后的值）
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
+
+	// Network 是主网卡所在的网络名（Phase 21 新增，空表示默认 bridge 网络）
+	Network string `json:"network,omitempty"`
+
+	// Endpoints 是通过 network connect 附加的网卡（Phase 21 新增）
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+}
+
+// Endpoint 描述容器在某个网络上的一块附加网卡（Phase 21 新增）
+type Endpoint struct {
+	// Network 是网络名
+	Network string `json:"network"`
+
+	// IPAddress 是网卡的 IP 地址
+	IPAddress string `json:"ipAddress"`
+
+	// Gateway 是该网络的网关地址
+	Gateway string `json:"gateway,omitempty"`
+
+	// IPv6Address 是网卡的 IPv6 地址（仅双栈网络）
+	IPv6Address string `json:"ipv6Address,omitempty"`
+
+	// IPv6Gateway 是该网络的 IPv6 网关地址
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+
+	// MacAddress 是容器内网卡的 MAC 地址
+	MacAddress string `json:"macAddress,omitempty"`
+
+	// MTU 是容器内网卡的 MTU
+	MTU int `json:"mtu,omitempty"`
+
+	// VethHost 是宿主机侧的 veth 接口名
+	VethHost string `json:"vethHost"`
+
+	// VethContainer 是容器内的接口名（eth1、eth2...）
+	VethContainer string `json:"vethContainer"`
+}
+
+// GetNetworkName 返回主网卡所在的网络名，默认为 bridge
+func (s *NetworkState) GetNetworkName() string {
+	if s == nil || s.Network == "" {
+		return DefaultNetworkName
+	}
+	return s.Network
 }
 
 // Manager 定义网络管理器接口
@@ -159,71 +267,137 @@ type Manager interface {
 	// EnsureBridge 确保 bridge 接口存在
 	// 如果不存在则创建，并配置 IP 和 NAT 规则
 	EnsureBridge(config *NetworkConfig) error
+
+	// Connect 将运行中的容器接入另一个网络（Phase 21 新增）
+	// ifName: 容器内的接口名（如 eth1）
+	// 返回新网卡的端点信息
+	Connect(containerID, networkName string, pid int, ifName string) (*Endpoint, error)
+
+	// Disconnect 移除 Connect 创建的网卡并释放 IP（Phase 21 新增）
+	Disconnect(containerID string, endpoint *Endpoint) error
 }
 
-// ParsePortMapping 解析端口映射字符串
+// ParsePortMappings 解析端口映射字符串，端口范围展开为多个映射（Phase 21: 取代 ParsePortMapping）
 // 支持格式：
-//   - containerPort (如 "80")
-//   - hostPort:containerPort (如 "8080:80")
-//   - hostPort:containerPort/protocol (如 "8080:80/tcp")
-//   - hostIP:hostPort:containerPort (如 "0.0.0.0:8080:80")
-//   - hostIP:hostPort:containerPort/protocol (如 "0.0.0.0:8080:80/udp")
-func ParsePortMapping(s string) (PortMapping, error) {
-	var pm PortMapping
-	pm.Protocol = "tcp" // 默认协议
+//   - containerPort[-end] (如 "80"、"8000-8010"，宿主机端口随机分配)
+//   - hostPort[-end]:containerPort[-end] (如 "8080:80"、"8000-8010:8000-8010")
+//   - hostIP:hostPort:containerPort (如 "127.0.0.1:8080:80")
+//   - hostIP::containerPort (如 "127.0.0.1::80"，宿主机端口随机分配)
+//   - [ipv6]:hostPort:containerPort (如 "[::1]:8080:80")
+//   - 以上格式均可追加 /protocol (tcp 或 udp，默认 tcp)
+//
+// 宿主机端口为 0 表示由 Setup 从临时端口范围中分配空闲端口。
+func ParsePortMappings(s string) ([]PortMapping, error) {
+	protocol := "tcp" // 默认协议
 
 	// 分离协议部分
-	parts := strings.Split(s, "/")
-	portPart := parts[0]
-	if len(parts) > 1 {
-		protocol := strings.ToLower(parts[1])
+	portPart := s
+	if idx := strings.LastIndex(s, "/"); idx != -1 {
+		protocol = strings.ToLower(s[idx+1:])
 		if protocol != "tcp" && protocol != "udp" {
-			return pm, fmt.Errorf("unsupported protocol: %s (must be tcp or udp)", parts[1])
+			return nil, fmt.Errorf("unsupported protocol: %s (supported: tcp, udp)", s[idx+1:])
 		}
-		pm.Protocol = protocol
+		portPart = s[:idx]
 	}
 
-	// 分离端口部分
+	// 分离端口部分（Phase 21: IPv6 宿主机地址用方括号包裹）
+	hostIP, portPart, err := splitPortHostIP(portPart)
+	if err != nil {
+		return nil, err
+	}
 	portParts := strings.Split(portPart, ":")
+	if hostIP != "" {
+		if len(portParts) != 2 {
+			return nil, fmt.Errorf("invalid format, expected [hostIP]:hostPort:containerPort")
+		}
+		portParts = append([]string{hostIP}, portParts...)
+	}
+
+	var hostPorts string
+	var containerPorts string
 	switch len(portParts) {
 	case 1:
-		// 只有 containerPort
-		port, err := parsePort(portParts[0])
-		if err != nil {
-			return pm, fmt.Errorf("invalid container port: %w", err)
-		}
-		pm.ContainerPort = port
-		pm.HostPort = port // 默认使用相同端口
+		// 只有 containerPort：宿主机端口随机分配
+		containerPorts = portParts[0]
 	case 2:
 		// hostPort:containerPort
-		hostPort, err := parsePort(portParts[0])
-		if err != nil {
-			return pm, fmt.Errorf("invalid host port: %w", err)
+		hostPorts, containerPorts = portParts[0], portParts[1]
+	case 3:
+		// hostIP:hostPort:containerPort（hostPort 可为空）
+		hostIP, hostPorts, containerPorts = portParts[0], portParts[1], portParts[2]
+		if net.ParseIP(hostIP) == nil {
+			return nil, fmt.Errorf("invalid host IP: %s", hostIP)
 		}
-		containerPort, err := parsePort(portParts[1])
+	default:
+		return nil, fmt.Errorf("invalid format, expected [[hostIP:]hostPort:]containerPort")
+	}
+
+	containerStart, containerEnd, err := parsePortRange(containerPorts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid container port: %w", err)
+	}
+	var hostStart uint16
+	if hostPorts != "" {
+		var hostEnd uint16
+		hostStart, hostEnd, err = parsePortRange(hostPorts)
 		if err != nil {
-			return pm, fmt.Errorf("invalid container port: %w", err)
+			return nil, fmt.Errorf("invalid host port: %w", err)
 		}
-		pm.HostPort = hostPort
-		pm.ContainerPort = containerPort
-	case 3:
-		// hostIP:hostPort:containerPort
-		pm.HostIP = portParts[0]
-		hostPort, err := parsePort(portParts[1])
-		if err != nil {
-			return pm, fmt.Errorf("invalid host port: %w", err)
+		if hostEnd-hostStart != containerEnd-containerStart {
+			return nil, fmt.Errorf("host port range %s and container port range %s must have the same size", hostPorts, containerPorts)
 		}
-		containerPort, err := parsePort(portParts[2])
-		if err != nil {
-			return pm, fmt.Errorf("invalid container port: %w", err)
+	}
+
+	mappings := make([]PortMapping, 0, int(containerEnd-containerStart)+1)
+	for i := 0; i <= int(containerEnd-containerStart); i++ {
+		pm := PortMapping{
+			HostIP:        hostIP,
+			ContainerPort: containerStart + uint16(i),
+			Protocol:      protocol,
 		}
-		pm.HostPort = hostPort
-		pm.ContainerPort = containerPort
-	default:
-		return pm, fmt.Errorf("invalid port mapping format: %s", s)
+		if hostStart != 0 {
+			pm.HostPort = hostStart + uint16(i)
+		}
+		mappings = append(mappings, pm)
 	}
+	return mappings, nil
+}
 
-	return pm, nil
+// parsePortRange 解析单个端口或 start-end 端口范围
+func parsePortRange(s string) (uint16, uint16, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := parsePort(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := parsePort(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid port range %s: end port is less than start port", s)
+	}
+	return start, end, nil
+}
+
+// splitPortHostIP 拆分端口映射中方括号包裹的 IPv6 宿主机地址（Phase 21 新增）。
+// "[::1]:8080:80" 返回 "::1" 和 "8080:80"；不以 "[" 开头时原样返回。
+func splitPortHostIP(spec string) (hostIP, rest string, err error) {
+	if !strings.HasPrefix(spec, "[") {
+		return "", spec, nil
+	}
+	end := strings.Index(spec, "]:")
+	if end < 0 {
+		return "", "", fmt.Errorf("invalid IPv6 host address in %q: expected [address]:hostPort:containerPort", spec)
+	}
+	hostIP = spec[1:end]
+	if ip := net.ParseIP(hostIP); ip == nil || ip.To4() != nil {
+		return "", "", fmt.Errorf("invalid IPv6 host address: %s", hostIP)
+	}
+	return hostIP, spec[end+2:], nil
 }
 
 // parsePort 解析端口字符串
@@ -233,7 +407,7 @@ func parsePort(s string) (uint16, error) {
 		return 0, fmt.Errorf("invalid port number: %s", s)
 	}
 	if port == 0 {
-		return 0, fmt.Errorf("port cannot be 0")
+		return 0, fmt.Errorf("port must be between 1 and 65535")
 	}
 	return uint16(port), nil
 }
@@ -3,7 +3,12 @@
 
 package network
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
 
 // 默认网络配置常量
 const (
@@ -11,6 +16,8 @@ const (
 	DefaultSubnet     = "172.17.0.0/16"
 	DefaultGateway    = "172.17.0.1"
 	DefaultSubnetMask = 16
+
+	DefaultNetworkName = "bridge"
 )
 
 // NetworkMode 定义容器的网络模式
@@ -20,8 +27,21 @@ const (
 	NetworkModeBridge NetworkMode = "bridge"
 	NetworkModeHost   NetworkMode = "host"
 	NetworkModeNone   NetworkMode = "none"
+
+	NetworkModeContainerPrefix = "container:"
 )
 
+func (m NetworkMode) IsContainer() bool {
+	return strings.HasPrefix(string(m), NetworkModeContainerPrefix)
+}
+
+func (m NetworkMode) ConnectedContainer() string {
+	if !m.IsContainer() {
+		return ""
+	}
+	return strings.TrimPrefix(string(m), NetworkModeContainerPrefix)
+}
+
 // PortMapping 定义端口映射配置
 type PortMapping struct {
 	HostIP        string `json:"hostIP,omitempty"`
@@ -44,14 +64,26 @@ func (p *PortMapping) GetHostIP() string {
 	return p.HostIP
 }
 
+func (p *PortMapping) IsIPv6() bool {
+	ip := net.ParseIP(p.HostIP)
+	return ip != nil && ip.To4() == nil
+}
+
 func (p *PortMapping) String() string {
-	return fmt.Sprintf("%s:%d->%d/%s", p.GetHostIP(), p.HostPort, p.ContainerPort, p.GetProtocol())
+	return fmt.Sprintf("%s->%d/%s", net.JoinHostPort(p.GetHostIP(), strconv.Itoa(int(p.HostPort))), p.ContainerPort, p.GetProtocol())
 }
 
 // NetworkConfig 定义容器的网络配置
 type NetworkConfig struct {
 	Mode         NetworkMode   `json:"mode"`
 	BridgeName   string        `json:"bridgeName,omitempty"`
+	Network      string        `json:"network,omitempty"`
+	Aliases      []string      `json:"aliases,omitempty"`
+	IPAddress    string        `json:"ipAddress,omitempty"`
+	IPv6Address  string        `json:"ipv6Address,omitempty"`
+	MacAddress   string        `json:"macAddress,omitempty"`
+	IngressRate  uint64        `json:"ingressRate,omitempty"`
+	EgressRate   uint64        `json:"egressRate,omitempty"`
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
 }
 
@@ -73,6 +105,13 @@ func (c *NetworkConfig) GetBridgeName() string {
 	return c.BridgeName
 }
 
+func (c *NetworkConfig) GetNetworkName() string {
+	if c == nil || c.Network == "" {
+		return DefaultNetworkName
+	}
+	return c.Network
+}
+
 func (c *NetworkConfig) NeedsNetworkNamespace() bool {
 	mode := c.GetMode()
 	return mode == NetworkModeBridge || mode == NetworkModeNone
@@ -83,10 +122,35 @@ type NetworkState struct {
 	Mode          NetworkMode   `json:"mode"`
 	IPAddress     string        `json:"ipAddress,omitempty"`
 	Gateway       string        `json:"gateway,omitempty"`
+	IPv6Address   string        `json:"ipv6Address,omitempty"`
+	IPv6Gateway   string        `json:"ipv6Gateway,omitempty"`
 	MacAddress    string        `json:"macAddress,omitempty"`
+	MTU           int           `json:"mtu,omitempty"`
 	VethHost      string        `json:"vethHost,omitempty"`
 	VethContainer string        `json:"vethContainer,omitempty"`
 	PortMappings  []PortMapping `json:"portMappings,omitempty"`
+	Network       string        `json:"network,omitempty"`
+	Endpoints     []Endpoint    `json:"endpoints,omitempty"`
+}
+
+// Endpoint 描述容器在某个网络上的一块附加网卡
+type Endpoint struct {
+	Network       string `json:"network"`
+	IPAddress     string `json:"ipAddress"`
+	Gateway       string `json:"gateway,omitempty"`
+	IPv6Address   string `json:"ipv6Address,omitempty"`
+	IPv6Gateway   string `json:"ipv6Gateway,omitempty"`
+	MacAddress    string `json:"macAddress,omitempty"`
+	MTU           int    `json:"mtu,omitempty"`
+	VethHost      string `json:"vethHost"`
+	VethContainer string `json:"vethContainer"`
+}
+
+func (s *NetworkState) GetNetworkName() string {
+	if s == nil || s.Network == "" {
+		return DefaultNetworkName
+	}
+	return s.Network
 }
 
 // Manager 定义网络管理器接口
@@ -94,9 +158,16 @@ type Manager interface {
 	Setup(containerID string, config *NetworkConfig, pid int) (*NetworkState, error)
 	Teardown(containerID string, state *NetworkState) error
 	EnsureBridge(config *NetworkConfig) error
+	Connect(containerID, networkName string, pid int, ifName string) (*Endpoint, error)
+	Disconnect(containerID string, endpoint *Endpoint) error
+}
+
+// ParsePortMappings 解析端口映射字符串（非 Linux 平台 stub）
+func ParsePortMappings(s string) ([]PortMapping, error) {
+	return nil, fmt.Errorf("network is only supported on Linux")
 }
 
-// ParsePortMapping 解析端口映射字符串（非 Linux 平台 stub）
-func ParsePortMapping(s string) (PortMapping, error) {
-	return PortMapping{}, fmt.Errorf("network is only supported on Linux")
+// ParseRate 解析限速值（非 Linux 平台 stub）
+func ParseRate(s string) (uint64, error) {
+	return 0, fmt.Errorf("network is only supported on Linux")
 }
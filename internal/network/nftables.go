@@ -0,0 +1,384 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"minidocker/internal/state"
+	"minidocker/pkg/idutil"
+)
+
+// Phase 21: nftables 防火墙后端
+//
+// 所有规则位于 minidocker 自己的表（ip minidocker / ip6 minidocker）中，不修改其他表：
+//
+//	prerouting、output（nat）  fib daddr type local jump port-dnat
+//	postrouting（nat）         每个网络的出网、loopback 访问与 hairpin masquerade 规则（comment 为 bridge 名）
+//	forward（filter）          每个网络的 iifname/oifname accept 规则与 icc=false 时的 drop 规则（comment 为 bridge 名），
+//	                           以及每个容器的 jump fwd-<id>（comment 为容器 ID）
+//	port-dnat                  每个容器的 jump dnat-<id>（comment 为容器 ID）
+//	dnat-<id>、fwd-<id>        容器的 DNAT 与转发规则，每次整体替换
+//
+// 每次变更通过 nft -f 作为一个事务提交；清理时按 comment 查找规则句柄删除，只影响 minidocker 的规则。
+// 表由宿主机上的所有容器（以及所有数据目录）共享，读取句柄到提交之间持有宿主机范围的文件锁，
+// 避免并发启动的容器按过期的句柄删除或重复添加规则。
+
+// nftTableName 是 minidocker 使用的 nftables 表名
+const nftTableName = "minidocker"
+
+// nftPortDNATChain 是 prerouting/output 跳转到的端口发布链
+const nftPortDNATChain = "port-dnat"
+
+// nftLockDir 是 nftables 变更的文件锁目录（宿主机范围，不随数据目录变化）
+const nftLockDir = "/run/minidocker/nftables"
+
+// nftablesManager 管理 nftables 规则
+type nftablesManager struct {
+	family     string // ip 或 ip6
+	bridgeName string
+	subnet     string
+
+	// userlandProxy 表示发布端口的 loopback/hairpin 流量由用户态代理处理
+	userlandProxy bool
+}
+
+// newNftablesManager 创建新的 nftables 管理器（每个 bridge 网络、每个地址族一个）
+func newNftablesManager(bridgeName, subnet string, userlandProxy bool) (*nftablesManager, error) {
+	if _, err := exec.LookPath("nft"); err != nil {
+		return nil, fmt.Errorf("create nftables manager: %w", err)
+	}
+	family := "ip"
+	if ip, _, err := net.ParseCIDR(subnet); err == nil && ip.To4() == nil {
+		family = "ip6"
+	}
+	return &nftablesManager{
+		family:        family,
+		bridgeName:    bridgeName,
+		subnet:        subnet,
+		userlandProxy: userlandProxy,
+	}, nil
+}
+
+// table 返回命令中使用的表引用，例如 "ip minidocker"
+func (m *nftablesManager) table() string {
+	return m.family + " " + nftTableName
+}
+
+// baseCommands 返回创建表和基础链的命令。
+// add table/chain 是幂等的；prerouting/output 只有固定的跳转规则，每次清空后重建。
+func (m *nftablesManager) baseCommands() []string {
+	t := m.table()
+	return []string{
+		"add table " + t,
+		"add chain " + t + " " + nftPortDNATChain,
+		"add chain " + t + " prerouting { type nat hook prerouting priority -100; policy accept; }",
+		"add chain " + t + " output { type nat hook output priority -100; policy accept; }",
+		"add chain " + t + " postrouting { type nat hook postrouting priority 100; policy accept; }",
+		"add chain " + t + " forward { type filter hook forward priority 0; policy accept; }",
+		"flush chain " + t + " prerouting",
+		"add rule " + t + " prerouting fib daddr type local jump " + nftPortDNATChain,
+		"flush chain " + t + " output",
+		"add rule " + t + " output fib daddr type local jump " + nftPortDNATChain,
+	}
+}
+
+// SetupMasquerade 设置 MASQUERADE 规则用于出网 NAT
+// nft add rule ip minidocker postrouting ip saddr 172.17.0.0/16 oifname != "minidocker0" masquerade
+func (m *nftablesManager) SetupMasquerade() error {
+	if err := m.replaceNetworkRules("postrouting",
+		fmt.Sprintf("%s saddr %s oifname != %q masquerade", m.family, m.subnet, m.bridgeName),
+		// 宿主机本地地址（包括 route_localnet 下的 127.0.0.1）经 DNAT 访问容器时改写源地址
+		fmt.Sprintf("oifname %q fib saddr type local masquerade", m.bridgeName),
+		// 容器经发布端口访问同网络容器（hairpin，包括自身）时改写源地址
+		fmt.Sprintf("oifname %q %s saddr %s ct status dnat masquerade", m.bridgeName, m.family, m.subnet),
+	); err != nil {
+		return fmt.Errorf("add masquerade rule: %w", err)
+	}
+	return nil
+}
+
+// TeardownMasquerade 移除 MASQUERADE 规则
+func (m *nftablesManager) TeardownMasquerade() error {
+	if err := m.deleteRules("postrouting", m.bridgeName); err != nil {
+		return fmt.Errorf("delete masquerade rule: %w", err)
+	}
+	return nil
+}
+
+// SetupForwardAccept 设置允许 bridge 流量转发的规则
+func (m *nftablesManager) SetupForwardAccept(icc bool) error {
+	var rules []string
+	// Phase 21: icc=false 时丢弃 bridge 内容器之间的流量，经发布端口 DNAT 的流量（hairpin）除外
+	if !icc {
+		if err := enableBridgeNetfilter(m.family == "ip6"); err != nil {
+			return err
+		}
+		rules = append(rules,
+			fmt.Sprintf("iifname %q oifname %q ct status dnat accept", m.bridgeName, m.bridgeName),
+			fmt.Sprintf("iifname %q oifname %q drop", m.bridgeName, m.bridgeName),
+		)
+	}
+	rules = append(rules,
+		fmt.Sprintf("iifname %q accept", m.bridgeName),
+		fmt.Sprintf("oifname %q accept", m.bridgeName),
+	)
+	if err := m.replaceNetworkRules("forward", rules...); err != nil {
+		return fmt.Errorf("add FORWARD rules: %w", err)
+	}
+	return nil
+}
+
+// TeardownForwardAccept 移除 bridge 流量转发规则
+func (m *nftablesManager) TeardownForwardAccept() error {
+	if err := m.deleteRules("forward", m.bridgeName); err != nil {
+		return fmt.Errorf("delete FORWARD rules: %w", err)
+	}
+	return nil
+}
+
+// SetupPortMappings 在一个事务中替换容器的 DNAT 与转发规则
+func (m *nftablesManager) SetupPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	if err := m.update(func(rs *nftRuleset) []string {
+		return m.portMappingCommands(rs, containerID, containerIP, mappings)
+	}); err != nil {
+		return fmt.Errorf("add port mapping rules: %w", err)
+	}
+	return nil
+}
+
+// portMappingCommands 返回替换容器链并在缺少时添加跳转规则的命令
+func (m *nftablesManager) portMappingCommands(rs *nftRuleset, containerID, containerIP string, mappings []PortMapping) []string {
+	t := m.table()
+	dnatChain, fwdChain := nftContainerChains(containerID)
+	cmds := m.baseCommands()
+	cmds = append(cmds,
+		"add chain "+t+" "+dnatChain,
+		"flush chain "+t+" "+dnatChain,
+		"add chain "+t+" "+fwdChain,
+		"flush chain "+t+" "+fwdChain,
+	)
+	for _, pm := range mappings {
+		protocol := pm.GetProtocol()
+		destination := net.JoinHostPort(containerIP, strconv.Itoa(int(pm.ContainerPort)))
+		if match, ok := m.dnatMatch(pm.GetHostIP()); ok {
+			cmds = append(cmds, fmt.Sprintf("add rule %s %s %s%s dport %d dnat to %s", t, dnatChain, match, protocol, pm.HostPort, destination))
+		}
+		cmds = append(cmds, fmt.Sprintf("add rule %s %s %s daddr %s %s dport %d accept", t, fwdChain, m.family, containerIP, protocol, pm.ContainerPort))
+	}
+	if len(rs.find(nftPortDNATChain, containerID)) == 0 {
+		cmds = append(cmds, fmt.Sprintf("add rule %s %s jump %s comment %q", t, nftPortDNATChain, dnatChain, containerID))
+	}
+	if len(rs.find("forward", containerID)) == 0 {
+		cmds = append(cmds, fmt.Sprintf("add rule %s forward jump %s comment %q", t, fwdChain, containerID))
+	}
+	return cmds
+}
+
+// dnatMatch 返回 DNAT 规则的地址匹配条件；ok 为 false 表示不需要 DNAT（由用户态代理处理）。
+// userlandProxy 时排除来自网络子网的 hairpin 流量（output 钩子中没有输入接口，不能用 iifname 匹配）
+// 和访问 loopback 的流量。
+func (m *nftablesManager) dnatMatch(hostIP string) (string, bool) {
+	match := ""
+	if !isUnspecifiedIP(hostIP) {
+		if m.userlandProxy && isLoopbackIP(hostIP) {
+			return "", false
+		}
+		match = fmt.Sprintf("%s daddr %s ", m.family, hostIP)
+	} else if m.userlandProxy {
+		match = fmt.Sprintf("%s daddr != %s ", m.family, loopbackCIDR(m.family == "ip6"))
+	}
+	if m.userlandProxy {
+		match += fmt.Sprintf("%s saddr != %s ", m.family, m.subnet)
+	}
+	return match, true
+}
+
+// TeardownPortMappings 删除容器的跳转规则和容器链
+func (m *nftablesManager) TeardownPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	if err := m.update(func(rs *nftRuleset) []string {
+		return m.containerDeleteCommands(rs, containerID)
+	}); err != nil {
+		return fmt.Errorf("delete port mapping rules: %w", err)
+	}
+	return nil
+}
+
+// containerDeleteCommands 返回删除容器跳转规则和容器链的命令
+func (m *nftablesManager) containerDeleteCommands(rs *nftRuleset, containerID string) []string {
+	t := m.table()
+	var cmds []string
+	for _, chain := range []string{nftPortDNATChain, "forward"} {
+		for _, rule := range rs.find(chain, containerID) {
+			cmds = append(cmds, fmt.Sprintf("delete rule %s %s handle %d", t, chain, rule.Handle))
+		}
+	}
+	dnatChain, fwdChain := nftContainerChains(containerID)
+	for _, chain := range []string{dnatChain, fwdChain} {
+		if rs.chains[chain] {
+			cmds = append(cmds, "flush chain "+t+" "+chain, "delete chain "+t+" "+chain)
+		}
+	}
+	return cmds
+}
+
+// PrunePortMappings 删除 keep 不保留的容器的跳转规则和容器链（实现 firewall）。
+// 容器规则的 comment 为容器 ID；表按地址族共享，其他网络上的孤儿规则同样会被删除。
+func (m *nftablesManager) PrunePortMappings(keep func(containerID string) bool) (int, error) {
+	removed := 0
+	err := m.update(func(rs *nftRuleset) []string {
+		pruned := make(map[string]bool)
+		for _, rule := range rs.rules {
+			if rule.Chain != nftPortDNATChain && rule.Chain != "forward" {
+				continue
+			}
+			if !idutil.IsFullID(rule.Comment) || pruned[rule.Comment] || keep(rule.Comment) {
+				continue
+			}
+			pruned[rule.Comment] = true
+		}
+
+		var cmds []string
+		for containerID := range pruned {
+			removed += len(rs.find(nftPortDNATChain, containerID)) + len(rs.find("forward", containerID))
+			cmds = append(cmds, m.containerDeleteCommands(rs, containerID)...)
+		}
+		return cmds
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete port mapping rules: %w", err)
+	}
+	return removed, nil
+}
+
+// replaceNetworkRules 在一个事务中用 rules 替换链中属于该网络（comment 为 bridge 名）的规则
+func (m *nftablesManager) replaceNetworkRules(chain string, rules ...string) error {
+	return m.update(func(rs *nftRuleset) []string {
+		t := m.table()
+		cmds := m.baseCommands()
+		for _, rule := range rs.find(chain, m.bridgeName) {
+			cmds = append(cmds, fmt.Sprintf("delete rule %s %s handle %d", t, chain, rule.Handle))
+		}
+		for _, rule := range rules {
+			cmds = append(cmds, fmt.Sprintf("add rule %s %s %s comment %q", t, chain, rule, m.bridgeName))
+		}
+		return cmds
+	})
+}
+
+// deleteRules 删除链中 comment 匹配的规则
+func (m *nftablesManager) deleteRules(chain, comment string) error {
+	return m.update(func(rs *nftRuleset) []string {
+		var cmds []string
+		for _, rule := range rs.find(chain, comment) {
+			cmds = append(cmds, fmt.Sprintf("delete rule %s %s handle %d", m.table(), chain, rule.Handle))
+		}
+		return cmds
+	})
+}
+
+// update 在宿主机范围的文件锁保护下读取表中的规则，并把 build 生成的命令作为一个事务提交，
+// 保证按句柄删除的规则在读取与提交之间没有被其他进程修改。build 没有生成命令时不提交。
+func (m *nftablesManager) update(build func(rs *nftRuleset) []string) error {
+	if err := os.MkdirAll(nftLockDir, 0755); err != nil {
+		return fmt.Errorf("create nftables lock directory: %w", err)
+	}
+	lock, err := state.AcquireLock(nftLockDir)
+	if err != nil {
+		return fmt.Errorf("acquire nftables lock: %w", err)
+	}
+	defer lock.Release()
+
+	rs, err := m.list()
+	if err != nil {
+		return err
+	}
+	cmds := build(rs)
+	if len(cmds) == 0 {
+		return nil
+	}
+	return m.apply(cmds)
+}
+
+// apply 通过 nft -f 将命令作为一个事务提交
+func (m *nftablesManager) apply(cmds []string) error {
+	cmd := exec.Command("nft", "-f", "-")
+	cmd.Stdin = strings.NewReader(strings.Join(cmds, "\n") + "\n")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("nft: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// nftRule 是 nft -j 输出中 minidocker 关心的规则字段
+type nftRule struct {
+	Chain   string `json:"chain"`
+	Handle  int    `json:"handle"`
+	Comment string `json:"comment"`
+}
+
+// nftRuleset 是 minidocker 表中的链和规则
+type nftRuleset struct {
+	chains map[string]bool
+	rules  []nftRule
+}
+
+// find 返回链中 comment 匹配的规则
+func (rs *nftRuleset) find(chain, comment string) []nftRule {
+	var rules []nftRule
+	for _, rule := range rs.rules {
+		if rule.Chain == chain && rule.Comment == comment {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// list 读取 minidocker 表中的链和规则；表不存在时返回空结果
+func (m *nftablesManager) list() (*nftRuleset, error) {
+	rs := &nftRuleset{chains: make(map[string]bool)}
+
+	output, err := exec.Command("nft", "-j", "list", "table", m.family, nftTableName).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "No such file or directory") {
+			return rs, nil
+		}
+		return nil, fmt.Errorf("list nftables table %s: %w", m.table(), err)
+	}
+
+	var doc struct {
+		Nftables []struct {
+			Chain *struct {
+				Name string `json:"name"`
+			} `json:"chain"`
+			Rule *nftRule `json:"rule"`
+		} `json:"nftables"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		return nil, fmt.Errorf("parse nftables table %s: %w", m.table(), err)
+	}
+	for _, obj := range doc.Nftables {
+		if obj.Chain != nil {
+			rs.chains[obj.Chain.Name] = true
+		}
+		if obj.Rule != nil {
+			rs.rules = append(rs.rules, *obj.Rule)
+		}
+	}
+	return rs, nil
+}
+
+// nftContainerChains 返回容器的 DNAT 链和转发链名
+func nftContainerChains(containerID string) (string, string) {
+	id := idutil.ShortID(containerID)
+	return "dnat-" + id, "fwd-" + id
+}
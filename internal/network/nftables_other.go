@@ -0,0 +1,41 @@
+//go:build !linux
+// +build !linux
+
+package network
+
+import "fmt"
+
+// nftablesManager 管理 nftables 规则（非 Linux 平台 stub）
+type nftablesManager struct{}
+
+func newNftablesManager(bridgeName, subnet string, userlandProxy bool) (*nftablesManager, error) {
+	return nil, fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) SetupMasquerade() error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) TeardownMasquerade() error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) SetupPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) TeardownPortMappings(containerID, containerIP string, mappings []PortMapping) error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) SetupForwardAccept(icc bool) error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) TeardownForwardAccept() error {
+	return fmt.Errorf("nftables is only supported on Linux")
+}
+
+func (m *nftablesManager) PrunePortMappings(keep func(containerID string) bool) (int, error) {
+	return 0, fmt.Errorf("nftables is only supported on Linux")
+}
@@ -0,0 +1,307 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"minidocker/internal/state"
+	"minidocker/pkg/fileutil"
+)
+
+// Phase 21: 宿主机端口分配
+//
+// -p 80、-p 127.0.0.1::80 和 -P 未指定宿主机端口，由 Setup 从内核临时端口范围
+// （/proc/sys/net/ipv4/ip_local_port_range）中分配。所有容器发布的宿主机端口记录在
+// network/ports.json 中，分配时跳过其他容器已发布的端口和宿主机上已被监听的端口。
+// 多个 CLI/shim 进程可能并发分配，读-改-写在 network 目录的文件锁（与 networks.json 相同）保护下进行。
+
+const (
+	// portAllocFile 是宿主机端口分配状态文件（位于 network 目录下）
+	portAllocFile = "ports.json"
+
+	// localPortRangeFile 是内核临时端口范围
+	localPortRangeFile = "/proc/sys/net/ipv4/ip_local_port_range"
+
+	// 读取临时端口范围失败时使用的默认值（与内核默认值一致）
+	defaultEphemeralPortStart = 32768
+	defaultEphemeralPortEnd   = 60999
+)
+
+// PortAllocConfig 保存宿主机端口分配的持久化状态
+type PortAllocConfig struct {
+	// Allocated 保存每个容器发布的端口映射（containerID -> 映射，宿主机端口已分配）
+	Allocated map[string][]PortMapping `json:"allocated"`
+
+	// LastAllocated 是最后随机分配的宿主机端口（用于顺序分配）
+	LastAllocated uint16 `json:"lastAllocated"`
+}
+
+// portAllocator 为未指定宿主机端口的映射分配空闲端口
+type portAllocator struct {
+	mu       sync.Mutex
+	dir      string // $MINIDOCKER_ROOT/network（文件锁所在目录）
+	filePath string
+}
+
+// newPortAllocator 创建宿主机端口分配器
+func newPortAllocator(dataDir string) (*portAllocator, error) {
+	networkDir := filepath.Join(dataDir, "network")
+	if err := os.MkdirAll(networkDir, 0755); err != nil {
+		return nil, fmt.Errorf("create network directory: %w", err)
+	}
+	return &portAllocator{dir: networkDir, filePath: filepath.Join(networkDir, portAllocFile)}, nil
+}
+
+// Allocate 为容器的端口映射分配宿主机端口，返回宿主机端口均已确定的映射并记录下来。
+// 已指定宿主机端口的映射原样返回（同样记录，避免被随机分配给其他容器），
+// 与其他容器已发布的端口冲突时返回错误。
+func (a *portAllocator) Allocate(containerID string, mappings []PortMapping) ([]PortMapping, error) {
+	var result []PortMapping
+	err := a.update(func(config *PortAllocConfig) error {
+		var err error
+		result, err = allocatePorts(config, containerID, mappings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// allocatePorts 在分配状态中为容器的端口映射分配宿主机端口并记录
+func allocatePorts(config *PortAllocConfig, containerID string, mappings []PortMapping) ([]PortMapping, error) {
+	// 其他容器发布的端口（容器自身的旧记录被本次分配替换）
+	var used []PortMapping
+	for id, published := range config.Allocated {
+		if id != containerID {
+			used = append(used, published...)
+		}
+	}
+	for _, pm := range mappings {
+		if pm.HostPort == 0 {
+			continue
+		}
+		if conflictsWithPublished(used, pm) {
+			return nil, fmt.Errorf("bind for %s/%s failed: port is already allocated",
+				net.JoinHostPort(pm.GetHostIP(), strconv.Itoa(int(pm.HostPort))), pm.GetProtocol())
+		}
+	}
+	for _, pm := range mappings {
+		if pm.HostPort != 0 {
+			used = append(used, pm)
+		}
+	}
+
+	start, end := ephemeralPortRange()
+	result := make([]PortMapping, len(mappings))
+	for i, pm := range mappings {
+		if pm.HostPort == 0 {
+			port, err := findFreePort(pm, used, start, end, config.LastAllocated)
+			if err != nil {
+				return nil, err
+			}
+			pm.HostPort = port
+			config.LastAllocated = port
+			used = append(used, pm)
+		}
+		result[i] = pm
+	}
+
+	config.Allocated[containerID] = result
+	return result, nil
+}
+
+// List 返回所有容器发布的宿主机端口（containerID -> 映射）
+func (a *portAllocator) List() (map[string][]PortMapping, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lock, err := state.AcquireLock(a.dir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire port allocation lock: %w", err)
+	}
+	defer lock.Release()
+
+	config, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+	return config.Allocated, nil
+}
+
+// Release 释放容器发布的宿主机端口（幂等）
+func (a *portAllocator) Release(containerID string) error {
+	return a.update(func(config *PortAllocConfig) error {
+		delete(config.Allocated, containerID)
+		return nil
+	})
+}
+
+// Prune 在同一次加锁中释放 keep 返回 false 的容器发布的宿主机端口，返回被释放的映射
+func (a *portAllocator) Prune(keep func(containerID string) bool) (map[string][]PortMapping, error) {
+	released := make(map[string][]PortMapping)
+	err := a.update(func(config *PortAllocConfig) error {
+		for containerID, published := range config.Allocated {
+			if !keep(containerID) {
+				released[containerID] = published
+				delete(config.Allocated, containerID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return released, nil
+}
+
+// update 在文件锁保护下执行读-改-写，fn 返回错误时不保存
+func (a *portAllocator) update(fn func(config *PortAllocConfig) error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lock, err := state.AcquireLock(a.dir)
+	if err != nil {
+		return fmt.Errorf("acquire port allocation lock: %w", err)
+	}
+	defer lock.Release()
+
+	config, err := a.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(config); err != nil {
+		return err
+	}
+	if err := a.save(config); err != nil {
+		return fmt.Errorf("save port allocation state: %w", err)
+	}
+	return nil
+}
+
+// findFreePort 从上次分配的位置开始在 [start, end] 中查找既未被发布、也未被监听的端口
+func findFreePort(pm PortMapping, used []PortMapping, start, end, last uint16) (uint16, error) {
+	size := int(end-start) + 1
+	offset := 0
+	if last >= start && last < end {
+		offset = int(last-start) + 1
+	}
+	for i := 0; i < size; i++ {
+		port := start + uint16((offset+i)%size)
+		if isPortPublished(used, pm.GetProtocol(), port) {
+			continue
+		}
+		if isPortListening(pm.HostIP, pm.GetProtocol(), port) {
+			continue
+		}
+		return port, nil
+	}
+	return 0, fmt.Errorf("no available host port in range %d-%d for %s", start, end, pm.String())
+}
+
+// isPortPublished 判断宿主机端口是否已在映射中发布（同一协议上不区分宿主机地址）
+func isPortPublished(mappings []PortMapping, protocol string, port uint16) bool {
+	for _, pm := range mappings {
+		if pm.HostPort == port && pm.GetProtocol() == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// conflictsWithPublished 判断指定宿主机端口的映射是否与已发布的映射冲突：
+// 协议和端口相同，且宿主机地址相同或任一方覆盖对方（未指定地址覆盖两个地址族，通配地址覆盖本地址族）
+func conflictsWithPublished(published []PortMapping, pm PortMapping) bool {
+	for _, p := range published {
+		if p.HostPort == pm.HostPort && p.GetProtocol() == pm.GetProtocol() && hostIPsOverlap(p.HostIP, pm.HostIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostIPsOverlap 判断两个端口映射的宿主机地址是否有重叠
+func hostIPsOverlap(a, b string) bool {
+	if a == "" || b == "" {
+		return true
+	}
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+	if (ipA.To4() == nil) != (ipB.To4() == nil) {
+		return false
+	}
+	return ipA.IsUnspecified() || ipB.IsUnspecified() || ipA.Equal(ipB)
+}
+
+// isPortListening 尝试在宿主机地址上绑定端口，判断是否已被占用。
+// 未指定宿主机地址时绑定双栈通配地址；地址不属于本机等其他错误不视为占用。
+func isPortListening(hostIP, protocol string, port uint16) bool {
+	addr := net.JoinHostPort(hostIP, strconv.Itoa(int(port)))
+	var err error
+	if protocol == "udp" {
+		var conn net.PacketConn
+		if conn, err = net.ListenPacket("udp", addr); err == nil {
+			conn.Close()
+		}
+	} else {
+		var l net.Listener
+		if l, err = net.Listen("tcp", addr); err == nil {
+			l.Close()
+		}
+	}
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+// ephemeralPortRange 返回内核临时端口范围
+func ephemeralPortRange() (uint16, uint16) {
+	data, err := os.ReadFile(localPortRangeFile)
+	if err != nil {
+		return defaultEphemeralPortStart, defaultEphemeralPortEnd
+	}
+	var start, end uint16
+	if _, err := fmt.Sscanf(string(data), "%d %d", &start, &end); err != nil || start == 0 || end < start {
+		return defaultEphemeralPortStart, defaultEphemeralPortEnd
+	}
+	return start, end
+}
+
+// load 加载端口分配状态
+func (a *portAllocator) load() (*PortAllocConfig, error) {
+	config := &PortAllocConfig{Allocated: make(map[string][]PortMapping)}
+
+	data, err := os.ReadFile(a.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("read port allocation file: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("parse port allocation file: %w", err)
+	}
+	if config.Allocated == nil {
+		config.Allocated = make(map[string][]PortMapping)
+	}
+	return config, nil
+}
+
+// save 保存端口分配状态
+func (a *portAllocator) save(config *PortAllocConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal port allocation state: %w", err)
+	}
+	return fileutil.AtomicWriteFile(a.filePath, data, 0644)
+}
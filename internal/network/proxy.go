@@ -0,0 +1,228 @@
+package network
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Phase 21: 用户态端口代理
+//
+// daemon.json 启用 userland-proxy 时，shim（前台 run 为 CLI 进程）为每个发布端口在宿主机网络命名空间中
+// 监听 hostIP:hostPort，并把连接转发到容器地址。防火墙的 DNAT 规则不再处理 loopback 访问和来自 bridge 的
+// hairpin 流量，这些连接由代理接收；外部流量仍然经 DNAT 直接到达容器。
+
+const (
+	// proxyDialTimeout 是代理连接容器的超时
+	proxyDialTimeout = 5 * time.Second
+
+	// udpIdleTimeout 是 UDP 代理会话的空闲超时
+	udpIdleTimeout = 90 * time.Second
+)
+
+// PortProxy 是容器所有发布端口的用户态代理（Phase 21 新增）
+type PortProxy struct {
+	listeners []io.Closer
+}
+
+// proxyBinding 是一个地址族上的代理监听地址和容器地址
+type proxyBinding struct {
+	network string // tcp4、tcp6、udp4 或 udp6
+	listen  string
+	backend string
+}
+
+// StartPortProxy 为容器的发布端口启动用户态代理；任一端口监听失败时关闭已启动的代理并返回错误
+func StartPortProxy(state *NetworkState) (*PortProxy, error) {
+	p := &PortProxy{}
+	for _, pm := range state.PortMappings {
+		for _, b := range proxyBindings(state, pm) {
+			if err := p.listen(b); err != nil {
+				p.Close()
+				return nil, fmt.Errorf("%s: %w", pm.String(), err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// proxyBindings 返回端口映射在容器各地址族上的代理（与防火墙规则的地址族选择一致）
+func proxyBindings(state *NetworkState, pm PortMapping) []proxyBinding {
+	protocol := pm.GetProtocol()
+	hostPort := strconv.Itoa(int(pm.HostPort))
+	containerPort := strconv.Itoa(int(pm.ContainerPort))
+
+	var bindings []proxyBinding
+	if (pm.HostIP == "" || !pm.IsIPv6()) && state.IPAddress != "" {
+		bindings = append(bindings, proxyBinding{
+			network: protocol + "4",
+			listen:  net.JoinHostPort(pm.GetHostIP(), hostPort),
+			backend: net.JoinHostPort(state.IPAddress, containerPort),
+		})
+	}
+	if (pm.HostIP == "" || pm.IsIPv6()) && state.IPv6Address != "" {
+		hostIP := pm.HostIP
+		if hostIP == "" {
+			hostIP = "::"
+		}
+		bindings = append(bindings, proxyBinding{
+			network: protocol + "6",
+			listen:  net.JoinHostPort(hostIP, hostPort),
+			backend: net.JoinHostPort(state.IPv6Address, containerPort),
+		})
+	}
+	return bindings
+}
+
+// listen 在宿主机上监听并开始转发
+func (p *PortProxy) listen(b proxyBinding) error {
+	switch b.network {
+	case "tcp4", "tcp6":
+		l, err := net.Listen(b.network, b.listen)
+		if err != nil {
+			return err
+		}
+		p.listeners = append(p.listeners, l)
+		go serveTCPProxy(l, b.backend)
+	default:
+		addr, err := net.ResolveUDPAddr(b.network, b.listen)
+		if err != nil {
+			return err
+		}
+		conn, err := net.ListenUDP(b.network, addr)
+		if err != nil {
+			return err
+		}
+		p.listeners = append(p.listeners, conn)
+		go serveUDPProxy(conn, b.backend)
+	}
+	return nil
+}
+
+// Close 停止所有代理监听（nil 安全）；已建立的 TCP 连接在任一端关闭后结束
+func (p *PortProxy) Close() {
+	if p == nil {
+		return
+	}
+	for _, l := range p.listeners {
+		_ = l.Close()
+	}
+}
+
+func serveTCPProxy(l net.Listener, backend string) {
+	for {
+		client, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go proxyTCPConn(client, backend)
+	}
+}
+
+// proxyTCPConn 双向复制一个 TCP 连接，一个方向结束时半关闭另一端的写方向
+func proxyTCPConn(client net.Conn, backend string) {
+	defer client.Close()
+	server, err := net.DialTimeout("tcp", backend, proxyDialTimeout)
+	if err != nil {
+		return
+	}
+	defer server.Close()
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		if c, ok := dst.(*net.TCPConn); ok {
+			_ = c.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(server, client)
+	go pipe(client, server)
+	<-done
+	<-done
+}
+
+// udpSession 是一个客户端地址到容器的 UDP 会话
+type udpSession struct {
+	upstream   *net.UDPConn
+	lastActive time.Time
+}
+
+// serveUDPProxy 为每个客户端地址维护一个到容器的 UDP 会话，空闲超时后关闭。
+// 会话的查找、写入和空闲回收都在同一把锁下进行，回收不会关闭主循环正在写入的连接。
+func serveUDPProxy(conn *net.UDPConn, backend string) {
+	var mu sync.Mutex
+	sessions := make(map[string]*udpSession)
+	defer func() {
+		mu.Lock()
+		for _, s := range sessions {
+			_ = s.upstream.Close()
+		}
+		mu.Unlock()
+	}()
+
+	backendAddr, err := net.ResolveUDPAddr("udp", backend)
+	if err != nil {
+		return
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, client, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+
+		key := client.String()
+		mu.Lock()
+		s, ok := sessions[key]
+		if !ok {
+			upstream, err := net.DialUDP("udp", nil, backendAddr)
+			if err != nil {
+				mu.Unlock()
+				continue
+			}
+			s = &udpSession{upstream: upstream}
+			sessions[key] = s
+			go relayUDPReplies(conn, s, client, &mu, func() {
+				if sessions[key] == s {
+					delete(sessions, key)
+				}
+			})
+		}
+		s.lastActive = time.Now()
+		_, _ = s.upstream.Write(buf[:n])
+		mu.Unlock()
+	}
+}
+
+// relayUDPReplies 将容器的应答转发回客户端，直到会话空闲超时或被关闭。
+// 读超时后持锁确认会话确实空闲，再从会话表中移除（remove）并关闭连接。
+func relayUDPReplies(conn *net.UDPConn, s *udpSession, client *net.UDPAddr, mu *sync.Mutex, remove func()) {
+	buf := make([]byte, 65535)
+	for {
+		_ = s.upstream.SetReadDeadline(time.Now().Add(udpIdleTimeout))
+		n, err := s.upstream.Read(buf)
+		if err == nil {
+			mu.Lock()
+			s.lastActive = time.Now()
+			mu.Unlock()
+			if _, err = conn.WriteToUDP(buf[:n], client); err == nil {
+				continue
+			}
+		}
+
+		mu.Lock()
+		// 读超时期间客户端仍有新请求：会话未空闲，继续等待应答
+		if ne, ok := err.(net.Error); ok && ne.Timeout() && time.Since(s.lastActive) < udpIdleTimeout {
+			mu.Unlock()
+			continue
+		}
+		remove()
+		_ = s.upstream.Close()
+		mu.Unlock()
+		return
+	}
+}
@@ -0,0 +1,141 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// Phase 21: 容器网络限速
+//
+// 限速设置在宿主机端 veth 上，veth 删除时随之清除：
+//   - 容器接收方向（宿主机 veth 出方向）：tbf 队列整形，超出速率的报文排队
+//   - 容器发送方向（宿主机 veth 入方向）：ingress 队列 + matchall police，超出速率的报文丢弃
+
+const (
+	// rateLimitLatencyMs 是 tbf 队列允许的最大排队延迟
+	rateLimitLatencyMs = 50
+
+	// rateLimitMinBurst 是令牌桶的最小突发字节数（至少容纳若干个完整报文）
+	rateLimitMinBurst = 16 * 1024
+)
+
+// rateUnits 是限速值支持的单位（与 tc 一致：bit 为比特/秒，bps 为字节/秒）
+var rateUnits = []struct {
+	suffix string
+	bits   uint64
+}{
+	{"kbit", 1000}, {"mbit", 1000 * 1000}, {"gbit", 1000 * 1000 * 1000},
+	{"kbps", 8 * 1000}, {"mbps", 8 * 1000 * 1000}, {"gbps", 8 * 1000 * 1000 * 1000},
+	{"bit", 1}, {"bps", 8},
+}
+
+// ParseRate 解析限速值（如 10mbit、512kbit、1gbit、2mbps），返回 bit/s
+func ParseRate(s string) (uint64, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	multiplier := uint64(1)
+	for _, unit := range rateUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSuffix(value, unit.suffix)
+			multiplier = unit.bits
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid rate %q (examples: 512kbit, 10mbit, 1gbit)", s)
+	}
+	bits := n * float64(multiplier)
+	// police 动作的速率字段为 32 位字节/秒
+	if bits/8 > math.MaxUint32 {
+		return 0, fmt.Errorf("rate %q is too large", s)
+	}
+	if bits < 8 {
+		return 0, fmt.Errorf("rate %q is too small", s)
+	}
+	return uint64(bits), nil
+}
+
+// setupRateLimit 在宿主机端 veth 上设置容器接收（ingressRate）和发送（egressRate）方向的限速，单位 bit/s
+func setupRateLimit(hostVeth netlink.Link, ingressRate, egressRate uint64) error {
+	if ingressRate > 0 {
+		if err := addTbfQdisc(hostVeth, ingressRate/8); err != nil {
+			return fmt.Errorf("limit ingress rate: %w", err)
+		}
+	}
+	if egressRate > 0 {
+		if err := addIngressPolice(hostVeth, egressRate/8); err != nil {
+			return fmt.Errorf("limit egress rate: %w", err)
+		}
+	}
+	return nil
+}
+
+// rateLimitBurst 返回速率对应的令牌桶大小（约 10ms 的流量，不小于 rateLimitMinBurst）
+func rateLimitBurst(rateBytes uint64) uint32 {
+	burst := rateBytes / 100
+	if burst < rateLimitMinBurst {
+		burst = rateLimitMinBurst
+	}
+	return uint32(burst)
+}
+
+// addTbfQdisc 在接口出方向添加 tbf 根队列
+func addTbfQdisc(link netlink.Link, rateBytes uint64) error {
+	burst := rateLimitBurst(rateBytes)
+	qdisc := &netlink.Tbf{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(1, 0),
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		Rate:   rateBytes,
+		Buffer: netlink.Xmittime(rateBytes, burst),
+		// 队列长度 = 最大排队延迟内可发送的字节 + 突发
+		Limit: uint32(rateBytes*rateLimitLatencyMs/1000) + burst,
+	}
+	if err := netlink.QdiscAdd(qdisc); err != nil {
+		return fmt.Errorf("add tbf qdisc: %w", err)
+	}
+	return nil
+}
+
+// addIngressPolice 在接口入方向添加 ingress 队列和丢弃超速报文的 matchall police 过滤器
+func addIngressPolice(link netlink.Link, rateBytes uint64) error {
+	ingress := &netlink.Ingress{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    netlink.MakeHandle(0xffff, 0),
+			Parent:    netlink.HANDLE_INGRESS,
+		},
+	}
+	if err := netlink.QdiscAdd(ingress); err != nil {
+		return fmt.Errorf("add ingress qdisc: %w", err)
+	}
+
+	police := netlink.NewPoliceAction()
+	police.Rate = uint32(rateBytes)
+	police.Burst = rateLimitBurst(rateBytes)
+	police.ExceedAction = netlink.TC_POLICE_SHOT
+	filter := &netlink.MatchAll{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    netlink.MakeHandle(0xffff, 0),
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []netlink.Action{police},
+	}
+	if err := netlink.FilterAdd(filter); err != nil {
+		return fmt.Errorf("add matchall police filter: %w", err)
+	}
+	return nil
+}
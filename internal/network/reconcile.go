@@ -0,0 +1,350 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"minidocker/internal/state"
+	"minidocker/pkg/fileutil"
+	"minidocker/pkg/idutil"
+
+	"github.com/vishvananda/netlink"
+)
+
+// Phase 21: 网络状态恢复
+//
+// IPAM 文件、宿主机端口分配（ports.json）、bridge 上的 veth 和端口映射规则各自持久化或随宿主机重启消失：
+// 宿主机重启或 shim 崩溃后，IPAM 仍然占用地址，端口映射规则可能残留。reconcile 以容器状态（state.json）为准，
+// 只有正在创建或进程仍存在的容器拥有网络资源，其余容器的地址、宿主机端口、veth 和端口映射规则都视为孤儿并清理。
+// 每次创建网络管理器时自动执行：本次开机已执行过（network/reconcile/boot_id 记录 boot ID）且
+// IPAM 与宿主机端口分配中没有记录不再活动的容器时跳过，因此 shim 崩溃遗留的资源由下一条命令清理。
+// network reconcile 命令随时手动执行并输出清理结果。两者由 reconcile 目录的文件锁串行化；
+// 地址、宿主机端口和防火墙规则的释放与分配使用相同的锁（network 目录锁与 nftables 锁）。
+
+const (
+	// reconcileDirName 是 reconcile 文件锁和开机标记所在的目录（位于 network 目录下）
+	reconcileDirName = "reconcile"
+
+	// reconcileBootFile 记录最近一次成功执行 reconcile 时的 boot ID
+	reconcileBootFile = "boot_id"
+
+	// bootIDFile 是内核为每次开机生成的随机 ID
+	bootIDFile = "/proc/sys/kernel/random/boot_id"
+)
+
+// Reconcile 清理所有 bridge 网络上的孤儿网络资源，返回清理记录
+func Reconcile(dataDir string) ([]string, error) {
+	m, err := newManager(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return m.reconcileLocked(false)
+}
+
+// reconcileLocked 在 reconcile 文件锁保护下执行 reconcile，成功后记录本次开机的 boot ID。
+// auto 为 true 时（创建网络管理器时自动执行），本次开机已成功执行过且没有孤儿候选则跳过。
+func (m *networkManager) reconcileLocked(auto bool) ([]string, error) {
+	dir := filepath.Join(m.store.dir, reconcileDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create reconcile directory: %w", err)
+	}
+	lock, err := state.AcquireLock(dir)
+	if err != nil {
+		return nil, fmt.Errorf("acquire reconcile lock: %w", err)
+	}
+	defer lock.Release()
+
+	data, err := os.ReadFile(bootIDFile)
+	if err != nil {
+		return nil, fmt.Errorf("read boot id: %w", err)
+	}
+	bootID := strings.TrimSpace(string(data))
+	markerPath := filepath.Join(dir, reconcileBootFile)
+	if auto {
+		if data, err := os.ReadFile(markerPath); err == nil && strings.TrimSpace(string(data)) == bootID {
+			candidates, err := m.hasOrphanCandidates()
+			if err != nil {
+				return nil, err
+			}
+			if !candidates {
+				return nil, nil
+			}
+		}
+	}
+
+	actions, err := m.reconcile()
+	if err != nil {
+		return actions, err
+	}
+	if err := fileutil.AtomicWriteFile(markerPath, []byte(bootID+"\n"), 0644); err != nil {
+		return actions, fmt.Errorf("save reconcile marker: %w", err)
+	}
+	return actions, nil
+}
+
+// containerActivity 判断容器是否仍拥有网络资源（结果按容器 ID 缓存）
+type containerActivity struct {
+	store  *state.Store
+	active map[string]bool
+}
+
+// isActive 返回容器是否正在创建或仍在运行。容器不存在时返回 false；
+// 状态文件无法读取时保守地认为容器仍在使用网络资源。
+// 只读取状态，不修正 shim 崩溃后残留的 running 状态（修正由持有容器锁的命令完成）。
+func (a *containerActivity) isActive(containerID string) bool {
+	if active, ok := a.active[containerID]; ok {
+		return active
+	}
+
+	active := false
+	if a.store.Exists(containerID) {
+		active = true
+		if st, err := state.LoadState(a.store.ContainerDir(containerID)); err == nil {
+			// ProcessAlive 按启动时间识别宿主机重启后被复用的 PID
+			active = st.Status == state.StatusCreating || st.ProcessAlive()
+		}
+	}
+	a.active[containerID] = active
+	return active
+}
+
+// hasOrphanCandidates 判断 IPAM 或宿主机端口分配中是否记录了不再活动的容器。
+// 只读取分配记录，不创建 bridge 驱动和防火墙后端；容器退出后遗留的 veth 和端口映射规则总是伴随这些记录。
+func (m *networkManager) hasOrphanCandidates() (bool, error) {
+	store, err := state.NewStore(m.dataDir)
+	if err != nil {
+		return false, fmt.Errorf("open state store: %w", err)
+	}
+	activity := &containerActivity{store: store, active: make(map[string]bool)}
+
+	networks, err := m.store.List()
+	if err != nil {
+		return false, err
+	}
+	for _, info := range networks {
+		if info.Driver != DriverBridge {
+			continue
+		}
+		ipam, err := newNetworkIPAM(m.dataDir, info)
+		if err != nil {
+			return false, err
+		}
+		ipam6, err := newNetworkIPAM6(m.dataDir, info)
+		if err != nil {
+			return false, err
+		}
+		for _, ipam := range []IPAM{ipam, ipam6} {
+			if ipam == nil {
+				continue
+			}
+			allocated, err := ipam.List()
+			if err != nil {
+				return false, err
+			}
+			for containerID := range allocated {
+				if !activity.isActive(containerID) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	published, err := m.ports.List()
+	if err != nil {
+		return false, err
+	}
+	for containerID := range published {
+		if !activity.isActive(containerID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reconcile 依次清理每个 bridge 网络的 veth、IP 地址和端口映射规则，最后释放宿主机端口
+func (m *networkManager) reconcile() ([]string, error) {
+	store, err := state.NewStore(m.dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("open state store: %w", err)
+	}
+	activity := &containerActivity{store: store, active: make(map[string]bool)}
+
+	networks, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []string
+	var lastErr error
+	rulesRemoved := 0
+	for _, info := range networks {
+		if info.Driver != DriverBridge {
+			continue
+		}
+		n, err := m.network(info.Name)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		done, removed, err := n.reconcile(store, activity)
+		actions = append(actions, done...)
+		rulesRemoved += removed
+		if err != nil {
+			lastErr = fmt.Errorf("network %s: %w", info.Name, err)
+		}
+	}
+	// nftables 的表按地址族共享，删除的规则数不按网络区分
+	if rulesRemoved > 0 {
+		actions = append(actions, fmt.Sprintf("removed %d orphaned port mapping rules", rulesRemoved))
+	}
+
+	done, err := m.reconcilePorts(activity)
+	actions = append(actions, done...)
+	if err != nil {
+		lastErr = err
+	}
+	return actions, lastErr
+}
+
+// reconcile 清理网络上的孤儿资源，返回清理记录和删除的端口映射规则数。
+// veth 先于 IP 释放，端口映射规则按规则中记录的容器 ID（comment）判断归属。
+// bridge 不存在时（从未使用或宿主机已重启）只有 IPAM 文件可能残留。
+func (n *bridgeNetwork) reconcile(store *state.Store, activity *containerActivity) ([]string, int, error) {
+	var actions []string
+	_, err := netlink.LinkByName(n.info.BridgeName)
+	bridgeExists := err == nil
+
+	if bridgeExists {
+		done, err := n.reconcileVeths(store, activity)
+		actions = append(actions, done...)
+		if err != nil {
+			return actions, 0, err
+		}
+	}
+
+	for _, ipam := range []IPAM{n.ipam, n.ipam6} {
+		if ipam == nil {
+			continue
+		}
+		done, err := n.reconcileIPAM(ipam, activity)
+		actions = append(actions, done...)
+		if err != nil {
+			return actions, 0, err
+		}
+	}
+
+	if !bridgeExists {
+		return actions, 0, nil
+	}
+	rulesRemoved := 0
+	for _, fw := range n.firewalls() {
+		removed, err := fw.PrunePortMappings(activity.isActive)
+		rulesRemoved += removed
+		if err != nil {
+			return actions, rulesRemoved, fmt.Errorf("prune port mapping rules: %w", err)
+		}
+	}
+	return actions, rulesRemoved, nil
+}
+
+// reconcileVeths 删除接入 bridge、但不属于任何活动容器的 veth。
+// 先列出 veth 再读取容器列表：veth 创建时容器状态已经存在，不会误删正在创建的容器的 veth。
+func (n *bridgeNetwork) reconcileVeths(store *state.Store, activity *containerActivity) ([]string, error) {
+	veths, err := n.bridge.AttachedVeths()
+	if err != nil {
+		return nil, err
+	}
+	if len(veths) == 0 {
+		return nil, nil
+	}
+
+	containers, err := store.List(true)
+	if err != nil {
+		return nil, fmt.Errorf("list containers: %w", err)
+	}
+
+	var actions []string
+	for _, name := range veths {
+		prefix, ok := vethOwnerPrefix(name)
+		if !ok {
+			// 不是 minidocker 创建的 veth
+			continue
+		}
+		owned := false
+		for _, c := range containers {
+			if strings.HasPrefix(c.ID, prefix) && activity.isActive(c.ID) {
+				owned = true
+				break
+			}
+		}
+		if owned {
+			continue
+		}
+		if err := n.bridge.TeardownVeth(name); err != nil {
+			return actions, fmt.Errorf("delete veth %s: %w", name, err)
+		}
+		actions = append(actions, fmt.Sprintf("removed orphaned veth %s from network %s", name, n.info.Name))
+	}
+	return actions, nil
+}
+
+// vethOwnerPrefix 返回宿主机端 veth 名中的容器 ID 前缀：
+// 主网卡为 veth<容器 ID 前 8 位>（SetupVeth），附加网卡为 vn<容器 ID 前 6 位><网络 ID 前 7 位>（SetupEndpoint）
+func vethOwnerPrefix(name string) (string, bool) {
+	switch {
+	case len(name) == 12 && strings.HasPrefix(name, "veth"):
+		return name[4:], true
+	case len(name) == 15 && strings.HasPrefix(name, "vn"):
+		return name[2:8], true
+	}
+	return "", false
+}
+
+// reconcileIPAM 释放不属于活动容器的地址（判断与释放在同一次加锁中完成）
+func (n *bridgeNetwork) reconcileIPAM(ipam IPAM, activity *containerActivity) ([]string, error) {
+	released, err := ipam.Prune(activity.isActive)
+	if err != nil {
+		return nil, fmt.Errorf("release IP addresses: %w", err)
+	}
+
+	ids := make([]string, 0, len(released))
+	for containerID := range released {
+		ids = append(ids, containerID)
+	}
+	sort.Strings(ids)
+
+	var actions []string
+	for _, containerID := range ids {
+		actions = append(actions, fmt.Sprintf("released IP %s on network %s (container %s)",
+			released[containerID], n.info.Name, idutil.ShortID(containerID)))
+	}
+	return actions, nil
+}
+
+// reconcilePorts 释放不属于活动容器的宿主机端口（判断与释放在同一次加锁中完成）
+func (m *networkManager) reconcilePorts(activity *containerActivity) ([]string, error) {
+	released, err := m.ports.Prune(activity.isActive)
+	if err != nil {
+		return nil, fmt.Errorf("release host ports: %w", err)
+	}
+
+	ids := make([]string, 0, len(released))
+	for containerID := range released {
+		ids = append(ids, containerID)
+	}
+	sort.Strings(ids)
+
+	var actions []string
+	for _, containerID := range ids {
+		for _, pm := range released[containerID] {
+			actions = append(actions, fmt.Sprintf("released host port %s (container %s)", pm.String(), idutil.ShortID(containerID)))
+		}
+	}
+	return actions, nil
+}
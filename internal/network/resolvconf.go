@@ -0,0 +1,82 @@
+package network
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"strings"
+)
+
+const (
+	// ResolverAddress 是内嵌 DNS 在容器网络命名空间内的监听地址（Phase 21 新增，与 Docker 一致）
+	ResolverAddress = "127.0.0.11"
+
+	// HostResolvConfPath 是宿主机的 resolv.conf 路径
+	HostResolvConfPath = "/etc/resolv.conf"
+)
+
+// DefaultNameservers 是没有可用 nameserver 时使用的公共 DNS（与 Docker 一致）
+var DefaultNameservers = []string{"8.8.8.8", "8.8.4.4"}
+
+// ResolvConf 是 resolv.conf 中 minidocker 关心的字段（Phase 21 新增）
+type ResolvConf struct {
+	Nameservers []string
+	Search      []string
+	Options     []string
+}
+
+// ReadResolvConf 解析 resolv.conf；文件不存在时返回空配置
+func ReadResolvConf(path string) ResolvConf {
+	var rc ResolvConf
+	f, err := os.Open(path)
+	if err != nil {
+		return rc
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			rc.Nameservers = append(rc.Nameservers, fields[1])
+		case "search", "domain":
+			// 后出现的 search/domain 覆盖之前的（与 glibc 行为一致）
+			rc.Search = fields[1:]
+		case "options":
+			rc.Options = append(rc.Options, fields[1:]...)
+		}
+	}
+	return rc
+}
+
+// RemoteNameservers 过滤掉 loopback 地址的 nameserver。
+// 宿主机上的 127.0.0.53 等本地解析器在容器独立的网络命名空间内不可达。
+func RemoteNameservers(nameservers []string) []string {
+	var remote []string
+	for _, ns := range nameservers {
+		if ip := net.ParseIP(ns); ip != nil && ip.IsLoopback() {
+			continue
+		}
+		remote = append(remote, ns)
+	}
+	return remote
+}
+
+// String 按 resolv.conf 格式输出
+func (rc ResolvConf) String() string {
+	var b strings.Builder
+	for _, ns := range rc.Nameservers {
+		b.WriteString("nameserver " + ns + "\n")
+	}
+	if len(rc.Search) > 0 {
+		b.WriteString("search " + strings.Join(rc.Search, " ") + "\n")
+	}
+	if len(rc.Options) > 0 {
+		b.WriteString("options " + strings.Join(rc.Options, " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,225 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/vishvananda/netns"
+)
+
+// NameLookup 为内嵌 DNS 解析同网络容器的名称（Phase 21 新增）
+type NameLookup interface {
+	// LookupHost 返回名称（容器名、--network-alias 别名或容器 ID）在共享网络上的 IPv4/IPv6 地址；
+	// 不是容器名称时返回空
+	LookupHost(name string) []net.IP
+
+	// LookupAddr 返回共享网络上某个 IP 所属容器的名称；未知时返回空字符串
+	LookupAddr(ip net.IP) string
+}
+
+// upstreamTimeout 是转发到宿主机上游 DNS 的单次超时
+const upstreamTimeout = 5 * time.Second
+
+// Resolver 是运行在容器网络命名空间内的内嵌 DNS（Phase 21 新增）。
+//
+// 监听 socket 在容器的网络命名空间中创建（127.0.0.11:53，UDP/TCP），
+// 由宿主机侧进程（shim 或前台 run）提供服务：
+// - A/AAAA/PTR 查询命中同网络容器的名称、别名时直接应答
+// - 其他查询转发给上游 nameserver（--dns 或宿主机 /etc/resolv.conf 中的配置）。
+// 转发 socket 在宿主机网络命名空间中创建，因此宿主机的 127.0.0.53 等本地解析器同样可用。
+type Resolver struct {
+	udp       *net.UDPConn
+	tcp       *net.TCPListener
+	lookup    NameLookup
+	upstreams []string
+}
+
+// StartResolver 在 pid 所在的网络命名空间内启动内嵌 DNS，外部查询转发给 upstreams（IP 地址）
+func StartResolver(pid int, upstreams []string, lookup NameLookup) (*Resolver, error) {
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return nil, fmt.Errorf("get container network namespace: %w", err)
+	}
+	defer ns.Close()
+
+	r := &Resolver{lookup: lookup}
+	for _, upstream := range upstreams {
+		if upstream != ResolverAddress {
+			r.upstreams = append(r.upstreams, net.JoinHostPort(upstream, "53"))
+		}
+	}
+	addr := net.ParseIP(ResolverAddress)
+	err = withNetns(ns, func() error {
+		udp, err := net.ListenUDP("udp4", &net.UDPAddr{IP: addr, Port: 53})
+		if err != nil {
+			return fmt.Errorf("listen udp %s:53: %w", ResolverAddress, err)
+		}
+		tcp, err := net.ListenTCP("tcp4", &net.TCPAddr{IP: addr, Port: 53})
+		if err != nil {
+			udp.Close()
+			return fmt.Errorf("listen tcp %s:53: %w", ResolverAddress, err)
+		}
+		r.udp, r.tcp = udp, tcp
+		return nil
+	})
+	if err != nil {
+		if r.udp != nil {
+			r.Close()
+		}
+		return nil, err
+	}
+
+	go r.serveUDP()
+	go r.serveTCP()
+	return r, nil
+}
+
+// Close 停止内嵌 DNS（nil 安全）
+func (r *Resolver) Close() {
+	if r == nil {
+		return
+	}
+	_ = r.udp.Close()
+	_ = r.tcp.Close()
+}
+
+func (r *Resolver) serveUDP() {
+	buf := make([]byte, 65535)
+	for {
+		n, client, err := r.udp.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		msg := append([]byte(nil), buf[:n]...)
+		go func() {
+			if resp := r.handle(msg, "udp"); resp != nil {
+				_, _ = r.udp.WriteToUDP(resp, client)
+			}
+		}()
+	}
+}
+
+func (r *Resolver) serveTCP() {
+	for {
+		conn, err := r.tcp.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			for {
+				_ = conn.SetDeadline(time.Now().Add(2 * upstreamTimeout))
+				msg, err := readTCPMessage(conn)
+				if err != nil {
+					return
+				}
+				resp := r.handle(msg, "tcp")
+				if resp == nil || writeTCPMessage(conn, resp) != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+// handle 处理一条查询：容器名称本地应答，其余转发给上游；返回 nil 表示丢弃
+func (r *Resolver) handle(msg []byte, proto string) []byte {
+	q, err := parseDNSQuery(msg)
+	if err != nil {
+		if len(msg) < dnsHeaderLen {
+			return nil
+		}
+		return r.forward(msg, proto)
+	}
+
+	if q.qclass == dnsClassIN {
+		if q.qtype == dnsTypePTR {
+			if ip := reverseAddr(q.name); ip != nil {
+				if name := r.lookup.LookupAddr(ip); name != "" {
+					return q.reply(dnsRcodeSuccess, [][]byte{dnsRecord(dnsTypePTR, encodeDNSName(name))})
+				}
+			}
+		} else if ips := r.lookup.LookupHost(q.name); len(ips) > 0 {
+			// 按查询类型返回对应地址族；其他类型（或容器没有该地址族）返回空应答，避免查询泄露到上游
+			var answers [][]byte
+			for _, ip := range ips {
+				if v4 := ip.To4(); v4 != nil && q.qtype == dnsTypeA {
+					answers = append(answers, dnsRecord(dnsTypeA, v4))
+				} else if v4 == nil && q.qtype == dnsTypeAAAA {
+					answers = append(answers, dnsRecord(dnsTypeAAAA, ip.To16()))
+				}
+			}
+			return q.reply(dnsRcodeSuccess, answers)
+		}
+	}
+
+	if resp := r.forward(msg, proto); resp != nil {
+		return resp
+	}
+	return q.reply(dnsRcodeServFail, nil)
+}
+
+// forward 依次尝试上游 nameserver，返回第一个有效应答
+func (r *Resolver) forward(msg []byte, proto string) []byte {
+	for _, upstream := range r.upstreams {
+		resp, err := exchange(proto, upstream, msg)
+		if err == nil && len(resp) >= dnsHeaderLen && resp[0] == msg[0] && resp[1] == msg[1] {
+			return resp
+		}
+	}
+	return nil
+}
+
+// exchange 向上游发送一条查询并读取应答
+func exchange(proto, upstream string, msg []byte) ([]byte, error) {
+	conn, err := net.DialTimeout(proto, upstream, upstreamTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(upstreamTimeout))
+
+	if proto == "tcp" {
+		if err := writeTCPMessage(conn, msg); err != nil {
+			return nil, err
+		}
+		return readTCPMessage(conn)
+	}
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// readTCPMessage 读取一条带 2 字节长度前缀的 DNS 报文
+func readTCPMessage(conn net.Conn) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// writeTCPMessage 写入一条带 2 字节长度前缀的 DNS 报文
+func writeTCPMessage(conn net.Conn, msg []byte) error {
+	buf := make([]byte, 2, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	_, err := conn.Write(append(buf, msg...))
+	return err
+}
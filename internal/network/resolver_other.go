@@ -0,0 +1,26 @@
+//go:build !linux
+// +build !linux
+
+package network
+
+import (
+	"fmt"
+	"net"
+)
+
+// NameLookup 为内嵌 DNS 解析同网络容器的名称（非 Linux 平台 stub）
+type NameLookup interface {
+	LookupHost(name string) []net.IP
+	LookupAddr(ip net.IP) string
+}
+
+// Resolver 是容器内嵌 DNS（非 Linux 平台 stub）
+type Resolver struct{}
+
+// StartResolver 在非 Linux 平台返回错误
+func StartResolver(pid int, upstreams []string, lookup NameLookup) (*Resolver, error) {
+	return nil, fmt.Errorf("embedded DNS is only supported on Linux")
+}
+
+// Close 停止内嵌 DNS（非 Linux 平台 stub）
+func (r *Resolver) Close() {}
@@ -0,0 +1,455 @@
+//go:build linux
+// +build linux
+
+package network
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"minidocker/internal/state"
+	"minidocker/pkg/fileutil"
+	"minidocker/pkg/idutil"
+)
+
+// Phase 21: 用户自定义网络注册表
+//
+// 每个用户自定义网络对应一个独立的 Linux bridge（md-<id 前 12 位>）、子网、网关和
+// IPAM 文件（network/ipam-<id>.json）。注册表保存在 $MINIDOCKER_ROOT/network/networks.json。
+// 内置网络 bridge/host/none 不落盘，由 List/Get 合成返回。
+
+// 网络驱动
+const (
+	DriverBridge = "bridge"
+	DriverHost   = "host"
+	DriverNull   = "null"
+)
+
+// userSubnetPool 是自动分配子网的地址池：172.18.0.0/16 ~ 172.31.0.0/16（对齐 Docker）
+const (
+	userSubnetFirst = 18
+	userSubnetLast  = 31
+)
+
+// networkNameRegex 限制网络名：字母数字开头，可包含 . _ -
+var networkNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,63}$`)
+
+// NetworkInfo 描述一个网络
+type NetworkInfo struct {
+	// ID 是 64 位十六进制网络 ID（内置网络为固定值）
+	ID string `json:"id"`
+
+	// Name 是网络名（唯一）
+	Name string `json:"name"`
+
+	// Driver 是网络驱动（bridge/host/null）
+	Driver string `json:"driver"`
+
+	// BridgeName 是宿主机上的 bridge 接口名（仅 bridge 驱动）
+	BridgeName string `json:"bridgeName,omitempty"`
+
+	// Subnet 是网络子网（CIDR）
+	Subnet string `json:"subnet,omitempty"`
+
+	// Gateway 是网关 IP（bridge 接口地址）
+	Gateway string `json:"gateway,omitempty"`
+
+	// EnableIPv6 表示双栈网络（Phase 21 新增，network create --ipv6）
+	EnableIPv6 bool `json:"enableIPv6,omitempty"`
+
+	// SubnetV6 是 IPv6 子网（CIDR，仅双栈网络）
+	SubnetV6 string `json:"subnetV6,omitempty"`
+
+	// GatewayV6 是 IPv6 网关（bridge 接口的 IPv6 地址）
+	GatewayV6 string `json:"gatewayV6,omitempty"`
+
+	// MTU 是 bridge 和容器网卡的 MTU（Phase 21 新增，0 表示内核默认值 1500）
+	MTU int `json:"mtu,omitempty"`
+
+	// DisableICC 禁止 bridge 上容器之间互相访问（Phase 21 新增，network create --opt icc=false；
+	// 默认 bridge 网络由 daemon.json 的 icc 设置）。经发布端口的访问不受影响。
+	DisableICC bool `json:"disableICC,omitempty"`
+
+	// CreatedAt 是创建时间
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Builtin 表示内置网络（不可删除）
+	Builtin bool `json:"builtin,omitempty"`
+}
+
+// IsBuiltinNetwork 返回名称是否为内置网络（bridge/host/none）
+func IsBuiltinNetwork(name string) bool {
+	switch NetworkMode(name) {
+	case NetworkModeBridge, NetworkModeHost, NetworkModeNone:
+		return true
+	}
+	return false
+}
+
+// builtinNetworks 返回内置网络的描述
+func builtinNetworks() []*NetworkInfo {
+	return []*NetworkInfo{
+		{ID: builtinNetworkID(DefaultNetworkName), Name: DefaultNetworkName, Driver: DriverBridge,
+			BridgeName: DefaultBridgeName, Subnet: DefaultSubnet, Gateway: DefaultGateway, Builtin: true},
+		{ID: builtinNetworkID(string(NetworkModeHost)), Name: string(NetworkModeHost), Driver: DriverHost, Builtin: true},
+		{ID: builtinNetworkID(string(NetworkModeNone)), Name: string(NetworkModeNone), Driver: DriverNull, Builtin: true},
+	}
+}
+
+// builtinNetworkID 为内置网络生成稳定的 ID（名称的十六进制编码，补零到 64 位）
+func builtinNetworkID(name string) string {
+	id := fmt.Sprintf("%x", name)
+	return id + strings.Repeat("0", 64-len(id))
+}
+
+// networkRegistry 是 networks.json 的持久化格式
+type networkRegistry struct {
+	Networks map[string]*NetworkInfo `json:"networks"` // id -> network
+}
+
+// Store 管理用户自定义网络的元数据
+type Store struct {
+	dir      string // $MINIDOCKER_ROOT/network
+	metaPath string // $MINIDOCKER_ROOT/network/networks.json
+}
+
+// NewStore 创建网络注册表
+func NewStore(dataDir string) (*Store, error) {
+	dir := filepath.Join(dataDir, "network")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create network directory: %w", err)
+	}
+
+	return &Store{
+		dir:      dir,
+		metaPath: filepath.Join(dir, "networks.json"),
+	}, nil
+}
+
+// CreateOptions 是 network create 的参数
+type CreateOptions struct {
+	Name    string
+	Driver  string
+	Subnet  string // 可选，空表示从地址池自动分配
+	Gateway string // 可选，空表示子网的第一个地址
+
+	// Phase 21: 双栈网络
+	EnableIPv6 bool
+	SubnetV6   string // 可选，空表示根据网络 ID 生成 ULA /64 子网
+	GatewayV6  string // 可选，空表示 IPv6 子网的第一个地址
+
+	// Phase 21: bridge 与容器网卡的 MTU，0 表示内核默认值
+	MTU int
+
+	// Phase 21: 禁止容器之间互相访问（--opt icc=false）
+	DisableICC bool
+}
+
+// Create 注册一个新的 bridge 网络（不创建宿主机资源，bridge 在首个容器接入时创建）
+func (s *Store) Create(opts CreateOptions) (*NetworkInfo, error) {
+	if !networkNameRegex.MatchString(opts.Name) {
+		return nil, fmt.Errorf("invalid network name: %s (must start with an alphanumeric character and contain only [a-zA-Z0-9_.-], 1-64 chars)", opts.Name)
+	}
+	if IsBuiltinNetwork(opts.Name) || strings.HasPrefix(opts.Name, NetworkModeContainerPrefix) {
+		return nil, fmt.Errorf("network name %s is reserved", opts.Name)
+	}
+	if opts.Driver == "" {
+		opts.Driver = DriverBridge
+	}
+	if opts.Driver != DriverBridge {
+		return nil, fmt.Errorf("unsupported network driver: %s (supported: %s)", opts.Driver, DriverBridge)
+	}
+	if !opts.EnableIPv6 && (opts.SubnetV6 != "" || opts.GatewayV6 != "") {
+		return nil, fmt.Errorf("an IPv6 subnet or gateway requires --ipv6")
+	}
+	if opts.MTU != 0 {
+		// IPv6 要求链路 MTU 至少为 1280
+		minMTU := 68
+		if opts.EnableIPv6 {
+			minMTU = 1280
+		}
+		if opts.MTU < minMTU || opts.MTU > 65535 {
+			return nil, fmt.Errorf("invalid MTU %d: must be between %d and 65535", opts.MTU, minMTU)
+		}
+	}
+
+	id := idutil.GenerateID()
+	info := &NetworkInfo{
+		ID:         id,
+		Name:       opts.Name,
+		Driver:     opts.Driver,
+		BridgeName: "md-" + id[:12],
+		MTU:        opts.MTU,
+		DisableICC: opts.DisableICC,
+		CreatedAt:  time.Now(),
+	}
+
+	err := s.update(func(reg *networkRegistry) error {
+		for _, existing := range reg.Networks {
+			if existing.Name == opts.Name {
+				return fmt.Errorf("network with name %s already exists", opts.Name)
+			}
+		}
+
+		subnet, gateway, err := chooseSubnet(reg, opts.Subnet, opts.Gateway)
+		if err != nil {
+			return err
+		}
+		info.Subnet = subnet.String()
+		info.Gateway = gateway.String()
+
+		if opts.EnableIPv6 {
+			subnet6, gateway6, err := chooseSubnetV6(reg, id, opts.SubnetV6, opts.GatewayV6)
+			if err != nil {
+				return err
+			}
+			info.EnableIPv6 = true
+			info.SubnetV6 = subnet6.String()
+			info.GatewayV6 = gateway6.String()
+		}
+
+		reg.Networks[id] = info
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// chooseSubnet 校验用户指定的子网/网关，或从地址池中选择一个未被占用的子网
+func chooseSubnet(reg *networkRegistry, subnetStr, gatewayStr string) (*net.IPNet, net.IP, error) {
+	// 已占用的子网（包括默认 bridge 网络）
+	used := []*net.IPNet{}
+	_, defaultNet, _ := net.ParseCIDR(DefaultSubnet)
+	used = append(used, defaultNet)
+	for _, n := range reg.Networks {
+		if _, ipnet, err := net.ParseCIDR(n.Subnet); err == nil {
+			used = append(used, ipnet)
+		}
+	}
+	overlaps := func(candidate *net.IPNet) bool {
+		for _, u := range used {
+			if u.Contains(candidate.IP) || candidate.Contains(u.IP) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var subnet *net.IPNet
+	if subnetStr != "" {
+		ip, ipnet, err := net.ParseCIDR(subnetStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid subnet %q: %w", subnetStr, err)
+		}
+		if ip.To4() == nil {
+			return nil, nil, fmt.Errorf("invalid subnet %q: only IPv4 is supported", subnetStr)
+		}
+		if ones, bits := ipnet.Mask.Size(); bits-ones < 2 {
+			return nil, nil, fmt.Errorf("invalid subnet %q: too small", subnetStr)
+		}
+		if overlaps(ipnet) {
+			return nil, nil, fmt.Errorf("subnet %s overlaps with an existing network", ipnet.String())
+		}
+		subnet = ipnet
+	} else {
+		if gatewayStr != "" {
+			return nil, nil, fmt.Errorf("--gateway requires --subnet")
+		}
+		for i := userSubnetFirst; i <= userSubnetLast && subnet == nil; i++ {
+			_, candidate, _ := net.ParseCIDR(fmt.Sprintf("172.%d.0.0/16", i))
+			if !overlaps(candidate) {
+				subnet = candidate
+			}
+		}
+		if subnet == nil {
+			return nil, nil, fmt.Errorf("no available subnet in the default address pool, specify --subnet")
+		}
+	}
+
+	// 默认网关：子网的第一个地址
+	networkIP := binary.BigEndian.Uint32(subnet.IP.To4())
+	gateway := make(net.IP, 4)
+	binary.BigEndian.PutUint32(gateway, networkIP+1)
+	if gatewayStr != "" {
+		gateway = net.ParseIP(gatewayStr).To4()
+		if gateway == nil || !subnet.Contains(gateway) {
+			return nil, nil, fmt.Errorf("invalid gateway %q: must be an IPv4 address in subnet %s", gatewayStr, subnet.String())
+		}
+		// 网络地址和广播地址不能作为网关（与 AllocateAddress 的保留地址一致）
+		if gateway.Equal(subnet.IP) || gateway.Equal(ipv4Broadcast(subnet)) {
+			return nil, nil, fmt.Errorf("invalid gateway %q: address is reserved in subnet %s", gatewayStr, subnet.String())
+		}
+	}
+	return subnet, gateway, nil
+}
+
+// chooseSubnetV6 校验用户指定的 IPv6 子网/网关（Phase 21 新增）。
+// 未指定子网时按 RFC 4193 生成唯一本地地址（ULA）子网：fd + 网络 ID 前 40 位作为全局 ID，子网 ID 为 0。
+func chooseSubnetV6(reg *networkRegistry, id, subnetStr, gatewayStr string) (*net.IPNet, net.IP, error) {
+	var subnet *net.IPNet
+	if subnetStr != "" {
+		ip, ipnet, err := net.ParseCIDR(subnetStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid IPv6 subnet %q: %w", subnetStr, err)
+		}
+		if ip.To4() != nil {
+			return nil, nil, fmt.Errorf("invalid IPv6 subnet %q: not an IPv6 prefix", subnetStr)
+		}
+		if ones, bits := ipnet.Mask.Size(); bits-ones < 2 {
+			return nil, nil, fmt.Errorf("invalid IPv6 subnet %q: too small", subnetStr)
+		}
+		subnet = ipnet
+	} else {
+		if gatewayStr != "" {
+			return nil, nil, fmt.Errorf("an IPv6 --gateway requires an IPv6 --subnet")
+		}
+		_, subnet, _ = net.ParseCIDR(fmt.Sprintf("fd%s:%s:%s::/64", id[0:2], id[2:6], id[6:10]))
+	}
+
+	for _, n := range reg.Networks {
+		if _, used, err := net.ParseCIDR(n.SubnetV6); err == nil && (used.Contains(subnet.IP) || subnet.Contains(used.IP)) {
+			return nil, nil, fmt.Errorf("subnet %s overlaps with an existing network", subnet.String())
+		}
+	}
+
+	// 默认网关：子网的第一个地址
+	gateway := ipAdd(subnet.IP, 1)
+	if gatewayStr != "" {
+		gateway = net.ParseIP(gatewayStr)
+		if gateway == nil || gateway.To4() != nil || !subnet.Contains(gateway) {
+			return nil, nil, fmt.Errorf("invalid gateway %q: must be an IPv6 address in subnet %s", gatewayStr, subnet.String())
+		}
+		if gateway.Equal(subnet.IP) {
+			return nil, nil, fmt.Errorf("invalid gateway %q: address is reserved in subnet %s", gatewayStr, subnet.String())
+		}
+	}
+	return subnet, gateway, nil
+}
+
+// Get 通过名称、完整 ID 或 ID 前缀（至少 3 个字符）查找网络（包括内置网络）
+func (s *Store) Get(nameOrID string) (*NetworkInfo, error) {
+	networks, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// 名称和完整 ID 优先精确匹配
+	for _, n := range networks {
+		if n.Name == nameOrID || n.ID == nameOrID {
+			return n, nil
+		}
+	}
+
+	if err := idutil.ValidatePrefix(nameOrID); err != nil {
+		return nil, fmt.Errorf("network %s not found", nameOrID)
+	}
+
+	var match *NetworkInfo
+	for _, n := range networks {
+		if strings.HasPrefix(n.ID, nameOrID) {
+			if match != nil {
+				return nil, fmt.Errorf("ambiguous network ID prefix: %s", nameOrID)
+			}
+			match = n
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("network %s not found", nameOrID)
+	}
+	return match, nil
+}
+
+// List 返回内置网络和所有用户自定义网络
+func (s *Store) List() ([]*NetworkInfo, error) {
+	reg, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	networks := builtinNetworks()
+	for _, n := range reg.Networks {
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+// Delete 删除用户自定义网络的元数据和 IPAM 文件（宿主机资源由 Manager.RemoveNetwork 清理）
+func (s *Store) Delete(id string) error {
+	err := s.update(func(reg *networkRegistry) error {
+		if _, exists := reg.Networks[id]; !exists {
+			return fmt.Errorf("network %s not found", idutil.ShortID(id))
+		}
+		delete(reg.Networks, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{ipamFilePath(s.dir, id), ipam6FilePath(s.dir, id)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove IPAM file: %w", err)
+		}
+	}
+	return nil
+}
+
+// update 在文件锁保护下执行读-改-写（network 命令可能由多个 CLI 进程并发调用）
+func (s *Store) update(fn func(reg *networkRegistry) error) error {
+	lock, err := state.AcquireLock(s.dir)
+	if err != nil {
+		return fmt.Errorf("acquire networks lock: %w", err)
+	}
+	defer lock.Release()
+
+	reg, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(reg); err != nil {
+		return err
+	}
+	return s.save(reg)
+}
+
+// load 从磁盘读取 networks.json
+func (s *Store) load() (*networkRegistry, error) {
+	reg := &networkRegistry{Networks: make(map[string]*NetworkInfo)}
+
+	data, err := os.ReadFile(s.metaPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reg, nil
+		}
+		return nil, fmt.Errorf("read networks.json: %w", err)
+	}
+
+	if err := json.Unmarshal(data, reg); err != nil {
+		return nil, fmt.Errorf("parse networks.json: %w", err)
+	}
+	if reg.Networks == nil {
+		reg.Networks = make(map[string]*NetworkInfo)
+	}
+	return reg, nil
+}
+
+// save 原子写入 networks.json
+func (s *Store) save(reg *networkRegistry) error {
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal networks.json: %w", err)
+	}
+	if err := fileutil.AtomicWriteFile(s.metaPath, data, 0644); err != nil {
+		return fmt.Errorf("save networks.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+//go:build !linux
+// +build !linux
+
+package network
+
+import (
+	"fmt"
+	"time"
+)
+
+// 网络驱动
+const (
+	DriverBridge = "bridge"
+	DriverHost   = "host"
+	DriverNull   = "null"
+)
+
+// NetworkInfo 描述一个网络
+type NetworkInfo struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Driver     string    `json:"driver"`
+	BridgeName string    `json:"bridgeName,omitempty"`
+	Subnet     string    `json:"subnet,omitempty"`
+	Gateway    string    `json:"gateway,omitempty"`
+	EnableIPv6 bool      `json:"enableIPv6,omitempty"`
+	SubnetV6   string    `json:"subnetV6,omitempty"`
+	GatewayV6  string    `json:"gatewayV6,omitempty"`
+	MTU        int       `json:"mtu,omitempty"`
+	DisableICC bool      `json:"disableICC,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Builtin    bool      `json:"builtin,omitempty"`
+}
+
+// IsBuiltinNetwork 返回名称是否为内置网络（bridge/host/none）
+func IsBuiltinNetwork(name string) bool {
+	switch NetworkMode(name) {
+	case NetworkModeBridge, NetworkModeHost, NetworkModeNone:
+		return true
+	}
+	return false
+}
+
+// CreateOptions 是 network create 的参数
+type CreateOptions struct {
+	Name       string
+	Driver     string
+	Subnet     string
+	Gateway    string
+	EnableIPv6 bool
+	SubnetV6   string
+	GatewayV6  string
+	MTU        int
+	DisableICC bool
+}
+
+// Store 是网络注册表的 stub
+type Store struct{}
+
+// NewStore 在非 Linux 平台上返回错误
+func NewStore(dataDir string) (*Store, error) {
+	return nil, fmt.Errorf("network management is only supported on Linux")
+}
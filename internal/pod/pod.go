@@ -0,0 +1,212 @@
+//go:build linux
+// +build linux
+
+// Package pod 管理 pod：一组共享 network/IPC/UTS 命名空间和 cgroup 父目录的容器（Phase 15 新增）。
+//
+// 每个 pod 有一个 infra（pause）容器，负责持有共享命名空间；成员容器通过
+// `run --pod` 以 container:<infra-id> 模式加入。pod 元数据保存在
+// $MINIDOCKER_ROOT/pods/pods.json，与容器状态（state.Store）并列。
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"minidocker/internal/state"
+	"minidocker/pkg/fileutil"
+	"minidocker/pkg/idutil"
+)
+
+// DefaultPodsDir 是 pod 元数据目录名
+const DefaultPodsDir = "pods"
+
+// Pod 描述一个 pod
+type Pod struct {
+	// ID 是 64 位十六进制 pod ID
+	ID string `json:"id"`
+
+	// Name 是 pod 名称（唯一）
+	Name string `json:"name"`
+
+	// CreatedAt 是创建时间
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Hostname 是 pod 共享的主机名（默认为 pod 名称）
+	Hostname string `json:"hostname"`
+
+	// NetworkMode 是 infra 容器的网络模式（bridge/host/none）
+	NetworkMode string `json:"networkMode"`
+
+	// PortMappings 是 pod 级端口发布，由 infra 容器的网络配置承载
+	PortMappings []state.PortMapping `json:"portMappings,omitempty"`
+
+	// CgroupParent 是 pod 内容器共享的 cgroup 父路径（minidocker/pod-<id>）
+	CgroupParent string `json:"cgroupParent"`
+
+	// InfraContainerID 是当前 infra（pause）容器 ID；pod start 重建 infra 后会更新
+	InfraContainerID string `json:"infraContainerId,omitempty"`
+}
+
+// registry 是 pods.json 的持久化格式
+type registry struct {
+	Pods map[string]*Pod `json:"pods"` // id -> pod
+}
+
+// Store 管理 pod 元数据
+type Store struct {
+	dir      string // $MINIDOCKER_ROOT/pods
+	metaPath string // $MINIDOCKER_ROOT/pods/pods.json
+}
+
+// NewStore 创建 pod 存储
+func NewStore(rootDir string) (*Store, error) {
+	dir := filepath.Join(rootDir, DefaultPodsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create pods directory: %w", err)
+	}
+
+	return &Store{
+		dir:      dir,
+		metaPath: filepath.Join(dir, "pods.json"),
+	}, nil
+}
+
+// Create 保存一个新 pod，名称必须唯一
+func (s *Store) Create(p *Pod) error {
+	return s.update(func(reg *registry) error {
+		if _, exists := reg.Pods[p.ID]; exists {
+			return fmt.Errorf("pod %s already exists", idutil.ShortID(p.ID))
+		}
+		for _, existing := range reg.Pods {
+			if existing.Name == p.Name {
+				return fmt.Errorf("pod name %q is already in use by pod %s", p.Name, idutil.ShortID(existing.ID))
+			}
+		}
+		reg.Pods[p.ID] = p
+		return nil
+	})
+}
+
+// Update 覆盖已存在 pod 的元数据
+func (s *Store) Update(p *Pod) error {
+	return s.update(func(reg *registry) error {
+		if _, exists := reg.Pods[p.ID]; !exists {
+			return fmt.Errorf("pod not found: %s", p.Name)
+		}
+		reg.Pods[p.ID] = p
+		return nil
+	})
+}
+
+// Delete 删除 pod 元数据
+func (s *Store) Delete(id string) error {
+	return s.update(func(reg *registry) error {
+		if _, exists := reg.Pods[id]; !exists {
+			return fmt.Errorf("pod not found: %s", idutil.ShortID(id))
+		}
+		delete(reg.Pods, id)
+		return nil
+	})
+}
+
+// Get 通过名称、完整 ID 或 ID 前缀（至少 3 个字符）查找 pod
+func (s *Store) Get(nameOrID string) (*Pod, error) {
+	reg, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	// 名称和完整 ID 优先精确匹配
+	for _, p := range reg.Pods {
+		if p.Name == nameOrID || p.ID == nameOrID {
+			return p, nil
+		}
+	}
+
+	if err := idutil.ValidatePrefix(nameOrID); err != nil {
+		return nil, fmt.Errorf("pod not found: %s", nameOrID)
+	}
+
+	var match *Pod
+	for _, p := range reg.Pods {
+		if strings.HasPrefix(p.ID, nameOrID) {
+			if match != nil {
+				return nil, fmt.Errorf("ambiguous pod ID prefix: %s", nameOrID)
+			}
+			match = p
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("pod not found: %s", nameOrID)
+	}
+	return match, nil
+}
+
+// List 返回所有 pod
+func (s *Store) List() ([]*Pod, error) {
+	reg, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	pods := make([]*Pod, 0, len(reg.Pods))
+	for _, p := range reg.Pods {
+		pods = append(pods, p)
+	}
+	return pods, nil
+}
+
+// update 在文件锁保护下执行读-改-写（pod 命令可能由多个 CLI 进程并发调用）
+func (s *Store) update(fn func(reg *registry) error) error {
+	lock, err := state.AcquireLock(s.dir)
+	if err != nil {
+		return fmt.Errorf("acquire pods lock: %w", err)
+	}
+	defer lock.Release()
+
+	reg, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(reg); err != nil {
+		return err
+	}
+	return s.save(reg)
+}
+
+// load 从磁盘读取 pods.json
+func (s *Store) load() (*registry, error) {
+	reg := &registry{Pods: make(map[string]*Pod)}
+
+	data, err := os.ReadFile(s.metaPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reg, nil
+		}
+		return nil, fmt.Errorf("read pods.json: %w", err)
+	}
+
+	if err := json.Unmarshal(data, reg); err != nil {
+		return nil, fmt.Errorf("parse pods.json: %w", err)
+	}
+	if reg.Pods == nil {
+		reg.Pods = make(map[string]*Pod)
+	}
+	return reg, nil
+}
+
+// save 原子写入 pods.json
+func (s *Store) save(reg *registry) error {
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pods.json: %w", err)
+	}
+	if err := fileutil.AtomicWriteFile(s.metaPath, data, 0644); err != nil {
+		return fmt.Errorf("save pods.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+package pod
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// DefaultPodsDir 是 pod 元数据目录名
+const DefaultPodsDir = "pods"
+
+// Pod 描述一个 pod
+type Pod struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	CreatedAt        time.Time `json:"createdAt"`
+	Hostname         string    `json:"hostname"`
+	NetworkMode      string    `json:"networkMode"`
+	CgroupParent     string    `json:"cgroupParent"`
+	InfraContainerID string    `json:"infraContainerId,omitempty"`
+}
+
+// Store 是 pod 存储的 stub
+type Store struct{}
+
+// NewStore 在非 Linux 平台上返回错误
+func NewStore(rootDir string) (*Store, error) {
+	return nil, fmt.Errorf("pods are only supported on Linux (current OS: %s)", runtime.GOOS)
+}
@@ -0,0 +1,509 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+)
+
+// AttachSocketName 是 shim 在容器目录下创建的 attach socket 文件名（Phase 18 新增）
+const AttachSocketName = "attach.sock"
+
+// DefaultDetachKeys 是默认的 detach 按键序列（对齐 Docker）
+const DefaultDetachKeys = "ctrl-p,ctrl-q"
+
+// attach 协议的流类型。
+//
+// 每一帧为 1 字节流类型 + 4 字节大端长度 + 负载：
+// - stdin（客户端 -> shim）：写入容器 stdin / PTY master
+// - stdout/stderr（shim -> 客户端）：容器输出（TTY 模式下只有 stdout）
+// - resize（客户端 -> shim）：2 字节 rows + 2 字节 cols，调整 PTY 大小
+// - exit（shim -> 客户端）：4 字节退出码，之后 shim 关闭连接
+const (
+	attachStreamStdin  byte = 0
+	attachStreamStdout byte = 1
+	attachStreamStderr byte = 2
+	attachStreamResize byte = 3
+	attachStreamExit   byte = 4
+)
+
+const (
+	// attachMaxFrame 限制单帧负载大小，防止异常客户端耗尽 shim 内存
+	attachMaxFrame = 1 << 20
+
+	// attachWriteTimeout 是向单个客户端写输出的超时；超时的客户端被断开
+	attachWriteTimeout = 5 * time.Second
+
+	// attachClientQueue 是每个客户端待发送的帧数上限。广播只把帧放入队列，由客户端各自的
+	// 写协程发送；队列满（客户端跟不上输出）时断开该客户端，不阻塞容器输出和日志写入
+	attachClientQueue = 256
+)
+
+// ErrDetached 表示 attach 客户端通过 detach 按键序列主动断开，容器继续运行
+var ErrDetached = errors.New("detached from container")
+
+// writeAttachFrame 写入一帧（单次 Write，保证并发写入时帧不交错）
+func writeAttachFrame(w io.Writer, stream byte, payload []byte) error {
+	_, err := w.Write(encodeAttachFrame(stream, payload))
+	return err
+}
+
+// encodeAttachFrame 编码一帧（复制负载，调用方可以复用 payload）
+func encodeAttachFrame(stream byte, payload []byte) []byte {
+	buf := make([]byte, 5+len(payload))
+	buf[0] = stream
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(payload)))
+	copy(buf[5:], payload)
+	return buf
+}
+
+// readAttachFrame 读取一帧
+func readAttachFrame(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:5])
+	if size > attachMaxFrame {
+		return 0, nil, fmt.Errorf("attach frame too large: %d bytes", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// withAttachSocketPath 以不超过 sun_path 长度限制（108 字节）的等价路径调用 fn。
+//
+// 容器目录包含 64 位 ID，绝对路径很容易超长；这里先打开目录，
+// 再通过 /proc/self/fd/<fd>/attach.sock 引用同一个 socket 文件。
+func withAttachSocketPath(containerDir string, fn func(path string) error) error {
+	fd, err := unix.Open(containerDir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("open container dir: %w", err)
+	}
+	defer unix.Close(fd)
+	return fn(fmt.Sprintf("/proc/self/fd/%d/%s", fd, AttachSocketName))
+}
+
+// attachServer 在 shim 中持有容器 stdio，并通过 attach socket 多路复用给
+// 任意数量的 attach 客户端（Phase 18 新增）。
+type attachServer struct {
+	socketPath string
+	listener   *net.UnixListener
+
+	mu      sync.Mutex
+	clients map[net.Conn]*attachClient
+	closed  bool
+
+	// writers 等待所有客户端写协程退出（close 时用于把退出码帧发送完）
+	writers sync.WaitGroup
+
+	// stdin 接收客户端输入；nil 表示容器未开启 -i，stdin 帧被丢弃
+	stdinMu sync.Mutex
+	stdin   io.Writer
+
+	// resize 调整 PTY 大小；nil 表示非 TTY 容器
+	resize func(ws *pty.Winsize)
+}
+
+// attachClient 是一个 attach 连接及其待发送的输出帧
+type attachClient struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+// newAttachServer 在容器目录下创建 attach socket
+func newAttachServer(containerDir string) (*attachServer, error) {
+	socketPath := filepath.Join(containerDir, AttachSocketName)
+	_ = os.Remove(socketPath)
+
+	var listener *net.UnixListener
+	err := withAttachSocketPath(containerDir, func(path string) error {
+		l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+		if err != nil {
+			return err
+		}
+		listener = l
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listen on attach socket: %w", err)
+	}
+	// 监听地址是 /proc/self/fd 路径，关闭时由 close 按真实路径删除
+	listener.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		_ = listener.Close()
+		_ = os.Remove(socketPath)
+		return nil, fmt.Errorf("chmod attach socket: %w", err)
+	}
+
+	return &attachServer{
+		socketPath: socketPath,
+		listener:   listener,
+		clients:    make(map[net.Conn]*attachClient),
+	}, nil
+}
+
+// serve 在后台接受 attach 连接；必须在设置好 stdin/resize 之后调用
+func (s *attachServer) serve() {
+	go func() {
+		for {
+			conn, err := s.listener.Accept()
+			if err != nil {
+				return // listener 已关闭
+			}
+			client := &attachClient{conn: conn, frames: make(chan []byte, attachClientQueue)}
+			s.mu.Lock()
+			if s.closed {
+				s.mu.Unlock()
+				_ = conn.Close()
+				return
+			}
+			s.clients[conn] = client
+			s.writers.Add(1)
+			s.mu.Unlock()
+			go s.writeClient(client)
+			go s.handleClient(conn)
+		}
+	}()
+}
+
+// handleClient 读取客户端的 stdin/resize 帧，直到连接断开
+func (s *attachServer) handleClient(conn net.Conn) {
+	defer s.removeClient(conn)
+
+	for {
+		stream, payload, err := readAttachFrame(conn)
+		if err != nil {
+			return
+		}
+
+		switch stream {
+		case attachStreamStdin:
+			s.stdinMu.Lock()
+			if s.stdin != nil {
+				_, _ = s.stdin.Write(payload)
+			}
+			s.stdinMu.Unlock()
+		case attachStreamResize:
+			if s.resize != nil && len(payload) == 4 {
+				s.resize(&pty.Winsize{
+					Rows: binary.BigEndian.Uint16(payload[0:2]),
+					Cols: binary.BigEndian.Uint16(payload[2:4]),
+				})
+			}
+		}
+	}
+}
+
+// writeClient 依次发送客户端队列中的帧，直到队列关闭；写失败或超时的客户端被断开
+func (s *attachServer) writeClient(client *attachClient) {
+	defer s.writers.Done()
+	defer client.conn.Close()
+
+	for frame := range client.frames {
+		_ = client.conn.SetWriteDeadline(time.Now().Add(attachWriteTimeout))
+		if _, err := client.conn.Write(frame); err != nil {
+			s.removeClient(client.conn)
+			// 排空队列，直到 removeClient 关闭它
+			for range client.frames {
+			}
+			return
+		}
+	}
+}
+
+// removeClient 断开并移除一个客户端
+func (s *attachServer) removeClient(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropClientLocked(conn)
+}
+
+// dropClientLocked 移除客户端并关闭其队列与连接（调用方持有 s.mu）
+func (s *attachServer) dropClientLocked(conn net.Conn) {
+	if client, ok := s.clients[conn]; ok {
+		delete(s.clients, conn)
+		close(client.frames)
+		_ = conn.Close()
+	}
+}
+
+// broadcast 将一帧放入所有客户端的发送队列（不阻塞），队列已满的客户端被断开
+func (s *attachServer) broadcast(stream byte, payload []byte) {
+	frame := encodeAttachFrame(stream, payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn, client := range s.clients {
+		select {
+		case client.frames <- frame:
+		default:
+			s.dropClientLocked(conn)
+		}
+	}
+}
+
+// output 返回一个把容器输出广播给客户端的 Writer（总是成功，不影响日志写入）
+func (s *attachServer) output(stream byte) io.Writer {
+	return attachOutput{server: s, stream: stream}
+}
+
+type attachOutput struct {
+	server *attachServer
+	stream byte
+}
+
+func (o attachOutput) Write(p []byte) (int, error) {
+	o.server.broadcast(o.stream, p)
+	return len(p), nil
+}
+
+// closeWithExit 通知所有客户端容器退出码，等待已排队的输出发送完，然后关闭连接与 socket
+func (s *attachServer) closeWithExit(exitCode int) {
+	var payload [4]byte
+	binary.BigEndian.PutUint32(payload[:], uint32(int32(exitCode)))
+	s.broadcast(attachStreamExit, payload[:])
+
+	_ = s.listener.Close()
+	_ = os.Remove(s.socketPath)
+
+	// 关闭队列：写协程发送完剩余的帧后关闭连接
+	s.mu.Lock()
+	s.closed = true
+	var conns []net.Conn
+	for conn, client := range s.clients {
+		delete(s.clients, conn)
+		close(client.frames)
+		conns = append(conns, conn)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.writers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(attachWriteTimeout):
+		// 仍未发送完的客户端直接断开
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}
+}
+
+// close 关闭 socket 与所有客户端连接，并删除 socket 文件
+func (s *attachServer) close() {
+	_ = s.listener.Close()
+	_ = os.Remove(s.socketPath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.clients {
+		s.dropClientLocked(conn)
+	}
+}
+
+// AttachOptions 配置 attach 客户端
+type AttachOptions struct {
+	// Stdin 为 nil 时不转发输入（容器未开启 -i 或 --no-stdin）
+	Stdin io.Reader
+
+	// Stdout/Stderr 接收容器输出
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// TTY 表示容器使用 PTY：本地终端切换为 raw mode，并同步窗口大小
+	TTY bool
+
+	// DetachKeys 是 detach 按键序列（见 ParseDetachKeys），为空时不检测
+	DetachKeys []byte
+}
+
+// Attach 连接到容器 shim 的 attach socket，转发输入输出直到容器退出或用户 detach。
+//
+// 返回容器退出码；用户通过 detach 按键断开时返回 ErrDetached（容器继续运行）。
+func Attach(containerDir string, opts *AttachOptions) (int, error) {
+	var conn *net.UnixConn
+	err := withAttachSocketPath(containerDir, func(path string) error {
+		c, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+		if err != nil {
+			return err
+		}
+		conn = c
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) || errors.Is(err, unix.ECONNREFUSED) {
+			return -1, fmt.Errorf("no attach socket (only containers started with -d can be attached)")
+		}
+		return -1, fmt.Errorf("connect to attach socket: %w", err)
+	}
+	defer conn.Close()
+
+	// 输入与 resize 可能并发写连接，需要串行化
+	var writeMu sync.Mutex
+	send := func(stream byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeAttachFrame(conn, stream, payload)
+	}
+
+	if opts.TTY && opts.Stdin != nil {
+		defer rawStdin(true)()
+		defer watchResize(func(ws *pty.Winsize) {
+			var payload [4]byte
+			binary.BigEndian.PutUint16(payload[0:2], ws.Rows)
+			binary.BigEndian.PutUint16(payload[2:4], ws.Cols)
+			_ = send(attachStreamResize, payload[:])
+		})()
+	}
+
+	type result struct {
+		exitCode int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		for {
+			stream, payload, err := readAttachFrame(conn)
+			if err != nil {
+				done <- result{-1, fmt.Errorf("attach connection closed before container exited: %w", err)}
+				return
+			}
+			switch stream {
+			case attachStreamStdout:
+				_, _ = opts.Stdout.Write(payload)
+			case attachStreamStderr:
+				_, _ = opts.Stderr.Write(payload)
+			case attachStreamExit:
+				code := -1
+				if len(payload) == 4 {
+					code = int(int32(binary.BigEndian.Uint32(payload)))
+				}
+				done <- result{code, nil}
+				return
+			}
+		}
+	}()
+
+	detached := make(chan struct{})
+	if opts.Stdin != nil {
+		// 注意：stdin 读取可能阻塞，不能等待它退出
+		go func() {
+			keys := &detachKeyMatcher{keys: opts.DetachKeys}
+			buf := make([]byte, 32*1024)
+			for {
+				n, err := opts.Stdin.Read(buf)
+				if n > 0 {
+					out, detach := keys.feed(buf[:n])
+					if len(out) > 0 {
+						if send(attachStreamStdin, out) != nil {
+							return
+						}
+					}
+					if detach {
+						close(detached)
+						return
+					}
+				}
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	select {
+	case res := <-done:
+		return res.exitCode, res.err
+	case <-detached:
+		return 0, ErrDetached
+	}
+}
+
+// detachKeyMatcher 在输入流中识别 detach 按键序列（可能跨多次读取）。
+// 部分匹配的按键会被暂存，匹配失败时原样补发。
+type detachKeyMatcher struct {
+	keys    []byte
+	matched int
+}
+
+// feed 处理一段输入，返回应转发给容器的字节，以及是否触发 detach
+func (m *detachKeyMatcher) feed(in []byte) ([]byte, bool) {
+	if len(m.keys) == 0 {
+		return in, false
+	}
+
+	out := make([]byte, 0, len(in)+m.matched)
+	for _, b := range in {
+		if b == m.keys[m.matched] {
+			m.matched++
+			if m.matched == len(m.keys) {
+				m.matched = 0
+				return out, true
+			}
+			continue
+		}
+
+		out = append(out, m.keys[:m.matched]...)
+		m.matched = 0
+		if b == m.keys[0] {
+			m.matched = 1
+			continue
+		}
+		out = append(out, b)
+	}
+	return out, false
+}
+
+// ParseDetachKeys 解析 --detach-keys（对齐 Docker）：逗号分隔的按键，
+// 每个按键为单个字符或 ctrl-<value>（<value> 为 a-z、@、[、\、]、^、_）。
+func ParseDetachKeys(spec string) ([]byte, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	var keys []byte
+	for _, key := range strings.Split(spec, ",") {
+		if len(key) == 1 {
+			keys = append(keys, key[0])
+			continue
+		}
+
+		value, ok := strings.CutPrefix(strings.ToLower(key), "ctrl-")
+		if !ok || len(value) != 1 {
+			return nil, fmt.Errorf("invalid detach key %q (expected a single character or ctrl-<value>)", key)
+		}
+		switch c := value[0]; {
+		case c >= 'a' && c <= 'z':
+			keys = append(keys, c-'a'+1)
+		case c == '@':
+			keys = append(keys, 0)
+		case c == '[', c == '\\', c == ']', c == '^', c == '_':
+			keys = append(keys, c-'['+27)
+		default:
+			return nil, fmt.Errorf("invalid detach key %q (ctrl-<value> requires a-z, @, [, \\, ], ^ or _)", key)
+		}
+	}
+	return keys, nil
+}
@@ -25,9 +25,12 @@ type ContainerConfig struct {
 	Hostname string
 
 	// TTY 指示是否分配伪终端。
-	// 第一阶段提供基本支持；完整的 PTY 处理在第五阶段。
+	// Phase 18: init 的 stdio 连接到 PTY slave，master 由前台 runtime 或 shim（-d）持有。
 	TTY bool
 
+	// Interactive 指示是否保持 STDIN 打开（-i，Phase 18 新增）
+	Interactive bool
+
 	// --- 未来阶段的字段（定义但未在第一阶段实现） ---
 
 	// Env 保存环境变量（第11阶段：-e KEY=VALUE）
@@ -65,6 +68,110 @@ type ContainerConfig struct {
 	// --- Phase 10: 卷挂载 ---
 	// Mounts 保存容器的挂载配置（bind mounts 和 named volumes）
 	Mounts []volume.Mount
+
+	// --- Phase 14: 设备管理 ---
+	// Devices 是通过 --device 映射进容器的宿主设备
+	Devices []Device
+
+	// Privileged 解除默认设备白名单（--privileged）
+	Privileged bool
+
+	// CgroupNS 是 cgroup namespace 模式（CgroupNSHost 或 CgroupNSPrivate）
+	CgroupNS string
+
+	// --- Phase 15: 命名空间共享 ---
+	// PidMode/IpcMode/UTSMode 控制 pid/ipc/uts 命名空间：私有（默认）、host 或 container:<id>。
+	// 网络命名空间的共享方式由 NetworkConfig.Mode 表达（container:<id>）。
+	PidMode NamespaceMode
+	IpcMode NamespaceMode
+	UTSMode NamespaceMode
+
+	// Pod 是容器所属 pod 的 ID（run --pod），空表示不属于任何 pod
+	Pod string
+
+	// CgroupParent 是容器 cgroup 的父路径（如 minidocker/pod-<id>），空表示 minidocker/
+	CgroupParent string
+
+	// --- Phase 17: 资源限制（rlimit）与内核参数 ---
+	// Ulimits 是 init 在 exec 用户命令前通过 prlimit 设置的进程资源限制（--ulimit）
+	Ulimits []Ulimit
+
+	// Sysctls 是写入容器 /proc/sys 的命名空间化内核参数（--sysctl）
+	Sysctls map[string]string
+
+	// --- Phase 19: 日志 ---
+	// LogDriver 是日志驱动（--log-driver），空表示默认的 json-file
+	LogDriver string
+
+	// LogOpts 是日志驱动选项（--log-opt），例如 json-file 的 max-size/max-file
+	LogOpts map[string]string
+
+	// --- Phase 21: 容器内的 /etc/hosts 与 /etc/resolv.conf ---
+	// DNS 是容器使用的 nameserver（--dns）；用户自定义网络上作为内嵌 DNS 的上游
+	DNS []string
+
+	// DNSSearch 是 resolv.conf 的 search 域（--dns-search）
+	DNSSearch []string
+
+	// DNSOptions 是 resolv.conf 的 options（--dns-option）
+	DNSOptions []string
+
+	// ExtraHosts 是追加到 /etc/hosts 的 "name:ip" 条目（--add-host）
+	ExtraHosts []string
+}
+
+// cgroup namespace 模式（Phase 14 新增，对齐 Docker --cgroupns）
+const (
+	// CgroupNSHost 与宿主共享 cgroup namespace
+	CgroupNSHost = "host"
+
+	// CgroupNSPrivate 在加入容器 cgroup 后创建私有 cgroup namespace，
+	// 并在 /sys/fs/cgroup 只读挂载容器自己的 cgroup 子树
+	CgroupNSPrivate = "private"
+)
+
+// Device 描述一个映射进容器的设备节点（Phase 14 新增）。
+// 设备信息在父进程中从宿主路径解析（pivot_root 后宿主路径不可见），
+// init 进程据此在容器 /dev 中 mknod。
+type Device struct {
+	// PathOnHost 宿主机上的设备路径，如 /dev/fuse
+	PathOnHost string
+
+	// PathInContainer 容器内的设备路径，默认与 PathOnHost 相同
+	PathInContainer string
+
+	// Permissions cgroup 访问权限（"rwm" 的子集）
+	Permissions string
+
+	// Type 设备类型："c"（字符设备）或 "b"（块设备）
+	Type string
+
+	// Major/Minor 设备号
+	Major int64
+	Minor int64
+
+	// FileMode 设备节点的权限位（来自宿主设备）
+	FileMode uint32
+
+	// UID/GID 设备节点属主（来自宿主设备）
+	UID uint32
+	GID uint32
+}
+
+// Ulimit 描述一个进程资源限制（Phase 17 新增，对齐 Docker --ulimit）。
+type Ulimit struct {
+	// Name 是资源名（如 nofile、nproc、core），见 ulimit.go 中的映射表
+	Name string
+
+	// Soft/Hard 是软/硬限制；RLIM_INFINITY 表示不限制
+	Soft uint64
+	Hard uint64
+}
+
+// needsCgroup 判断是否需要为容器创建独立 cgroup（Phase 14 新增）：
+// 资源限制、设备白名单，或私有 cgroup namespace（namespace 根必须是容器自己的 cgroup）。
+func (c *ContainerConfig) needsCgroup() bool {
+	return !c.CgroupConfig.IsEmpty() || c.CgroupNS == CgroupNSPrivate
 }
 
 // GenerateContainerID 生成一个随机的64个字符的十六进制字符串。
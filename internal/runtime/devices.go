@@ -0,0 +1,223 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/state"
+
+	"golang.org/x/sys/unix"
+)
+
+// DeviceFromPath 从宿主设备路径解析设备信息（Phase 14 新增）。
+//
+// 必须在父进程中调用：init 进程 pivot_root 后无法再访问宿主 /dev。
+// 只接受字符设备和块设备；符号链接会被解析（如 /dev/disk/by-id/*）。
+func DeviceFromPath(pathOnHost, pathInContainer, permissions string) (*Device, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(pathOnHost, &st); err != nil {
+		return nil, fmt.Errorf("stat device %s: %w", pathOnHost, err)
+	}
+
+	var devType string
+	switch st.Mode & unix.S_IFMT {
+	case unix.S_IFCHR:
+		devType = "c"
+	case unix.S_IFBLK:
+		devType = "b"
+	default:
+		return nil, fmt.Errorf("%s is not a character or block device", pathOnHost)
+	}
+
+	if pathInContainer == "" {
+		pathInContainer = pathOnHost
+	}
+
+	return &Device{
+		PathOnHost:      pathOnHost,
+		PathInContainer: pathInContainer,
+		Permissions:     permissions,
+		Type:            devType,
+		Major:           int64(unix.Major(uint64(st.Rdev))),
+		Minor:           int64(unix.Minor(uint64(st.Rdev))),
+		FileMode:        st.Mode &^ unix.S_IFMT,
+		UID:             st.Uid,
+		GID:             st.Gid,
+	}, nil
+}
+
+// deviceStagingDir 是容器目录下存放 --device 设备节点副本的目录
+const deviceStagingDir = "devices"
+
+// stagedDevicePath 返回第 i 个映射设备的节点副本路径
+func stagedDevicePath(containerDir string, i int) string {
+	return filepath.Join(containerDir, deviceStagingDir, strconv.Itoa(i))
+}
+
+// stageDevices 在容器目录中为 --device 映射的设备创建节点副本（由父进程在启动 init 前调用）。
+//
+// init 加入容器 cgroup 后受设备白名单限制，不能 mknod 白名单之外的设备；
+// 父进程不受限制，由它创建节点，init 只需 bind mount 副本，白名单保持用户指定的权限。
+// 副本属于容器自己，容器内的 chmod/chown 不会影响宿主的设备节点。
+func stageDevices(containerDir string, devices []Device) error {
+	dir := filepath.Join(containerDir, deviceStagingDir)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove staged devices: %w", err)
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create device staging dir: %w", err)
+	}
+
+	for i, d := range devices {
+		path := stagedDevicePath(containerDir, i)
+
+		var fileType uint32 = unix.S_IFCHR
+		if d.Type == "b" {
+			fileType = unix.S_IFBLK
+		}
+		dev := int(unix.Mkdev(uint32(d.Major), uint32(d.Minor)))
+		if err := unix.Mknod(path, fileType|d.FileMode, dev); err != nil {
+			return fmt.Errorf("mknod %s: %w", d.PathOnHost, err)
+		}
+
+		// mknod 受 umask 影响，显式恢复宿主设备的权限位和属主
+		if err := unix.Chmod(path, d.FileMode); err != nil {
+			return fmt.Errorf("chmod %s: %w", d.PathOnHost, err)
+		}
+		if err := unix.Chown(path, int(d.UID), int(d.GID)); err != nil {
+			return fmt.Errorf("chown %s: %w", d.PathOnHost, err)
+		}
+	}
+	return nil
+}
+
+// openStagedDevices 以 open_tree 克隆父进程创建的节点副本（init 在 pivot_root 前调用）。
+// pivot_root 后容器目录不再可见，克隆出的分离挂载仍可由 mountDevices 挂到容器 /dev 中。
+func openStagedDevices(containerDir string, devices []Device) ([]int, error) {
+	fds := make([]int, 0, len(devices))
+	for i, d := range devices {
+		fd, err := unix.OpenTree(unix.AT_FDCWD, stagedDevicePath(containerDir, i), unix.OPEN_TREE_CLONE|unix.OPEN_TREE_CLOEXEC)
+		if err != nil {
+			for _, opened := range fds {
+				unix.Close(opened)
+			}
+			return nil, fmt.Errorf("open staged device %s: %w", d.PathOnHost, err)
+		}
+		fds = append(fds, fd)
+	}
+	return fds, nil
+}
+
+// mountDevices 将 --device 映射的设备节点副本挂载到容器 /dev 中。
+// 在 pivot_root 与 mountDev 之后调用，路径均为容器内路径；挂载不需要 mknod 权限。
+func mountDevices(devices []Device, fds []int) error {
+	defer func() {
+		for _, fd := range fds {
+			unix.Close(fd)
+		}
+	}()
+
+	for i, d := range devices {
+		if err := os.MkdirAll(filepath.Dir(d.PathInContainer), 0755); err != nil {
+			return fmt.Errorf("create parent dir for %s: %w", d.PathInContainer, err)
+		}
+
+		// 目标路径可能已存在（例如与默认设备同名），先删除再创建挂载点
+		_ = os.Remove(d.PathInContainer)
+		target, err := os.OpenFile(d.PathInContainer, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("create mount point %s: %w", d.PathInContainer, err)
+		}
+		target.Close()
+
+		if err := unix.MoveMount(fds[i], "", unix.AT_FDCWD, d.PathInContainer, unix.MOVE_MOUNT_F_EMPTY_PATH); err != nil {
+			return fmt.Errorf("mount device %s: %w", d.PathInContainer, err)
+		}
+	}
+	return nil
+}
+
+// deviceCgroupRules 返回容器的设备白名单：默认设备 + --device 映射的设备（权限与用户指定的一致）。
+func deviceCgroupRules(devices []Device) []cgroups.DeviceRule {
+	rules := cgroups.DefaultDeviceRules()
+	for _, d := range devices {
+		rules = append(rules, cgroups.DeviceRule{
+			Type:        d.Type,
+			Major:       d.Major,
+			Minor:       d.Minor,
+			Permissions: d.Permissions,
+		})
+	}
+	return rules
+}
+
+// withDeviceRules 将设备白名单合并进 cgroup 配置，返回新的配置。
+//
+// 以下情况原样返回 cfg（不做设备限制）：
+// - privileged 容器
+// - cgroup v1 宿主（设备控制器依赖 cgroup v2 + eBPF）
+func withDeviceRules(cfg *cgroups.CgroupConfig, privileged bool, devices []Device) *cgroups.CgroupConfig {
+	if privileged || !cgroups.IsCgroupV2() {
+		return cfg
+	}
+
+	merged := &cgroups.CgroupConfig{}
+	if cfg != nil {
+		*merged = *cfg
+	}
+	merged.Devices = deviceCgroupRules(devices)
+	return merged
+}
+
+// toStateDevices 将运行时设备配置转换为持久化格式。
+func toStateDevices(devices []Device) []state.DeviceConfig {
+	if len(devices) == 0 {
+		return nil
+	}
+	out := make([]state.DeviceConfig, len(devices))
+	for i, d := range devices {
+		out[i] = state.DeviceConfig{
+			PathOnHost:      d.PathOnHost,
+			PathInContainer: d.PathInContainer,
+			Permissions:     d.Permissions,
+			Type:            d.Type,
+			Major:           d.Major,
+			Minor:           d.Minor,
+			FileMode:        d.FileMode,
+			UID:             d.UID,
+			GID:             d.GID,
+		}
+	}
+	return out
+}
+
+// fromStateDevices 从持久化格式恢复运行时设备配置。
+func fromStateDevices(devices []state.DeviceConfig) []Device {
+	if len(devices) == 0 {
+		return nil
+	}
+	out := make([]Device, len(devices))
+	for i, d := range devices {
+		out[i] = Device{
+			PathOnHost:      d.PathOnHost,
+			PathInContainer: d.PathInContainer,
+			Permissions:     d.Permissions,
+			Type:            d.Type,
+			Major:           d.Major,
+			Minor:           d.Minor,
+			FileMode:        d.FileMode,
+			UID:             d.UID,
+			GID:             d.GID,
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"net"
+	"strings"
+
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+	"minidocker/pkg/idutil"
+)
+
+// usesEmbeddedDNS 返回容器是否使用内嵌 DNS（Phase 21 新增）：主网卡接入用户自定义网络。
+// 默认 bridge/host/none 网络不启用（与 Docker 一致）。
+func usesEmbeddedDNS(ns *state.NetworkState) bool {
+	return ns != nil && ns.Mode == string(network.NetworkModeBridge) &&
+		ns.Network != "" && ns.Network != network.DefaultNetworkName
+}
+
+// startEmbeddedDNS 在容器网络命名空间内启动内嵌 DNS；未启用时返回 nil。
+// resolv.conf 由 writeEtcFiles 指向 127.0.0.11，二者都须在 releaseInit 之前完成。
+func startEmbeddedDNS(config *ContainerConfig, store *state.Store, containerDir string, ns *state.NetworkState, pid int) (*network.Resolver, error) {
+	if !usesEmbeddedDNS(ns) {
+		return nil, nil
+	}
+	return network.StartResolver(pid, embeddedUpstreams(config), &containerNames{
+		store:          store,
+		containerDir:   containerDir,
+		primaryNetwork: ns.Network,
+	})
+}
+
+// containerNames 在与容器共享网络的运行中容器里解析名称，实现 network.NameLookup
+type containerNames struct {
+	store        *state.Store
+	containerDir string
+
+	// primaryNetwork 是容器主网卡所在的网络（启动时确定，不依赖 state.json 是否已写入）
+	primaryNetwork string
+}
+
+// containerAddr 是容器在某个网络上的地址与别名
+type containerAddr struct {
+	ip      string
+	ip6     string // Phase 21: 双栈网络上的 IPv6 地址
+	aliases []string
+}
+
+// networkAddrs 返回容器在各网络上的地址（网络名 -> 地址），包括主网卡和 network connect 附加的网卡
+func networkAddrs(ns *state.NetworkState) map[string]containerAddr {
+	addrs := make(map[string]containerAddr)
+	if ns == nil || ns.Mode != string(network.NetworkModeBridge) {
+		return addrs
+	}
+	primary := ns.Network
+	if primary == "" {
+		primary = network.DefaultNetworkName
+	}
+	addrs[primary] = containerAddr{ip: ns.IPAddress, ip6: ns.IPv6Address, aliases: ns.Aliases}
+	for _, ep := range ns.Endpoints {
+		addrs[ep.Network] = containerAddr{ip: ep.IPAddress, ip6: ep.IPv6Address, aliases: ep.Aliases}
+	}
+	return addrs
+}
+
+// networks 返回当前容器接入的网络（每次查询重新读取 state.json，以反映 network connect/disconnect）
+func (n *containerNames) networks() map[string]bool {
+	networks := map[string]bool{n.primaryNetwork: true}
+	if st, err := state.LoadState(n.containerDir); err == nil {
+		for name := range networkAddrs(st.NetworkState) {
+			networks[name] = true
+		}
+	}
+	return networks
+}
+
+// peer 是共享网络上的一个容器地址
+type peer struct {
+	id   string
+	name string
+	addr containerAddr
+}
+
+// peers 返回与当前容器共享网络的运行中容器地址（包括当前容器自身）
+func (n *containerNames) peers() []peer {
+	containers, err := n.store.List(false)
+	if err != nil {
+		return nil
+	}
+	networks := n.networks()
+
+	var peers []peer
+	for _, c := range containers {
+		name := n.store.NameStore.GetName(c.ID)
+		for netName, addr := range networkAddrs(c.NetworkState) {
+			if networks[netName] && addr.ip != "" {
+				peers = append(peers, peer{id: c.ID, name: name, addr: addr})
+			}
+		}
+	}
+	return peers
+}
+
+// LookupHost 按容器名、别名、完整 ID 或 12 位短 ID 解析地址（不区分大小写），双栈网络同时返回 IPv6 地址
+func (n *containerNames) LookupHost(name string) []net.IP {
+	var ips []net.IP
+	seen := make(map[string]bool)
+	for _, p := range n.peers() {
+		if !p.matches(name) {
+			continue
+		}
+		for _, addr := range []string{p.addr.ip, p.addr.ip6} {
+			if ip := net.ParseIP(addr); ip != nil && !seen[addr] {
+				seen[addr] = true
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
+
+// LookupAddr 返回共享网络上 IP 所属容器的名称（未命名时使用短 ID）
+func (n *containerNames) LookupAddr(ip net.IP) string {
+	for _, p := range n.peers() {
+		if p.addr.ip == ip.String() || p.addr.ip6 == ip.String() {
+			if p.name != "" {
+				return p.name
+			}
+			return idutil.ShortID(p.id)
+		}
+	}
+	return ""
+}
+
+func (p peer) matches(name string) bool {
+	if name == "" {
+		return false
+	}
+	if strings.EqualFold(p.name, name) || p.id == name || idutil.ShortID(p.id) == name {
+		return true
+	}
+	for _, alias := range p.addr.aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"minidocker/internal/network"
+	"minidocker/internal/state"
+	"minidocker/pkg/fileutil"
+)
+
+// 容器目录下由 runtime 生成、启动时挂载到容器 /etc 的文件（Phase 21 新增）
+const (
+	hostsFile      = "hosts"
+	hostnameFile   = "hostname"
+	resolvConfFile = "resolv.conf"
+
+	// hostHostsPath 是宿主机的 /etc/hosts（host 网络模式沿用）
+	hostHostsPath = "/etc/hosts"
+)
+
+// managedEtcFiles 是 init 在 pivot_root 前挂载到 rootfs/etc 下的文件
+var managedEtcFiles = []string{hostsFile, hostnameFile, resolvConfFile}
+
+// defaultHosts 是容器 /etc/hosts 的固定条目（与 Docker 一致）
+const defaultHosts = `127.0.0.1	localhost
+::1	localhost ip6-localhost ip6-loopback
+fe00::0	ip6-localnet
+ff00::0	ip6-mcastprefix
+ff02::1	ip6-allnodes
+ff02::2	ip6-allrouters
+`
+
+// writeEtcFiles 在容器目录生成 hosts、hostname 和 resolv.conf（Phase 21 新增）。
+//
+// 必须在网络配置完成后（容器 IP 已知）、releaseInit 之前调用：init 在放行后才准备 rootfs。
+//   - bridge/none：hosts 为固定条目 + --add-host + "<容器 IP> <hostname>"；
+//     resolv.conf 使用 --dns 或宿主机配置（去掉容器内不可达的 loopback nameserver）
+//   - 用户自定义网络：resolv.conf 指向内嵌 DNS（127.0.0.11），--dns 作为其上游
+//   - host：沿用宿主机的 hosts/resolv.conf（--dns/--add-host 仍然生效）
+//   - container:<id>：沿用目标容器生成的 hosts/resolv.conf
+//
+// hostname 只在私有 UTS namespace 下生成。
+func writeEtcFiles(config *ContainerConfig, containerDir string, ns *state.NetworkState, store *state.Store) error {
+	if config.NetworkConfig.IsEmpty() {
+		return nil
+	}
+	mode := config.NetworkConfig.GetMode()
+
+	files := make(map[string]string)
+	if config.UTSMode.IsPrivate() {
+		files[hostnameFile] = config.GetHostname() + "\n"
+	}
+
+	if mode.IsContainer() {
+		targetDir := store.ContainerDir(mode.ConnectedContainer())
+		for _, name := range []string{hostsFile, resolvConfFile} {
+			if data, err := os.ReadFile(filepath.Join(targetDir, name)); err == nil {
+				files[name] = string(data)
+			}
+		}
+	} else {
+		files[hostsFile] = buildHosts(config, ns)
+		files[resolvConfFile] = buildResolvConf(config, ns).String()
+	}
+
+	for _, name := range managedEtcFiles {
+		path := filepath.Join(containerDir, name)
+		content, ok := files[name]
+		if !ok {
+			_ = os.Remove(path)
+			continue
+		}
+		if err := fileutil.AtomicWriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// buildHosts 生成容器的 /etc/hosts
+func buildHosts(config *ContainerConfig, ns *state.NetworkState) string {
+	var b strings.Builder
+	if config.NetworkConfig.GetMode() == network.NetworkModeHost {
+		data, err := os.ReadFile(hostHostsPath)
+		if err != nil {
+			data = []byte(defaultHosts)
+		}
+		b.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			b.WriteByte('\n')
+		}
+	} else {
+		b.WriteString(defaultHosts)
+	}
+
+	for _, entry := range config.ExtraHosts {
+		if name, ip, ok := strings.Cut(entry, ":"); ok {
+			b.WriteString(ip + "\t" + name + "\n")
+		}
+	}
+	if config.UTSMode.IsPrivate() && ns != nil {
+		// Phase 21: 双栈网络同时写入 IPv6 地址
+		for _, ip := range []string{ns.IPAddress, ns.IPv6Address} {
+			if ip != "" {
+				b.WriteString(ip + "\t" + config.GetHostname() + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// buildResolvConf 生成容器的 /etc/resolv.conf
+func buildResolvConf(config *ContainerConfig, ns *state.NetworkState) network.ResolvConf {
+	host := network.ReadResolvConf(network.HostResolvConfPath)
+	rc := network.ResolvConf{
+		Nameservers: config.DNS,
+		Search:      config.DNSSearch,
+		Options:     config.DNSOptions,
+	}
+	if len(rc.Search) == 0 {
+		rc.Search = host.Search
+	} else if len(rc.Search) == 1 && rc.Search[0] == "." {
+		// --dns-search . 表示不设置 search 域（与 Docker 一致）
+		rc.Search = nil
+	}
+
+	switch {
+	case usesEmbeddedDNS(ns):
+		// 外部查询由内嵌 DNS 转发；ndots:0 让单标签的容器名不先拼接 search 域
+		rc.Nameservers = []string{network.ResolverAddress}
+		if len(rc.Options) == 0 {
+			rc.Options = []string{"ndots:0"}
+			for _, opt := range host.Options {
+				if !strings.HasPrefix(opt, "ndots:") {
+					rc.Options = append(rc.Options, opt)
+				}
+			}
+		}
+	case config.NetworkConfig.GetMode() == network.NetworkModeHost:
+		if len(rc.Nameservers) == 0 {
+			rc.Nameservers = host.Nameservers
+		}
+		if len(rc.Options) == 0 {
+			rc.Options = host.Options
+		}
+	default:
+		if len(rc.Nameservers) == 0 {
+			rc.Nameservers = network.RemoteNameservers(host.Nameservers)
+		}
+		if len(rc.Nameservers) == 0 {
+			rc.Nameservers = network.DefaultNameservers
+		}
+		if len(rc.Options) == 0 {
+			rc.Options = host.Options
+		}
+	}
+	return rc
+}
+
+// embeddedUpstreams 返回内嵌 DNS 的上游：--dns 优先，其次宿主机的 nameserver。
+// 转发在宿主机网络命名空间中进行，宿主机的 loopback 解析器同样可用。
+func embeddedUpstreams(config *ContainerConfig) []string {
+	if len(config.DNS) > 0 {
+		return config.DNS
+	}
+	if nameservers := network.ReadResolvConf(network.HostResolvConfPath).Nameservers; len(nameservers) > 0 {
+		return nameservers
+	}
+	return network.DefaultNameservers
+}
@@ -0,0 +1,36 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"strconv"
+
+	"minidocker/internal/cgroups"
+	"minidocker/internal/events"
+)
+
+// logContainerEvent 记录一条容器事件（Phase 20 新增），附带容器名称与镜像。
+// 事件日志只用于观测，写入失败时忽略。
+func logContainerEvent(rootDir string, config *ContainerConfig, action string, extra map[string]string) {
+	attrs := map[string]string{
+		"name":  config.Name,
+		"image": config.Image,
+	}
+	for key, value := range extra {
+		attrs[key] = value
+	}
+	_ = events.Log(rootDir, events.TypeContainer, action, config.ID, attrs)
+}
+
+// logExitEvents 在容器退出后、销毁 cgroup 之前记录 oom（发生过 OOM kill 时）与 die 事件
+func logExitEvents(rootDir string, config *ContainerConfig, exitCode int, cgroupManager cgroups.Manager, cgroupPath string) {
+	if cgroupManager != nil && cgroupPath != "" {
+		if stats, err := cgroupManager.GetStats(cgroupPath); err == nil && stats.OOMKillCount > 0 {
+			logContainerEvent(rootDir, config, events.ActionOOM, nil)
+		}
+	}
+	logContainerEvent(rootDir, config, events.ActionDie, map[string]string{
+		"exitCode": strconv.Itoa(exitCode),
+	})
+}
@@ -132,6 +132,7 @@ func joinNamespaces(pid int) error {
 		{"ipc", unix.CLONE_NEWIPC},
 		{"uts", unix.CLONE_NEWUTS},
 		{"pid", unix.CLONE_NEWPID},
+		{"cgroup", unix.CLONE_NEWCGROUP}, // Phase 14: 私有 cgroup namespace
 		{"mnt", unix.CLONE_NEWNS},
 		// 注意：CLONE_NEWNET 将在 Phase 7 添加
 		// 注意：CLONE_NEWUSER 将在 Phase 16 添加
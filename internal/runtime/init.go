@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	goruntime "runtime"
 	"strconv"
 	"strings"
 	"syscall"
@@ -31,7 +32,18 @@ import (
 // 3. 以主子进程的退出代码退出
 //
 // 此设计与 tini/dumb-init 的行为一致。
+//
+// Phase 14 更新：
+// - 启动前等待父进程放行（已加入容器 cgroup、网络已配置）
+// - 私有 cgroup namespace 模式下 unshare(CLONE_NEWCGROUP)，使容器 cgroup 成为 namespace 根
+//
+// Phase 17 更新：
+// - exec 用户命令前通过 prlimit 设置 --ulimit
 func RunContainerInit() {
+	// unshare(CLONE_NEWCGROUP) 只影响当前 OS 线程；锁线程确保后续挂载与 fork/exec
+	// 都发生在同一线程上（与 exec.go 中 setns 的处理一致）。
+	goruntime.LockOSThread()
+
 	// 从环境获取容器配置
 	config, err := getConfig()
 	if err != nil {
@@ -39,12 +51,32 @@ func RunContainerInit() {
 		os.Exit(1)
 	}
 
+	// Phase 14: 等待父进程完成 cgroup 加入与网络配置
+	if err := waitForParent(); err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Phase 14: 私有 cgroup namespace（必须在加入容器 cgroup 之后创建）
+	if config.CgroupNS == CgroupNSPrivate {
+		if err := unix.Unshare(unix.CLONE_NEWCGROUP); err != nil {
+			fmt.Fprintf(os.Stderr, "init: unshare cgroup namespace: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// 设置容器环境
 	if err := setupContainerEnvironment(config); err != nil {
 		fmt.Fprintf(os.Stderr, "init: setup failed: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Phase 17: 设置资源限制（在 switchUser 之前，提升硬限制需要 root）
+	if err := applyUlimits(config.Ulimits); err != nil {
+		fmt.Fprintf(os.Stderr, "init: set ulimits: %v\n", err)
+		os.Exit(1)
+	}
+
 	// 运行用户命令并处理信号
 	exitCode := runUserCommand(config)
 	os.Exit(exitCode)
@@ -85,12 +117,25 @@ func getConfig() (*ContainerConfig, error) {
 		TTY:        cfg.TTY,
 		Rootfs:     cfg.Rootfs,
 		Detached:   cfg.Detached,
-		Name:       cfg.Name,       // Phase 11
-		Env:        cfg.Env,        // Phase 11
-		WorkingDir: cfg.WorkingDir, // Phase 11
-		User:       cfg.User,       // Phase 11
+		Name:       cfg.Name,                      // Phase 11
+		Env:        cfg.Env,                       // Phase 11
+		WorkingDir: cfg.WorkingDir,                // Phase 11
+		User:       cfg.User,                      // Phase 11
+		Privileged: cfg.Privileged,                // Phase 14
+		Devices:    fromStateDevices(cfg.Devices), // Phase 14
+		CgroupNS:   cfg.CgroupNS,                  // Phase 14
+		PidMode:    NamespaceMode(cfg.PidMode),    // Phase 15
+		IpcMode:    NamespaceMode(cfg.IpcMode),    // Phase 15
+		UTSMode:    NamespaceMode(cfg.UTSMode),    // Phase 15
 	}
 
+	// Phase 18: -i 保持 STDIN 打开
+	config.Interactive = cfg.Interactive
+
+	// Phase 17: 资源限制与内核参数
+	config.Ulimits = fromStateUlimits(cfg.Ulimits)
+	config.Sysctls = cfg.Sysctls
+
 	// Phase 10: 加载挂载配置
 	if len(cfg.Mounts) > 0 {
 		config.Mounts = make([]volume.Mount, len(cfg.Mounts))
@@ -108,6 +153,28 @@ func getConfig() (*ContainerConfig, error) {
 	return config, nil
 }
 
+// waitForParent 阻塞直到父进程通过同步管道放行（Phase 14 新增）。
+// 未设置同步 fd 时直接返回（兼容旧的启动方式）；读到 EOF 表示父进程放弃启动。
+func waitForParent() error {
+	fdStr := os.Getenv(envutil.InitSyncFdEnvVar)
+	if strings.TrimSpace(fdStr) == "" {
+		return nil
+	}
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", envutil.InitSyncFdEnvVar, err)
+	}
+
+	syncR := os.NewFile(uintptr(fd), "init-sync")
+	defer syncR.Close()
+
+	buf := make([]byte, 1)
+	if _, err := syncR.Read(buf); err != nil {
+		return fmt.Errorf("wait for parent: %w", err)
+	}
+	return nil
+}
+
 // setupContainerEnvironment 配置容器环境。
 // 这将在命名空间隔离到位后调用。
 func setupContainerEnvironment(config *ContainerConfig) error {
@@ -118,9 +185,12 @@ func setupContainerEnvironment(config *ContainerConfig) error {
 	}
 
 	// 1. 设置主机名（UTS namespace 必须被隔离）
-	hostname := config.GetHostname()
-	if err := unix.Sethostname([]byte(hostname)); err != nil {
-		return fmt.Errorf("failed to set hostname to %q: %w", hostname, err)
+	// Phase 15: 共享 UTS namespace（host/container:<id>）时沿用目标的主机名
+	if config.UTSMode.IsPrivate() {
+		hostname := config.GetHostname()
+		if err := unix.Sethostname([]byte(hostname)); err != nil {
+			return fmt.Errorf("failed to set hostname to %q: %w", hostname, err)
+		}
 	}
 
 	// 2. 将挂载传播设置为私有
@@ -183,6 +253,12 @@ func runUserCommand(config *ContainerConfig) int {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
+	// Phase 18: TTY 模式下用户命令成为新会话的首进程，并以 PTY slave（fd 0）为控制终端。
+	// 这样 shell 才有作业控制，Ctrl+C 产生的 SIGINT 也只发给前台进程组而不是 init。
+	if config.TTY && IsTerminal(os.Stdin) {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	}
+
 	// Phase 11: 设置环境变量
 	// 1. 从基础环境开始（过滤掉 MINIDOCKER_* 变量）
 	// 2. 合并用户指定的环境变量（覆盖同名变量）
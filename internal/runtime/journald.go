@@ -0,0 +1,80 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// defaultJournaldAddress 是 systemd-journald 原生协议的套接字
+const defaultJournaldAddress = "/run/systemd/journal/socket"
+
+// newJournaldDriver 创建 journald 驱动。
+//
+// 每行输出作为一个 journald 原生协议数据报发送（见 systemd.journal-fields(7)），包含：
+// MESSAGE、PRIORITY（stdout 为 6，stderr 为 3）、SYSLOG_IDENTIFIER（tag）、
+// CONTAINER_ID、CONTAINER_ID_FULL、CONTAINER_NAME、CONTAINER_TAG。
+//
+// --log-opt journald-address 可指定其他套接字（minidocker 扩展，便于测试）。
+func newJournaldDriver(config *ContainerConfig) (LogDriver, error) {
+	address, err := parseUnixgramAddress("journald-address", config.LogOpts["journald-address"], defaultJournaldAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := logTag(config)
+	shortID := config.ID
+	if len(shortID) > 12 {
+		shortID = shortID[:12]
+	}
+	fields := [][2]string{
+		{"SYSLOG_IDENTIFIER", tag},
+		{"CONTAINER_ID", shortID},
+		{"CONTAINER_ID_FULL", config.ID},
+		{"CONTAINER_NAME", config.Name},
+		{"CONTAINER_TAG", tag},
+	}
+
+	d, err := newDatagramLogDriver(address, func(stream string, line []byte) []byte {
+		priority := syslogSeverityInfo
+		if stream == LogStreamStderr {
+			priority = syslogSeverityErr
+		}
+
+		var buf bytes.Buffer
+		writeJournalField(&buf, "MESSAGE", line)
+		writeJournalField(&buf, "PRIORITY", []byte(strconv.Itoa(priority)))
+		for _, f := range fields {
+			if f[1] != "" {
+				writeJournalField(&buf, f[0], []byte(f[1]))
+			}
+		}
+		return buf.Bytes()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to journald at %s: %w", address, err)
+	}
+	return d, nil
+}
+
+// writeJournalField 按 journald 原生协议编码一个字段：
+// 值不含换行时为 KEY=VALUE\n；否则为 KEY\n + 64 位小端长度 + VALUE + \n
+func writeJournalField(buf *bytes.Buffer, key string, value []byte) {
+	if bytes.IndexByte(value, '\n') < 0 {
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.Write(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.Write(value)
+	buf.WriteByte('\n')
+}
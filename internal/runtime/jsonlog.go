@@ -0,0 +1,463 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONLogFileName 是 json-file 格式的容器日志文件名（Phase 19 新增）。
+//
+// 每行一条 JSON 记录（对齐 Docker json-file 驱动）：
+//
+//	{"log":"hello\n","stream":"stdout","time":"2024-01-02T03:04:05.123456789Z"}
+//
+// stdout 与 stderr 写入同一个文件，因此记录顺序即输出顺序。
+//
+// Phase 19 更新：配置 max-size 后按大小轮转，历史文件为
+// container-json.log.1（最新）到 container-json.log.<max-file - 1>（最旧）。
+const JSONLogFileName = "container-json.log"
+
+// Phase 3 ~ Phase 18 使用的原始日志文件（按流分开、不带时间戳）
+const (
+	legacyStdoutLog = "stdout.log"
+	legacyStderrLog = "stderr.log"
+)
+
+// 日志流名称
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+)
+
+// maxLogLineSize 是单条记录的最大长度；超过时按部分行切分（对齐 Docker 的 16K）
+const maxLogLineSize = 16 * 1024
+
+// LogRecord 是一条日志记录
+type LogRecord struct {
+	// Log 是一行输出（包含结尾的换行；部分行不包含）
+	Log    string    `json:"log"`
+	Stream string    `json:"stream"`
+	Time   time.Time `json:"time"`
+}
+
+// jsonLogOptions 是 json-file 日志的轮转配置（--log-opt）
+type jsonLogOptions struct {
+	// maxSize 是单个日志文件的最大字节数，0 表示不轮转
+	maxSize int64
+
+	// maxFiles 是保留的日志文件总数（包含当前文件）
+	maxFiles int
+}
+
+// parseJSONLogOpts 解析 json-file 的 --log-opt（对齐 Docker）：
+// - max-size：单个文件的最大大小，例如 10m（k/m/g 为 1024 进制），默认不限制
+// - max-file：保留的文件数（包含当前文件），默认 1；大于 1 时必须同时设置 max-size
+func parseJSONLogOpts(opts map[string]string) (jsonLogOptions, error) {
+	result := jsonLogOptions{maxFiles: 1}
+
+	for key, value := range opts {
+		switch key {
+		case "max-size":
+			size, err := parseLogSize(value)
+			if err != nil {
+				return result, fmt.Errorf("invalid log-opt max-size %q: %w", value, err)
+			}
+			result.maxSize = size
+		case "max-file":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return result, fmt.Errorf("invalid log-opt max-file %q (must be a positive integer)", value)
+			}
+			result.maxFiles = n
+		default:
+			return result, fmt.Errorf("unknown log-opt %q for json-file log driver (supported: max-size, max-file)", key)
+		}
+	}
+
+	if result.maxFiles > 1 && result.maxSize == 0 {
+		return result, fmt.Errorf("log-opt max-file can only be used together with max-size")
+	}
+	return result, nil
+}
+
+// parseLogSize 解析 max-size（如 "10m" -> 10485760），支持 b/k/kb/m/mb/g/gb 后缀
+func parseLogSize(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	numStr := strings.TrimSuffix(s, "b")
+
+	var multiplier int64 = 1
+	switch {
+	case strings.HasSuffix(numStr, "k"):
+		multiplier = 1024
+	case strings.HasSuffix(numStr, "m"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(numStr, "g"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		numStr = numStr[:len(numStr)-1]
+	}
+
+	num, err := strconv.ParseFloat(numStr, 64)
+	if err != nil || num <= 0 {
+		return 0, fmt.Errorf("expected a positive size such as 10m")
+	}
+	if num > float64(math.MaxInt64)/float64(multiplier) {
+		return 0, fmt.Errorf("size too large")
+	}
+	return int64(num * float64(multiplier)), nil
+}
+
+// jsonLogWriter 是 json-file 日志驱动：将多个输出流按行编码为 JSON 记录写入同一个日志文件
+type jsonLogWriter struct {
+	lineSplitter
+
+	path string
+	file *os.File
+	size int64
+	opts jsonLogOptions
+}
+
+// openJSONLog 以追加模式打开 json-file 日志
+func openJSONLog(logDir string, opts jsonLogOptions) (*jsonLogWriter, error) {
+	path := filepath.Join(logDir, JSONLogFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("create json log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat json log: %w", err)
+	}
+	w := &jsonLogWriter{path: path, file: file, size: info.Size(), opts: opts}
+	w.emit = w.writeRecord
+	return w, nil
+}
+
+// lineSplitter 将各输出流按行切分后交给 emit，供各日志驱动复用（Phase 19 新增）。
+// 不完整的行缓存到遇到换行或达到 maxLogLineSize；emit 调用时持有 mu。
+type lineSplitter struct {
+	mu      sync.Mutex
+	emit    func(stream string, line []byte) error
+	streams []*lineStream
+}
+
+// lineStream 是单个输出流的写入端
+type lineStream struct {
+	s      *lineSplitter
+	stream string
+	buf    []byte
+}
+
+// Stream 返回指定输出流的 Writer
+func (l *lineSplitter) Stream(name string) io.Writer {
+	st := &lineStream{s: l, stream: name}
+	l.streams = append(l.streams, st)
+	return st
+}
+
+func (st *lineStream) Write(p []byte) (int, error) {
+	st.s.mu.Lock()
+	defer st.s.mu.Unlock()
+
+	st.buf = append(st.buf, p...)
+	for {
+		i := bytes.IndexByte(st.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := st.s.emit(st.stream, st.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		st.buf = st.buf[i+1:]
+	}
+	for len(st.buf) >= maxLogLineSize {
+		if err := st.s.emit(st.stream, st.buf[:maxLogLineSize]); err != nil {
+			return 0, err
+		}
+		st.buf = st.buf[maxLogLineSize:]
+	}
+	return len(p), nil
+}
+
+// flush 写出各流中剩余的部分行（调用方持有 mu）
+func (l *lineSplitter) flush() {
+	for _, st := range l.streams {
+		if len(st.buf) > 0 {
+			_ = l.emit(st.stream, st.buf)
+			st.buf = nil
+		}
+	}
+}
+
+// writeRecord 写入一条记录，写入后超过 max-size 时先轮转（调用方持有 w.mu）
+func (w *jsonLogWriter) writeRecord(stream string, line []byte) error {
+	data, err := encodeLogRecord(&LogRecord{Log: string(line), Stream: stream, Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	if w.opts.maxSize > 0 && w.size > 0 && w.size+int64(len(data)) > w.opts.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(data)
+	w.size += int64(n)
+	return err
+}
+
+// rotate 轮转日志文件：.N-1 -> .N、...、当前文件 -> .1，然后创建新的当前文件。
+// max-file 为 1 时没有历史文件，直接截断当前文件。
+//
+// 先重命名再创建新文件，follow 模式的读取者持有的 fd 仍指向旧文件，
+// 读完剩余记录后再切换到新文件，因此轮转期间不会丢失记录。
+func (w *jsonLogWriter) rotate() error {
+	if w.opts.maxFiles < 2 {
+		if err := w.file.Truncate(0); err != nil {
+			return fmt.Errorf("truncate json log: %w", err)
+		}
+		w.size = 0
+		return nil
+	}
+
+	for i := w.opts.maxFiles - 1; i > 1; i-- {
+		if err := os.Rename(rotatedLogPath(w.path, i-1), rotatedLogPath(w.path, i)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotate json log: %w", err)
+		}
+	}
+	if err := os.Rename(w.path, rotatedLogPath(w.path, 1)); err != nil {
+		return fmt.Errorf("rotate json log: %w", err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create json log: %w", err)
+	}
+	w.file.Close()
+	w.file = file
+	w.size = 0
+	return nil
+}
+
+func rotatedLogPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// RotatedLogFiles 返回已轮转的历史日志文件，按从旧到新排序（不包含当前文件）
+func RotatedLogFiles(logDir string) ([]string, error) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	type rotated struct {
+		path string
+		n    int
+	}
+	var files []rotated
+	for _, e := range entries {
+		suffix, ok := strings.CutPrefix(e.Name(), JSONLogFileName+".")
+		if !ok {
+			continue
+		}
+		// 跳过迁移用的临时文件等非数字后缀
+		n, err := strconv.Atoi(suffix)
+		if err != nil || n < 1 {
+			continue
+		}
+		files = append(files, rotated{filepath.Join(logDir, e.Name()), n})
+	}
+
+	// 编号越大越旧
+	sort.Slice(files, func(i, j int) bool { return files[i].n > files[j].n })
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// Close 写出各流中剩余的部分行并关闭日志文件
+func (w *jsonLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flush()
+	return w.file.Close()
+}
+
+// writeLogRecord 编码并写入一条记录（单次 Write，避免行被截断交错）
+func writeLogRecord(w io.Writer, rec *LogRecord) error {
+	data, err := encodeLogRecord(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// encodeLogRecord 将记录编码为一行 JSON（包含结尾换行）
+func encodeLogRecord(rec *LogRecord) ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// DecodeLogRecords 解析 json-file 日志内容中的完整行。
+//
+// 返回解析出的记录与已消费的字节数；结尾不完整的行（写入中）不计入，
+// 以便 follow 模式下次从该偏移继续读取。无法解析的行被跳过。
+func DecodeLogRecords(data []byte) ([]LogRecord, int) {
+	var records []LogRecord
+	consumed := 0
+	for {
+		i := bytes.IndexByte(data[consumed:], '\n')
+		if i < 0 {
+			break
+		}
+		line := data[consumed : consumed+i]
+		consumed += i + 1
+
+		var rec LogRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, consumed
+}
+
+// ReadLegacyLogs 将 Phase 19 之前的原始日志（stdout.log / stderr.log）转换为记录。
+//
+// 原始日志没有逐行时间，所有记录使用文件的修改时间；两个流无法恢复交错顺序，
+// 按时间稳定排序后 stdout 在前。文件不存在时返回空。
+func ReadLegacyLogs(logDir string) ([]LogRecord, error) {
+	var records []LogRecord
+	for _, f := range []struct{ name, stream string }{
+		{legacyStdoutLog, LogStreamStdout},
+		{legacyStderrLog, LogStreamStderr},
+	} {
+		recs, err := readLegacyLog(filepath.Join(logDir, f.name), f.stream)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, recs...)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+func readLegacyLog(path, stream string) ([]LogRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	mtime := info.ModTime().UTC()
+
+	var records []LogRecord
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			records = append(records, LogRecord{Log: line, Stream: stream, Time: mtime})
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+	}
+}
+
+// MigrateLegacyLogs 将原始日志转换并合并到 json-file 日志的开头，然后删除原始日志。
+//
+// 只能在没有写入者时调用（容器已停止），否则旧版本 shim 仍在写入已删除的文件。
+func MigrateLegacyLogs(logDir string) error {
+	records, err := ReadLegacyLogs(logDir)
+	if err != nil {
+		return err
+	}
+	if records == nil {
+		return removeLegacyLogs(logDir)
+	}
+
+	jsonPath := filepath.Join(logDir, JSONLogFileName)
+	existing, err := os.ReadFile(jsonPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read json log: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(logDir, JSONLogFileName+".migrate-*")
+	if err != nil {
+		return fmt.Errorf("create migrated log: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for i := range records {
+		if err := writeLogRecord(w, &records[i]); err != nil {
+			tmp.Close()
+			return fmt.Errorf("write migrated log: %w", err)
+		}
+	}
+	if _, err := w.Write(existing); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write migrated log: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write migrated log: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod migrated log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close migrated log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), jsonPath); err != nil {
+		return fmt.Errorf("replace json log: %w", err)
+	}
+	return removeLegacyLogs(logDir)
+}
+
+func removeLegacyLogs(logDir string) error {
+	for _, name := range []string{legacyStdoutLog, legacyStderrLog} {
+		if err := os.Remove(filepath.Join(logDir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove legacy log: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 日志驱动名称（Phase 19 新增，对齐 Docker --log-driver）
+const (
+	// LogDriverNone 丢弃容器输出（attach 仍可看到输出）
+	LogDriverNone = "none"
+
+	// LogDriverJSONFile 写入容器目录下的 json-file 日志（默认），logs 命令可读取
+	LogDriverJSONFile = "json-file"
+
+	// LogDriverSyslog 以 syslog 格式发送到 Unix 数据报套接字（默认 /dev/log）
+	LogDriverSyslog = "syslog"
+
+	// LogDriverJournald 以 journald 原生协议发送到 Unix 数据报套接字
+	LogDriverJournald = "journald"
+)
+
+// DefaultLogDriver 是未指定 --log-driver 时使用的驱动
+const DefaultLogDriver = LogDriverJSONFile
+
+// LogDriver 是容器日志的写入后端（Phase 19 新增）。
+//
+// 由持有容器输出的一方（shim 或前台 runtime）创建，stdout/stderr 的输出经
+// Stream 返回的 Writer 写入；容器退出、输出复制结束后调用 Close。
+type LogDriver interface {
+	// Stream 返回指定输出流（LogStreamStdout / LogStreamStderr）的 Writer
+	Stream(name string) io.Writer
+
+	// Close 写出缓存的部分行并释放资源
+	Close() error
+}
+
+// logDriverOpts 列出各驱动支持的 --log-opt
+var logDriverOpts = map[string][]string{
+	LogDriverNone:     nil,
+	LogDriverJSONFile: {"max-file", "max-size"},
+	LogDriverSyslog:   {"syslog-address", "syslog-facility", "tag"},
+	LogDriverJournald: {"journald-address", "tag"},
+}
+
+// ValidateLogConfig 检查 --log-driver 与 --log-opt 的组合（Phase 19 新增）。
+// driver 为空时按默认驱动处理。
+func ValidateLogConfig(driver string, opts map[string]string) error {
+	if driver == "" {
+		driver = DefaultLogDriver
+	}
+
+	supported, ok := logDriverOpts[driver]
+	if !ok {
+		return fmt.Errorf("unknown log driver %q (supported: %s, %s, %s, %s)",
+			driver, LogDriverNone, LogDriverJSONFile, LogDriverSyslog, LogDriverJournald)
+	}
+
+	keys := make([]string, 0, len(opts))
+	for key := range opts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(supported, key) {
+			if len(supported) == 0 {
+				return fmt.Errorf("log driver %s does not accept any log-opt (got %q)", driver, key)
+			}
+			return fmt.Errorf("unknown log-opt %q for %s log driver (supported: %s)", key, driver, strings.Join(supported, ", "))
+		}
+	}
+
+	switch driver {
+	case LogDriverJSONFile:
+		_, err := parseJSONLogOpts(opts)
+		return err
+	case LogDriverSyslog:
+		_, err := parseSyslogOpts(opts, "")
+		return err
+	case LogDriverJournald:
+		_, err := parseUnixgramAddress("journald-address", opts["journald-address"], defaultJournaldAddress)
+		return err
+	}
+	return nil
+}
+
+// IsLogDriverReadable 判断 logs 命令能否读回该驱动写入的日志
+func IsLogDriverReadable(driver string) bool {
+	return driver == "" || driver == LogDriverJSONFile
+}
+
+// newLogDriver 按容器配置创建日志驱动
+func newLogDriver(containerDir string, config *ContainerConfig) (LogDriver, error) {
+	driver := config.LogDriver
+	if driver == "" {
+		driver = DefaultLogDriver
+	}
+	if err := ValidateLogConfig(driver, config.LogOpts); err != nil {
+		return nil, err
+	}
+
+	switch driver {
+	case LogDriverNone:
+		return noneLogDriver{}, nil
+	case LogDriverSyslog:
+		return newSyslogDriver(config)
+	case LogDriverJournald:
+		return newJournaldDriver(config)
+	default:
+		opts, err := parseJSONLogOpts(config.LogOpts)
+		if err != nil {
+			return nil, err
+		}
+		return openJSONLog(filepath.Join(containerDir, "logs"), opts)
+	}
+}
+
+// logTag 返回 syslog/journald 使用的标识（--log-opt tag，默认容器 ID 前 12 位）
+func logTag(config *ContainerConfig) string {
+	if tag := config.LogOpts["tag"]; tag != "" {
+		return tag
+	}
+	if len(config.ID) > 12 {
+		return config.ID[:12]
+	}
+	return config.ID
+}
+
+// parseUnixgramAddress 解析 Unix 数据报套接字地址：unixgram:///path 或绝对路径，空值使用默认地址
+func parseUnixgramAddress(opt, value, defaultPath string) (string, error) {
+	if value == "" {
+		return defaultPath, nil
+	}
+	path := strings.TrimPrefix(value, "unixgram://")
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("invalid log-opt %s %q (expected unixgram:///path/to/socket)", opt, value)
+	}
+	return path, nil
+}
+
+// noneLogDriver 丢弃所有输出
+type noneLogDriver struct{}
+
+func (noneLogDriver) Stream(string) io.Writer { return io.Discard }
+func (noneLogDriver) Close() error            { return nil }
+
+// datagramSendTimeout 是向日志套接字发送单条消息的超时；
+// 接收方阻塞时丢弃消息，避免拖慢容器输出
+const datagramSendTimeout = time.Second
+
+// datagramLogDriver 将每行输出格式化后作为一个数据报发送到 Unix 套接字（syslog/journald 共用）。
+// 发送失败（接收方不存在、队列已满）时丢弃该行，不影响容器运行。
+type datagramLogDriver struct {
+	lineSplitter
+
+	conn   *net.UnixConn
+	format func(stream string, line []byte) []byte
+}
+
+func newDatagramLogDriver(path string, format func(stream string, line []byte) []byte) (*datagramLogDriver, error) {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	d := &datagramLogDriver{conn: conn, format: format}
+	d.emit = d.send
+	return d, nil
+}
+
+// send 发送一行（调用方持有 mu）；去掉结尾的换行（TTY 模式下为 \r\n）
+func (d *datagramLogDriver) send(stream string, line []byte) error {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	_ = d.conn.SetWriteDeadline(time.Now().Add(datagramSendTimeout))
+	_, _ = d.conn.Write(d.format(stream, line))
+	return nil
+}
+
+// Close 发送剩余的部分行并关闭套接字
+func (d *datagramLogDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.flush()
+	return d.conn.Close()
+}
@@ -0,0 +1,147 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+
+	"minidocker/internal/state"
+
+	"golang.org/x/sys/unix"
+)
+
+// nsJoin 描述一个需要通过 setns 加入的命名空间（Phase 15 新增）。
+type nsJoin struct {
+	name string // ns 文件名，如 "net"、"pid"
+	flag int    // CLONE_NEW* 标志
+	path string // /proc/<pid>/ns/<name>
+}
+
+// namespaceCloneFlags 根据命名空间共享模式计算 clone 标志。
+//
+// 只有私有模式会新建命名空间：
+// - host 模式：不新建，直接继承宿主（父进程）的命名空间
+// - container 模式：不新建，启动时由 startInNamespaces 通过 setns 加入
+func namespaceCloneFlags(config *ContainerConfig) uintptr {
+	cloneFlags := unix.CLONE_NEWNS // 新的 Mount namespace (文件系统挂载)
+
+	if config.UTSMode.IsPrivate() {
+		cloneFlags |= unix.CLONE_NEWUTS // 新的 UTS namespace (主机名)
+	}
+	if config.PidMode.IsPrivate() {
+		cloneFlags |= unix.CLONE_NEWPID // 新的 PID namespace (进程 ID)
+	}
+	if config.IpcMode.IsPrivate() {
+		cloneFlags |= unix.CLONE_NEWIPC // 新的 IPC namespace (System V IPC, POSIX 消息队列)
+	}
+
+	// Phase 7: 根据网络配置决定是否创建网络命名空间
+	// - bridge 模式: 需要 CLONE_NEWNET（容器有独立网络栈）
+	// - none 模式: 需要 CLONE_NEWNET（容器有独立但空的网络栈）
+	// - host 模式: 不需要 CLONE_NEWNET（共享宿主机网络）
+	// - container 模式: 不需要 CLONE_NEWNET（加入目标容器的网络命名空间）
+	if config.NetworkConfig != nil && config.NetworkConfig.NeedsNetworkNamespace() {
+		cloneFlags |= unix.CLONE_NEWNET
+	}
+
+	return uintptr(cloneFlags)
+}
+
+// resolveNamespaceJoins 解析 container:<id> 模式，返回需要加入的命名空间。
+//
+// 目标容器必须处于运行状态；通过其 init 进程的 /proc/<pid>/ns/<name> 引用命名空间。
+// 在启动前一刻解析，尽量缩小目标退出导致 PID 复用的窗口。
+func resolveNamespaceJoins(config *ContainerConfig, store *state.Store) ([]nsJoin, error) {
+	var joins []nsJoin
+
+	add := func(name string, flag int, targetID string) error {
+		pid, err := runningContainerPID(store, targetID)
+		if err != nil {
+			return fmt.Errorf("join %s namespace: %w", name, err)
+		}
+		joins = append(joins, nsJoin{
+			name: name,
+			flag: flag,
+			path: fmt.Sprintf("/proc/%d/ns/%s", pid, name),
+		})
+		return nil
+	}
+
+	if config.NetworkConfig != nil && config.NetworkConfig.Mode.IsContainer() {
+		if err := add("net", unix.CLONE_NEWNET, config.NetworkConfig.Mode.ConnectedContainer()); err != nil {
+			return nil, err
+		}
+	}
+	if config.PidMode.IsContainer() {
+		if err := add("pid", unix.CLONE_NEWPID, config.PidMode.Container()); err != nil {
+			return nil, err
+		}
+	}
+	if config.IpcMode.IsContainer() {
+		if err := add("ipc", unix.CLONE_NEWIPC, config.IpcMode.Container()); err != nil {
+			return nil, err
+		}
+	}
+	if config.UTSMode.IsContainer() {
+		if err := add("uts", unix.CLONE_NEWUTS, config.UTSMode.Container()); err != nil {
+			return nil, err
+		}
+	}
+
+	return joins, nil
+}
+
+// runningContainerPID 返回目标容器 init 进程的 PID，要求容器正在运行。
+func runningContainerPID(store *state.Store, containerID string) (int, error) {
+	if store == nil {
+		return 0, fmt.Errorf("state store is required to resolve container %s", containerID)
+	}
+	st, err := store.Get(containerID)
+	if err != nil {
+		return 0, err
+	}
+	if !st.IsRunning() {
+		return 0, fmt.Errorf("container %s is not running", st.ID[:12])
+	}
+	return st.Pid, nil
+}
+
+// startInNamespaces 在加入目标命名空间的线程上启动子进程。
+//
+// setns() 只影响调用线程，而 fork 出的子进程继承调用线程的命名空间
+// （对 PID namespace 而言是 pid_for_children）。因此：
+//  1. 在独立 goroutine 中 LockOSThread
+//  2. 依次 setns 到目标命名空间
+//  3. 在同一线程上 cmd.Start()
+//  4. 不调用 UnlockOSThread：该线程的命名空间已被修改，goroutine 退出时 Go 运行时会销毁它
+func startInNamespaces(cmd *exec.Cmd, joins []nsJoin) error {
+	if len(joins) == 0 {
+		return cmd.Start()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		goruntime.LockOSThread()
+
+		for _, j := range joins {
+			fd, err := unix.Open(j.path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				errCh <- fmt.Errorf("open %s namespace (%s): %w", j.name, j.path, err)
+				return
+			}
+			err = unix.Setns(fd, j.flag)
+			unix.Close(fd)
+			if err != nil {
+				errCh <- fmt.Errorf("setns %s: %w", j.name, err)
+				return
+			}
+		}
+
+		errCh <- cmd.Start()
+	}()
+
+	return <-errCh
+}
@@ -0,0 +1,41 @@
+package runtime
+
+import "strings"
+
+// NamespaceMode 描述 pid/ipc/uts 命名空间的共享方式（Phase 15 新增，对齐 Docker --pid/--ipc/--uts）。
+//
+//	""               私有命名空间（默认，clone 时新建）
+//	"host"           与宿主共享（不新建命名空间）
+//	"container:<id>" 通过 setns 加入另一个容器的命名空间
+type NamespaceMode string
+
+const (
+	// NamespaceModeHost 与宿主共享命名空间
+	NamespaceModeHost NamespaceMode = "host"
+
+	// NamespaceModeContainerPrefix 是加入其他容器命名空间的模式前缀
+	NamespaceModeContainerPrefix = "container:"
+)
+
+// IsPrivate 返回是否使用私有命名空间
+func (m NamespaceMode) IsPrivate() bool {
+	return m == ""
+}
+
+// IsHost 返回是否与宿主共享命名空间
+func (m NamespaceMode) IsHost() bool {
+	return m == NamespaceModeHost
+}
+
+// IsContainer 返回是否加入其他容器的命名空间
+func (m NamespaceMode) IsContainer() bool {
+	return strings.HasPrefix(string(m), NamespaceModeContainerPrefix)
+}
+
+// Container 返回 container:<id> 模式下的目标容器 ID
+func (m NamespaceMode) Container() string {
+	if !m.IsContainer() {
+		return ""
+	}
+	return strings.TrimPrefix(string(m), NamespaceModeContainerPrefix)
+}
@@ -0,0 +1,19 @@
+package runtime
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunPause 是 pod infra（pause）容器主进程的入口点（Phase 15 新增）。
+// 当检测到 MINIDOCKER_PAUSE=1 时调用。
+//
+// pause 进程只负责持有 pod 共享的 network/IPC/UTS 命名空间：
+// 它不做任何工作，收到终止信号后以 0 退出（对齐 Kubernetes pause 容器）。
+func RunPause() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	<-sigChan
+	os.Exit(0)
+}
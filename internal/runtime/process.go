@@ -6,6 +6,7 @@ package runtime
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"minidocker/internal/cgroups"
+	"minidocker/internal/events"
 	"minidocker/internal/network"
 	"minidocker/internal/snapshot"
 	"minidocker/internal/state"
@@ -29,18 +31,20 @@ type RunOptions struct {
 	StateStore *state.Store
 }
 
-// logFiles 用于跟踪需要关闭的日志文件
+// logFiles 是容器日志的写入端
+//
+// Phase 19 更新：stdout/stderr 不再是两个原始文件，而是日志驱动的两个流；
+// 容器进程的输出经父进程（shim 或前台 runtime）交给日志驱动写入。
 type logFiles struct {
-	stdout *os.File
-	stderr *os.File
+	driver LogDriver
+	stdout io.Writer
+	stderr io.Writer
 }
 
+// Close 写出剩余的部分行并关闭日志驱动；必须在输出复制结束后调用
 func (l *logFiles) Close() {
-	if l.stdout != nil {
-		l.stdout.Close()
-	}
-	if l.stderr != nil {
-		l.stderr.Close()
+	if l.driver != nil {
+		l.driver.Close()
 	}
 }
 
@@ -66,6 +70,17 @@ func (l *logFiles) Close() {
 // - 在启动进程前解析 named volumes（自动创建不存在的卷）
 // - 卷挂载在 init 进程中执行（在 pivot_root 前挂到 rootfs/<target>，对齐 runc）
 //
+// Phase 14 更新：
+// - 默认设备白名单（cgroup v2 eBPF 设备控制器），--privileged 解除限制
+// - 未配置资源限制时也会创建 cgroup 以挂载设备过滤程序
+// - 私有 cgroup namespace：init 通过同步管道等待父进程完成 cgroup 加入后再 unshare
+//
+// Phase 18 更新：
+// - -t 分配 PTY：init 的 stdio 连接到 slave，前台模式下本地终端经 master 交互（raw mode、SIGWINCH）
+//
+// Phase 19 更新：
+// - 日志改为 json-file 格式：每行一条带流标签与时间戳的记录，stdout/stderr 写入同一文件
+//
 // 注意：这个函数不应该调用 os.Exit。
 // 退出码应由 CLI（或后续阶段的 daemon/manager）统一处理。
 func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
@@ -97,12 +112,39 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 		Rootfs:     config.Rootfs,
 		TTY:        config.TTY,
 		Detached:   config.Detached,
-		Image:      config.Image,      // Phase 9
-		Name:       config.Name,       // Phase 11
-		Env:        config.Env,        // Phase 11
-		WorkingDir: config.WorkingDir, // Phase 11
-		User:       config.User,       // Phase 11
-	}
+		Image:      config.Image,                   // Phase 9
+		Name:       config.Name,                    // Phase 11
+		Env:        config.Env,                     // Phase 11
+		WorkingDir: config.WorkingDir,              // Phase 11
+		User:       config.User,                    // Phase 11
+		Privileged: config.Privileged,              // Phase 14
+		Devices:    toStateDevices(config.Devices), // Phase 14
+		CgroupNS:   config.CgroupNS,                // Phase 14
+		PidMode:    string(config.PidMode),         // Phase 15
+		IpcMode:    string(config.IpcMode),         // Phase 15
+		UTSMode:    string(config.UTSMode),         // Phase 15
+	}
+
+	// Phase 15: pod 成员信息
+	stateConfig.Pod = config.Pod
+	stateConfig.CgroupParent = config.CgroupParent
+
+	// Phase 18: -i 保持 STDIN 打开
+	stateConfig.Interactive = config.Interactive
+
+	// Phase 17: 资源限制与内核参数
+	stateConfig.Ulimits = toStateUlimits(config.Ulimits)
+	stateConfig.Sysctls = config.Sysctls
+
+	// Phase 19: 日志驱动与选项
+	stateConfig.LogDriver = config.LogDriver
+	stateConfig.LogOpts = config.LogOpts
+
+	// Phase 21: DNS 与 /etc/hosts 配置
+	stateConfig.DNS = config.DNS
+	stateConfig.DNSSearch = config.DNSSearch
+	stateConfig.DNSOptions = config.DNSOptions
+	stateConfig.ExtraHosts = config.ExtraHosts
 
 	// Phase 6: 添加 cgroup 配置到状态
 	if config.CgroupConfig != nil && !config.CgroupConfig.IsEmpty() {
@@ -116,6 +158,13 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 	// Phase 7: 添加网络配置到状态
 	if config.NetworkConfig != nil {
 		stateConfig.NetworkMode = string(config.NetworkConfig.GetMode())
+		stateConfig.Network = config.NetworkConfig.Network         // Phase 21
+		stateConfig.NetworkAliases = config.NetworkConfig.Aliases  // Phase 21
+		stateConfig.IPAddress = config.NetworkConfig.IPAddress     // Phase 21
+		stateConfig.IPv6Address = config.NetworkConfig.IPv6Address // Phase 21
+		stateConfig.MacAddress = config.NetworkConfig.MacAddress   // Phase 21
+		stateConfig.IngressRate = config.NetworkConfig.IngressRate // Phase 21
+		stateConfig.EgressRate = config.NetworkConfig.EgressRate   // Phase 21
 		if len(config.NetworkConfig.PortMappings) > 0 {
 			stateConfig.PortMappings = make([]state.PortMapping, len(config.NetworkConfig.PortMappings))
 			for i, pm := range config.NetworkConfig.PortMappings {
@@ -166,6 +215,7 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 
 	// 清理函数：启动失败时删除状态目录、cgroup 和网络
 	cleanupOnError := true
+	createLogged := false
 	var cgroupPath string
 	var cgroupManager cgroups.Manager
 	var networkManager network.Manager
@@ -181,9 +231,17 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 				_ = cgroupManager.Destroy(cgroupPath)
 			}
 			opts.StateStore.ForceDelete(config.ID)
+			// Phase 20: 已记录 create 的容器启动失败时补记 destroy
+			if createLogged {
+				logContainerEvent(opts.StateStore.RootDir, config, events.ActionDestroy, nil)
+			}
 		}
 	}()
 
+	// Phase 20: 记录 create 事件（后台模式的 start/die 由 shim 记录）
+	logContainerEvent(opts.StateStore.RootDir, config, events.ActionCreate, nil)
+	createLogged = true
+
 	if config.Detached {
 		// 后台模式：启动 per-container shim 进程，并等待其将状态更新为 running。
 		// run -d 必须立即返回，但 exitCode/state 的最终更新需要一个持久的父进程（类似 containerd-shim）。
@@ -196,15 +254,18 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 		return 0, nil
 	}
 
+	// Phase 14: 合并设备白名单（privileged 或 cgroup v1 时不做限制）
+	config.CgroupConfig = withDeviceRules(config.CgroupConfig, config.Privileged, config.Devices)
+
 	// Phase 6: 前台模式创建 cgroup（后台模式由 shim 负责创建/加入/清理）
-	if config.CgroupConfig != nil && !config.CgroupConfig.IsEmpty() {
+	if config.needsCgroup() {
 		var err error
 		cgroupManager, err = cgroups.NewManager()
 		if err != nil {
 			return -1, fmt.Errorf("failed to initialize cgroup manager: %w", err)
 		}
 
-		cgroupPath = cgroups.GetCgroupPath(config.ID)
+		cgroupPath = cgroups.GetCgroupPathWithParent(config.CgroupParent, config.ID)
 		if err := cgroupManager.Create(cgroupPath, config.CgroupConfig); err != nil {
 			return -1, fmt.Errorf("failed to create cgroup: %w", err)
 		}
@@ -230,23 +291,43 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 	}
 
 	// 2. 设置日志文件（前台模式）
-	logs, err := setupLogFiles(containerState.GetContainerDir())
+	logs, err := setupLogFiles(containerState.GetContainerDir(), config)
 	if err != nil {
 		return -1, fmt.Errorf("failed to setup log files: %w", err)
 	}
 
+	// Phase 18: -t 分配 PTY，master 由当前进程持有
+	var cons *console
+	if config.TTY {
+		if cons, err = newConsole(); err != nil {
+			logs.Close()
+			return -1, fmt.Errorf("failed to allocate tty: %w", err)
+		}
+		defer cons.Close()
+	}
+
 	// 3. 创建父进程
-	cmd, err := newParentProcess(config, containerState.GetContainerDir(), logs)
+	cmd, syncW, err := newParentProcess(config, containerState.GetContainerDir(), logs, cons)
 	if err != nil {
 		logs.Close()
 		return -1, fmt.Errorf("failed to create parent process: %w", err)
 	}
+	defer syncW.Close()
+
+	// Phase 15: 解析需要加入的其他容器命名空间（container:<id> 模式）
+	joins, err := resolveNamespaceJoins(config, opts.StateStore)
+	if err != nil {
+		logs.Close()
+		return -1, err
+	}
 
 	// 4. 启动子进程
-	if err := cmd.Start(); err != nil {
+	if err := startInNamespaces(cmd, joins); err != nil {
 		logs.Close()
 		return -1, fmt.Errorf("failed to start container process: %w", err)
 	}
+	closeChildFiles(cmd)
+	cons.closeSlave()
 
 	// Phase 6: 将进程加入 cgroup
 	if cgroupManager != nil && cgroupPath != "" {
@@ -275,8 +356,13 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 			IPAddress:     networkState.IPAddress,
 			Gateway:       networkState.Gateway,
 			MacAddress:    networkState.MacAddress,
+			MTU:           networkState.MTU, // Phase 21
 			VethHost:      networkState.VethHost,
 			VethContainer: networkState.VethContainer,
+			Network:       networkState.Network,         // Phase 21
+			Aliases:       config.NetworkConfig.Aliases, // Phase 21
+			IPv6Address:   networkState.IPv6Address,     // Phase 21
+			IPv6Gateway:   networkState.IPv6Gateway,     // Phase 21
 		}
 		if len(networkState.PortMappings) > 0 {
 			containerState.NetworkState.PortMappings = make([]state.PortMapping, len(networkState.PortMappings))
@@ -291,6 +377,42 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 		}
 	}
 
+	// Phase 21: 生成容器的 /etc/hosts、/etc/resolv.conf、/etc/hostname（须在放行 init 前完成）
+	if err := writeEtcFiles(config, containerState.GetContainerDir(), containerState.NetworkState, opts.StateStore); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		return -1, fmt.Errorf("failed to write container etc files: %w", err)
+	}
+
+	// Phase 21: 用户自定义网络上的容器启用内嵌 DNS
+	resolver, err := startEmbeddedDNS(config, opts.StateStore, containerState.GetContainerDir(), containerState.NetworkState, cmd.Process.Pid)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		return -1, fmt.Errorf("failed to start embedded dns: %w", err)
+	}
+	defer resolver.Close()
+
+	// Phase 21: 启用 userland-proxy 时为发布端口启动用户态代理
+	proxy, err := startUserlandProxy(opts.StateStore.RootDir, networkState)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		return -1, fmt.Errorf("failed to start userland proxy: %w", err)
+	}
+	defer proxy.Close()
+
+	// Phase 14: cgroup 与网络就绪，放行 init 继续启动
+	if err := releaseInit(syncW); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		return -1, fmt.Errorf("failed to release container init: %w", err)
+	}
+
 	// 5. 更新状态为 running
 	if err := containerState.SetRunning(cmd.Process.Pid); err != nil {
 		// 启动成功但状态更新失败，尝试杀死进程
@@ -302,14 +424,31 @@ func Run(config *ContainerConfig, opts *RunOptions) (int, error) {
 
 	// 启动成功，取消清理
 	cleanupOnError = false
+	logContainerEvent(opts.StateStore.RootDir, config, events.ActionStart, nil)
 
 	// 前台模式：等待退出
-	exitCode := waitForExit(cmd)
+	var exitCode int
+	if cons != nil {
+		// Phase 18: 连接本地终端与 PTY（raw mode、SIGWINCH 传播），输出同时写入日志
+		restore := rawStdin(config.Interactive)
+		stopResize := forwardResize(cons.master)
+		doneOut := pipePTY(cons.master, io.MultiWriter(os.Stdout, logs.stdout), config.Interactive)
+		exitCode = waitForExit(cmd)
+		drainPTY(cons.master, doneOut)
+		stopResize()
+		restore()
+	} else {
+		exitCode = waitForExit(cmd)
+	}
+	// Phase 21: network connect 可能在运行期间更新了 state.json 中的附加网卡
+	_ = containerState.Reload()
 	containerState.SetStopped(exitCode)
 	logs.Close()
+	logExitEvents(opts.StateStore.RootDir, config, exitCode, cgroupManager, cgroupPath)
 
 	// Phase 7: 前台模式下清理网络（先于 cgroup）
 	if networkManager != nil && networkState != nil {
+		networkState.Endpoints = connectedEndpoints(containerState.NetworkState)
 		_ = networkManager.Teardown(config.ID, networkState)
 	}
 
@@ -395,34 +534,34 @@ func startDetachedShim(containerDir string) error {
 	}
 }
 
-// setupLogFiles 创建日志文件
-func setupLogFiles(containerDir string) (*logFiles, error) {
+// setupLogFiles 创建日志写入端（Phase 19 更新：按 --log-driver 创建日志驱动，见 LogDriver）
+func setupLogFiles(containerDir string, config *ContainerConfig) (*logFiles, error) {
 	logDir := filepath.Join(containerDir, "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return nil, fmt.Errorf("create log directory: %w", err)
 	}
 
-	stdoutPath := filepath.Join(logDir, "stdout.log")
-	stderrPath := filepath.Join(logDir, "stderr.log")
-
-	stdout, err := os.OpenFile(stdoutPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("create stdout log: %w", err)
-	}
-
-	stderr, err := os.OpenFile(stderrPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	driver, err := newLogDriver(containerDir, config)
 	if err != nil {
-		stdout.Close()
-		return nil, fmt.Errorf("create stderr log: %w", err)
+		return nil, err
 	}
 
-	return &logFiles{stdout: stdout, stderr: stderr}, nil
+	return &logFiles{
+		driver: driver,
+		stdout: driver.Stream(LogStreamStdout),
+		stderr: driver.Stream(LogStreamStderr),
+	}, nil
 }
 
 // waitForExit 等待进程退出并返回退出码
 func waitForExit(cmd *exec.Cmd) int {
 	if err := cmd.Wait(); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
+			// Phase 20: 被信号终止时按 shell/Docker 惯例记为 128+signal（如 SIGKILL 为 137），
+			// 而不是 ExitCode() 返回的 -1，便于 wait/events 报告
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				return 128 + int(status.Signal())
+			}
 			return exitErr.ExitCode()
 		}
 		return -1
@@ -439,31 +578,26 @@ func waitForExit(cmd *exec.Cmd) int {
 //     否则容易受到运行时多线程的影响而产生难以定位的问题
 //  3. 通过 re-exec，子进程从一开始就处在目标 namespace 中，并进入明确的 init(PID1) 路径，
 //     组织方式更贴近 runc
-func newParentProcess(config *ContainerConfig, containerDir string, logs *logFiles) (*exec.Cmd, error) {
+//
+// Phase 14: 额外返回启动同步管道的写端。init 会阻塞在读端，
+// 直到父进程调用 releaseInit（已加入 cgroup、网络已配置）。
+//
+// Phase 18: cons 非空时（-t），init 的 stdio 连接到 PTY slave。
+func newParentProcess(config *ContainerConfig, containerDir string, logs *logFiles, cons *console) (*exec.Cmd, *os.File, error) {
 	// 重新执行当前二进制文件
 	// /proc/self/exe 始终指向当前可执行文件
 	cmd := exec.Command("/proc/self/exe")
 
 	// 使用克隆标志配置命名空间隔离
 	// 这些标志告诉内核为子进程创建新的命名空间
-	cloneFlags := syscall.CLONE_NEWUTS | // 新的 UTS namespace (主机名)
-		syscall.CLONE_NEWPID | // 新的 PID namespace (进程 ID)
-		syscall.CLONE_NEWNS | // 新的 Mount namespace (文件系统挂载)
-		syscall.CLONE_NEWIPC // 新的 IPC namespace (System V IPC, POSIX 消息队列)
-
-	// Phase 7: 根据网络配置决定是否创建网络命名空间
-	// - bridge 模式: 需要 CLONE_NEWNET（容器有独立网络栈）
-	// - none 模式: 需要 CLONE_NEWNET（容器有独立但空的网络栈）
-	// - host 模式: 不需要 CLONE_NEWNET（共享宿主机网络）
-	if config.NetworkConfig != nil && config.NetworkConfig.NeedsNetworkNamespace() {
-		cloneFlags |= syscall.CLONE_NEWNET
-	}
+	// Phase 15: 共享模式（host/container:<id>）下不新建对应命名空间，见 namespaceCloneFlags
+	cloneFlags := namespaceCloneFlags(config)
 
 	// 注意：CLONE_NEWUSER 未包含在第1阶段中。
 	// 用户命名空间将在第16阶段 (feat-rootless) 中添加。
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Cloneflags: uintptr(cloneFlags),
+		Cloneflags: cloneFlags,
 	}
 
 	// 后台模式：创建新会话，脱离控制终端
@@ -471,23 +605,34 @@ func newParentProcess(config *ContainerConfig, containerDir string, logs *logFil
 		cmd.SysProcAttr.Setsid = true
 	}
 
+	// Phase 14: init 加入容器 cgroup 后不能 mknod，由父进程预先创建 --device 节点副本
+	if err := stageDevices(containerDir, config.Devices); err != nil {
+		return nil, nil, fmt.Errorf("stage devices: %w", err)
+	}
+
+	// Phase 14: 启动同步管道（读端作为 fd 3 传给 init）
+	syncR, syncW, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create init sync pipe: %w", err)
+	}
+	cmd.ExtraFiles = []*os.File{syncR}
+
 	// 为 init 进程设置环境
 	cmd.Env = append(os.Environ(),
 		envutil.InitEnvVar+"=1",
 		envutil.StatePathEnvVar+"="+containerDir,
+		envutil.InitSyncFdEnvVar+"=3",
 	)
 
 	// 设置标准输入输出
-	if config.Detached {
-		// 后台模式：关闭 stdin，重定向 stdout/stderr 到日志文件
+	if cons != nil {
+		// Phase 18: TTY 模式：stdio 连接到 PTY slave，由 master 持有者转发到终端/日志
+		cons.setupCmd(cmd)
+	} else if config.Detached {
+		// 后台模式：关闭 stdin，stdout/stderr 经管道写入日志
 		cmd.Stdin = nil
 		cmd.Stdout = logs.stdout
 		cmd.Stderr = logs.stderr
-	} else if config.TTY {
-		// TTY 模式：直接连接到终端
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
 	} else {
 		// 非 TTY 前台模式：透传 stdin，同时写入终端和日志文件
 		cmd.Stdin = os.Stdin
@@ -495,17 +640,34 @@ func newParentProcess(config *ContainerConfig, containerDir string, logs *logFil
 		cmd.Stderr = newTeeWriter(os.Stderr, logs.stderr)
 	}
 
-	return cmd, nil
+	return cmd, syncW, nil
+}
+
+// closeChildFiles 关闭已由子进程继承的 ExtraFiles（父进程侧不再需要）。
+func closeChildFiles(cmd *exec.Cmd) {
+	for _, f := range cmd.ExtraFiles {
+		_ = f.Close()
+	}
+}
+
+// releaseInit 通知 init 进程继续启动（Phase 14 新增）。
+// 写入一个字节后关闭写端；若父进程未调用即退出，init 读到 EOF 并放弃启动。
+func releaseInit(syncW *os.File) error {
+	defer syncW.Close()
+	if _, err := syncW.Write([]byte{0}); err != nil {
+		return fmt.Errorf("write init sync pipe: %w", err)
+	}
+	return nil
 }
 
 // teeWriter 同时写入多个 Writer
 type teeWriter struct {
 	primary *os.File
-	extra   *os.File
+	extra   io.Writer
 }
 
 // newTeeWriter 创建一个同时写入两个目标的 Writer
-func newTeeWriter(primary, extra *os.File) *teeWriter {
+func newTeeWriter(primary *os.File, extra io.Writer) *teeWriter {
 	return &teeWriter{primary: primary, extra: extra}
 }
 
@@ -590,3 +752,26 @@ func prepareMounts(mounts []volume.Mount, rootDir string) error {
 
 	return nil
 }
+
+// connectedEndpoints 将 state.json 中通过 network connect 附加的网卡转换为网络端点（Phase 21 新增），
+// 供容器退出时一并清理。
+func connectedEndpoints(ns *state.NetworkState) []network.Endpoint {
+	if ns == nil || len(ns.Endpoints) == 0 {
+		return nil
+	}
+	endpoints := make([]network.Endpoint, len(ns.Endpoints))
+	for i, ep := range ns.Endpoints {
+		endpoints[i] = network.Endpoint{
+			Network:       ep.Network,
+			IPAddress:     ep.IPAddress,
+			Gateway:       ep.Gateway,
+			IPv6Address:   ep.IPv6Address,
+			IPv6Gateway:   ep.IPv6Gateway,
+			MacAddress:    ep.MacAddress,
+			MTU:           ep.MTU,
+			VethHost:      ep.VethHost,
+			VethContainer: ep.VethContainer,
+		}
+	}
+	return endpoints
+}
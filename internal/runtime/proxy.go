@@ -0,0 +1,24 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"minidocker/internal/network"
+)
+
+// startUserlandProxy 在 daemon.json 启用 userland-proxy 时为容器的发布端口启动用户态代理（Phase 21 新增）；
+// 未启用或没有发布端口时返回 nil。与内嵌 DNS 一样由 shim（前台 run 为 CLI 进程）在容器运行期间提供服务。
+func startUserlandProxy(rootDir string, ns *network.NetworkState) (*network.PortProxy, error) {
+	if ns == nil || ns.Mode != network.NetworkModeBridge || len(ns.PortMappings) == 0 {
+		return nil, nil
+	}
+	config, err := network.LoadDaemonConfig(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if !config.UserlandProxy {
+		return nil, nil
+	}
+	return network.StartPortProxy(ns)
+}
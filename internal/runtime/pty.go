@@ -10,11 +10,17 @@ import (
 	"os/exec"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/creack/pty"
 	"golang.org/x/sys/unix"
 )
 
+// ptyDrainTimeout 是命令退出后等待 PTY 输出读完的最长时间。
+// 正常情况下所有 slave 持有者退出后 master 读到 EIO 即结束；
+// 若有后台进程仍持有 slave（例如共享 PID namespace），超时后强制关闭 master。
+const ptyDrainTimeout = 2 * time.Second
+
 // execWithPTY 在 TTY 模式下执行命令，提供完整的 PTY 支持
 func execWithPTY(cmd *exec.Cmd, config *ExecConfig) (int, error) {
 	// 使用 PTY 启动命令
@@ -25,15 +31,108 @@ func execWithPTY(cmd *exec.Cmd, config *ExecConfig) (int, error) {
 	defer ptmx.Close()
 
 	// 处理终端大小调整
+	defer forwardResize(ptmx)()
+
+	// 在交互模式下将 stdin 设置为原始模式（Ctrl+C 等控制字符会透传到 PTY，由从端决定信号行为）
+	defer rawStdin(config.Interactive)()
+
+	// stdout 始终透传；stdin 仅在 -i 时透传（符合 docker 的 -i 语义直觉）
+	doneOut := pipePTY(ptmx, os.Stdout, config.Interactive)
+
+	// 等待命令完成
+	err = cmd.Wait()
+	drainPTY(ptmx, doneOut)
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// Go 的 ExitCode() 在被信号杀死时可能返回 -1；这里统一转换为 shell 惯例 128+signal。
+			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+				return 128 + int(ws.Signal()), nil
+			}
+			return exitErr.ExitCode(), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// console 是容器的伪终端（Phase 18 新增）。
+//
+// slave 作为 init 进程的 stdin/stdout/stderr；master 留在前台 runtime（run -t）
+// 或 shim（run -dt）中，负责与本地终端或日志之间复制数据。
+type console struct {
+	master *os.File
+	slave  *os.File
+}
+
+// newConsole 分配一对 PTY
+func newConsole() (*console, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open pty: %w", err)
+	}
+
+	// 后台容器没有本地终端可继承大小，先使用常见的默认值
+	_ = pty.Setsize(master, &pty.Winsize{Rows: 24, Cols: 80})
+
+	return &console{master: master, slave: slave}, nil
+}
+
+// setupCmd 将 slave 连接为 init 的标准输入输出。
+//
+// init 在新会话中启动（脱离调用者的控制终端），由 init 把 slave 设置为
+// 用户命令的控制终端，见 runUserCommand。
+func (c *console) setupCmd(cmd *exec.Cmd) {
+	cmd.Stdin = c.slave
+	cmd.Stdout = c.slave
+	cmd.Stderr = c.slave
+	cmd.SysProcAttr.Setsid = true
+}
+
+// closeSlave 在 init 启动后关闭父进程侧的 slave，
+// 否则容器退出后 master 永远读不到 EIO。
+func (c *console) closeSlave() {
+	if c == nil || c.slave == nil {
+		return
+	}
+	_ = c.slave.Close()
+	c.slave = nil
+}
+
+// Close 关闭 PTY 的两端
+func (c *console) Close() {
+	if c == nil {
+		return
+	}
+	c.closeSlave()
+	_ = c.master.Close()
+}
+
+// IsTerminal 判断文件是否为终端
+func IsTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
+// forwardResize 将本地终端大小同步到 PTY，并在收到 SIGWINCH 时重新同步。
+// 返回停止函数；stdin 不是终端时同步失败会被忽略（尽力而为）。
+func forwardResize(ptmx *os.File) func() {
+	return watchResize(func(ws *pty.Winsize) {
+		_ = pty.Setsize(ptmx, ws)
+	})
+}
+
+// watchResize 读取本地终端大小并调用 fn，之后每次收到 SIGWINCH 时重新调用。
+// 返回停止函数；stdin 不是终端时不会调用 fn。
+func watchResize(fn func(ws *pty.Winsize)) func() {
 	resizeCh := make(chan os.Signal, 1)
 	signal.Notify(resizeCh, syscall.SIGWINCH)
-	defer signal.Stop(resizeCh)
 
 	go func() {
 		for range resizeCh {
-			// 将当前终端大小传播到 PTY
-			if err := pty.InheritSize(os.Stdin, ptmx); err != nil {
-				// 忽略错误，尽力而为
+			// 将当前终端大小传播出去
+			if ws, err := pty.GetsizeFull(os.Stdin); err == nil {
+				fn(ws)
 			}
 		}
 	}()
@@ -41,43 +140,51 @@ func execWithPTY(cmd *exec.Cmd, config *ExecConfig) (int, error) {
 	// 初始调整大小
 	resizeCh <- syscall.SIGWINCH
 
-	// 在交互模式下将 stdin 设置为原始模式（Ctrl+C 等控制字符会透传到 PTY，由从端决定信号行为）
-	if config.Interactive {
-		if oldState, err := makeRawTerminal(int(os.Stdin.Fd())); err == nil {
-			defer restoreTerminal(int(os.Stdin.Fd()), oldState)
-		}
+	return func() {
+		signal.Stop(resizeCh)
+		close(resizeCh)
 	}
+}
 
-	// stdout 始终透传；stdin 仅在 -i 时透传（符合 docker 的 -i 语义直觉）
+// rawStdin 在交互模式下将本地终端切换到 raw mode，返回恢复函数。
+// 非交互模式或 stdin 不是终端时不做任何事。
+func rawStdin(interactive bool) func() {
+	if !interactive {
+		return func() {}
+	}
+	fd := int(os.Stdin.Fd())
+	oldState, err := makeRawTerminal(fd)
+	if err != nil {
+		return func() {}
+	}
+	return func() { restoreTerminal(fd, oldState) }
+}
+
+// pipePTY 在本地 stdio 与 PTY master 之间复制数据：master 的输出写入 out，
+// interactive 时将 os.Stdin 写入 master。返回的 channel 在输出复制结束时关闭。
+//
+// 注意：stdin 读取可能阻塞，不能等待它退出，否则在命令结束后可能 hang。
+func pipePTY(ptmx *os.File, out io.Writer, interactive bool) <-chan struct{} {
 	doneOut := make(chan struct{})
 	go func() {
 		defer close(doneOut)
-		_, _ = io.Copy(os.Stdout, ptmx)
+		_, _ = io.Copy(out, ptmx)
 	}()
 
-	if config.Interactive {
-		// 注意：stdin 读取可能阻塞，不能等待它退出，否则在命令结束后可能 hang。
+	if interactive {
 		go func() { _, _ = io.Copy(ptmx, os.Stdin) }()
 	}
+	return doneOut
+}
 
-	// 等待命令完成
-	err = cmd.Wait()
-
-	// 关闭 ptmx 以尽快结束 stdout 复制；stdin goroutine 由进程退出自然清理
+// drainPTY 在命令退出后等待剩余输出读完，然后关闭 master。
+func drainPTY(ptmx *os.File, doneOut <-chan struct{}) {
+	select {
+	case <-doneOut:
+	case <-time.After(ptyDrainTimeout):
+	}
 	_ = ptmx.Close()
 	<-doneOut
-
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Go 的 ExitCode() 在被信号杀死时可能返回 -1；这里统一转换为 shell 惯例 128+signal。
-			if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
-				return 128 + int(ws.Signal()), nil
-			}
-			return exitErr.ExitCode(), nil
-		}
-		return -1, err
-	}
-	return 0, nil
 }
 
 // makeRawTerminal 将终端切换到 raw mode，并返回旧的 termios 以便恢复。
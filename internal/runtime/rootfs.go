@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 
+	"minidocker/internal/volume"
+	"minidocker/pkg/envutil"
+
 	"golang.org/x/sys/unix"
 )
 
@@ -49,6 +52,11 @@ func setupRootfs(config *ContainerConfig) error {
 		return fmt.Errorf("bind mount rootfs: %w", err)
 	}
 
+	// Phase 21: runtime 生成的 hosts/hostname/resolv.conf 先于用户 mounts 挂载（用户显式挂载时以用户为准）
+	if err := mountEtcFiles(rootfs); err != nil {
+		return fmt.Errorf("mount etc files: %w", err)
+	}
+
 	// Phase 10: 先把用户 mounts 挂到 rootfs/<target> 上，再 pivot_root。
 	// 这样 mount(2) 的 source（宿主路径/卷路径）仍可解析，同时 pivot_root 后容器内可见路径为 /<target>。
 	// 这对齐 runc 的常见实现方式：在 pivot_root 前把 mounts 准备到 newRoot 下。
@@ -58,6 +66,12 @@ func setupRootfs(config *ContainerConfig) error {
 		}
 	}
 
+	// Phase 14: --device 节点副本位于容器目录，pivot_root 后不可见，先打开
+	deviceFds, err := openStagedDevices(os.Getenv(envutil.StatePathEnvVar), config.Devices)
+	if err != nil {
+		return err
+	}
+
 	// 4. 执行 pivot_root 切换根
 	if err := pivotRoot(rootfs); err != nil {
 		return fmt.Errorf("pivot_root: %w", err)
@@ -67,14 +81,62 @@ func setupRootfs(config *ContainerConfig) error {
 	if err := mountProc(); err != nil {
 		return fmt.Errorf("mount /proc: %w", err)
 	}
+	// Phase 17: --sysctl 必须在 /proc 被限制为只读之前写入
+	if err := applySysctls(config.Sysctls); err != nil {
+		return fmt.Errorf("apply sysctls: %w", err)
+	}
+	if err := restrictProc(config.Privileged); err != nil {
+		return fmt.Errorf("restrict /proc: %w", err)
+	}
 	if err := mountDev(); err != nil {
 		return fmt.Errorf("mount /dev: %w", err)
 	}
+	// Phase 14: 挂载 --device 映射的设备节点
+	if err := mountDevices(config.Devices, deviceFds); err != nil {
+		return fmt.Errorf("mount devices: %w", err)
+	}
 	if err := mountSys(); err != nil {
 		// /sys 失败降级为警告（不阻塞启动）
 		fmt.Fprintf(os.Stderr, "warning: mount /sys failed: %v\n", err)
+	} else if config.CgroupNS == CgroupNSPrivate {
+		// Phase 14: 私有 cgroup namespace 下挂载容器自己的 cgroup 子树
+		if err := mountCgroup(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: mount /sys/fs/cgroup failed: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// mountEtcFiles 将容器目录下生成的 hosts/hostname/resolv.conf bind mount 到 rootfs/etc（Phase 21 新增）。
+// 文件不存在时保留 rootfs 自带的版本（例如 host UTS 模式下不生成 hostname）。
+// rootfs 中的目标是符号链接时跳过，避免挂载目标解析到 rootfs 之外。
+func mountEtcFiles(rootfs string) error {
+	containerDir := os.Getenv(envutil.StatePathEnvVar)
+	if containerDir == "" {
+		return nil
 	}
 
+	for _, name := range managedEtcFiles {
+		source := filepath.Join(containerDir, name)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+
+		target := filepath.Join(rootfs, "etc", name)
+		if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			fmt.Fprintf(os.Stderr, "warning: %s is a symlink, generated /etc/%s not mounted\n", target, name)
+			continue
+		}
+
+		if err := setupMounts(rootfs, []volume.Mount{{
+			Type:   volume.MountTypeBind,
+			Source: source,
+			Target: "/etc/" + name,
+		}}); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -193,7 +255,7 @@ func mountProc() error {
 }
 
 // mountDev 挂载 /dev（Phase 2: 最小 tmpfs + 手动创建设备节点）。
-// Phase 14 可升级为 devtmpfs 或更完整的设备管理。
+// Phase 14: 额外设备由 mountDevices 挂载，访问控制由 cgroup 设备白名单负责。
 func mountDev() error {
 	target := "/dev"
 
@@ -266,6 +328,22 @@ func mountDev() error {
 	return nil
 }
 
+// mountCgroup 在 /sys/fs/cgroup 只读挂载 cgroup2（Phase 14 新增）。
+//
+// 必须在私有 cgroup namespace 中调用：cgroup2 挂载的根是调用者 cgroup namespace 的根，
+// 即容器自己的 cgroup，因此容器内只能看到自己的 memory.max、pids.max 等文件
+// （systemd、JVM 等据此识别容器资源限制）。
+func mountCgroup() error {
+	target := "/sys/fs/cgroup"
+
+	flags := unix.MS_NOSUID | unix.MS_NOEXEC | unix.MS_NODEV | unix.MS_RDONLY
+	if err := unix.Mount("cgroup2", target, "cgroup2", uintptr(flags), ""); err != nil {
+		return fmt.Errorf("mount cgroup2: %w", err)
+	}
+
+	return nil
+}
+
 // mountSys 挂载 /sys（只读）。
 func mountSys() error {
 	target := "/sys"
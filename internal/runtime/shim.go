@@ -5,18 +5,22 @@ package runtime
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"minidocker/internal/cgroups"
+	"minidocker/internal/events"
 	"minidocker/internal/image"
 	"minidocker/internal/network"
 	"minidocker/internal/snapshot"
 	"minidocker/internal/state"
 	"minidocker/internal/volume"
 	"minidocker/pkg/envutil"
+
+	"github.com/creack/pty"
 )
 
 // RunContainerShim is the entrypoint for the per-container shim process.
@@ -42,6 +46,17 @@ import (
 // - 解析 named volumes（自动创建不存在的卷）
 // - 卷挂载在 init 进程中执行
 //
+// Phase 14 更新：
+// - 恢复设备映射，按需挂载设备白名单程序
+// - 加入 cgroup 并配置网络后才放行 init（私有 cgroup namespace 依赖此顺序）
+//
+// Phase 18 更新：
+// - -t 时分配 PTY 并持有 master，容器输出经 master 写入日志
+// - 持有容器 stdio（或 PTY master），通过容器目录下的 attach socket 供 minidocker attach 使用
+//
+// Phase 19 更新：
+// - 容器输出经 shim 编码为 json-file 日志记录（后台模式下 init 不再直接写日志文件）
+//
 // This aligns with the industry "per-container shim" model (e.g. containerd-shim).
 func RunContainerShim() {
 	containerDir := os.Getenv(envutil.StatePathEnvVar)
@@ -60,12 +75,18 @@ func RunContainerShim() {
 	var snapshotter snapshot.Snapshotter
 	var snapshotPath string
 
+	// Phase 18: attach socket
+	var attach *attachServer
+
 	fail := func(format string, args ...any) {
 		msg := fmt.Sprintf(format, args...)
 		if notify != nil {
 			fmt.Fprintf(notify, "ERR: %s\n", msg)
 			notify.Close()
 		}
+		if attach != nil {
+			attach.close()
+		}
 		// Phase 9: 清理快照（先于网络和 cgroup）
 		if snapshotter != nil && containerID != "" {
 			_ = snapshotter.Remove(containerID)
@@ -110,6 +131,7 @@ func RunContainerShim() {
 		Detached: true, // shim only exists for detached containers
 		Image:    cfg.Image,
 	}
+	rCfg.Interactive = cfg.Interactive // Phase 18
 
 	// Phase 9: 从配置中恢复镜像配置并准备 snapshot
 	if cfg.Image != "" {
@@ -151,22 +173,51 @@ func RunContainerShim() {
 	}
 
 	// Phase 6: 从配置中恢复 cgroup 配置
+	var cgroupConfig *cgroups.CgroupConfig
 	if cfg.HasCgroupConfig() {
-		rCfg.CgroupConfig = &cgroups.CgroupConfig{
+		cgroupConfig = &cgroups.CgroupConfig{
 			Memory:     cfg.Memory,
 			MemorySwap: cfg.MemorySwap,
 			CPUQuota:   cfg.CPUQuota,
 			CPUPeriod:  cfg.CPUPeriod,
 			PidsLimit:  cfg.PidsLimit,
 		}
+	}
 
+	// Phase 14: 恢复设备映射并合并设备白名单
+	rCfg.Privileged = cfg.Privileged
+	rCfg.Devices = fromStateDevices(cfg.Devices)
+	rCfg.CgroupNS = cfg.CgroupNS
+
+	// Phase 15: 恢复命名空间共享模式
+	rCfg.PidMode = NamespaceMode(cfg.PidMode)
+	rCfg.IpcMode = NamespaceMode(cfg.IpcMode)
+	rCfg.UTSMode = NamespaceMode(cfg.UTSMode)
+
+	// Phase 17: 恢复资源限制与内核参数（由 init 应用）
+	rCfg.Ulimits = fromStateUlimits(cfg.Ulimits)
+	rCfg.Sysctls = cfg.Sysctls
+
+	// Phase 19: 恢复日志驱动与选项（由 shim 写日志时使用；容器名写入 journald 字段）
+	rCfg.Name = cfg.Name
+	rCfg.LogDriver = cfg.LogDriver
+	rCfg.LogOpts = cfg.LogOpts
+
+	// Phase 21: 恢复 DNS 与 /etc/hosts 配置（生成容器 /etc 文件时使用）
+	rCfg.DNS = cfg.DNS
+	rCfg.DNSSearch = cfg.DNSSearch
+	rCfg.DNSOptions = cfg.DNSOptions
+	rCfg.ExtraHosts = cfg.ExtraHosts
+	rCfg.CgroupConfig = withDeviceRules(cgroupConfig, rCfg.Privileged, rCfg.Devices)
+
+	if rCfg.needsCgroup() {
 		// 创建 cgroup
 		cgroupManager, err = cgroups.NewManager()
 		if err != nil {
 			fail("initialize cgroup manager: %v", err)
 		}
 
-		cgroupPath = cgroups.GetCgroupPath(cfg.ID)
+		cgroupPath = cgroups.GetCgroupPathWithParent(cfg.CgroupParent, cfg.ID)
 		if err := cgroupManager.Create(cgroupPath, rCfg.CgroupConfig); err != nil {
 			fail("create cgroup: %v", err)
 		}
@@ -178,7 +229,14 @@ func RunContainerShim() {
 	// Phase 7: 从配置中恢复网络配置
 	if cfg.NetworkMode != "" {
 		rCfg.NetworkConfig = &network.NetworkConfig{
-			Mode: network.NetworkMode(cfg.NetworkMode),
+			Mode:        network.NetworkMode(cfg.NetworkMode),
+			Network:     cfg.Network,        // Phase 21
+			Aliases:     cfg.NetworkAliases, // Phase 21
+			IPAddress:   cfg.IPAddress,      // Phase 21
+			IPv6Address: cfg.IPv6Address,    // Phase 21
+			MacAddress:  cfg.MacAddress,     // Phase 21
+			IngressRate: cfg.IngressRate,    // Phase 21
+			EgressRate:  cfg.EgressRate,     // Phase 21
 		}
 		if len(cfg.PortMappings) > 0 {
 			rCfg.NetworkConfig.PortMappings = make([]network.PortMapping, len(cfg.PortMappings))
@@ -240,22 +298,76 @@ func RunContainerShim() {
 	}
 
 	// Open log files for the container init
-	logs, err := setupLogFiles(containerDir)
+	logs, err := setupLogFiles(containerDir, rCfg)
 	if err != nil {
 		fail("setup log files: %v", err)
 	}
 
+	// Phase 18: -t 时由 shim 持有 PTY master（容器输出经 master 写入日志）
+	var cons *console
+	if rCfg.TTY {
+		if cons, err = newConsole(); err != nil {
+			logs.Close()
+			fail("allocate tty: %v", err)
+		}
+	}
+
 	// Start the container init process as a child of the shim
-	cmd, err := newParentProcess(rCfg, containerDir, logs)
+	cmd, syncW, err := newParentProcess(rCfg, containerDir, logs, cons)
 	if err != nil {
 		logs.Close()
 		fail("create container process: %v", err)
 	}
 
-	if err := cmd.Start(); err != nil {
+	// Phase 18: shim 持有容器 stdio，通过 attach socket 提供给 minidocker attach
+	if attach, err = newAttachServer(containerDir); err != nil {
+		logs.Close()
+		fail("%v", err)
+	}
+	var stdinR, stdinW *os.File
+	if cons != nil {
+		if rCfg.Interactive {
+			attach.stdin = cons.master
+		}
+		attach.resize = func(ws *pty.Winsize) { _ = pty.Setsize(cons.master, ws) }
+	} else {
+		// 非 TTY：输出同时写入日志并广播给 attach 客户端；
+		// -i 时 stdin 改为管道，由 attach 客户端写入
+		cmd.Stdout = io.MultiWriter(logs.stdout, attach.output(attachStreamStdout))
+		cmd.Stderr = io.MultiWriter(logs.stderr, attach.output(attachStreamStderr))
+		// 容器内残留进程可能持有输出管道，init 退出后最多等待 ptyDrainTimeout
+		cmd.WaitDelay = ptyDrainTimeout
+		if rCfg.Interactive {
+			if stdinR, stdinW, err = os.Pipe(); err != nil {
+				logs.Close()
+				fail("create stdin pipe: %v", err)
+			}
+			cmd.Stdin = stdinR
+			attach.stdin = stdinW
+		}
+	}
+	attach.serve()
+
+	// Phase 15: 解析需要加入的其他容器命名空间（container:<id> 模式）
+	var joins []nsJoin
+	store, err := state.NewStore(filepath.Dir(filepath.Dir(containerDir)))
+	if err == nil {
+		joins, err = resolveNamespaceJoins(rCfg, store)
+	}
+	if err != nil {
+		logs.Close()
+		fail("%v", err)
+	}
+
+	if err := startInNamespaces(cmd, joins); err != nil {
 		logs.Close()
 		fail("start container process: %v", err)
 	}
+	closeChildFiles(cmd)
+	cons.closeSlave()
+	if stdinR != nil {
+		_ = stdinR.Close()
+	}
 
 	// Phase 6: 将进程加入 cgroup
 	if cgroupManager != nil && cgroupPath != "" {
@@ -283,8 +395,13 @@ func RunContainerShim() {
 			IPAddress:     networkState.IPAddress,
 			Gateway:       networkState.Gateway,
 			MacAddress:    networkState.MacAddress,
+			MTU:           networkState.MTU, // Phase 21
 			VethHost:      networkState.VethHost,
 			VethContainer: networkState.VethContainer,
+			Network:       networkState.Network,     // Phase 21
+			Aliases:       cfg.NetworkAliases,       // Phase 21
+			IPv6Address:   networkState.IPv6Address, // Phase 21
+			IPv6Gateway:   networkState.IPv6Gateway, // Phase 21
 		}
 		if len(networkState.PortMappings) > 0 {
 			st.NetworkState.PortMappings = make([]state.PortMapping, len(networkState.PortMappings))
@@ -299,6 +416,40 @@ func RunContainerShim() {
 		}
 	}
 
+	// Phase 21: 生成容器的 /etc/hosts、/etc/resolv.conf、/etc/hostname（须在放行 init 前完成）
+	if err := writeEtcFiles(rCfg, containerDir, st.NetworkState, store); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		fail("write container etc files: %v", err)
+	}
+
+	// Phase 21: 用户自定义网络上的容器启用内嵌 DNS
+	resolver, err := startEmbeddedDNS(rCfg, store, containerDir, st.NetworkState, cmd.Process.Pid)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		fail("start embedded dns: %v", err)
+	}
+
+	// Phase 21: 启用 userland-proxy 时为发布端口启动用户态代理
+	proxy, err := startUserlandProxy(filepath.Dir(filepath.Dir(containerDir)), networkState)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		fail("start userland proxy: %v", err)
+	}
+
+	// Phase 14: cgroup 与网络就绪，放行 init 继续启动
+	if err := releaseInit(syncW); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		logs.Close()
+		fail("release container init: %v", err)
+	}
+
 	// Persist running state (must happen before notifying the parent)
 	if err := st.SetRunning(cmd.Process.Pid); err != nil {
 		_ = cmd.Process.Kill()
@@ -307,16 +458,37 @@ func RunContainerShim() {
 		fail("update state to running: %v", err)
 	}
 
+	// Phase 20: 记录 start 事件（先于通知父进程，保证 run -d 返回时事件已可见）
+	logContainerEvent(filepath.Dir(filepath.Dir(containerDir)), rCfg, events.ActionStart, nil)
+
 	// Notify the parent process that the container is running and state is updated.
 	if notify != nil {
 		_, _ = fmt.Fprintln(notify, "OK")
 		_ = notify.Close()
 	}
 
+	// Phase 18: 将 PTY 输出复制到日志（TTY 模式下 stdout/stderr 合并）
+	var doneOut <-chan struct{}
+	if cons != nil {
+		doneOut = pipePTY(cons.master, io.MultiWriter(logs.stdout, attach.output(attachStreamStdout)), false)
+	}
+
 	// Wait for container exit and persist exit code
 	exitCode := waitForExit(cmd)
+	if cons != nil {
+		drainPTY(cons.master, doneOut)
+	}
+	if stdinW != nil {
+		_ = stdinW.Close()
+	}
+	resolver.Close() // Phase 21: 容器退出后停止内嵌 DNS
+	proxy.Close()    // Phase 21: 容器退出后停止用户态代理
+	// Phase 21: network connect 可能在运行期间更新了 state.json 中的附加网卡
+	_ = st.Reload()
 	_ = st.SetStopped(exitCode)
+	attach.closeWithExit(exitCode)
 	logs.Close()
+	logExitEvents(filepath.Dir(filepath.Dir(containerDir)), rCfg, exitCode, cgroupManager, cgroupPath)
 
 	// Phase 9: 清理快照（先于网络和 cgroup）
 	if snapshotter != nil {
@@ -325,6 +497,7 @@ func RunContainerShim() {
 
 	// Phase 7: 清理网络（先于 cgroup）
 	if networkManager != nil && networkState != nil {
+		networkState.Endpoints = connectedEndpoints(st.NetworkState)
 		_ = networkManager.Teardown(cfg.ID, networkState)
 	}
 
@@ -0,0 +1,116 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// ipcSysctlPrefixes 是属于 IPC namespace 的 sysctl 前缀
+var ipcSysctlPrefixes = []string{"kernel.shm", "kernel.msg", "fs.mqueue."}
+
+// ValidateSysctls 检查 --sysctl 是否只修改容器自己的命名空间（Phase 17 新增）。
+//
+// 对齐 Docker 的白名单：
+// - net.*：要求私有网络命名空间（bridge/none），host/container: 模式下会改到别人的网络
+// - kernel.shm*、kernel.msg*、fs.mqueue.*：要求私有 IPC 命名空间
+// 其余 sysctl 不区分命名空间，写入会影响宿主机，一律拒绝。
+func ValidateSysctls(config *ContainerConfig) error {
+	privateNet := config.NetworkConfig != nil && config.NetworkConfig.NeedsNetworkNamespace()
+
+	for key := range config.Sysctls {
+		if key == "" || strings.ContainsAny(key, "/ ") || strings.Contains(key, "..") {
+			return fmt.Errorf("invalid sysctl key %q", key)
+		}
+
+		switch {
+		case strings.HasPrefix(key, "net."):
+			if !privateNet {
+				return fmt.Errorf("sysctl %q is not allowed: the container does not have a private network namespace (--network %s)",
+					key, config.NetworkConfig.GetMode())
+			}
+		case isIPCSysctl(key):
+			if !config.IpcMode.IsPrivate() {
+				return fmt.Errorf("sysctl %q is not allowed: the container does not have a private IPC namespace (--ipc %s)",
+					key, config.IpcMode)
+			}
+		default:
+			return fmt.Errorf("sysctl %q is not allowed: only namespaced sysctls are supported (net.*, kernel.shm*, kernel.msg*, fs.mqueue.*)", key)
+		}
+	}
+	return nil
+}
+
+// isIPCSysctl 判断 key 是否属于 IPC namespace
+func isIPCSysctl(key string) bool {
+	for _, prefix := range ipcSysctlPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySysctls 将 sysctl 写入容器的 /proc/sys。
+// 在 mountProc 之后、restrictProc 之前调用（此后 /proc/sys 变为只读）。
+func applySysctls(sysctls map[string]string) error {
+	// 按 key 排序，保证写入顺序稳定（部分参数之间存在依赖，如 shmmax/shmall）
+	keys := make([]string, 0, len(sysctls))
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := filepath.Join("/proc/sys", strings.ReplaceAll(key, ".", "/"))
+		if err := os.WriteFile(path, []byte(sysctls[key]), 0644); err != nil {
+			return fmt.Errorf("write sysctl %s=%s: %w", key, sysctls[key], err)
+		}
+	}
+	return nil
+}
+
+// procReadonlyPaths 是容器内以只读方式重新挂载的 /proc 子路径（对齐 Docker 默认配置）
+var procReadonlyPaths = []string{
+	"/proc/bus",
+	"/proc/fs",
+	"/proc/irq",
+	"/proc/sys",
+	"/proc/sysrq-trigger",
+}
+
+// restrictProc 将 /proc 中可影响宿主内核的路径重新挂载为只读（Phase 17 新增）。
+//
+// 通过 bind mount 到自身再 remount 只读实现；--sysctl 必须在此之前写入。
+// privileged 容器保持 /proc 可写。
+func restrictProc(privileged bool) error {
+	if privileged {
+		return nil
+	}
+
+	for _, path := range procReadonlyPaths {
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("stat %s: %w", path, err)
+		}
+
+		if err := unix.Mount(path, path, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind mount %s: %w", path, err)
+		}
+		// remount 时必须保留 proc 挂载原有的 nosuid/nodev/noexec，否则内核拒绝
+		flags := uintptr(unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY | unix.MS_NOSUID | unix.MS_NODEV | unix.MS_NOEXEC)
+		if err := unix.Mount("", path, "", flags, ""); err != nil {
+			return fmt.Errorf("remount %s read-only: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// defaultSyslogAddress 是本机 syslog 守护进程的套接字
+const defaultSyslogAddress = "/dev/log"
+
+// syslog severity（RFC 5424）：stdout 记为 info，stderr 记为 err
+const (
+	syslogSeverityErr  = 3
+	syslogSeverityInfo = 6
+)
+
+// syslogFacilities 是 --log-opt syslog-facility 支持的设施名称（RFC 5424 编号）
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3,
+	"auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogOptions 是 syslog 驱动的配置
+type syslogOptions struct {
+	address  string
+	facility int
+	tag      string
+}
+
+// parseSyslogOpts 解析 syslog 的 --log-opt：
+// - syslog-address：unixgram:///path 或绝对路径，默认 /dev/log
+// - syslog-facility：设施名称，默认 daemon（对齐 Docker）
+// - tag：消息标识，默认容器 ID 前 12 位
+func parseSyslogOpts(opts map[string]string, defaultTag string) (syslogOptions, error) {
+	result := syslogOptions{facility: syslogFacilities["daemon"], tag: defaultTag}
+
+	address, err := parseUnixgramAddress("syslog-address", opts["syslog-address"], defaultSyslogAddress)
+	if err != nil {
+		return result, err
+	}
+	result.address = address
+
+	if name := opts["syslog-facility"]; name != "" {
+		facility, ok := syslogFacilities[name]
+		if !ok {
+			return result, fmt.Errorf("invalid log-opt syslog-facility %q", name)
+		}
+		result.facility = facility
+	}
+
+	if tag := opts["tag"]; tag != "" {
+		result.tag = tag
+	}
+	return result, nil
+}
+
+// newSyslogDriver 创建 syslog 驱动。
+//
+// 每行输出作为一条 RFC 3164 格式的本地消息发送（与 glibc syslog(3) 写入 /dev/log 的格式相同）：
+//
+//	<PRI>Jan  2 15:04:05 TAG[PID]: MESSAGE
+func newSyslogDriver(config *ContainerConfig) (LogDriver, error) {
+	opts, err := parseSyslogOpts(config.LogOpts, logTag(config))
+	if err != nil {
+		return nil, err
+	}
+
+	pid := os.Getpid()
+	d, err := newDatagramLogDriver(opts.address, func(stream string, line []byte) []byte {
+		severity := syslogSeverityInfo
+		if stream == LogStreamStderr {
+			severity = syslogSeverityErr
+		}
+		header := fmt.Sprintf("<%d>%s %s[%d]: ", opts.facility*8+severity, time.Now().Format(time.Stamp), opts.tag, pid)
+		return append([]byte(header), line...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to syslog at %s: %w", opts.address, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,201 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks 是 /proc/<pid>/stat 中 CPU 时间的单位（USER_HZ，Linux 对用户态固定为 100）
+const clockTicks = 100
+
+// ProcessInfo 描述容器内的一个进程（Phase 20 新增，用于 top）
+type ProcessInfo struct {
+	PID     int           // 宿主机 PID
+	NSPID   int           // 容器 PID 命名空间内的 PID（/proc/<pid>/status 的 NSpid 最后一项）
+	PPID    int           // 宿主机父进程 PID
+	UID     int           // 真实 UID（宿主机视角）
+	CPUTime time.Duration // 用户态 + 内核态 CPU 时间
+	Command string        // 完整命令行；内核线程或僵尸进程为 [comm]
+}
+
+// ContainerPIDs 返回属于容器的所有宿主机 PID（升序）。
+//
+// 优先读取容器 cgroup 的 cgroup.procs（cgroupDir 为空或不可读时跳过）；
+// 否则在容器拥有私有 PID 命名空间时，遍历 /proc 找出与 init 进程处于同一
+// PID 命名空间的进程；与宿主或其他容器共享 PID 命名空间时（sharedPidNS），
+// 只能退化为 init 进程的后代进程树。
+func ContainerPIDs(initPid int, cgroupDir string, sharedPidNS bool) ([]int, error) {
+	if cgroupDir != "" {
+		if pids, err := readCgroupProcs(cgroupDir); err == nil && len(pids) > 0 {
+			return pids, nil
+		}
+	}
+
+	initNS, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", initPid))
+	if err != nil {
+		return nil, fmt.Errorf("read pid namespace of %d: %w", initPid, err)
+	}
+	selfNS, err := os.Readlink("/proc/self/ns/pid")
+	if err != nil {
+		return nil, fmt.Errorf("read own pid namespace: %w", err)
+	}
+
+	pids, err := listProcPIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	if !sharedPidNS && initNS != selfNS {
+		var result []int
+		for _, pid := range pids {
+			if ns, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", pid)); err == nil && ns == initNS {
+				result = append(result, pid)
+			}
+		}
+		return result, nil
+	}
+
+	return descendantPIDs(initPid, pids), nil
+}
+
+// readCgroupProcs 读取 cgroup.procs 中的 PID 列表
+func readCgroupProcs(cgroupDir string) ([]int, error) {
+	data, err := os.ReadFile(filepath.Join(cgroupDir, "cgroup.procs"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, field := range strings.Fields(string(data)) {
+		if pid, err := strconv.Atoi(field); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// listProcPIDs 列出 /proc 下的所有进程 PID（升序）
+func listProcPIDs() ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("read /proc: %w", err)
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// descendantPIDs 返回 root 及其所有后代进程（按 PPID 构建进程树）
+func descendantPIDs(root int, pids []int) []int {
+	children := make(map[int][]int)
+	for _, pid := range pids {
+		if stat, err := readProcStat(pid); err == nil {
+			children[stat.ppid] = append(children[stat.ppid], pid)
+		}
+	}
+
+	result := []int{root}
+	for i := 0; i < len(result); i++ {
+		result = append(result, children[result[i]]...)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// procStat 是 /proc/<pid>/stat 中 top 关心的字段
+type procStat struct {
+	comm  string
+	ppid  int
+	ticks uint64 // utime + stime
+}
+
+// readProcStat 解析 /proc/<pid>/stat。
+// comm 可能包含空格和括号，因此以最后一个 ')' 为界切分。
+func readProcStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	text := string(data)
+	open := strings.IndexByte(text, '(')
+	end := strings.LastIndexByte(text, ')')
+	if open < 0 || end < open {
+		return nil, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+
+	// ')' 之后从第 3 个字段（state）开始
+	fields := strings.Fields(text[end+1:])
+	if len(fields) < 13 {
+		return nil, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+
+	stat := &procStat{comm: text[open+1 : end]}
+	stat.ppid, _ = strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	stat.ticks = utime + stime
+	return stat, nil
+}
+
+// ReadProcessInfo 读取宿主机 PID 对应进程的信息
+func ReadProcessInfo(pid int) (*ProcessInfo, error) {
+	stat, err := readProcStat(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ProcessInfo{
+		PID:     pid,
+		NSPID:   pid,
+		PPID:    stat.ppid,
+		CPUTime: time.Duration(stat.ticks) * time.Second / clockTicks,
+	}
+
+	// status 中的 Uid: <real> <effective> <saved> <fs>；NSpid: <host> ... <innermost>
+	if f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid)); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), ":")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(value)
+			if len(fields) == 0 {
+				continue
+			}
+			switch key {
+			case "Uid":
+				info.UID, _ = strconv.Atoi(fields[0])
+			case "NSpid":
+				info.NSPID, _ = strconv.Atoi(fields[len(fields)-1])
+			}
+		}
+		f.Close()
+	}
+
+	// cmdline 以 NUL 分隔参数；内核线程和僵尸进程为空
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil && len(data) > 0 {
+		info.Command = strings.Join(strings.Split(strings.TrimRight(string(data), "\x00"), "\x00"), " ")
+	} else {
+		info.Command = "[" + stat.comm + "]"
+	}
+
+	return info, nil
+}
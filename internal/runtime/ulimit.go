@@ -0,0 +1,133 @@
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"minidocker/internal/state"
+
+	"golang.org/x/sys/unix"
+)
+
+// ulimitResources 是 --ulimit 资源名到 RLIMIT_* 的映射（对齐 Docker/runc）
+var ulimitResources = map[string]int{
+	"as":         unix.RLIMIT_AS,
+	"core":       unix.RLIMIT_CORE,
+	"cpu":        unix.RLIMIT_CPU,
+	"data":       unix.RLIMIT_DATA,
+	"fsize":      unix.RLIMIT_FSIZE,
+	"locks":      unix.RLIMIT_LOCKS,
+	"memlock":    unix.RLIMIT_MEMLOCK,
+	"msgqueue":   unix.RLIMIT_MSGQUEUE,
+	"nice":       unix.RLIMIT_NICE,
+	"nofile":     unix.RLIMIT_NOFILE,
+	"nproc":      unix.RLIMIT_NPROC,
+	"rss":        unix.RLIMIT_RSS,
+	"rtprio":     unix.RLIMIT_RTPRIO,
+	"rttime":     unix.RLIMIT_RTTIME,
+	"sigpending": unix.RLIMIT_SIGPENDING,
+	"stack":      unix.RLIMIT_STACK,
+}
+
+// ParseUlimit 解析 --ulimit 参数（Phase 17 新增）。
+//
+// 格式: <name>=<soft>[:<hard>]，省略 hard 时与 soft 相同；
+// -1 或 unlimited 表示不限制。例如 nofile=1024:2048、core=0、memlock=-1。
+func ParseUlimit(spec string) (Ulimit, error) {
+	name, limits, ok := strings.Cut(spec, "=")
+	if !ok || name == "" || limits == "" {
+		return Ulimit{}, fmt.Errorf("invalid ulimit %q (expected <name>=<soft>[:<hard>])", spec)
+	}
+
+	name = strings.ToLower(name)
+	if _, known := ulimitResources[name]; !known {
+		return Ulimit{}, fmt.Errorf("invalid ulimit type %q (supported: %s)", name, strings.Join(ulimitNames(), ", "))
+	}
+
+	softStr, hardStr, hasHard := strings.Cut(limits, ":")
+	soft, err := parseUlimitValue(softStr)
+	if err != nil {
+		return Ulimit{}, fmt.Errorf("invalid soft limit for %s: %w", name, err)
+	}
+	hard := soft
+	if hasHard {
+		if hard, err = parseUlimitValue(hardStr); err != nil {
+			return Ulimit{}, fmt.Errorf("invalid hard limit for %s: %w", name, err)
+		}
+	}
+	if soft > hard {
+		return Ulimit{}, fmt.Errorf("ulimit soft limit must be less than or equal to hard limit: %s", spec)
+	}
+
+	return Ulimit{Name: name, Soft: soft, Hard: hard}, nil
+}
+
+// parseUlimitValue 解析单个限制值，-1/unlimited 转换为 RLIM_INFINITY
+func parseUlimitValue(s string) (uint64, error) {
+	if s == "-1" || s == "unlimited" {
+		return unix.RLIM_INFINITY, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a non-negative integer, -1 or unlimited", s)
+	}
+	return v, nil
+}
+
+// ulimitNames 返回排序后的资源名列表（用于错误提示）
+func ulimitNames() []string {
+	names := make([]string, 0, len(ulimitResources))
+	for name := range ulimitResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyUlimits 在 init 进程中设置资源限制，随后 fork/exec 的用户命令会继承。
+//
+// 必须在 switchUser 之前调用：提升硬限制需要 CAP_SYS_RESOURCE。
+// unix.Prlimit 走 syscall.prlimit，对 RLIMIT_NOFILE 会清除 Go 运行时启动时
+// 保存的原始软限制，因此 os/exec 不会在子进程中把 nofile 恢复成旧值。
+func applyUlimits(ulimits []Ulimit) error {
+	for _, u := range ulimits {
+		resource, ok := ulimitResources[u.Name]
+		if !ok {
+			return fmt.Errorf("unknown ulimit type %q", u.Name)
+		}
+		rlimit := unix.Rlimit{Cur: u.Soft, Max: u.Hard}
+		if err := unix.Prlimit(0, resource, &rlimit, nil); err != nil {
+			return fmt.Errorf("prlimit %s=%d:%d: %w", u.Name, u.Soft, u.Hard, err)
+		}
+	}
+	return nil
+}
+
+// toStateUlimits 将运行时资源限制转换为持久化格式。
+func toStateUlimits(ulimits []Ulimit) []state.UlimitConfig {
+	if len(ulimits) == 0 {
+		return nil
+	}
+	out := make([]state.UlimitConfig, len(ulimits))
+	for i, u := range ulimits {
+		out[i] = state.UlimitConfig{Name: u.Name, Soft: u.Soft, Hard: u.Hard}
+	}
+	return out
+}
+
+// fromStateUlimits 从持久化格式恢复运行时资源限制。
+func fromStateUlimits(ulimits []state.UlimitConfig) []Ulimit {
+	if len(ulimits) == 0 {
+		return nil
+	}
+	out := make([]Ulimit, len(ulimits))
+	for i, u := range ulimits {
+		out[i] = Ulimit{Name: u.Name, Soft: u.Soft, Hard: u.Hard}
+	}
+	return out
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"minidocker/pkg/fileutil"
 	"minidocker/pkg/idutil"
@@ -35,6 +36,9 @@ type ContainerConfig struct {
 	// 是否分配 TTY
 	TTY bool `json:"tty"`
 
+	// 是否保持 STDIN 打开（-i，Phase 18）
+	Interactive bool `json:"interactive,omitempty"`
+
 	// 是否后台运行（Phase 3）
 	Detached bool `json:"detached"`
 
@@ -58,6 +62,25 @@ type ContainerConfig struct {
 	// 网络模式（bridge/host/none）
 	NetworkMode string `json:"networkMode,omitempty"`
 
+	// Phase 21: 用户自定义网络名（bridge 模式，空表示默认 bridge 网络）
+	Network string `json:"network,omitempty"`
+
+	// Phase 21: 主网络上的 DNS 别名（--network-alias）
+	NetworkAliases []string `json:"networkAliases,omitempty"`
+
+	// Phase 21: --ip 指定的静态 IPv4 地址
+	IPAddress string `json:"ipAddress,omitempty"`
+
+	// Phase 21: --ip6 指定的静态 IPv6 地址
+	IPv6Address string `json:"ipv6Address,omitempty"`
+
+	// Phase 21: --mac-address 指定的容器网卡 MAC 地址
+	MacAddress string `json:"macAddress,omitempty"`
+
+	// Phase 21: --network-opt 指定的容器接收/发送方向限速（bit/s）
+	IngressRate uint64 `json:"ingressRate,omitempty"`
+	EgressRate  uint64 `json:"egressRate,omitempty"`
+
 	// 端口映射
 	PortMappings []PortMapping `json:"portMappings,omitempty"`
 
@@ -82,6 +105,106 @@ type ContainerConfig struct {
 	// --- Phase 10: 卷挂载 ---
 	// Mounts 保存挂载点配置（bind mounts 和 named volumes）
 	Mounts []MountConfig `json:"mounts,omitempty"`
+
+	// --- Phase 14: 设备管理 ---
+	// Devices 保存 --device 映射的设备
+	Devices []DeviceConfig `json:"devices,omitempty"`
+
+	// Privileged 是否解除默认设备白名单
+	Privileged bool `json:"privileged,omitempty"`
+
+	// CgroupNS cgroup namespace 模式（host/private）
+	CgroupNS string `json:"cgroupns,omitempty"`
+
+	// --- Phase 15: 命名空间共享 ---
+	// PidMode/IpcMode/UTSMode："" 为私有，"host" 或 "container:<id>"
+	PidMode string `json:"pidMode,omitempty"`
+	IpcMode string `json:"ipcMode,omitempty"`
+	UTSMode string `json:"utsMode,omitempty"`
+
+	// Pod 所属 pod 的 ID；CgroupParent 为 pod 的 cgroup 父路径
+	Pod          string `json:"pod,omitempty"`
+	CgroupParent string `json:"cgroupParent,omitempty"`
+
+	// --- Phase 17: 资源限制（rlimit）与内核参数 ---
+	// Ulimits 保存 --ulimit 设置的进程资源限制
+	Ulimits []UlimitConfig `json:"ulimits,omitempty"`
+
+	// Sysctls 保存 --sysctl 设置的命名空间化内核参数
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// --- Phase 19: 日志 ---
+	// LogDriver 保存 --log-driver，空表示 json-file（兼容旧容器）
+	LogDriver string `json:"logDriver,omitempty"`
+
+	// LogOpts 保存 --log-opt 设置的日志驱动选项（如 max-size、max-file）
+	LogOpts map[string]string `json:"logOpts,omitempty"`
+
+	// --- Phase 21: /etc/hosts 与 /etc/resolv.conf ---
+	// DNS/DNSSearch/DNSOptions 保存 --dns、--dns-search、--dns-option
+	DNS        []string `json:"dns,omitempty"`
+	DNSSearch  []string `json:"dnsSearch,omitempty"`
+	DNSOptions []string `json:"dnsOptions,omitempty"`
+
+	// ExtraHosts 保存 --add-host 追加的 "name:ip" 条目
+	ExtraHosts []string `json:"extraHosts,omitempty"`
+}
+
+// containerModePrefix 是加入其他容器命名空间的模式前缀（与 runtime/network 保持一致）
+const containerModePrefix = "container:"
+
+// NamespaceDependencies 返回该容器通过 container:<id> 模式共享命名空间的目标容器 ID（去重）。
+// 用于依赖跟踪：目标容器在依赖者运行期间不可删除。
+func (c *ContainerConfig) NamespaceDependencies() []string {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, mode := range []string{c.NetworkMode, c.PidMode, c.IpcMode, c.UTSMode} {
+		if !strings.HasPrefix(mode, containerModePrefix) {
+			continue
+		}
+		id := strings.TrimPrefix(mode, containerModePrefix)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			deps = append(deps, id)
+		}
+	}
+	return deps
+}
+
+// DeviceConfig 表示持久化的设备映射配置
+type DeviceConfig struct {
+	// PathOnHost 是宿主机上的设备路径
+	PathOnHost string `json:"pathOnHost"`
+
+	// PathInContainer 是容器内的设备路径
+	PathInContainer string `json:"pathInContainer"`
+
+	// Permissions 是 cgroup 访问权限（rwm 子集）
+	Permissions string `json:"permissions"`
+
+	// Type 是设备类型（c 或 b）
+	Type string `json:"type"`
+
+	// Major/Minor 是设备号
+	Major int64 `json:"major"`
+	Minor int64 `json:"minor"`
+
+	// FileMode 是设备节点权限位
+	FileMode uint32 `json:"fileMode"`
+
+	// UID/GID 是设备节点属主
+	UID uint32 `json:"uid"`
+	GID uint32 `json:"gid"`
+}
+
+// UlimitConfig 表示持久化的进程资源限制（Phase 17 新增）
+type UlimitConfig struct {
+	// Name 是资源名（如 nofile）
+	Name string `json:"name"`
+
+	// Soft/Hard 是软/硬限制（RLIM_INFINITY 表示不限制）
+	Soft uint64 `json:"soft"`
+	Hard uint64 `json:"hard"`
 }
 
 // MountConfig 表示持久化的挂载配置
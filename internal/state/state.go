@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -58,6 +60,10 @@ type ContainerState struct {
 	// Phase 9: 镜像引用（用于显示）
 	ImageRef string `json:"imageRef,omitempty"`
 
+	// Phase 21: 容器进程的启动时间（/proc/<pid>/stat 的 starttime，开机以来的时钟节拍）。
+	// 宿主机重启后 PID 可能被其他进程复用，与 PID 一起才能确认进程仍是容器进程。
+	PidStartTime uint64 `json:"pidStartTime,omitempty"`
+
 	// 内部字段（不序列化）
 	containerDir string
 }
@@ -68,9 +74,37 @@ type NetworkState struct {
 	IPAddress     string        `json:"ipAddress,omitempty"`
 	Gateway       string        `json:"gateway,omitempty"`
 	MacAddress    string        `json:"macAddress,omitempty"`
+	MTU           int           `json:"mtu,omitempty"`
 	VethHost      string        `json:"vethHost,omitempty"`
 	VethContainer string        `json:"vethContainer,omitempty"`
 	PortMappings  []PortMapping `json:"portMappings,omitempty"`
+
+	// Phase 21: 主网卡所在的用户自定义网络（空表示默认 bridge 网络）
+	Network string `json:"network,omitempty"`
+
+	// Phase 21: 通过 network connect 附加的网卡
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+
+	// Phase 21: 主网络上的 DNS 别名，由内嵌 DNS 解析
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Phase 21: 双栈网络上的 IPv6 地址与网关
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	IPv6Gateway string `json:"ipv6Gateway,omitempty"`
+}
+
+// Endpoint 表示容器在附加网络上的网卡（Phase 21 新增）
+type Endpoint struct {
+	Network       string   `json:"network"`
+	IPAddress     string   `json:"ipAddress"`
+	Gateway       string   `json:"gateway,omitempty"`
+	IPv6Address   string   `json:"ipv6Address,omitempty"`
+	IPv6Gateway   string   `json:"ipv6Gateway,omitempty"`
+	MacAddress    string   `json:"macAddress,omitempty"`
+	MTU           int      `json:"mtu,omitempty"`
+	VethHost      string   `json:"vethHost"`
+	VethContainer string   `json:"vethContainer"`
+	Aliases       []string `json:"aliases,omitempty"`
 }
 
 // NewState 创建一个新的容器状态
@@ -154,6 +188,7 @@ func (s *ContainerState) Reload() error {
 	s.NetworkState = newState.NetworkState   // Phase 7
 	s.SnapshotPath = newState.SnapshotPath   // Phase 9
 	s.ImageRef = newState.ImageRef           // Phase 9
+	s.PidStartTime = newState.PidStartTime   // Phase 21
 
 	return nil
 }
@@ -162,6 +197,8 @@ func (s *ContainerState) Reload() error {
 func (s *ContainerState) SetRunning(pid int) error {
 	s.Status = StatusRunning
 	s.Pid = pid
+	// Phase 21: 记录进程启动时间（读取失败时为 0，ProcessAlive 退化为只检查 PID）
+	_, s.PidStartTime, _ = processStat(pid)
 	now := time.Now()
 	s.StartedAt = &now
 	return s.Save()
@@ -210,6 +247,52 @@ func (s *ContainerState) IsRunning() bool {
 	return true
 }
 
+// ProcessAlive 检查状态为 running 的容器进程是否仍然存在（Phase 21 新增）。
+// 与 IsRunning 不同，它只读取 /proc，不修正也不保存状态：调用方不持有容器锁时，
+// 写回状态可能覆盖 shim 同时记录的真实退出码。
+// 记录了启动时间时，同一 PID 的进程启动时间不同说明 PID 已被复用（例如宿主机重启后）；
+// 已退出但尚未被回收的进程（zombie）同样视为不存在。
+func (s *ContainerState) ProcessAlive() bool {
+	if s.Status != StatusRunning || s.Pid == 0 {
+		return false
+	}
+
+	procState, startTime, err := processStat(s.Pid)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+		// 无法读取 /proc 时保守认为仍在运行
+		return true
+	}
+	if procState == "Z" || procState == "X" {
+		return false
+	}
+	return s.PidStartTime == 0 || startTime == s.PidStartTime
+}
+
+// processStat 返回进程的状态（/proc/<pid>/stat 第 3 个字段）和启动时间（第 22 个字段）
+func processStat(pid int) (string, uint64, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return "", 0, err
+	}
+	// comm 字段可能包含空格和括号，从最后一个 ')' 之后解析（之后的第一个字段是第 3 个字段 state）
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return "", 0, fmt.Errorf("parse /proc/%d/stat: missing comm", pid)
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 20 {
+		return "", 0, fmt.Errorf("parse /proc/%d/stat: too few fields", pid)
+	}
+	startTime, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse /proc/%d/stat starttime: %w", pid, err)
+	}
+	return fields[0], startTime, nil
+}
+
 // GetContainerDir 返回容器目录路径
 func (s *ContainerState) GetContainerDir() string {
 	return s.containerDir
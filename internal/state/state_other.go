@@ -39,10 +39,30 @@ type NetworkState struct {
 	Mode          string
 	IPAddress     string
 	Gateway       string
+	IPv6Address   string
+	IPv6Gateway   string
 	MacAddress    string
+	MTU           int
 	VethHost      string
 	VethContainer string
 	PortMappings  []PortMapping
+	Network       string
+	Endpoints     []Endpoint
+	Aliases       []string
+}
+
+// Endpoint 表示容器在附加网络上的网卡
+type Endpoint struct {
+	Network       string
+	IPAddress     string
+	Gateway       string
+	IPv6Address   string
+	IPv6Gateway   string
+	MacAddress    string
+	MTU           int
+	VethHost      string
+	VethContainer string
+	Aliases       []string
 }
 
 // ContainerLock 是容器锁的 stub
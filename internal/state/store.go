@@ -200,6 +200,34 @@ func (s *Store) Delete(containerID string) error {
 	return nil
 }
 
+// Dependents 返回共享了指定容器命名空间、且仍在运行的容器 ID 列表（Phase 15 新增）。
+// 通过扫描各容器 config.json 中的 container:<id> 模式实现，无需额外索引。
+func (s *Store) Dependents(containerID string) ([]string, error) {
+	states, err := s.List(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []string
+	for _, st := range states {
+		if st.ID == containerID {
+			continue
+		}
+		cfg, err := LoadConfig(st.GetContainerDir())
+		if err != nil {
+			continue // 配置损坏的容器不影响判断
+		}
+		for _, dep := range cfg.NamespaceDependencies() {
+			if dep == containerID {
+				dependents = append(dependents, st.ID)
+				break
+			}
+		}
+	}
+
+	return dependents, nil
+}
+
 // Exists 检查容器是否存在
 func (s *Store) Exists(containerID string) bool {
 	containerDir := s.ContainerDir(containerID)
@@ -35,6 +35,16 @@ const (
 	// ExecConfigEnvVar passes exec configuration JSON.
 	// Contains container PID, command, and TTY settings.
 	ExecConfigEnvVar = "MINIDOCKER_EXEC_CONFIG"
+
+	// InitSyncFdEnvVar specifies the fd number of the init sync pipe.
+	// The container init blocks on this fd until the parent (CLI or shim) has
+	// placed it into its cgroup and configured networking.
+	InitSyncFdEnvVar = "MINIDOCKER_INIT_SYNC_FD"
+
+	// PauseEnvVar triggers pause mode when set to "1".
+	// Used as the main process of a pod's infra container: it only holds the
+	// pod's namespaces and sleeps until it receives a termination signal.
+	PauseEnvVar = "MINIDOCKER_PAUSE"
 )
 
 // internalEnvPrefixes lists all MINIDOCKER_* environment variable prefixes
@@ -47,6 +57,8 @@ var internalEnvPrefixes = []string{
 	ShimEnvVar + "=",
 	ShimNotifyFdEnvVar + "=",
 	ExecConfigEnvVar + "=",
+	InitSyncFdEnvVar + "=",
+	PauseEnvVar + "=",
 }
 
 // FilterMinidockerEnv removes all MINIDOCKER_* environment variables from the list.
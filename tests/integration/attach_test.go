@@ -0,0 +1,173 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// Phase 18: minidocker attach 集成测试
+//
+// 后台容器的 shim 持有容器 stdio（-t 时为 PTY master），通过容器目录下的
+// attach socket 多路复用给任意数量的 attach 客户端。
+
+// startDetached 以 run -d 启动容器并返回容器 ID
+func startDetached(t *testing.T, stateRoot, rootfs string, args ...string) string {
+	t.Helper()
+
+	runArgs := append([]string{"--root", stateRoot, "run", "-d", "--network", "none", "--rootfs", rootfs}, args...)
+	output, err := exec.Command(minidockerBin, runArgs...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run -d failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	// 等待 shim 创建 attach socket
+	time.Sleep(500 * time.Millisecond)
+	return containerID
+}
+
+// TestAttachStdin 测试 attach 转发 stdin、分离 stdout/stderr，并透传退出码
+func TestAttachStdin(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "-i",
+		"/bin/sh", "-c", "read line; echo got $line; echo oops >&2; exit 3")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "attach", containerID)
+	cmd.Stdin = strings.NewReader("hello\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok || exitErr.ExitCode() != 3 {
+		t.Errorf("expected exit code 3, got: %v. Stderr: %s", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "got hello") {
+		t.Errorf("expected stdin to reach the container, stdout: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "oops") {
+		t.Errorf("expected container stderr on attach stderr, got: %q", stderr.String())
+	}
+}
+
+// TestAttachMultiple 测试多个 attach 同时连接时都能收到输出
+func TestAttachMultiple(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs,
+		"/bin/sh", "-c", "sleep 1; echo tick; sleep 1; echo tock")
+
+	outputs := make([][]byte, 2)
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs[i], _ = exec.Command(minidockerBin, "--root", stateRoot, "attach", "--no-stdin", containerID).CombinedOutput()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, out := range outputs {
+		if !strings.Contains(string(out), "tick") || !strings.Contains(string(out), "tock") {
+			t.Errorf("attacher %d: expected tick and tock, got: %q", i, out)
+		}
+	}
+}
+
+// TestAttachDetachKeys 测试 TTY 容器通过 detach 按键断开后继续运行
+func TestAttachDetachKeys(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "-it", "/bin/sh")
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "attach",
+		"--detach-keys", "ctrl-x,x", containerID)
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: 30, Cols: 90})
+	if err != nil {
+		t.Fatalf("start pty failed: %v", err)
+	}
+	defer ptmx.Close()
+
+	var buf bytes.Buffer
+	go func() { _, _ = io.Copy(&buf, ptmx) }()
+
+	time.Sleep(500 * time.Millisecond)
+	_, _ = ptmx.Write([]byte("stty size\r"))
+	time.Sleep(500 * time.Millisecond)
+	_, _ = ptmx.Write([]byte{0x18, 'x'})
+
+	waitDone := make(chan error, 1)
+	go func() { waitDone <- cmd.Wait() }()
+
+	select {
+	case err := <-waitDone:
+		if err != nil {
+			t.Errorf("expected attach to exit 0 after detach, got: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatalf("timeout waiting for detach. Output so far: %q", buf.String())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if !strings.Contains(buf.String(), "30 90") {
+		t.Errorf("expected container tty size 30 90, got: %q", buf.String())
+	}
+
+	psOutput, err := exec.Command(minidockerBin, "--root", stateRoot, "ps", "-q").CombinedOutput()
+	if err != nil {
+		t.Fatalf("ps failed: %v\nOutput: %s", err, psOutput)
+	}
+	if !strings.Contains(string(psOutput), containerID[:12]) {
+		t.Errorf("expected container %s to keep running after detach, got: %s", containerID[:12], psOutput)
+	}
+}
+
+// TestAttachErrors 测试非法 detach 按键与已停止容器
+func TestAttachErrors(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "/bin/true")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "attach", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "is not running") {
+		t.Errorf("expected attach to a stopped container to fail, got: %v\nOutput: %s", err, output)
+	}
+
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "attach",
+		"--detach-keys", "ctrl-1", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "invalid detach key") {
+		t.Errorf("expected invalid detach key error, got: %v\nOutput: %s", err, output)
+	}
+}
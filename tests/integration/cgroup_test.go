@@ -406,7 +406,9 @@ func TestCombinedLimits(t *testing.T) {
 	}
 }
 
-// TestNoCgroupWithoutLimits 测试无资源限制时不创建 cgroup
+// TestNoCgroupWithoutLimits 测试 privileged 且无资源限制时不创建 cgroup
+// Phase 14: 普通容器总会创建 cgroup 以挂载设备白名单，私有 cgroup namespace 也需要独立 cgroup，
+// 只有 --privileged --cgroupns host 才跳过
 func TestNoCgroupWithoutLimits(t *testing.T) {
 	skipIfNotRoot(t)
 	skipIfNotCgroupV2(t)
@@ -416,9 +418,11 @@ func TestNoCgroupWithoutLimits(t *testing.T) {
 
 	stateRoot := t.TempDir()
 
-	// 运行不带资源限制的后台容器：此时不应该创建 /sys/fs/cgroup/minidocker/<id>
+	// 运行不带资源限制的 privileged 后台容器：此时不应该创建 /sys/fs/cgroup/minidocker/<id>
 	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
 		"-d",
+		"--privileged",
+		"--cgroupns", "host",
 		"--rootfs", rootfs,
 		"/bin/sleep", "10")
 	output, err := cmd.CombinedOutput()
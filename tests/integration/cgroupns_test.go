@@ -0,0 +1,120 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 14: cgroup namespace 集成测试
+//
+// 测试环境要求：
+// - cgroup v2 统一层级
+// - Root 权限
+
+// TestCgroupNSPrivateDefault 测试 cgroup v2 下默认使用私有 cgroup namespace
+func TestCgroupNSPrivateDefault(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	// 私有 cgroup namespace 中，容器自己的 cgroup 就是根 "/"
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--rootfs", rootfs,
+		"/bin/cat", "/proc/self/cgroup")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), "0::/\n") {
+		t.Errorf("expected cgroup path to be namespace root, got: %s", output)
+	}
+	if strings.Contains(string(output), "minidocker") {
+		t.Errorf("host cgroup path leaked into container: %s", output)
+	}
+}
+
+// TestCgroupNSMountShowsOwnLimits 测试 /sys/fs/cgroup 只显示容器自己的 cgroup 且只读
+func TestCgroupNSMountShowsOwnLimits(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+	skipIfControllerMissing(t, "memory")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"-m", "64m",
+		"--cgroupns", "private",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "cat /sys/fs/cgroup/memory.max; echo 1 > /sys/fs/cgroup/memory.max || echo write-denied")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), "67108864") {
+		t.Errorf("expected container to see its own memory.max (67108864), got: %s", output)
+	}
+	if !strings.Contains(string(output), "write-denied") {
+		t.Errorf("expected /sys/fs/cgroup to be read-only, got: %s", output)
+	}
+}
+
+// TestCgroupNSHost 测试 --cgroupns host 共享宿主 cgroup namespace
+func TestCgroupNSHost(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--cgroupns", "host",
+		"--rootfs", rootfs,
+		"/bin/cat", "/proc/self/cgroup")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	containerID := findSingleContainerID(t, stateRoot)
+	if !strings.Contains(string(output), "/minidocker/"+containerID) {
+		t.Errorf("expected full host cgroup path, got: %s", output)
+	}
+}
+
+// TestCgroupNSInvalidMode 测试非法 --cgroupns 参数
+func TestCgroupNSInvalidMode(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--cgroupns", "shared",
+		"--rootfs", rootfs,
+		"/bin/true")
+	output, err := cmd.CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected run to fail with invalid cgroupns, got: %s", output)
+	}
+	if !strings.Contains(string(output), "invalid cgroupns") {
+		t.Errorf("expected cgroupns error, got: %s", output)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 20: 细分 cgroup 统计集成测试
+//
+// inspect 的 State.ResourceStats 与 stats --format json 的 CgroupStats 包含
+// memory.stat 分项、cpu.stat 限流计数、按设备的 io.stat 以及 PSI。
+
+// cgroupStats 对应 cgroups.Stats 中测试关心的字段
+type cgroupStats struct {
+	MemoryStat struct {
+		Anon   int64 `json:"anon"`
+		Kernel int64 `json:"kernel"`
+	} `json:"memoryStat"`
+	CPUUsage         int64 `json:"cpuUsage"`
+	CPUUserUsage     int64 `json:"cpuUserUsage"`
+	CPUPeriods       int64 `json:"cpuPeriods"`
+	CPUThrottled     int64 `json:"cpuThrottledPeriods"`
+	CPUThrottledTime int64 `json:"cpuThrottledTime"`
+	Pressure         *struct {
+		CPU *struct {
+			Some struct {
+				Total int64 `json:"total"`
+			} `json:"some"`
+		} `json:"cpu"`
+		Memory json.RawMessage `json:"memory"`
+		IO     json.RawMessage `json:"io"`
+	} `json:"pressure"`
+}
+
+// TestInspectResourceStats 测试 CPU 限流的忙循环容器在 inspect 中报告限流与 PSI
+func TestInspectResourceStats(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+	skipIfControllerMissing(t, "memory")
+	skipIfControllerMissing(t, "cpu")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "--memory", "64m", "--cpus", "0.2",
+		"/bin/sh", "-c", "while :; do :; done")
+
+	// 至少经过若干个 100ms 的 cpu.max 周期
+	time.Sleep(time.Second)
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "inspect", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker inspect failed: %v\nOutput: %s", err, output)
+	}
+
+	var results []struct {
+		State struct {
+			ResourceStats *cgroupStats `json:"ResourceStats"`
+		} `json:"State"`
+	}
+	if err := json.Unmarshal(output, &results); err != nil {
+		t.Fatalf("failed to parse inspect JSON: %v\nOutput: %s", err, output)
+	}
+	if len(results) != 1 || results[0].State.ResourceStats == nil {
+		t.Fatalf("expected ResourceStats in inspect output: %s", output)
+	}
+	stats := results[0].State.ResourceStats
+
+	if stats.MemoryStat.Anon <= 0 && stats.MemoryStat.Kernel <= 0 {
+		t.Errorf("expected memory.stat breakdown, got %+v", stats.MemoryStat)
+	}
+	if stats.CPUUsage <= 0 || stats.CPUUserUsage <= 0 {
+		t.Errorf("expected CPU usage, got usage=%d user=%d", stats.CPUUsage, stats.CPUUserUsage)
+	}
+	if stats.CPUPeriods <= 0 || stats.CPUThrottled <= 0 || stats.CPUThrottledTime <= 0 {
+		t.Errorf("expected CPU throttling with --cpus 0.2, got periods=%d throttled=%d time=%d",
+			stats.CPUPeriods, stats.CPUThrottled, stats.CPUThrottledTime)
+	}
+
+	// PSI 依赖内核 CONFIG_PSI
+	if _, err := os.Stat("/proc/pressure/cpu"); err == nil {
+		if stats.Pressure == nil || stats.Pressure.CPU == nil {
+			t.Fatalf("expected cpu pressure in inspect output: %s", output)
+		}
+		if stats.Pressure.CPU.Some.Total <= 0 {
+			t.Errorf("expected throttled container to accumulate cpu pressure, got %d", stats.Pressure.CPU.Some.Total)
+		}
+		if stats.Pressure.Memory == nil || stats.Pressure.IO == nil {
+			t.Errorf("expected memory and io pressure in inspect output: %s", output)
+		}
+	}
+}
+
+// TestStatsCgroupDetail 测试 stats --format json 附带完整 cgroup 统计
+func TestStatsCgroupDetail(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+	skipIfControllerMissing(t, "memory")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "--memory", "64m", "sleep", "30")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "stats", "--no-stream", "--format", "json", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker stats failed: %v\nOutput: %s", err, output)
+	}
+
+	var entry struct {
+		CgroupStats *cgroupStats `json:"CgroupStats"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(output))), &entry); err != nil {
+		t.Fatalf("failed to parse stats JSON: %v\nOutput: %s", err, output)
+	}
+	if entry.CgroupStats == nil {
+		t.Fatalf("expected CgroupStats in stats output: %s", output)
+	}
+	if entry.CgroupStats.MemoryStat.Anon <= 0 && entry.CgroupStats.MemoryStat.Kernel <= 0 {
+		t.Errorf("expected memory.stat breakdown, got %+v", entry.CgroupStats.MemoryStat)
+	}
+}
+
+// TestInspectStoppedNoResourceStats 测试已停止的容器不输出 ResourceStats
+func TestInspectStoppedNoResourceStats(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "--memory", "64m", "true")
+	time.Sleep(500 * time.Millisecond)
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "inspect", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker inspect failed: %v\nOutput: %s", err, output)
+	}
+	if strings.Contains(string(output), "ResourceStats") {
+		t.Errorf("expected no ResourceStats for stopped container: %s", output)
+	}
+}
@@ -0,0 +1,186 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Phase 14: 设备管理集成测试
+//
+// 测试环境要求：
+// - cgroup v2 统一层级（设备白名单依赖 eBPF 设备控制器）
+// - Root 权限
+// - rootfs 中包含 mknod（busybox 满足）
+
+// skipIfNoMknod 跳过 rootfs 中没有 mknod 的环境
+func skipIfNoMknod(t *testing.T, rootfs string) {
+	t.Helper()
+	for _, p := range []string{"bin/mknod", "usr/bin/mknod", "bin/busybox"} {
+		if _, err := os.Stat(filepath.Join(rootfs, p)); err == nil {
+			return
+		}
+	}
+	t.Skip("rootfs does not contain mknod")
+}
+
+// TestDeviceDefaultAllowlist 测试默认白名单：基础设备可用，其他设备 mknod 被拒绝
+func TestDeviceDefaultAllowlist(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+	skipIfNoMknod(t, rootfs)
+
+	stateRoot := t.TempDir()
+
+	// /dev/null (1:3) 在白名单内；/dev/kmsg (1:11) 不在白名单内
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c",
+		"echo x > /dev/null && echo null-ok; "+
+			"mknod /tmp/allowed c 1 3 && echo mknod-allowed-ok; "+
+			"mknod /tmp/denied c 1 11 || echo mknod-denied")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{"null-ok", "mknod-allowed-ok", "mknod-denied"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected output to contain %q, got: %s", want, output)
+		}
+	}
+}
+
+// TestDeviceMapping 测试 --device 创建设备节点并按权限放行
+func TestDeviceMapping(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	if _, err := os.Stat("/dev/kmsg"); err != nil {
+		t.Skip("/dev/kmsg not available on host")
+	}
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+	skipIfNoMknod(t, rootfs)
+
+	stateRoot := t.TempDir()
+
+	// 只读映射：节点存在，写入和 mknod 均被设备控制器拒绝（不隐式授予 m）
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--device", "/dev/kmsg:/dev/mykmsg:r",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c",
+		"test -c /dev/mykmsg && echo node-ok; echo x > /dev/mykmsg || echo write-denied; "+
+			"mknod /tmp/kmsg c 1 11 || echo mknod-denied")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{"node-ok", "write-denied", "mknod-denied"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected output to contain %q, got: %s", want, output)
+		}
+	}
+}
+
+// TestPrivilegedLiftsAllowlist 测试 --privileged 解除设备白名单
+func TestPrivilegedLiftsAllowlist(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+	skipIfNoMknod(t, rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--privileged",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "mknod /tmp/kmsg c 1 11 && echo mknod-ok")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), "mknod-ok") {
+		t.Errorf("expected privileged container to mknod freely, got: %s", output)
+	}
+}
+
+// TestDeviceCgroupCreated 测试无资源限制时也会创建 cgroup（用于挂载设备白名单）
+func TestDeviceCgroupCreated(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"-d",
+		"--rootfs", rootfs,
+		"/bin/sleep", "10")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	cgroupPath := filepath.Join("/sys/fs/cgroup/minidocker", containerID)
+	if _, err := os.Stat(cgroupPath); err != nil {
+		t.Fatalf("cgroup directory should exist for device allowlist: %v", err)
+	}
+}
+
+// TestDeviceInvalidSpec 测试非法 --device 参数
+func TestDeviceInvalidSpec(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"not a device", "/etc/passwd"},
+		{"missing device", "/dev/does-not-exist"},
+		{"relative container path", "/dev/null:mynull:rw"},
+		{"bad permissions", "/dev/null:/dev/null:rwx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+				"--device", tt.spec,
+				"--rootfs", rootfs,
+				"/bin/true")
+			output, err := cmd.CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail for --device %q, got: %s", tt.spec, output)
+			}
+			if !strings.Contains(string(output), "invalid device") {
+				t.Errorf("expected device error, got: %s", output)
+			}
+		})
+	}
+}
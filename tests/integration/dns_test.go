@@ -0,0 +1,73 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 内嵌 DNS 集成测试
+//
+// 用户自定义网络上的容器 /etc/resolv.conf 指向 127.0.0.11，
+// 由 shim 在容器网络命名空间内提供的内嵌 DNS 按容器名/别名解析同网络容器。
+
+// TestEmbeddedDNS 测试 resolv.conf 注入、--network-alias 校验与容器名解析
+func TestEmbeddedDNS(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.126.0.0/24", "dnsnet")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "dnsnet", "--name", "web", "--network-alias", "api",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	ep, ok := inspectNetwork(t, stateRoot, "dnsnet").Containers[containerID]
+	if !ok || ep.IPAddress == "" {
+		t.Fatalf("expected web on dnsnet, got %+v", ep)
+	}
+
+	// 用户自定义网络：resolv.conf 指向内嵌 DNS
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "dnsnet",
+		"--rootfs", rootfs, "cat", "/etc/resolv.conf").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "nameserver 127.0.0.11") {
+		t.Errorf("expected embedded DNS in resolv.conf, got err=%v output=%s", err, out)
+	}
+
+	// 默认 bridge 网络不启用内嵌 DNS，也不接受 --network-alias
+	out, _ = exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "bridge",
+		"--rootfs", rootfs, "--", "sh", "-c", "cat /etc/resolv.conf 2>/dev/null; true").CombinedOutput()
+	if strings.Contains(string(out), "127.0.0.11") {
+		t.Errorf("expected no embedded DNS on default bridge, got: %s", out)
+	}
+	out, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "bridge",
+		"--network-alias", "x", "--rootfs", rootfs, "true").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "user-defined networks") {
+		t.Errorf("expected --network-alias error on default bridge, got err=%v output=%s", err, out)
+	}
+
+	// 按容器名和别名解析（需要 busybox nslookup）
+	if _, err := os.Stat(filepath.Join(rootfs, "bin", "busybox")); err != nil {
+		t.Skip("busybox not available in rootfs, skipping name resolution")
+	}
+	for _, name := range []string{"web", "api"} {
+		out, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "dnsnet",
+			"--rootfs", rootfs, "/bin/busybox", "nslookup", name).CombinedOutput()
+		if err != nil || !strings.Contains(string(out), ep.IPAddress) {
+			t.Errorf("expected %s to resolve to %s, got err=%v output=%s", name, ep.IPAddress, err, out)
+		}
+	}
+}
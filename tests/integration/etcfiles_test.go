@@ -0,0 +1,87 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 容器 /etc/hosts、/etc/resolv.conf、/etc/hostname 集成测试
+//
+// 三个文件由 runtime 在容器目录生成（网络配置完成、容器 IP 已知之后），
+// init 在 pivot_root 前 bind mount 到 rootfs/etc。
+
+// TestManagedEtcFiles 测试 --hostname/--add-host 写入 hosts，--dns* 写入 resolv.conf
+func TestManagedEtcFiles(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--name", "etc", "--hostname", "myhost", "--add-host", "db:10.0.0.5",
+		"--dns", "1.1.1.1", "--dns-search", "example.com", "--dns-option", "ndots:2",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	ip := inspectNetwork(t, stateRoot, "bridge").Containers[containerID].IPAddress
+	if ip == "" {
+		t.Fatalf("expected container on default bridge network")
+	}
+
+	containerDir := stateRoot + "/containers/" + containerID
+	hosts, err := os.ReadFile(containerDir + "/hosts")
+	if err != nil {
+		t.Fatalf("failed to read generated hosts: %v", err)
+	}
+	for _, want := range []string{"127.0.0.1\tlocalhost", "10.0.0.5\tdb", ip + "\tmyhost"} {
+		if !strings.Contains(string(hosts), want) {
+			t.Errorf("expected %q in hosts, got:\n%s", want, hosts)
+		}
+	}
+
+	resolv, err := os.ReadFile(containerDir + "/resolv.conf")
+	if err != nil {
+		t.Fatalf("failed to read generated resolv.conf: %v", err)
+	}
+	for _, want := range []string{"nameserver 1.1.1.1", "search example.com", "options ndots:2"} {
+		if !strings.Contains(string(resolv), want) {
+			t.Errorf("expected %q in resolv.conf, got:\n%s", want, resolv)
+		}
+	}
+
+	// 容器内看到的是生成的文件
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--hostname", "inner",
+		"--add-host", "cache:10.0.0.6", "--rootfs", rootfs,
+		"--", "sh", "-c", "cat /etc/hosts /etc/hostname").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "10.0.0.6\tcache") || !strings.Contains(string(out), "inner") {
+		t.Errorf("expected generated hosts/hostname inside container, got err=%v output=%s", err, out)
+	}
+
+	// 参数校验
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--dns", "not-an-ip"}, "invalid --dns"},
+		{[]string{"--add-host", "noip"}, "invalid --add-host"},
+		{[]string{"--network", "container:" + containerID, "--dns", "1.1.1.1"}, "conflicting options"},
+	} {
+		args := append([]string{"--root", stateRoot, "run"}, tc.args...)
+		args = append(args, "--rootfs", rootfs, "true")
+		out, err := exec.Command(minidockerBin, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), tc.want) {
+			t.Errorf("run %v: expected %q error, got err=%v output=%s", tc.args, tc.want, err, out)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 20: minidocker events 集成测试
+//
+// 生命周期事件追加写入 <root>/events.jsonl，events 命令回放（--since）并跟踪新事件。
+
+// eventRecord 对应 events --format json 的单行输出
+type eventRecord struct {
+	Type       string            `json:"type"`
+	Action     string            `json:"action"`
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// parseEvents 解析 JSON 行格式的事件输出
+func parseEvents(t *testing.T, output string) []eventRecord {
+	t.Helper()
+
+	var evs []eventRecord
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var ev eventRecord
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("failed to parse event %q: %v", line, err)
+		}
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+// eventActions 返回指定对象的事件动作序列
+func eventActions(evs []eventRecord, id string) []string {
+	var actions []string
+	for _, ev := range evs {
+		if ev.ID == id {
+			actions = append(actions, ev.Action)
+		}
+	}
+	return actions
+}
+
+// TestEventsFollow 测试 events 跟踪容器 create/start/die 事件并携带退出码
+func TestEventsFollow(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	var stdout bytes.Buffer
+	follow := exec.Command(minidockerBin, "--root", stateRoot, "events", "--format", "json")
+	follow.Stdout = &stdout
+	if err := follow.Start(); err != nil {
+		t.Fatalf("failed to start events: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	containerID := startDetached(t, stateRoot, rootfs, "--name", "evt", "/bin/sh", "-c", "exit 3")
+	time.Sleep(500 * time.Millisecond)
+
+	_ = follow.Process.Signal(os.Interrupt)
+	_ = follow.Wait()
+
+	evs := parseEvents(t, stdout.String())
+	actions := strings.Join(eventActions(evs, containerID), ",")
+	if actions != "create,start,die" {
+		t.Fatalf("expected create,start,die events, got %q\nOutput: %s", actions, stdout.String())
+	}
+	die := evs[len(evs)-1]
+	if die.Attributes["exitCode"] != "3" || die.Attributes["name"] != "evt" {
+		t.Errorf("unexpected die attributes: %v", die.Attributes)
+	}
+}
+
+// TestEventsSinceFilter 测试 --since/--until 回放与 --filter 组合
+func TestEventsSinceFilter(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "sleep", "30")
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "stop", "-t", "1", containerID).CombinedOutput(); err != nil {
+		t.Fatalf("minidocker stop failed: %v\nOutput: %s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "volume", "create", "evtvol").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker volume create failed: %v\nOutput: %s", err, out)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "events",
+		"--since", "1m", "--until", "0s", "--format", "json",
+		"--filter", "type=container,event=kill", "--filter", "event=stop").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker events failed: %v\nOutput: %s", err, output)
+	}
+
+	evs := parseEvents(t, string(output))
+	if actions := strings.Join(eventActions(evs, containerID), ","); actions != "kill,stop" {
+		t.Fatalf("expected kill,stop events, got %q\nOutput: %s", actions, output)
+	}
+	if evs[0].Attributes["signal"] != "15" {
+		t.Errorf("expected kill signal 15, got %v", evs[0].Attributes)
+	}
+
+	// 文本格式与 volume 过滤
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "events",
+		"--since", "1m", "--until", "0s", "--filter", "volume=evtvol").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker events failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "volume create evtvol") {
+		t.Errorf("expected volume create event, got: %s", output)
+	}
+}
+
+// TestEventsInvalidFilter 测试非法过滤条件报错
+func TestEventsInvalidFilter(t *testing.T) {
+	skipIfNotRoot(t)
+
+	stateRoot := t.TempDir()
+	for _, filter := range []string{"foo=bar", "type"} {
+		out, err := exec.Command(minidockerBin, "--root", stateRoot, "events", "--filter", filter).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), "invalid filter") {
+			t.Errorf("expected invalid filter error for %q, got err=%v output=%s", filter, err, out)
+		}
+	}
+}
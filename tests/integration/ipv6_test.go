@@ -0,0 +1,90 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 双栈网络集成测试
+//
+// network create --ipv6 为网络分配 IPv6 子网（独立的 ipam6-<id>.json），
+// 容器 eth0 同时配置 IPv4/IPv6 地址，--ip6 指定静态地址，-p [::1]:... 通过 ip6tables 发布。
+
+// TestDualStackNetwork 测试 IPv6 子网分配、--ip6 静态地址与参数校验
+func TestDualStackNetwork(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := os.Stat("/proc/sys/net/ipv6"); err != nil {
+		t.Skip("IPv6 not available on this host")
+	}
+	if _, err := exec.LookPath("ip6tables"); err != nil {
+		t.Skip("ip6tables not available")
+	}
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--ipv6", "--subnet", "10.127.0.0/24", "--subnet", "fd00:127::/64", "dual")
+	createNetwork(t, stateRoot, "--ipv6", "auto6")
+	createNetwork(t, stateRoot, "--subnet", "10.128.0.0/24", "v4only")
+
+	dual := inspectNetwork(t, stateRoot, "dual")
+	if !dual.EnableIPv6 || dual.SubnetV6 != "fd00:127::/64" || dual.GatewayV6 != "fd00:127::1" {
+		t.Errorf("unexpected IPv6 config: enableIPv6=%v subnet=%s gateway=%s", dual.EnableIPv6, dual.SubnetV6, dual.GatewayV6)
+	}
+	// 未指定 IPv6 子网时生成 ULA /64
+	if auto6 := inspectNetwork(t, stateRoot, "auto6"); !strings.HasPrefix(auto6.SubnetV6, "fd") || !strings.HasSuffix(auto6.SubnetV6, "::/64") {
+		t.Errorf("expected generated ULA /64 subnet, got %q", auto6.SubnetV6)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "dual", "--ip6", "fd00:127::100", "-p", "[::1]:18080:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	ep := inspectNetwork(t, stateRoot, "dual").Containers[containerID]
+	if ep.IPAddress == "" || ep.IPv6Address != "fd00:127::100" {
+		t.Fatalf("expected dual-stack endpoint with fd00:127::100, got %+v", ep)
+	}
+
+	// 动态分配的容器同样获得 IPv6 地址，容器内可见
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "dual",
+		"--rootfs", rootfs, "cat", "/proc/net/if_inet6").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "fd000127000000000000000000000002") {
+		t.Errorf("expected fd00:127::2 inside container, got err=%v output=%s", err, out)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--network", "v4only", "--ip6", "fd00::5"}, "not IPv6-enabled"},
+		{[]string{"--ip6", "fd00::5"}, "user-defined networks"},
+		{[]string{"--network", "dual", "--ip6", "10.0.0.1"}, "invalid --ip6"},
+		{[]string{"--network", "dual", "--ip6", "fd00:127::100"}, "already in use"},
+		{[]string{"--network", "dual", "--ip6", "fd00:999::1"}, "does not belong"},
+		{[]string{"--network", "v4only", "-p", "[::1]:18081:80"}, "no IPv6 address"},
+	} {
+		args := append([]string{"--root", stateRoot, "run"}, tc.args...)
+		args = append(args, "--rootfs", rootfs, "true")
+		out, err := exec.Command(minidockerBin, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), tc.want) {
+			t.Errorf("run %v: expected %q error, got err=%v output=%s", tc.args, tc.want, err, out)
+		}
+	}
+
+	out, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "create",
+		"--subnet", "fd00:128::/64", "noflag").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "requires --ipv6") {
+		t.Errorf("expected --ipv6 error, got err=%v output=%s", err, out)
+	}
+}
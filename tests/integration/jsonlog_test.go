@@ -0,0 +1,149 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 19: json-file 日志集成测试
+//
+// 容器输出由 shim / 前台 runtime 编码为每行一条的 JSON 记录（log、stream、time），
+// logs 命令据此支持 -t、--since/--until，并按流输出到 stdout/stderr。
+
+// runLogs 执行 logs 命令并分别返回 stdout 与 stderr
+func runLogs(t *testing.T, stateRoot string, args ...string) (string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(minidockerBin, append([]string{"--root", stateRoot, "logs"}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("minidocker logs %v failed: %v\nStderr: %s", args, err, stderr.String())
+	}
+	return stdout.String(), stderr.String()
+}
+
+// TestLogsStreams 测试 stdout/stderr 记录分别输出到对应的流
+func TestLogsStreams(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs,
+		"/bin/sh", "-c", "echo out1; echo err1 >&2; echo out2")
+
+	stdout, stderr := runLogs(t, stateRoot, containerID)
+	if stdout != "out1\nout2\n" {
+		t.Errorf("expected stdout records on stdout, got: %q", stdout)
+	}
+	if stderr != "err1\n" {
+		t.Errorf("expected stderr records on stderr, got: %q", stderr)
+	}
+}
+
+// TestLogsTimestamps 测试 logs -t 为每行加上 RFC 3339 时间戳
+func TestLogsTimestamps(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	before := time.Now().Add(-time.Second)
+	containerID := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "echo stamped")
+
+	stdout, _ := runLogs(t, stateRoot, "-t", containerID)
+	ts, line, ok := strings.Cut(strings.TrimSpace(stdout), " ")
+	if !ok || line != "stamped" {
+		t.Fatalf("expected '<timestamp> stamped', got: %q", stdout)
+	}
+	stamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		t.Fatalf("invalid timestamp %q: %v", ts, err)
+	}
+	if stamp.Before(before) || stamp.After(time.Now()) {
+		t.Errorf("timestamp %s out of range", stamp)
+	}
+}
+
+// TestLogsSinceUntil 测试 --since / --until 按时间过滤
+func TestLogsSinceUntil(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs,
+		"/bin/sh", "-c", "echo early; sleep 2; echo late")
+	time.Sleep(2 * time.Second)
+
+	stdout, _ := runLogs(t, stateRoot, "--since", "1s", containerID)
+	if stdout != "late\n" {
+		t.Errorf("expected only 'late' with --since 1s, got: %q", stdout)
+	}
+
+	stdout, _ = runLogs(t, stateRoot, "--until", "1s", containerID)
+	if stdout != "early\n" {
+		t.Errorf("expected only 'early' with --until 1s, got: %q", stdout)
+	}
+
+	stdout, _ = runLogs(t, stateRoot, "--since", "2000-01-01", "--until", "2000-01-02", containerID)
+	if stdout != "" {
+		t.Errorf("expected no logs for a range in the past, got: %q", stdout)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", "--since", "yesterday", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "invalid --since value") {
+		t.Errorf("expected invalid --since error, got: %v\nOutput: %s", err, output)
+	}
+}
+
+// TestLogsLegacyMigration 测试旧版本的原始日志在容器停止后被迁移为 json-file
+func TestLogsLegacyMigration(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "echo new_line")
+
+	// 模拟旧版本写入的原始日志
+	logDir := filepath.Join(stateRoot, "containers", containerID, "logs")
+	if err := os.WriteFile(filepath.Join(logDir, "stdout.log"), []byte("old_stdout\n"), 0644); err != nil {
+		t.Fatalf("write legacy log: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, "stderr.log"), []byte("old_stderr\n"), 0644); err != nil {
+		t.Fatalf("write legacy log: %v", err)
+	}
+
+	stdout, stderr := runLogs(t, stateRoot, containerID)
+	if stdout != "old_stdout\nnew_line\n" {
+		t.Errorf("expected legacy stdout before new records, got: %q", stdout)
+	}
+	if stderr != "old_stderr\n" {
+		t.Errorf("expected legacy stderr, got: %q", stderr)
+	}
+
+	for _, name := range []string{"stdout.log", "stderr.log"} {
+		if _, err := os.Stat(filepath.Join(logDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected legacy %s to be removed after migration, stat err: %v", name, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(logDir, "container-json.log"))
+	if err != nil || !strings.Contains(string(data), `"log":"old_stderr\n","stream":"stderr"`) {
+		t.Errorf("expected migrated records in container-json.log: %v\n%s", err, data)
+	}
+}
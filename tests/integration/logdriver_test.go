@@ -0,0 +1,184 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 19: 日志驱动集成测试
+//
+// syslog / journald 驱动把每行输出作为一个数据报发送到 Unix 套接字，
+// 测试中用本地监听的套接字代替 /dev/log 与 journald。
+
+// listenLogSocket 监听一个 Unix 数据报套接字，返回地址与接收到的消息
+func listenLogSocket(t *testing.T) (string, <-chan string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("listen on %s: %v", path, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	messages := make(chan string, 64)
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			messages <- string(buf[:n])
+		}
+	}()
+	return path, messages
+}
+
+// receiveMessages 收集 n 条消息
+func receiveMessages(t *testing.T, messages <-chan string, n int) []string {
+	t.Helper()
+
+	var result []string
+	for len(result) < n {
+		select {
+		case msg := <-messages:
+			result = append(result, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for log messages, got: %q", result)
+		}
+	}
+	return result
+}
+
+// TestLogDriverSyslog 测试 syslog 驱动的消息格式、优先级与 tag
+func TestLogDriverSyslog(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	address, messages := listenLogSocket(t)
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "none",
+		"--log-driver", "syslog",
+		"--log-opt", "syslog-address=unixgram://"+address,
+		"--log-opt", "syslog-facility=local0",
+		"--log-opt", "tag=web",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "echo to_stdout; echo to_stderr >&2").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	msgs := strings.Join(receiveMessages(t, messages, 2), "\n")
+	// local0(16)*8 + info(6) = 134；local0*8 + err(3) = 131
+	if !strings.Contains(msgs, "<134>") || !strings.Contains(msgs, "web[") || !strings.Contains(msgs, "]: to_stdout") {
+		t.Errorf("unexpected stdout syslog message: %q", msgs)
+	}
+	if !strings.Contains(msgs, "<131>") || !strings.Contains(msgs, "]: to_stderr") {
+		t.Errorf("unexpected stderr syslog message: %q", msgs)
+	}
+}
+
+// TestLogDriverJournald 测试 journald 驱动发送原生协议字段
+func TestLogDriverJournald(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	address, messages := listenLogSocket(t)
+
+	containerID := startDetached(t, stateRoot, rootfs,
+		"--name", "journal-test",
+		"--log-driver", "journald",
+		"--log-opt", "journald-address="+address,
+		"/bin/sh", "-c", "echo journal_line")
+
+	msg := receiveMessages(t, messages, 1)[0]
+	for _, field := range []string{
+		"MESSAGE=journal_line\n",
+		"PRIORITY=6\n",
+		"CONTAINER_ID=" + containerID[:12] + "\n",
+		"CONTAINER_ID_FULL=" + containerID + "\n",
+		"CONTAINER_NAME=journal-test\n",
+	} {
+		if !strings.Contains(msg, field) {
+			t.Errorf("expected journald field %q in message: %q", field, msg)
+		}
+	}
+
+	// 非 json-file 驱动的日志无法通过 logs 读回
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "does not support reading") {
+		t.Errorf("expected logs to fail for journald driver, got: %v\nOutput: %s", err, output)
+	}
+}
+
+// TestLogDriverNone 测试 none 驱动不写日志文件
+func TestLogDriverNone(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "--log-driver", "none", "/bin/sh", "-c", "echo discarded")
+
+	logFile := filepath.Join(stateRoot, "containers", containerID, "logs", "container-json.log")
+	if _, err := os.Stat(logFile); !os.IsNotExist(err) {
+		t.Errorf("expected no json log with --log-driver none, stat err: %v", err)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "does not support reading") {
+		t.Errorf("expected logs to fail for none driver, got: %v\nOutput: %s", err, output)
+	}
+}
+
+// TestLogDriverInvalid 测试非法的驱动与选项组合
+func TestLogDriverInvalid(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown driver", []string{"--log-driver", "fluentd"}, "unknown log driver"},
+		{"option for none", []string{"--log-driver", "none", "--log-opt", "max-size=1m"}, "does not accept any log-opt"},
+		{"json option for syslog", []string{"--log-driver", "syslog", "--log-opt", "max-size=1m"}, "unknown log-opt"},
+		{"bad facility", []string{"--log-driver", "syslog", "--log-opt", "syslog-facility=nope"}, "invalid log-opt syslog-facility"},
+		{"tcp address", []string{"--log-driver", "syslog", "--log-opt", "syslog-address=tcp://127.0.0.1:514"}, "invalid log-opt syslog-address"},
+		{"missing socket", []string{"--log-driver", "syslog", "--log-opt", "syslog-address=/nonexistent/log.sock"}, "connect to syslog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--root", stateRoot, "run", "--network", "none"}, tt.args...)
+			args = append(args, "--rootfs", rootfs, "/bin/true")
+			output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail, got: %s", output)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected error containing %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 19: 日志轮转集成测试
+//
+// --log-opt max-size/max-file 持久化到容器配置，由写日志的一方（shim 或前台 runtime）
+// 轮转；logs 与 logs -f 按从旧到新的顺序跨文件读取。
+
+// expectedLines 返回 line_0 ~ line_<n-1>，每行以换行结尾
+func expectedLines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line_%d\n", i)
+	}
+	return b.String()
+}
+
+// TestLogRotation 测试超过 max-size 后轮转，且只保留 max-file 个文件
+func TestLogRotation(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs,
+		"--log-opt", "max-size=1k", "--log-opt", "max-file=3",
+		"/bin/sh", "-c", "i=0; while [ $i -lt 200 ]; do echo line_$i; i=$((i+1)); done")
+	time.Sleep(time.Second)
+
+	logDir := filepath.Join(stateRoot, "containers", containerID, "logs")
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		t.Fatalf("read log dir: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+		info, err := e.Info()
+		if err == nil && info.Size() > 1024 {
+			t.Errorf("%s exceeds max-size: %d bytes", e.Name(), info.Size())
+		}
+	}
+	if strings.Join(names, ",") != "container-json.log,container-json.log.1,container-json.log.2" {
+		t.Errorf("expected 3 log files, got: %v", names)
+	}
+
+	// logs 按顺序输出保留下来的记录，且以最后一行结尾
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logs failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.HasSuffix(expectedLines(200), string(output)) || len(output) == 0 {
+		t.Errorf("expected a contiguous tail of the output, got: %q", output)
+	}
+}
+
+// TestLogRotationFollow 测试 logs -f 在轮转期间不丢失、不重复记录
+func TestLogRotationFollow(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs,
+		"--log-opt", "max-size=1k", "--log-opt", "max-file=3",
+		"/bin/sh", "-c", "sleep 1; i=0; while [ $i -lt 60 ]; do echo line_$i; i=$((i+1)); sleep 0.02; done")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", "-f", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logs -f failed: %v\nOutput: %s", err, output)
+	}
+	if string(output) != expectedLines(60) {
+		t.Errorf("expected every line exactly once across rotations, got: %q", output)
+	}
+}
+
+// TestLogOptInspectAndInvalid 测试 inspect 输出 LogConfig 以及非法的 --log-opt
+func TestLogOptInspectAndInvalid(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startSleeper(t, stateRoot, rootfs, "--log-opt", "max-size=10m", "--log-opt", "max-file=2")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "inspect", containerID).Output()
+	if err != nil {
+		t.Fatalf("inspect failed: %v\nOutput: %s", err, output)
+	}
+	var result []struct {
+		HostConfig struct {
+			LogConfig struct {
+				Type   string            `json:"Type"`
+				Config map[string]string `json:"Config"`
+			} `json:"LogConfig"`
+		} `json:"HostConfig"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil || len(result) != 1 {
+		t.Fatalf("failed to parse inspect output: %v\nOutput: %s", err, output)
+	}
+	lc := result[0].HostConfig.LogConfig
+	if lc.Type != "json-file" || lc.Config["max-size"] != "10m" || lc.Config["max-file"] != "2" {
+		t.Errorf("unexpected LogConfig: %+v", lc)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown option", []string{"--log-opt", "foo=bar"}, "unknown log-opt"},
+		{"bad size", []string{"--log-opt", "max-size=abc"}, "invalid log-opt max-size"},
+		{"bad file count", []string{"--log-opt", "max-size=1m", "--log-opt", "max-file=0"}, "invalid log-opt max-file"},
+		{"max-file without max-size", []string{"--log-opt", "max-file=3"}, "only be used together with max-size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--root", stateRoot, "run", "--network", "none"}, tt.args...)
+			args = append(args, "--rootfs", rootfs, "/bin/true")
+			output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail, got: %s", output)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected error containing %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
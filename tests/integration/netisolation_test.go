@@ -0,0 +1,116 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 容器网络限速与 icc 隔离集成测试
+//
+// --network-opt ingress-rate 在宿主机端 veth 上添加 tbf 队列；network create -o icc=false
+// 在 forward 链中丢弃 bridge 内容器之间的流量，同时保留经发布端口（DNAT）的访问。
+
+// TestNetworkRateLimit 测试 --network-opt 限速
+func TestNetworkRateLimit(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("tc"); err != nil {
+		t.Skip("tc not available")
+	}
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network-opt", "ingress-rate=10mbit", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	vethHost := "veth" + containerID[:8]
+	out, err := exec.Command("tc", "qdisc", "show", "dev", vethHost).CombinedOutput()
+	if err != nil {
+		t.Fatalf("tc qdisc show failed: %v\nOutput: %s", err, out)
+	}
+	if !strings.Contains(string(out), "tbf") || !strings.Contains(string(out), "rate 10Mbit") {
+		t.Errorf("expected tbf qdisc with rate 10Mbit on %s, got:\n%s", vethHost, out)
+	}
+
+	// 非 bridge 模式和无效速率报错
+	for _, args := range [][]string{
+		{"--network", "host", "--network-opt", "rate=10mbit"},
+		{"--network-opt", "rate=fast"},
+		{"--network-opt", "burst=10k"},
+	} {
+		runArgs := append([]string{"--root", stateRoot, "run", "-d"}, args...)
+		runArgs = append(runArgs, "--rootfs", rootfs, "sleep", "30")
+		if out, err := exec.Command(minidockerBin, runArgs...).CombinedOutput(); err == nil {
+			cleanupContainer(t, stateRoot, strings.TrimSpace(string(out)))
+			t.Errorf("expected run %v to fail", args)
+		}
+	}
+}
+
+// TestNetworkICCDisabled 测试 icc=false 网络的 forward 规则
+func TestNetworkICCDisabled(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not available")
+	}
+	t.Setenv("MINIDOCKER_FIREWALL_BACKEND", "nftables")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "-o", "icc=false", "--subnet", "10.138.0.0/24", "isolated")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "isolated", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	record := inspectNetwork(t, stateRoot, "isolated")
+	if !record.DisableICC {
+		t.Errorf("expected network inspect to report disableICC")
+	}
+	bridge := record.BridgeName
+	ruleset := listNftTable(t)
+	for _, want := range []string{
+		"iifname \"" + bridge + "\" oifname \"" + bridge + "\" ct status dnat accept",
+		"iifname \"" + bridge + "\" oifname \"" + bridge + "\" drop",
+	} {
+		if !strings.Contains(ruleset, want) {
+			t.Errorf("expected %q in nftables ruleset, got:\n%s", want, ruleset)
+		}
+	}
+	if v := readSysctl(t, "/proc/sys/net/bridge/bridge-nf-call-iptables"); v != "1" {
+		t.Errorf("expected bridge-nf-call-iptables=1, got %q", v)
+	}
+
+	// 未设置 icc 的网络不添加 drop 规则
+	createNetwork(t, stateRoot, "--subnet", "10.139.0.0/24", "open")
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "open", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	openID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, openID) })
+
+	openBridge := inspectNetwork(t, stateRoot, "open").BridgeName
+	if ruleset = listNftTable(t); strings.Contains(ruleset, "oifname \""+openBridge+"\" drop") {
+		t.Errorf("expected no icc drop rule for %s, got:\n%s", openBridge, ruleset)
+	}
+}
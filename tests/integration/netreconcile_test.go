@@ -0,0 +1,273 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// Phase 21: 网络状态恢复集成测试
+//
+// shim 崩溃后容器的 IP 分配、宿主机端口和端口映射规则残留；network reconcile 按容器状态
+// 释放这些资源，并删除 bridge 上不属于任何运行中容器的 veth。
+
+// TestNetworkReconcile 测试 shim 崩溃后的孤儿网络资源清理
+func TestNetworkReconcile(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not available")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("ip not available")
+	}
+	t.Setenv("MINIDOCKER_FIREWALL_BACKEND", "nftables")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.140.0.0/24", "recover")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "recover", "--ip", "10.140.0.10", "-p", "18092:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	// 模拟 shim 崩溃：先杀死 shim，再杀死容器进程，网络资源不会被 shim 清理
+	pid := containerPid(t, stateRoot, containerID)
+	ppid := parentPid(t, pid)
+	_ = syscall.Kill(ppid, syscall.SIGKILL)
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+	waitProcessGone(t, pid)
+
+	if ruleset := listNftTable(t); !strings.Contains(ruleset, containerID[:12]) {
+		t.Fatalf("expected container rules to survive the crash, got:\n%s", ruleset)
+	}
+
+	// 接入 bridge 的孤儿 veth（不属于任何容器）
+	bridge := inspectNetwork(t, stateRoot, "recover").BridgeName
+	if out, err := exec.Command("ip", "link", "add", "vethdeadbeef", "type", "veth", "peer", "name", "mdrecpeer0").CombinedOutput(); err != nil {
+		t.Fatalf("ip link add failed: %v\nOutput: %s", err, out)
+	}
+	t.Cleanup(func() { _ = exec.Command("ip", "link", "del", "vethdeadbeef").Run() })
+	if out, err := exec.Command("ip", "link", "set", "vethdeadbeef", "master", bridge).CombinedOutput(); err != nil {
+		t.Fatalf("ip link set master failed: %v\nOutput: %s", err, out)
+	}
+
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "reconcile").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker network reconcile failed: %v\nOutput: %s", err, output)
+	}
+	for _, want := range []string{
+		"released IP 10.140.0.10 on network recover",
+		"released host port 0.0.0.0:18092->80/tcp",
+		"removed orphaned veth vethdeadbeef from network recover",
+		"orphaned port mapping rules",
+	} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected %q in reconcile output, got:\n%s", want, output)
+		}
+	}
+
+	if ruleset := listNftTable(t); strings.Contains(ruleset, containerID[:12]) {
+		t.Errorf("expected container rules removed, got:\n%s", ruleset)
+	}
+	if err := exec.Command("ip", "link", "show", "vethdeadbeef").Run(); err == nil {
+		t.Errorf("expected orphaned veth vethdeadbeef to be removed")
+	}
+
+	// 已清理的资源不再重复报告
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "reconcile").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker network reconcile failed: %v\nOutput: %s", err, output)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		t.Errorf("expected second reconcile to be a no-op, got:\n%s", output)
+	}
+
+	// 释放的地址可以重新分配
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "recover", "--ip", "10.140.0.10", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("expected 10.140.0.10 to be reusable after reconcile: %v\nOutput: %s", err, output)
+	}
+	reusedID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, reusedID) })
+}
+
+// TestNetworkReconcileOnNextCommand 测试 shim 崩溃后下一条创建网络管理器的命令自动清理孤儿资源
+func TestNetworkReconcileOnNextCommand(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not available")
+	}
+	t.Setenv("MINIDOCKER_FIREWALL_BACKEND", "nftables")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.142.0.0/24", "autorecover")
+
+	runStatic := func() (string, error) {
+		output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+			"--network", "autorecover", "--ip", "10.142.0.10", "-p", "18093:80",
+			"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+		if err != nil {
+			return string(output), err
+		}
+		containerID := strings.TrimSpace(string(output))
+		t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+		return containerID, nil
+	}
+
+	containerID, err := runStatic()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, containerID)
+	}
+
+	// 本次开机已经执行过自动 reconcile，shim 崩溃遗留的地址和端口仍会被下一条命令清理
+	pid := containerPid(t, stateRoot, containerID)
+	ppid := parentPid(t, pid)
+	_ = syscall.Kill(ppid, syscall.SIGKILL)
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+	waitProcessGone(t, pid)
+
+	if output, err := runStatic(); err != nil {
+		t.Fatalf("expected the crashed container's address and port to be reclaimed: %v\nOutput: %s", err, output)
+	}
+	if ruleset := listNftTable(t); strings.Contains(ruleset, containerID[:12]) {
+		t.Errorf("expected crashed container rules removed, got:\n%s", ruleset)
+	}
+}
+
+// TestNetworkReconcilePidReuse 测试 PID 被其他进程复用（启动时间不同）时容器不再被视为运行中，
+// 且 reconcile 不改写容器状态
+func TestNetworkReconcilePidReuse(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not available")
+	}
+	t.Setenv("MINIDOCKER_FIREWALL_BACKEND", "nftables")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.141.0.0/24", "reuse")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "reuse", "--ip", "10.141.0.10", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	// 进程仍在运行时不清理
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "reconcile").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "" {
+		t.Fatalf("expected reconcile to keep a running container, got err=%v output=%s", err, output)
+	}
+
+	// 模拟宿主机重启后 PID 被复用：记录的启动时间与当前进程不同
+	statePath := filepath.Join(stateRoot, "containers", containerID, "state.json")
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("read state.json: %v", err)
+	}
+	var st map[string]interface{}
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatalf("parse state.json: %v", err)
+	}
+	if _, ok := st["pidStartTime"]; !ok {
+		t.Fatalf("expected pidStartTime in state.json, got:\n%s", data)
+	}
+	st["pidStartTime"] = 1
+	if data, err = json.Marshal(st); err != nil {
+		t.Fatalf("marshal state.json: %v", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		t.Fatalf("write state.json: %v", err)
+	}
+
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "reconcile").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker network reconcile failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(string(output), "released IP 10.141.0.10 on network reuse") {
+		t.Errorf("expected the reused PID to be treated as stopped, got:\n%s", output)
+	}
+
+	after, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("read state.json: %v", err)
+	}
+	if string(after) != string(data) {
+		t.Errorf("expected reconcile to leave state.json untouched, got:\n%s", after)
+	}
+}
+
+// containerPid 返回 state.json 中记录的容器 init 进程 PID
+func containerPid(t *testing.T, stateRoot, containerID string) int {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(stateRoot, "containers", containerID, "state.json"))
+	if err != nil {
+		t.Fatalf("read state.json: %v", err)
+	}
+	var st struct {
+		Pid int `json:"pid"`
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		t.Fatalf("parse state.json: %v", err)
+	}
+	if st.Pid == 0 {
+		t.Fatalf("container %s has no pid", containerID[:12])
+	}
+	return st.Pid
+}
+
+// parentPid 返回进程的父进程 PID（/proc/<pid>/stat 第 4 个字段）
+func parentPid(t *testing.T, pid int) int {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		t.Fatalf("read /proc/%d/stat: %v", pid, err)
+	}
+	// comm 字段可能包含空格，从最后一个 ')' 之后解析
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		t.Fatalf("parse ppid of %d: %v", pid, err)
+	}
+	return ppid
+}
+
+// waitProcessGone 等待进程退出并被回收
+func waitProcessGone(t *testing.T, pid int) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if err := syscall.Kill(pid, 0); err == syscall.ESRCH {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("process %d did not exit", pid)
+}
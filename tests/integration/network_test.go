@@ -537,8 +537,9 @@ func TestInvalidNetworkMode(t *testing.T) {
 		t.Fatal("Expected error for invalid network mode")
 	}
 
-	if !strings.Contains(string(output), "unsupported network mode") {
-		t.Errorf("Expected error message about unsupported mode, got: %s", output)
+	// Phase 21: 非内置模式按用户自定义网络名解析
+	if !strings.Contains(string(output), "network invalid not found") {
+		t.Errorf("Expected error message about unknown network, got: %s", output)
 	}
 }
 
@@ -556,7 +557,8 @@ func TestInvalidPortFormat(t *testing.T) {
 		portSpec  string
 		wantError string
 	}{
-		{"missing_container_port", "8080", "expected hostPort:containerPort"},
+		{"missing_container_port", "8080:", "invalid container port"},
+		{"mismatched_range", "8000-8001:80", "must have the same size"},
 		{"invalid_host_port", "abc:80", "invalid host port"},
 		{"invalid_container_port", "8080:xyz", "invalid container port"},
 		{"invalid_protocol", "8080:80/icmp", "unsupported protocol"},
@@ -0,0 +1,82 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: nftables 防火墙后端集成测试
+//
+// MINIDOCKER_FIREWALL_BACKEND=nftables 时 NAT、端口发布和转发规则写入独立的 minidocker 表，
+// 容器的 DNAT/转发规则位于 dnat-<id>/fwd-<id> 链，清理时只删除 minidocker 的规则。
+
+// TestNftablesBackend 测试 nftables 后端的规则编程与清理
+func TestNftablesBackend(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not available")
+	}
+	t.Setenv("MINIDOCKER_FIREWALL_BACKEND", "nftables")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.135.0.0/24", "nftnet")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "nftnet", "--ip", "10.135.0.10", "-p", "18090:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	bridge := inspectNetwork(t, stateRoot, "nftnet").BridgeName
+	ruleset := listNftTable(t)
+	for _, want := range []string{
+		"dnat-" + containerID[:12],
+		"dport 18090 dnat to 10.135.0.10:80",
+		"ip saddr 10.135.0.0/24 oifname != \"" + bridge + "\" masquerade",
+	} {
+		if !strings.Contains(ruleset, want) {
+			t.Errorf("expected %q in nftables ruleset, got:\n%s", want, ruleset)
+		}
+	}
+
+	// 删除容器后只清理该容器的链和跳转规则
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "rm", "-f", containerID).CombinedOutput(); err != nil {
+		t.Fatalf("minidocker rm failed: %v\nOutput: %s", err, out)
+	}
+	ruleset = listNftTable(t)
+	if strings.Contains(ruleset, containerID[:12]) {
+		t.Errorf("expected container rules removed, got:\n%s", ruleset)
+	}
+	if !strings.Contains(ruleset, bridge) {
+		t.Errorf("expected network rules kept until network rm, got:\n%s", ruleset)
+	}
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", "nftnet").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network rm failed: %v\nOutput: %s", err, out)
+	}
+	if ruleset = listNftTable(t); strings.Contains(ruleset, bridge) {
+		t.Errorf("expected network rules removed, got:\n%s", ruleset)
+	}
+}
+
+// listNftTable 返回 minidocker IPv4 表的规则
+func listNftTable(t *testing.T) string {
+	t.Helper()
+
+	out, err := exec.Command("nft", "list", "table", "ip", "minidocker").CombinedOutput()
+	if err != nil {
+		t.Fatalf("nft list table failed: %v\nOutput: %s", err, out)
+	}
+	return string(out)
+}
@@ -0,0 +1,178 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 15: 命名空间共享集成测试
+//
+// 覆盖 --network/--pid/--ipc/--uts container:<name|id>，
+// 并验证被共享的容器在依赖者运行期间不可删除。
+
+// startSleeper 启动一个后台容器作为共享目标，返回容器 ID
+func startSleeper(t *testing.T, stateRoot, rootfs string, extraArgs ...string) string {
+	t.Helper()
+
+	args := []string{"--root", stateRoot, "run", "-d", "--network", "none"}
+	args = append(args, extraArgs...)
+	args = append(args, "--rootfs", rootfs, "/bin/sleep", "30")
+
+	output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to start target container: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+	return containerID
+}
+
+// TestSharePidNamespace 测试 --pid container:<name> 可以看到目标容器的进程
+func TestSharePidNamespace(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	startSleeper(t, stateRoot, rootfs, "--name", "pidtarget")
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--pid", "container:pidtarget",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "cat /proc/*/cmdline")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), "sleep") {
+		t.Errorf("expected target's sleep process to be visible, got: %s", output)
+	}
+}
+
+// TestShareUTSNamespace 测试 --uts container:<id> 沿用目标容器的主机名
+func TestShareUTSNamespace(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	targetID := startSleeper(t, stateRoot, rootfs, "--hostname", "shared-host")
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--uts", "container:"+targetID[:12],
+		"--rootfs", rootfs,
+		"/bin/cat", "/proc/sys/kernel/hostname")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if strings.TrimSpace(string(output)) != "shared-host" {
+		t.Errorf("expected hostname shared-host, got: %s", output)
+	}
+}
+
+// TestShareNetworkNamespace 测试 --network container:<name> 加入目标的网络命名空间
+func TestShareNetworkNamespace(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	targetID := startSleeper(t, stateRoot, rootfs, "--name", "nettarget")
+
+	targetPID := readContainerPIDFromState(t, stateRoot, targetID)
+	targetNS, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", targetPID))
+	if err != nil {
+		t.Fatalf("read target net namespace: %v", err)
+	}
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "container:nettarget",
+		"--rootfs", rootfs,
+		"/bin/ls", "-l", "/proc/self/ns/net")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(string(output), targetNS) {
+		t.Errorf("expected net namespace %s, got: %s", targetNS, output)
+	}
+}
+
+// TestRmRefusedWhileDependentRuns 测试依赖者运行期间目标容器不可删除（-f 也不行）
+func TestRmRefusedWhileDependentRuns(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	targetID := startSleeper(t, stateRoot, rootfs)
+	dependentID := startSleeper(t, stateRoot, rootfs, "--ipc", "container:"+targetID)
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "rm", "-f", targetID).CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected rm of shared container to fail, got: %s", output)
+	}
+	if !strings.Contains(string(output), dependentID[:12]) {
+		t.Errorf("expected error to mention dependent %s, got: %s", dependentID[:12], output)
+	}
+
+	// 删除依赖者后，目标可以被删除
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "rm", "-f", dependentID).CombinedOutput(); err != nil {
+		t.Fatalf("failed to remove dependent: %v\nOutput: %s", err, output)
+	}
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "rm", "-f", targetID).CombinedOutput(); err != nil {
+		t.Fatalf("expected rm of target to succeed after dependent removed: %v\nOutput: %s", err, output)
+	}
+}
+
+// TestNamespaceModeInvalid 测试非法的命名空间共享参数
+func TestNamespaceModeInvalid(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"bad pid mode", []string{"--pid", "private"}, "invalid --pid mode"},
+		{"empty container", []string{"--ipc", "container:"}, "invalid --ipc mode"},
+		{"missing target", []string{"--pid", "container:nope"}, "container not found"},
+		{"hostname with shared uts", []string{"--uts", "host", "--hostname", "x"}, "conflicting options"},
+		{"publish with container network", []string{"--network", "container:nope", "-p", "8080:80"}, "only supported in bridge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--root", stateRoot, "run"}, tt.args...)
+			args = append(args, "--rootfs", rootfs, "/bin/true")
+			output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail, got: %s", output)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected error containing %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Phase 15: pod 集成测试
+//
+// pod 由一个 infra（pause）容器持有 network/IPC/UTS 命名空间，
+// 成员容器通过 run --pod 加入，并共享同一个 cgroup 父目录。
+
+// podInspect 是 pod inspect 输出中测试关心的字段
+type podInspect struct {
+	ID               string `json:"Id"`
+	Name             string `json:"Name"`
+	State            string `json:"State"`
+	CgroupParent     string `json:"CgroupParent"`
+	InfraContainerID string `json:"InfraContainerId"`
+	NumContainers    int    `json:"NumContainers"`
+}
+
+// createPod 创建 pod 并注册清理函数，返回 pod ID
+func createPod(t *testing.T, stateRoot, name string, extraArgs ...string) string {
+	t.Helper()
+
+	args := []string{"--root", stateRoot, "pod", "create", "--name", name, "--network", "none"}
+	args = append(args, extraArgs...)
+	output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("pod create failed: %v\nOutput: %s", err, output)
+	}
+
+	t.Cleanup(func() {
+		exec.Command(minidockerBin, "--root", stateRoot, "pod", "rm", "-f", name).Run()
+	})
+	return strings.TrimSpace(string(output))
+}
+
+// inspectPod 返回 pod inspect 的解析结果
+func inspectPod(t *testing.T, stateRoot, name string) podInspect {
+	t.Helper()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "inspect", name).Output()
+	if err != nil {
+		t.Fatalf("pod inspect failed: %v\nOutput: %s", err, output)
+	}
+
+	var pods []podInspect
+	if err := json.Unmarshal(output, &pods); err != nil || len(pods) != 1 {
+		t.Fatalf("failed to parse pod inspect output: %v\nOutput: %s", err, output)
+	}
+	return pods[0]
+}
+
+// TestPodSharedNamespaces 测试 pod 成员共享 infra 容器的主机名与网络命名空间
+func TestPodSharedNamespaces(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createPod(t, stateRoot, "web")
+
+	infraPID := readContainerPIDFromState(t, stateRoot, inspectPod(t, stateRoot, "web").InfraContainerID)
+	infraNetNS, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", infraPID))
+	if err != nil {
+		t.Fatalf("read infra net namespace: %v", err)
+	}
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--pod", "web",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "cat /proc/sys/kernel/hostname; ls -l /proc/self/ns/net")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run --pod failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.HasPrefix(string(output), "web\n") {
+		t.Errorf("expected pod hostname 'web', got: %s", output)
+	}
+	if !strings.Contains(string(output), infraNetNS) {
+		t.Errorf("expected member to join infra net namespace %s, got: %s", infraNetNS, output)
+	}
+}
+
+// TestPodPsAndInspect 测试 pod ps / pod inspect 反映成员容器
+func TestPodPsAndInspect(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	podID := createPod(t, stateRoot, "app")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--pod", "app", "--rootfs", rootfs, "/bin/sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run --pod failed: %v\nOutput: %s", err, output)
+	}
+
+	info := inspectPod(t, stateRoot, "app")
+	if info.ID != podID || info.State != "Running" {
+		t.Errorf("unexpected pod inspect result: %+v", info)
+	}
+	if info.NumContainers != 2 {
+		t.Errorf("expected 2 containers (infra + member), got %d", info.NumContainers)
+	}
+
+	psOutput, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "ps").CombinedOutput()
+	if err != nil {
+		t.Fatalf("pod ps failed: %v\nOutput: %s", err, psOutput)
+	}
+	if !strings.Contains(string(psOutput), podID[:12]) || !strings.Contains(string(psOutput), "app") {
+		t.Errorf("expected pod ps to list pod app, got: %s", psOutput)
+	}
+}
+
+// TestPodCgroupParent 测试 pod 成员的 cgroup 创建在 pod 的 cgroup 父目录下
+func TestPodCgroupParent(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createPod(t, stateRoot, "cg")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--pod", "cg", "--rootfs", rootfs, "/bin/sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run --pod failed: %v\nOutput: %s", err, output)
+	}
+	memberID := strings.TrimSpace(string(output))
+
+	parent := inspectPod(t, stateRoot, "cg").CgroupParent
+	if _, err := os.Stat(filepath.Join("/sys/fs/cgroup", parent, memberID)); err != nil {
+		t.Errorf("expected member cgroup under pod parent %s: %v", parent, err)
+	}
+
+	// pod rm -f 后 pod 的 cgroup 父目录被清理
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "rm", "-f", "cg").CombinedOutput(); err != nil {
+		t.Fatalf("pod rm failed: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Stat(filepath.Join("/sys/fs/cgroup", parent)); !os.IsNotExist(err) {
+		t.Errorf("expected pod cgroup %s to be removed, stat err: %v", parent, err)
+	}
+}
+
+// TestPodStopStartRm 测试 pod 生命周期：运行中拒绝删除，stop 后可重新 start，rm 删除所有容器
+func TestPodStopStartRm(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createPod(t, stateRoot, "life")
+
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--pod", "life", "--rootfs", rootfs, "/bin/sleep", "30").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker run --pod failed: %v\nOutput: %s", err, output)
+	}
+
+	// 运行中的 pod 不能直接删除
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "rm", "life").CombinedOutput(); err == nil {
+		t.Fatalf("expected pod rm of running pod to fail, got: %s", output)
+	}
+
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "stop", "-t", "1", "life").CombinedOutput(); err != nil {
+		t.Fatalf("pod stop failed: %v\nOutput: %s", err, output)
+	}
+	if state := inspectPod(t, stateRoot, "life").State; state != "Exited" {
+		t.Errorf("expected pod state Exited after stop, got %s", state)
+	}
+
+	oldInfra := inspectPod(t, stateRoot, "life").InfraContainerID
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "start", "life").CombinedOutput(); err != nil {
+		t.Fatalf("pod start failed: %v\nOutput: %s", err, output)
+	}
+	info := inspectPod(t, stateRoot, "life")
+	if info.State != "Running" || info.InfraContainerID == oldInfra {
+		t.Errorf("expected pod running with a new infra container, got: %+v", info)
+	}
+
+	if output, err := exec.Command(minidockerBin, "--root", stateRoot, "pod", "rm", "-f", "life").CombinedOutput(); err != nil {
+		t.Fatalf("pod rm -f failed: %v\nOutput: %s", err, output)
+	}
+
+	psOutput, _ := exec.Command(minidockerBin, "--root", stateRoot, "ps", "-a", "-q").CombinedOutput()
+	if strings.TrimSpace(string(psOutput)) != "" {
+		t.Errorf("expected all pod containers to be removed, got: %s", psOutput)
+	}
+}
+
+// TestPodRunConflicts 测试 run --pod 与命名空间/网络参数冲突
+func TestPodRunConflicts(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createPod(t, stateRoot, "conf")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"network", []string{"--pod", "conf", "--network", "host"}, "--network cannot be used with --pod"},
+		{"publish", []string{"--pod", "conf", "-p", "8080:80"}, "--publish cannot be used with --pod"},
+		{"hostname", []string{"--pod", "conf", "--hostname", "x"}, "--hostname cannot be used with --pod"},
+		{"missing pod", []string{"--pod", "nope"}, "pod not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--root", stateRoot, "run"}, tt.args...)
+			args = append(args, "--rootfs", rootfs, "/bin/true")
+			output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail, got: %s", output)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected error containing %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
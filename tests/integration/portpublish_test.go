@@ -0,0 +1,162 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 端口范围、随机宿主机端口与 port 命令集成测试
+//
+// -p 8000-8010:8000-8010 展开为多个映射；-p 80 从临时端口范围分配空闲宿主机端口，
+// 不同容器不会分到同一个端口；minidocker port 输出网络状态中的实际映射。
+
+// TestPortRangeAndRandomHostPort 测试端口范围发布、随机端口分配与 port 命令
+func TestPortRangeAndRandomHostPort(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	runWithPorts := func(ports ...string) string {
+		t.Helper()
+		args := []string{"--root", stateRoot, "run", "-d"}
+		for _, p := range ports {
+			args = append(args, "-p", p)
+		}
+		args = append(args, "--rootfs", rootfs, "sleep", "30")
+		output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+		}
+		containerID := strings.TrimSpace(string(output))
+		t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+		return containerID
+	}
+
+	first := runWithPorts("18200-18202:8200-8202/udp", "80")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "port", first).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker port failed: %v\nOutput: %s", err, output)
+	}
+	for i := 0; i < 3; i++ {
+		want := fmt.Sprintf("%d/udp -> 0.0.0.0:%d", 8200+i, 18200+i)
+		if !strings.Contains(string(output), want) {
+			t.Errorf("expected %q in port output, got:\n%s", want, output)
+		}
+	}
+
+	firstPort := randomHostPort(t, stateRoot, first, "80")
+	start, end := readPortRange(t)
+	if firstPort < start || firstPort > end {
+		t.Errorf("expected host port in ephemeral range %d-%d, got %d", start, end, firstPort)
+	}
+
+	// 其他容器分配的随机端口不与已发布的端口重复
+	second := runWithPorts("127.0.0.1::80")
+	secondAddr := portOutput(t, stateRoot, second, "80")
+	if !strings.HasPrefix(secondAddr, "127.0.0.1:") {
+		t.Errorf("expected mapping on 127.0.0.1, got %q", secondAddr)
+	}
+	if secondPort := randomHostPort(t, stateRoot, second, "80"); secondPort == firstPort {
+		t.Errorf("expected distinct host ports, both containers got %d", firstPort)
+	}
+
+	// 未发布的端口报错
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "port", first, "9999").CombinedOutput(); err == nil {
+		t.Errorf("expected error for unpublished port, got: %s", out)
+	}
+
+	// 范围大小不一致时报错
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"-p", "18300-18302:80-81", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err == nil {
+		cleanupContainer(t, stateRoot, strings.TrimSpace(string(output)))
+		t.Fatal("expected run to fail for mismatched port ranges")
+	}
+	if !strings.Contains(string(output), "must have the same size") {
+		t.Errorf("expected range size error, got: %s", output)
+	}
+}
+
+// TestDuplicateHostPort 测试同一宿主机端口不能被两个容器同时发布
+func TestDuplicateHostPort(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"-p", "18400:80", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	first := strings.TrimSpace(string(output))
+	defer cleanupContainer(t, stateRoot, first)
+
+	// 同一协议、重叠的宿主机地址：报错
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"-p", "127.0.0.1:18400:80", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err == nil {
+		cleanupContainer(t, stateRoot, strings.TrimSpace(string(output)))
+		t.Fatal("expected run to fail for an already allocated host port")
+	}
+	if !strings.Contains(string(output), "port is already allocated") {
+		t.Errorf("expected port is already allocated error, got: %s", output)
+	}
+
+	// 不同协议不冲突
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"-p", "18400:80/udp", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run with udp port failed: %v\nOutput: %s", err, output)
+	}
+	defer cleanupContainer(t, stateRoot, strings.TrimSpace(string(output)))
+}
+
+// portOutput 返回 minidocker port CONTAINER PORT 的第一行输出
+func portOutput(t *testing.T, stateRoot, containerID, port string) string {
+	t.Helper()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "port", containerID, port).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker port %s failed: %v\nOutput: %s", port, err, output)
+	}
+	return strings.SplitN(strings.TrimSpace(string(output)), "\n", 2)[0]
+}
+
+// randomHostPort 返回容器端口映射到的宿主机端口
+func randomHostPort(t *testing.T, stateRoot, containerID, port string) int {
+	t.Helper()
+
+	addr := portOutput(t, stateRoot, containerID, port)
+	hostPort, err := strconv.Atoi(addr[strings.LastIndex(addr, ":")+1:])
+	if err != nil {
+		t.Fatalf("unexpected port output %q", addr)
+	}
+	return hostPort
+}
+
+// readPortRange 返回内核临时端口范围
+func readPortRange(t *testing.T) (int, int) {
+	t.Helper()
+
+	fields := strings.Fields(readSysctl(t, "/proc/sys/net/ipv4/ip_local_port_range"))
+	if len(fields) != 2 {
+		t.Fatalf("unexpected ip_local_port_range: %v", fields)
+	}
+	start, _ := strconv.Atoi(fields[0])
+	end, _ := strconv.Atoi(fields[1])
+	return start, end
+}
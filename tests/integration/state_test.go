@@ -546,24 +546,17 @@ func TestLogCapture(t *testing.T) {
 	// 等待容器执行完成
 	time.Sleep(500 * time.Millisecond)
 
-	// 检查 stdout 日志
-	stdoutLog := filepath.Join(stateRoot, "containers", containerID, "logs", "stdout.log")
-	stdoutData, err := os.ReadFile(stdoutLog)
+	// Phase 19: 日志为 json-file 格式，stdout/stderr 记录写入同一文件并带流标签
+	logPath := filepath.Join(stateRoot, "containers", containerID, "logs", "container-json.log")
+	logData, err := os.ReadFile(logPath)
 	if err != nil {
-		t.Fatalf("Failed to read stdout.log: %v", err)
+		t.Fatalf("Failed to read container-json.log: %v", err)
 	}
-	if !strings.Contains(string(stdoutData), "hello_stdout") {
-		t.Errorf("Expected stdout.log to contain 'hello_stdout', got: %s", stdoutData)
+	if !strings.Contains(string(logData), `{"log":"hello_stdout\n","stream":"stdout"`) {
+		t.Errorf("Expected a stdout record for 'hello_stdout', got: %s", logData)
 	}
-
-	// 检查 stderr 日志
-	stderrLog := filepath.Join(stateRoot, "containers", containerID, "logs", "stderr.log")
-	stderrData, err := os.ReadFile(stderrLog)
-	if err != nil {
-		t.Fatalf("Failed to read stderr.log: %v", err)
-	}
-	if !strings.Contains(string(stderrData), "hello_stderr") {
-		t.Errorf("Expected stderr.log to contain 'hello_stderr', got: %s", stderrData)
+	if !strings.Contains(string(logData), `{"log":"hello_stderr\n","stream":"stderr"`) {
+		t.Errorf("Expected a stderr record for 'hello_stderr', got: %s", logData)
 	}
 }
 
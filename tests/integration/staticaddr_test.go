@@ -0,0 +1,80 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 静态地址与 MTU 集成测试
+//
+// --ip 由 IPAM 预留指定地址（冲突时报错），--mac-address 设置在容器端 veth 上，
+// network create --mtu 同时作用于 bridge 和容器网卡，三者都持久化在 NetworkState 中。
+
+// TestStaticAddressAndMTU 测试 --ip、--mac-address、--mtu 与参数校验
+func TestStaticAddressAndMTU(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.133.0.0/24", "--mtu", "1400", "static")
+
+	if mtu := inspectNetwork(t, stateRoot, "static").MTU; mtu != 1400 {
+		t.Errorf("expected network MTU 1400, got %d", mtu)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "static", "--ip", "10.133.0.50", "--mac-address", "02:42:0a:85:00:32",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	ep := inspectNetwork(t, stateRoot, "static").Containers[containerID]
+	if ep.IPAddress != "10.133.0.50" || ep.MacAddress != "02:42:0a:85:00:32" {
+		t.Fatalf("expected static IP and MAC, got %+v", ep)
+	}
+
+	// 容器网卡使用网络的 MTU
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "--network", "static",
+		"--rootfs", rootfs, "cat", "/sys/class/net/eth0/mtu").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "1400" {
+		t.Errorf("expected eth0 MTU 1400, got err=%v output=%s", err, out)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--network", "static", "--ip", "10.133.0.50"}, "already in use"},
+		{[]string{"--network", "static", "--ip", "10.9.0.50"}, "does not belong"},
+		{[]string{"--network", "static", "--ip", "10.133.0.0"}, "is reserved"},
+		{[]string{"--network", "static", "--ip", "10.133.0.1"}, "is reserved"},
+		{[]string{"--network", "static", "--ip", "10.133.0.255"}, "is reserved"},
+		{[]string{"--network", "static", "--ip", "fd00::5"}, "invalid --ip"},
+		{[]string{"--ip", "10.133.0.60"}, "user-defined networks"},
+		{[]string{"--mac-address", "03:42:0a:85:00:32"}, "unicast"},
+		{[]string{"--mac-address", "not-a-mac"}, "invalid --mac-address"},
+		{[]string{"--network", "host", "--mac-address", "02:42:0a:85:00:33"}, "bridge network mode"},
+	} {
+		args := append([]string{"--root", stateRoot, "run"}, tc.args...)
+		args = append(args, "--rootfs", rootfs, "true")
+		out, err := exec.Command(minidockerBin, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), tc.want) {
+			t.Errorf("run %v: expected %q error, got err=%v output=%s", tc.args, tc.want, err, out)
+		}
+	}
+
+	out, err = exec.Command(minidockerBin, "--root", stateRoot, "network", "create", "--mtu", "40", "badmtu").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "invalid MTU") {
+		t.Errorf("expected invalid MTU error, got err=%v output=%s", err, out)
+	}
+}
@@ -0,0 +1,115 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 20: minidocker stats 集成测试
+//
+// stats 从容器 cgroup 读取 CPU/内存/PIDS/块设备 I/O，CPU% 由相邻两次采样计算。
+
+// statsEntry 对应 stats --format json 的单行输出
+type statsEntry struct {
+	ID            string  `json:"Id"`
+	Name          string  `json:"Name"`
+	CPUPercent    float64 `json:"CPUPercent"`
+	MemoryUsage   int64   `json:"MemoryUsage"`
+	MemoryLimit   int64   `json:"MemoryLimit"`
+	MemoryPercent float64 `json:"MemoryPercent"`
+	Pids          int64   `json:"Pids"`
+}
+
+// TestStatsNoStreamJSON 测试 --no-stream --format json 输出内存限制、PIDS 与 CPU%
+func TestStatsNoStreamJSON(t *testing.T) {
+	skipIfNotRoot(t)
+	skipIfNotCgroupV2(t)
+	skipIfControllerMissing(t, "memory")
+	skipIfControllerMissing(t, "cpu")
+	skipIfControllerMissing(t, "pids")
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "--name", "busy", "--memory", "64m",
+		"/bin/sh", "-c", "while :; do :; done")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "stats", "--no-stream", "--format", "json", "busy").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker stats failed: %v\nOutput: %s", err, output)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one JSON line, got: %s", output)
+	}
+	var entry statsEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to parse stats JSON: %v\nOutput: %s", err, output)
+	}
+
+	if entry.ID != containerID || entry.Name != "busy" {
+		t.Errorf("unexpected container identity: %+v", entry)
+	}
+	if entry.MemoryLimit != 64*1024*1024 {
+		t.Errorf("expected memory limit 64MiB, got %d", entry.MemoryLimit)
+	}
+	if entry.MemoryUsage <= 0 || entry.MemoryPercent <= 0 {
+		t.Errorf("expected non-zero memory usage, got %+v", entry)
+	}
+	if entry.Pids < 1 {
+		t.Errorf("expected at least one pid, got %d", entry.Pids)
+	}
+	// 忙循环至少应占用半个核
+	if entry.CPUPercent < 50 {
+		t.Errorf("expected busy loop CPU%% >= 50, got %.2f", entry.CPUPercent)
+	}
+}
+
+// TestStatsStream 测试不加 --no-stream 时持续刷新表格
+func TestStatsStream(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "sleep", "30")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+	defer cancel()
+	output, _ := exec.CommandContext(ctx, minidockerBin, "--root", stateRoot, "stats").Output()
+
+	if n := strings.Count(string(output), "CONTAINER ID"); n < 2 {
+		t.Errorf("expected at least 2 refreshes, got %d\nOutput: %s", n, output)
+	}
+	if !strings.Contains(string(output), containerID[:12]) {
+		t.Errorf("expected container %s in stats output: %s", containerID[:12], output)
+	}
+}
+
+// TestStatsErrors 测试未知容器与未知格式报错
+func TestStatsErrors(t *testing.T) {
+	skipIfNotRoot(t)
+
+	stateRoot := t.TempDir()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "stats", "--no-stream", "nosuchcontainer").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "not found") {
+		t.Errorf("expected not found error, got err=%v output=%s", err, output)
+	}
+
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "stats", "--no-stream", "--format", "yaml").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "unknown format") {
+		t.Errorf("expected unknown format error, got err=%v output=%s", err, output)
+	}
+}
@@ -0,0 +1,107 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 20: minidocker top 集成测试
+//
+// top 从 cgroup.procs 或 /proc 枚举容器进程，不依赖镜像中的 ps。
+
+// runTop 执行 top 命令并返回输出
+func runTop(t *testing.T, stateRoot string, args ...string) string {
+	t.Helper()
+
+	output, err := exec.Command(minidockerBin, append([]string{"--root", stateRoot, "top"}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker top %v failed: %v\nOutput: %s", args, err, output)
+	}
+	return string(output)
+}
+
+// TestTopDefault 测试默认输出包含容器内 PID 与命令行
+func TestTopDefault(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "sleep 101 & sleep 102")
+
+	output := runTop(t, stateRoot, containerID)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if got := strings.Fields(lines[0]); strings.Join(got, " ") != "USER PID NSPID PPID TIME COMMAND" {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+
+	// 私有 PID 命名空间中的进程 NSPID 应远小于宿主机 PID
+	for _, want := range []string{"sleep 101", "sleep 102"} {
+		found := false
+		for _, line := range lines[1:] {
+			if !strings.HasSuffix(line, want) {
+				continue
+			}
+			found = true
+			fields := strings.Fields(line)
+			if fields[0] != "root" || fields[2] == fields[1] {
+				t.Errorf("expected root user and namespaced pid in %q", line)
+			}
+		}
+		if !found {
+			t.Errorf("expected %q in top output:\n%s", want, output)
+		}
+	}
+}
+
+// TestTopPSOptions 测试 ps 选项透传并只保留容器内进程
+func TestTopPSOptions(t *testing.T) {
+	skipIfNotRoot(t)
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("host ps not available")
+	}
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "sleep", "103")
+
+	output := runTop(t, stateRoot, containerID, "-eo", "pid,args")
+	if !strings.Contains(output, "sleep 103") {
+		t.Errorf("expected container process in ps output:\n%s", output)
+	}
+	if strings.Contains(output, "minidocker") && strings.Contains(output, " top ") {
+		t.Errorf("expected host processes to be filtered out:\n%s", output)
+	}
+
+	// 缺少 PID 列时报错
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "top", containerID, "-eo", "args").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "PID") {
+		t.Errorf("expected missing PID column error, got err=%v output=%s", err, out)
+	}
+}
+
+// TestTopStopped 测试对已停止的容器报错
+func TestTopStopped(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "true")
+	time.Sleep(500 * time.Millisecond)
+
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "top", containerID).CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "not running") {
+		t.Errorf("expected not running error, got err=%v output=%s", err, out)
+	}
+}
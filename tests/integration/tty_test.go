@@ -0,0 +1,152 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// Phase 18: run -t / -it / -dit 集成测试
+//
+// init 的 stdio 连接到 PTY slave：前台模式由 CLI 持有 master 并与本地终端交互，
+// 后台模式由 shim 持有 master 并把输出写入日志。
+
+// TestRunTTY 测试 run -t 时容器进程的 stdin/stdout 是终端
+func TestRunTTY(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run", "-t",
+		"--network", "none", "--rootfs", rootfs,
+		"/bin/sh", "-c", "test -t 0 && test -t 1 && echo tty_ok")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run -t failed: %v\nOutput: %s", err, output)
+	}
+
+	// PTY 输出使用 \r\n 换行
+	if !strings.Contains(string(output), "tty_ok\r\n") {
+		t.Errorf("expected tty_ok from a terminal, got: %q", output)
+	}
+}
+
+// TestRunInteractiveTTY 通过外层 PTY 驱动 run -it：窗口大小同步、交互输入、退出码透传
+func TestRunInteractiveTTY(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run", "-it",
+		"--network", "none", "--rootfs", rootfs, "/bin/sh")
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: 40, Cols: 100})
+	if err != nil {
+		t.Fatalf("start pty failed: %v", err)
+	}
+	defer ptmx.Close()
+
+	var buf bytes.Buffer
+	copyDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&buf, ptmx)
+		close(copyDone)
+	}()
+
+	_, _ = ptmx.Write([]byte("stty size; exit 7\n"))
+
+	waitDone := make(chan error, 1)
+	go func() { waitDone <- cmd.Wait() }()
+
+	select {
+	case err := <-waitDone:
+		select {
+		case <-copyDone:
+		case <-time.After(2 * time.Second):
+		}
+
+		out := buf.String()
+		if !strings.Contains(out, "40 100") {
+			t.Errorf("expected container tty size 40 100, got: %q", out)
+		}
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok || exitErr.ExitCode() != 7 {
+			t.Errorf("expected exit code 7, got: %v. Output: %q", err, out)
+		}
+	case <-time.After(10 * time.Second):
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		t.Fatalf("timeout waiting for run -it to finish. Output so far: %q", buf.String())
+	}
+}
+
+// TestRunInteractiveTTYRequiresTerminal 测试前台 -it 在 stdin 不是终端时报错
+func TestRunInteractiveTTYRequiresTerminal(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-it",
+		"--network", "none", "--rootfs", rootfs, "/bin/true").CombinedOutput()
+	if err == nil {
+		t.Fatalf("expected run -it without a terminal to fail, got: %s", output)
+	}
+	if !strings.Contains(string(output), "not a TTY") {
+		t.Errorf("expected 'not a TTY' error, got: %s", output)
+	}
+}
+
+// TestRunDetachedTTY 测试 run -dit：容器在后台持有 PTY，输出写入日志
+func TestRunDetachedTTY(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	// 后台 TTY 容器的 shell 读取 PTY 时会阻塞而不是读到 EOF 退出
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-dit",
+		"--network", "none", "--rootfs", rootfs,
+		"/bin/sh", "-c", "test -t 0 && echo detached_tty_ok; read line").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run -dit failed: %v\nOutput: %s", err, output)
+	}
+	shellID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, shellID) })
+
+	time.Sleep(500 * time.Millisecond)
+
+	psOutput, err := exec.Command(minidockerBin, "--root", stateRoot, "ps", "-q").CombinedOutput()
+	if err != nil {
+		t.Fatalf("ps failed: %v\nOutput: %s", err, psOutput)
+	}
+	if !strings.Contains(string(psOutput), shellID[:12]) {
+		t.Errorf("expected container %s to keep running with a tty, got: %s", shellID[:12], psOutput)
+	}
+
+	logs, err := exec.Command(minidockerBin, "--root", stateRoot, "logs", shellID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("logs failed: %v\nOutput: %s", err, logs)
+	}
+	if !strings.Contains(string(logs), "detached_tty_ok") {
+		t.Errorf("expected tty output in logs, got: %q", logs)
+	}
+}
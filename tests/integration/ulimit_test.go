@@ -0,0 +1,177 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 17: --ulimit / --sysctl 集成测试
+//
+// rlimit 由 init 在 exec 用户命令前通过 prlimit 设置；sysctl 写入容器 /proc/sys，
+// 之后 /proc/sys 被重新挂载为只读（--privileged 除外）。
+
+// TestUlimitNofile 测试 --ulimit nofile=soft:hard 对容器进程生效
+func TestUlimitNofile(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--ulimit", "nofile=1024:2048",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "ulimit -Sn; ulimit -Hn")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if strings.TrimSpace(string(output)) != "1024\n2048" {
+		t.Errorf("expected nofile soft=1024 hard=2048, got: %s", output)
+	}
+}
+
+// TestSysctlApplied 测试 --sysctl 写入容器命名空间，且不影响宿主机
+func TestSysctlApplied(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	hostValue, err := os.ReadFile("/proc/sys/kernel/msgmax")
+	if err != nil {
+		t.Skipf("kernel.msgmax not available: %v", err)
+	}
+
+	cmd := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none",
+		"--sysctl", "kernel.msgmax=12345",
+		"--sysctl", "net.ipv4.ip_unprivileged_port_start=80",
+		"--rootfs", rootfs,
+		"/bin/sh", "-c", "cat /proc/sys/kernel/msgmax /proc/sys/net/ipv4/ip_unprivileged_port_start")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+
+	if strings.TrimSpace(string(output)) != "12345\n80" {
+		t.Errorf("expected sysctls applied in container, got: %s", output)
+	}
+
+	after, _ := os.ReadFile("/proc/sys/kernel/msgmax")
+	if string(after) != string(hostValue) {
+		t.Errorf("host kernel.msgmax changed: %s -> %s", hostValue, after)
+	}
+}
+
+// TestProcSysReadOnly 测试非特权容器的 /proc/sys 只读，--privileged 时可写
+func TestProcSysReadOnly(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	script := "echo 4096 > /proc/sys/kernel/msgmax && echo writable"
+
+	output, _ := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none", "--rootfs", rootfs,
+		"/bin/sh", "-c", script).CombinedOutput()
+	if strings.Contains(string(output), "writable") {
+		t.Errorf("expected /proc/sys to be read-only, got: %s", output)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run",
+		"--network", "none", "--privileged", "--rootfs", rootfs,
+		"/bin/sh", "-c", script).CombinedOutput()
+	if err != nil || !strings.Contains(string(output), "writable") {
+		t.Errorf("expected /proc/sys to be writable with --privileged: %v\nOutput: %s", err, output)
+	}
+}
+
+// TestUlimitSysctlInspect 测试 inspect 输出 Ulimits 与 Sysctls
+func TestUlimitSysctlInspect(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startSleeper(t, stateRoot, rootfs,
+		"--ulimit", "core=0", "--sysctl", "kernel.shmmni=1024")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "inspect", containerID).Output()
+	if err != nil {
+		t.Fatalf("inspect failed: %v\nOutput: %s", err, output)
+	}
+
+	var result []struct {
+		HostConfig struct {
+			Ulimits []struct {
+				Name string `json:"name"`
+				Soft uint64 `json:"soft"`
+				Hard uint64 `json:"hard"`
+			} `json:"Ulimits"`
+			Sysctls map[string]string `json:"Sysctls"`
+		} `json:"HostConfig"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil || len(result) != 1 {
+		t.Fatalf("failed to parse inspect output: %v\nOutput: %s", err, output)
+	}
+
+	hc := result[0].HostConfig
+	if len(hc.Ulimits) != 1 || hc.Ulimits[0].Name != "core" || hc.Ulimits[0].Hard != 0 {
+		t.Errorf("unexpected Ulimits: %+v", hc.Ulimits)
+	}
+	if hc.Sysctls["kernel.shmmni"] != "1024" {
+		t.Errorf("unexpected Sysctls: %+v", hc.Sysctls)
+	}
+}
+
+// TestUlimitSysctlInvalid 测试非法的 --ulimit / --sysctl 参数
+func TestUlimitSysctlInvalid(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown ulimit", []string{"--ulimit", "bogus=1"}, "invalid ulimit type"},
+		{"soft above hard", []string{"--ulimit", "nofile=2048:1024"}, "soft limit must be less than or equal to hard limit"},
+		{"bad ulimit value", []string{"--ulimit", "nofile=abc"}, "invalid soft limit"},
+		{"sysctl without value", []string{"--sysctl", "net.core.somaxconn"}, "expected KEY=VALUE"},
+		{"non-namespaced sysctl", []string{"--sysctl", "kernel.panic=1"}, "only namespaced sysctls"},
+		{"net sysctl with host network", []string{"--network", "host", "--sysctl", "net.core.somaxconn=1024"}, "private network namespace"},
+		{"ipc sysctl with host ipc", []string{"--ipc", "host", "--sysctl", "kernel.shmmax=1"}, "private IPC namespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--root", stateRoot, "run", "--network", "none"}, tt.args...)
+			args = append(args, "--rootfs", rootfs, "/bin/true")
+			output, err := exec.Command(minidockerBin, args...).CombinedOutput()
+			if err == nil {
+				t.Fatalf("expected run to fail, got: %s", output)
+			}
+			if !strings.Contains(string(output), tt.want) {
+				t.Errorf("expected error containing %q, got: %s", tt.want, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 21: 用户态端口代理与 hairpin 集成测试
+//
+// 默认（userland-proxy 关闭）时 bridge 开启 route_localnet、容器 veth 开启 hairpin；
+// daemon.json 设置 userland-proxy 后，shim 在宿主机上监听发布端口，容器删除后释放端口。
+
+// TestHairpinWithoutUserlandProxy 测试默认模式下的 route_localnet 与 hairpin 设置
+func TestHairpinWithoutUserlandProxy(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.136.0.0/24", "hairpinnet")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "hairpinnet", "-p", "127.0.0.1:18100:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	bridge := inspectNetwork(t, stateRoot, "hairpinnet").BridgeName
+	if v := readSysctl(t, filepath.Join("/proc/sys/net/ipv4/conf", bridge, "route_localnet")); v != "1" {
+		t.Errorf("expected route_localnet=1 on %s, got %q", bridge, v)
+	}
+
+	ports, _ := filepath.Glob(filepath.Join("/sys/class/net", bridge, "brif", "*", "hairpin_mode"))
+	if len(ports) == 0 {
+		t.Fatalf("expected container veth attached to %s", bridge)
+	}
+	for _, port := range ports {
+		if v := readSysctl(t, port); v != "1" {
+			t.Errorf("expected %s=1, got %q", port, v)
+		}
+	}
+
+	// 未启用代理时宿主机上不监听发布端口
+	if conn, err := net.DialTimeout("tcp", "127.0.0.1:18100", time.Second); err == nil {
+		conn.Close()
+		t.Error("expected no userland proxy listening on 127.0.0.1:18100")
+	}
+}
+
+// TestUserlandProxy 测试启用 userland-proxy 后发布端口的监听与释放
+func TestUserlandProxy(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	if err := os.WriteFile(filepath.Join(stateRoot, "daemon.json"), []byte(`{"userland-proxy": true}`), 0644); err != nil {
+		t.Fatalf("write daemon.json: %v", err)
+	}
+	createNetwork(t, stateRoot, "--subnet", "10.137.0.0/24", "proxynet")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "proxynet", "-p", "127.0.0.1:18101:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:18101", time.Second)
+	if err != nil {
+		t.Fatalf("expected userland proxy listening on 127.0.0.1:18101: %v", err)
+	}
+	conn.Close()
+
+	// 端口已被代理占用时，第二个容器启动失败
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "proxynet", "-p", "127.0.0.1:18101:80",
+		"--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err == nil {
+		cleanupContainer(t, stateRoot, strings.TrimSpace(string(output)))
+		t.Fatal("expected run to fail when host port is already in use")
+	}
+	if !strings.Contains(string(output), "address already in use") {
+		t.Errorf("expected address in use error, got: %s", output)
+	}
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "rm", "-f", containerID).CombinedOutput(); err != nil {
+		t.Fatalf("minidocker rm failed: %v\nOutput: %s", err, out)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", "127.0.0.1:18101", time.Second)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("expected userland proxy to release 127.0.0.1:18101 after rm")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// readSysctl 读取 /proc/sys 或 /sys 下的单值文件
+func readSysctl(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return strings.TrimSpace(string(data))
+}
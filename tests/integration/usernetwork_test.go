@@ -0,0 +1,204 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Phase 21: 用户自定义网络集成测试
+//
+// network create/ls/inspect/rm 管理 <root>/network/networks.json 中的网络；
+// run --network <name> 接入独立的 bridge，network connect/disconnect 增删附加网卡。
+
+// networkInspectRecord 对应 network inspect 输出中的单个网络
+type networkInspectRecord struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	BridgeName string `json:"bridgeName"`
+	Subnet     string `json:"subnet"`
+	Gateway    string `json:"gateway"`
+	EnableIPv6 bool   `json:"enableIPv6"`
+	SubnetV6   string `json:"subnetV6"`
+	GatewayV6  string `json:"gatewayV6"`
+	MTU        int    `json:"mtu"`
+	DisableICC bool   `json:"disableICC"`
+	Containers map[string]struct {
+		Name        string `json:"name"`
+		Interface   string `json:"interface"`
+		IPAddress   string `json:"ipAddress"`
+		IPv6Address string `json:"ipv6Address"`
+		MacAddress  string `json:"macAddress"`
+	} `json:"containers"`
+}
+
+// inspectNetwork 返回 network inspect 的解析结果
+func inspectNetwork(t *testing.T, stateRoot, name string) networkInspectRecord {
+	t.Helper()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "inspect", name).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker network inspect failed: %v\nOutput: %s", err, output)
+	}
+	var records []networkInspectRecord
+	if err := json.Unmarshal(output, &records); err != nil || len(records) != 1 {
+		t.Fatalf("failed to parse network inspect output: %v\nOutput: %s", err, output)
+	}
+	return records[0]
+}
+
+// createNetwork 创建用户自定义网络并在测试结束时删除
+func createNetwork(t *testing.T, stateRoot string, args ...string) {
+	t.Helper()
+
+	cmdArgs := append([]string{"--root", stateRoot, "network", "create"}, args...)
+	if output, err := exec.Command(minidockerBin, cmdArgs...).CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network create failed: %v\nOutput: %s", err, output)
+	}
+	name := args[len(args)-1]
+	t.Cleanup(func() {
+		_ = exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", name).Run()
+	})
+}
+
+// TestNetworkCreateLsRm 测试网络注册表：子网分配、重名/保留名/重叠校验与删除
+func TestNetworkCreateLsRm(t *testing.T) {
+	skipIfNotRoot(t)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "mynet")
+	createNetwork(t, stateRoot, "--subnet", "10.123.0.0/24", "--gateway", "10.123.0.254", "backend")
+
+	mynet := inspectNetwork(t, stateRoot, "mynet")
+	if mynet.Subnet != "172.18.0.0/16" || mynet.Gateway != "172.18.0.1" {
+		t.Errorf("expected auto-allocated 172.18.0.0/16 via 172.18.0.1, got %s via %s", mynet.Subnet, mynet.Gateway)
+	}
+	if mynet.BridgeName != "md-"+mynet.ID[:12] {
+		t.Errorf("unexpected bridge name %q", mynet.BridgeName)
+	}
+	if backend := inspectNetwork(t, stateRoot, "backend"); backend.Gateway != "10.123.0.254" {
+		t.Errorf("expected gateway 10.123.0.254, got %s", backend.Gateway)
+	}
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "ls").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker network ls failed: %v\nOutput: %s", err, output)
+	}
+	for _, name := range []string{"bridge", "host", "none", "mynet", "backend"} {
+		if !strings.Contains(string(output), name) {
+			t.Errorf("expected network %s in ls output:\n%s", name, output)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"mynet"}, "already exists"},
+		{[]string{"host"}, "reserved"},
+		{[]string{"--subnet", "172.18.1.0/24", "overlap"}, "overlaps"},
+		{[]string{"--gateway", "10.0.0.1", "nogw"}, "requires --subnet"},
+		{[]string{"--subnet", "10.124.0.0/24", "--gateway", "10.124.0.0", "netgw"}, "reserved"},
+		{[]string{"--subnet", "10.124.0.0/24", "--gateway", "10.124.0.255", "bcastgw"}, "reserved"},
+	} {
+		args := append([]string{"--root", stateRoot, "network", "create"}, tc.args...)
+		out, err := exec.Command(minidockerBin, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), tc.want) {
+			t.Errorf("network create %v: expected %q error, got err=%v output=%s", tc.args, tc.want, err, out)
+		}
+	}
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", "bridge").CombinedOutput(); err == nil || !strings.Contains(string(out), "pre-defined") {
+		t.Errorf("expected pre-defined network error, got err=%v output=%s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", "mynet").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network rm failed: %v\nOutput: %s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "inspect", "mynet").CombinedOutput(); err == nil {
+		t.Errorf("expected removed network to be gone, got: %s", out)
+	}
+}
+
+// TestNetworkRunConnectDisconnect 测试 run --network、network connect/disconnect 与 IP 释放
+func TestNetworkRunConnectDisconnect(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	createNetwork(t, stateRoot, "--subnet", "10.124.0.0/24", "front")
+	createNetwork(t, stateRoot, "--subnet", "10.125.0.0/24", "back")
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "run", "-d",
+		"--network", "front", "--name", "web", "--rootfs", rootfs, "sleep", "30").CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker run failed: %v\nOutput: %s", err, output)
+	}
+	containerID := strings.TrimSpace(string(output))
+	t.Cleanup(func() { cleanupContainer(t, stateRoot, containerID) })
+
+	front := inspectNetwork(t, stateRoot, "front")
+	if ep, ok := front.Containers[containerID]; !ok || ep.Interface != "eth0" || !strings.HasPrefix(ep.IPAddress, "10.124.0.") {
+		t.Fatalf("expected web on front via eth0, got %+v", front.Containers)
+	}
+	if _, err := os.Stat("/sys/class/net/" + front.BridgeName); err != nil {
+		t.Errorf("expected bridge %s to exist: %v", front.BridgeName, err)
+	}
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "connect", "back", "web").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network connect failed: %v\nOutput: %s", err, out)
+	}
+	back := inspectNetwork(t, stateRoot, "back")
+	if ep, ok := back.Containers[containerID]; !ok || ep.Interface != "eth1" || !strings.HasPrefix(ep.IPAddress, "10.125.0.") {
+		t.Fatalf("expected web on back via eth1, got %+v", back.Containers)
+	}
+
+	// 容器内可以看到附加网卡
+	out, err := exec.Command(minidockerBin, "--root", stateRoot, "exec", "web", "cat", "/proc/net/dev").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "eth1") {
+		t.Errorf("expected eth1 inside container, got err=%v output=%s", err, out)
+	}
+
+	// 重复 connect、断开主网络、删除有活动端点的网络均报错
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "connect", "back", "web").CombinedOutput(); err == nil || !strings.Contains(string(out), "already connected") {
+		t.Errorf("expected already connected error, got err=%v output=%s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "disconnect", "front", "web").CombinedOutput(); err == nil || !strings.Contains(string(out), "primary network") {
+		t.Errorf("expected primary network error, got err=%v output=%s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", "back").CombinedOutput(); err == nil || !strings.Contains(string(out), "active endpoints") {
+		t.Errorf("expected active endpoints error, got err=%v output=%s", err, out)
+	}
+
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "disconnect", "back", "web").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network disconnect failed: %v\nOutput: %s", err, out)
+	}
+	if back := inspectNetwork(t, stateRoot, "back"); len(back.Containers) != 0 {
+		t.Errorf("expected no containers on back after disconnect, got %+v", back.Containers)
+	}
+	vethHost := "vn" + containerID[:6] + back.ID[:7]
+	if _, err := os.Stat("/sys/class/net/" + vethHost); err == nil {
+		t.Errorf("expected host veth %s to be removed after disconnect", vethHost)
+	}
+
+	// 重新接入后停止容器：附加网卡的 IP 随容器退出释放，网络可以删除
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "connect", "back", "web").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker network connect failed: %v\nOutput: %s", err, out)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "stop", "-t", "1", "web").CombinedOutput(); err != nil {
+		t.Fatalf("minidocker stop failed: %v\nOutput: %s", err, out)
+	}
+	data, err := os.ReadFile(stateRoot + "/network/ipam-" + back.ID + ".json")
+	if err != nil || strings.Contains(string(data), containerID) {
+		t.Errorf("expected back IPAM allocation to be released, got err=%v data=%s", err, data)
+	}
+	if out, err := exec.Command(minidockerBin, "--root", stateRoot, "network", "rm", "back").CombinedOutput(); err != nil {
+		t.Errorf("minidocker network rm failed: %v\nOutput: %s", err, out)
+	}
+}
@@ -0,0 +1,112 @@
+//go:build integration && linux
+// +build integration,linux
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Phase 20: minidocker wait 集成测试
+//
+// wait 监听 state.json 的更新，在容器满足 --condition 时按参数顺序输出退出码。
+
+// TestWaitExitCodes 测试 wait 阻塞到容器退出并按顺序输出多个容器的退出码
+func TestWaitExitCodes(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	slow := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "sleep 1; exit 7")
+	fast := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "exit 2")
+
+	start := time.Now()
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "wait", slow, fast).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker wait failed: %v\nOutput: %s", err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "7\n2" {
+		t.Errorf("expected exit codes 7 and 2, got %q", got)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("wait took too long: %v", time.Since(start))
+	}
+
+	// 已停止的容器立即返回
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "wait", fast).CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "2" {
+		t.Errorf("expected immediate exit code 2, got err=%v output=%s", err, output)
+	}
+}
+
+// TestWaitSignaled 测试被信号杀死的容器报告 128+signal
+func TestWaitSignaled(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "sleep", "30")
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		_ = exec.Command(minidockerBin, "--root", stateRoot, "kill", containerID).Run()
+	}()
+
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "wait", containerID).CombinedOutput()
+	if err != nil {
+		t.Fatalf("minidocker wait failed: %v\nOutput: %s", err, output)
+	}
+	if got := strings.TrimSpace(string(output)); got != "137" {
+		t.Errorf("expected exit code 137 after SIGKILL, got %q", got)
+	}
+}
+
+// TestWaitConditions 测试 --condition removed 与 next-exit
+func TestWaitConditions(t *testing.T) {
+	skipIfNotRoot(t)
+
+	rootfs := prepareMinimalRootfs(t)
+	defer os.RemoveAll(rootfs)
+
+	stateRoot := t.TempDir()
+	containerID := startDetached(t, stateRoot, rootfs, "/bin/sh", "-c", "exit 4")
+
+	// removed：等待 rm 后输出最后的退出码
+	go func() {
+		time.Sleep(time.Second)
+		_ = exec.Command(minidockerBin, "--root", stateRoot, "rm", containerID).Run()
+	}()
+	output, err := exec.Command(minidockerBin, "--root", stateRoot, "wait", "--condition", "removed", containerID).CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "4" {
+		t.Errorf("expected exit code 4 after removal, got err=%v output=%s", err, output)
+	}
+
+	// next-exit：已停止的容器不会立即返回，被删除时报错
+	stopped := startDetached(t, stateRoot, rootfs, "true")
+	go func() {
+		time.Sleep(time.Second)
+		_ = exec.Command(minidockerBin, "--root", stateRoot, "rm", stopped).Run()
+	}()
+	start := time.Now()
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "wait", "--condition", "next-exit", stopped).CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "removed") {
+		t.Errorf("expected removed error for next-exit, got err=%v output=%s", err, output)
+	}
+	if time.Since(start) < 500*time.Millisecond {
+		t.Errorf("next-exit returned immediately for a stopped container")
+	}
+
+	// 非法条件
+	output, err = exec.Command(minidockerBin, "--root", stateRoot, "wait", "--condition", "bogus", "x").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "invalid condition") {
+		t.Errorf("expected invalid condition error, got err=%v output=%s", err, output)
+	}
+}